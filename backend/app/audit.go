@@ -0,0 +1,93 @@
+package app
+
+import (
+	"context"
+	"microservicetest/domain"
+	"time"
+)
+
+// Actions recorded in the audit log
+const (
+	AuditActionCreate             = "create"
+	AuditActionUpdate             = "update"
+	AuditActionDelete             = "delete"
+	AuditActionRestore            = "restore"
+	AuditActionReportStolen       = "report_stolen"
+	AuditActionRenewInsurance     = "renew_insurance"
+	AuditActionAddDocument        = "add_document"
+	AuditActionAppendDocumentFile = "append_document_file"
+	AuditActionDeleteDocument     = "delete_document"
+	AuditActionAddPictures        = "add_pictures"
+	AuditActionDeletePictures     = "delete_pictures"
+	AuditActionAddServiceRecord   = "add_service_record"
+)
+
+// AllAuditActions returns every audit action constant
+func AllAuditActions() []string {
+	return []string{
+		AuditActionCreate,
+		AuditActionUpdate,
+		AuditActionDelete,
+		AuditActionRestore,
+		AuditActionReportStolen,
+		AuditActionRenewInsurance,
+		AuditActionAddDocument,
+		AuditActionAppendDocumentFile,
+		AuditActionDeleteDocument,
+		AuditActionAddPictures,
+		AuditActionDeletePictures,
+		AuditActionAddServiceRecord,
+	}
+}
+
+// AuditEntry records who changed a vehicle, when, and for updates which fields
+type AuditEntry struct {
+	ID         string               `json:"id"`
+	VehicleID  string               `json:"vehicle_id"`
+	Action     string               `json:"action"`
+	Actor      string               `json:"actor"`
+	OccurredAt time.Time            `json:"occurred_at"`
+	Changes    []domain.FieldChange `json:"changes,omitempty"`
+	Override   *AuditOverride       `json:"override,omitempty"` // Set when a service let the change past a business rule
+	Status     string               `json:"status,omitempty"`   // Empty once the change is made, see AuditStatusPending
+}
+
+// Statuses of entries a FailClosedAuditLog writes before their change
+const (
+	AuditStatusPending = "pending" // The change was being made when the entry was written
+	AuditStatusFailed  = "failed"  // The change was not made
+)
+
+// AuditOverride records a business rule a change was let past and what the
+// rule would have required
+type AuditOverride struct {
+	Rule    string   `json:"rule"`
+	Service string   `json:"service"` // Service that asked for the override
+	Skipped []string `json:"skipped"`
+}
+
+// AuditFilter narrows a vehicle's audit entries. Empty fields do not filter.
+type AuditFilter struct {
+	Action string
+	Actor  string
+	From   time.Time // Inclusive
+	To     time.Time // Exclusive
+	Limit  int
+	Offset int
+}
+
+// AuditLog keeps the change history of vehicles
+type AuditLog interface {
+	// Record stores the entry, replacing an earlier one with the same ID
+	Record(ctx context.Context, entry AuditEntry) error
+	// List returns one page of a vehicle's entries, newest first, plus the
+	// number of entries matching the filter
+	List(ctx context.Context, vehicleID string, filter AuditFilter) ([]AuditEntry, int, error)
+}
+
+// FailClosedAuditLog is an audit log a change must not be made without. Its
+// entries are written as pending before the change, which is refused when that
+// write fails, and completed or marked failed once the change is done.
+type FailClosedAuditLog struct {
+	AuditLog
+}
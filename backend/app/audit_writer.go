@@ -0,0 +1,111 @@
+package app
+
+import (
+	"context"
+	"microservicetest/pkg/log"
+	"microservicetest/pkg/metrics"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// auditWriteTimeout bounds each background write to the audit store
+const auditWriteTimeout = 10 * time.Second
+
+// queuedAuditEntry keeps the request logger so failures are logged with the request ID
+type queuedAuditEntry struct {
+	entry  AuditEntry
+	logger *zap.Logger
+}
+
+// AsyncAuditLog records entries in the background, so audit writes add no
+// latency to requests and an unavailable audit store never fails them.
+// Entries wait in a bounded queue; when it is full, or the store rejects an
+// entry, the entry is logged in full and dropped. List passes straight through.
+type AsyncAuditLog struct {
+	AuditLog
+	mu     sync.RWMutex // Guards closed against queue being closed mid-send
+	closed bool
+	queue  chan queuedAuditEntry
+	done   chan struct{}
+}
+
+// NewAsyncAuditLog starts the writer with room for queueSize pending entries
+func NewAsyncAuditLog(auditLog AuditLog, queueSize int) *AsyncAuditLog {
+	l := &AsyncAuditLog{
+		AuditLog: auditLog,
+		queue:    make(chan queuedAuditEntry, queueSize),
+		done:     make(chan struct{}),
+	}
+	go l.run()
+	return l
+}
+
+// Record queues the entry and never fails
+func (l *AsyncAuditLog) Record(ctx context.Context, entry AuditEntry) error {
+	logger := log.FromContext(ctx)
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.closed {
+		metrics.AuditEntriesDropped.Add(1)
+		logger.Error("Audit log closed, dropping entry", AuditEntryFields(entry)...)
+		return nil
+	}
+
+	select {
+	case l.queue <- queuedAuditEntry{entry: entry, logger: logger}:
+		metrics.AuditQueueDepth.Add(1)
+	default:
+		metrics.AuditEntriesDropped.Add(1)
+		logger.Error("Audit queue full, dropping entry", AuditEntryFields(entry)...)
+	}
+	return nil
+}
+
+// Close stops accepting entries and waits up to timeout for the queued ones to
+// be written. Entries recorded afterwards are logged and dropped.
+func (l *AsyncAuditLog) Close(timeout time.Duration) {
+	l.mu.Lock()
+	l.closed = true
+	close(l.queue)
+	l.mu.Unlock()
+
+	select {
+	case <-l.done:
+	case <-time.After(timeout):
+		zap.L().Warn("Audit entries still queued at shutdown", zap.Int("queued", len(l.queue)))
+	}
+}
+
+func (l *AsyncAuditLog) run() {
+	defer close(l.done)
+
+	for queued := range l.queue {
+		metrics.AuditQueueDepth.Add(-1)
+
+		ctx, cancel := context.WithTimeout(log.WithLogger(context.Background(), queued.logger), auditWriteTimeout)
+		err := l.AuditLog.Record(ctx, queued.entry)
+		cancel()
+
+		if err != nil {
+			metrics.AuditEntriesDropped.Add(1)
+			queued.logger.Error("Failed to write audit entry", append(AuditEntryFields(queued.entry), zap.Error(err))...)
+		}
+	}
+}
+
+// AuditEntryFields logs the whole entry so a dropped one can be recovered from the logs
+func AuditEntryFields(entry AuditEntry) []zap.Field {
+	return []zap.Field{
+		zap.String("audit_id", entry.ID),
+		zap.String("vehicle_id", entry.VehicleID),
+		zap.String("action", entry.Action),
+		zap.String("actor", entry.Actor),
+		zap.Time("occurred_at", entry.OccurredAt),
+		zap.Any("changes", entry.Changes),
+		zap.String("status", entry.Status),
+	}
+}
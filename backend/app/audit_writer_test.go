@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"microservicetest/pkg/metrics"
+	"sync"
+	"testing"
+	"time"
+)
+
+// failingAuditLog stands in for an unavailable audit store
+type failingAuditLog struct {
+	AuditLog
+	mu       sync.Mutex
+	attempts []AuditEntry
+	release  chan struct{} // When set, writes wait until it is closed
+}
+
+func (l *failingAuditLog) Record(ctx context.Context, entry AuditEntry) error {
+	if l.release != nil {
+		<-l.release
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.attempts = append(l.attempts, entry)
+	return errors.New("audit store unavailable")
+}
+
+func TestAsyncAuditLog_StoreFailureDoesNotFailRecord(t *testing.T) {
+	store := &failingAuditLog{}
+	auditLog := NewAsyncAuditLog(store, 10)
+	dropped := metrics.AuditEntriesDropped.Value()
+
+	for _, id := range []string{"a1", "a2"} {
+		if err := auditLog.Record(context.Background(), AuditEntry{ID: id, VehicleID: "VEH_1"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	auditLog.Close(time.Second)
+
+	if len(store.attempts) != 2 {
+		t.Errorf("Expected both entries to be attempted, got %d", len(store.attempts))
+	}
+	if got := metrics.AuditEntriesDropped.Value() - dropped; got != 2 {
+		t.Errorf("Expected 2 dropped entries, got %d", got)
+	}
+}
+
+func TestAsyncAuditLog_DropsWhenQueueFull(t *testing.T) {
+	store := &failingAuditLog{release: make(chan struct{})}
+	auditLog := NewAsyncAuditLog(store, 1)
+	dropped := metrics.AuditEntriesDropped.Value()
+
+	// The writer takes the first entry and blocks on it, the second fills the queue
+	auditLog.Record(context.Background(), AuditEntry{ID: "a1"})
+	deadline := time.Now().Add(time.Second)
+	for metrics.AuditQueueDepth.Value() != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	auditLog.Record(context.Background(), AuditEntry{ID: "a2"})
+
+	start := time.Now()
+	if err := auditLog.Record(context.Background(), AuditEntry{ID: "a3"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if time.Since(start) > 100*time.Millisecond {
+		t.Error("Expected a full queue not to block the caller")
+	}
+	if got := metrics.AuditEntriesDropped.Value() - dropped; got != 1 {
+		t.Errorf("Expected the third entry to be dropped, got %d drops", got)
+	}
+
+	close(store.release)
+	auditLog.Close(time.Second)
+}
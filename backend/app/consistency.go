@@ -0,0 +1,17 @@
+package app
+
+import "context"
+
+type consistentReadsContextKey struct{}
+
+// WithConsistentReads asks the queries made with ctx to wait until the index
+// has caught up with every write made before them (read-your-writes)
+func WithConsistentReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, consistentReadsContextKey{}, true)
+}
+
+// ConsistentReads reports whether ctx asks for read-your-writes queries
+func ConsistentReads(ctx context.Context) bool {
+	consistent, _ := ctx.Value(consistentReadsContextKey{}).(bool)
+	return consistent
+}
@@ -0,0 +1,20 @@
+package app
+
+import (
+	"context"
+	"time"
+)
+
+// Event is a domain event other parts of the system (e.g. owner notifications) react to
+type Event struct {
+	Type       string            `json:"type"`
+	VehicleID  string            `json:"vehicle_id"`
+	OwnerID    string            `json:"owner_id,omitempty"`
+	OccurredAt time.Time         `json:"occurred_at"`
+	Data       map[string]string `json:"data,omitempty"`
+}
+
+// EventPublisher delivers events to whoever consumes them
+type EventPublisher interface {
+	Publish(ctx context.Context, event Event) error
+}
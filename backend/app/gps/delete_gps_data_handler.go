@@ -0,0 +1,75 @@
+package gps
+
+import (
+	"context"
+	"fmt"
+	"microservicetest/app"
+	cosmosdb "microservicetest/infra/cosmos"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/validator"
+	"time"
+)
+
+// maxGPSDeleteDays bounds the days one delete may cover, so a mistyped range
+// cannot wipe a device's whole history
+const maxGPSDeleteDays = 31
+
+type DeleteGPSDataRequest struct {
+	DeviceID  string `query:"device_id" validate:"required"`
+	StartDate string `query:"start_date" validate:"required"` // Format: 2006-01-02
+	EndDate   string `query:"end_date" validate:"required"`   // Format: 2006-01-02, inclusive
+}
+
+type DeleteGPSDataResponse struct {
+	Deleted int `json:"deleted"`
+}
+
+type DeleteGPSDataHandler struct {
+	repository *cosmosdb.GPSRepository
+}
+
+func NewDeleteGPSDataHandler(repository *cosmosdb.GPSRepository) *DeleteGPSDataHandler {
+	return &DeleteGPSDataHandler{
+		repository: repository,
+	}
+}
+
+// Handle deletes a device's GPS points between two dates, for corrections and
+// erasure requests. Only backend services calling with an API key may delete.
+func (h *DeleteGPSDataHandler) Handle(ctx context.Context, req *DeleteGPSDataRequest) (*DeleteGPSDataResponse, error) {
+	if _, ok := app.ServiceFromContext(ctx); !ok {
+		return nil, apperrors.ErrUnauthorized.WithDetails(map[string]string{
+			"header": "X-API-Key",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.ErrInvalidInput.WithDetails(map[string]string{
+			"validation": err.Error(),
+		})
+	}
+
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, apperrors.NewValidationError("start_date", "expected format YYYY-MM-DD")
+	}
+	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return nil, apperrors.NewValidationError("end_date", "expected format YYYY-MM-DD")
+	}
+	if endDate.Before(startDate) {
+		return nil, apperrors.NewValidationError("end_date", "must not be before start_date")
+	}
+	if endDate.Sub(startDate) >= maxGPSDeleteDays*24*time.Hour {
+		return nil, apperrors.NewValidationError("end_date", fmt.Sprintf("the range may cover at most %d days", maxGPSDeleteDays))
+	}
+	// Set to end of day
+	endDate = endDate.Add(24*time.Hour - time.Nanosecond)
+
+	deleted, err := h.repository.DeleteGPSDataByDateRange(ctx, req.DeviceID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DeleteGPSDataResponse{Deleted: deleted}, nil
+}
@@ -0,0 +1,46 @@
+package gps
+
+import (
+	"context"
+	"errors"
+	"microservicetest/app"
+	apperrors "microservicetest/pkg/errors"
+	"testing"
+)
+
+func TestDeleteGPSDataHandler_RequiresService(t *testing.T) {
+	handler := NewDeleteGPSDataHandler(nil)
+
+	_, err := handler.Handle(context.Background(), &DeleteGPSDataRequest{DeviceID: "gps-1", StartDate: "2026-01-01", EndDate: "2026-01-02"})
+
+	if !errors.Is(err, apperrors.ErrUnauthorized) {
+		t.Errorf("Expected ErrUnauthorized, got %v", err)
+	}
+}
+
+func TestDeleteGPSDataHandler_RejectsUnboundedRanges(t *testing.T) {
+	handler := NewDeleteGPSDataHandler(nil)
+	ctx := app.WithService(context.Background(), "privacy")
+
+	tests := []struct {
+		name string
+		req  DeleteGPSDataRequest
+	}{
+		{"no device", DeleteGPSDataRequest{StartDate: "2026-01-01", EndDate: "2026-01-02"}},
+		{"no start date", DeleteGPSDataRequest{DeviceID: "gps-1", EndDate: "2026-01-02"}},
+		{"no end date", DeleteGPSDataRequest{DeviceID: "gps-1", StartDate: "2026-01-01"}},
+		{"bad date", DeleteGPSDataRequest{DeviceID: "gps-1", StartDate: "01/01/2026", EndDate: "2026-01-02"}},
+		{"reversed", DeleteGPSDataRequest{DeviceID: "gps-1", StartDate: "2026-01-02", EndDate: "2026-01-01"}},
+		{"too long", DeleteGPSDataRequest{DeviceID: "gps-1", StartDate: "2026-01-01", EndDate: "2026-02-01"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := handler.Handle(ctx, &tt.req)
+
+			if !errors.Is(err, apperrors.ErrInvalidInput) {
+				t.Errorf("Expected ErrInvalidInput, got %v", err)
+			}
+		})
+	}
+}
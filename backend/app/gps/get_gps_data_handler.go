@@ -2,35 +2,60 @@ package gps
 
 import (
 	"context"
+	"fmt"
 	"microservicetest/domain"
 	cosmosdb "microservicetest/infra/cosmos"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/log"
+	"microservicetest/pkg/validator"
 	"time"
 
 	"go.uber.org/zap"
 )
 
 type GetGPSDataRequest struct {
-	DeviceID  string `query:"device_id" validate:"required"`
-	StartDate string `query:"start_date"` // Format: 2006-01-02
-	EndDate   string `query:"end_date"`   // Format: 2006-01-02
+	DeviceID       string `query:"device_id" validate:"required"`
+	StartDate      string `query:"start_date"`             // Format: 2006-01-02
+	EndDate        string `query:"end_date"`               // Format: 2006-01-02
+	Limit          int    `query:"limit" validate:"gte=0"` // Defaults to and is capped by gps_max_query_limit
+	FilterOutliers bool   `query:"filter_outliers"`        // Drops points implying speeds above gps_max_speed_kmh
 }
 
 type GetGPSDataResponse struct {
-	Data  []domain.GPSDataResponse `json:"data"`
-	Count int                      `json:"count"`
+	Data     []domain.GPSDataResponse `json:"data"`
+	Count    int                      `json:"count"`
+	Rejected int                      `json:"rejected,omitempty"` // Points dropped as outliers
 }
 
 type GetGPSDataHandler struct {
-	repository *cosmosdb.GPSRepository
+	repository  *cosmosdb.GPSRepository
+	maxLimit    int
+	maxSpeedKmh float64 // Outlier threshold for filter_outliers
 }
 
-func NewGetGPSDataHandler(repository *cosmosdb.GPSRepository) *GetGPSDataHandler {
+func NewGetGPSDataHandler(repository *cosmosdb.GPSRepository, maxLimit int, maxSpeedKmh float64) *GetGPSDataHandler {
 	return &GetGPSDataHandler{
-		repository: repository,
+		repository:  repository,
+		maxLimit:    maxLimit,
+		maxSpeedKmh: maxSpeedKmh,
 	}
 }
 
 func (h *GetGPSDataHandler) Handle(ctx context.Context, req *GetGPSDataRequest) (*GetGPSDataResponse, error) {
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.ErrInvalidInput.WithDetails(map[string]string{
+			"validation": err.Error(),
+		})
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = h.maxLimit
+	}
+	if limit > h.maxLimit {
+		return nil, apperrors.NewValidationError("limit", fmt.Sprintf("must be at most %d", h.maxLimit))
+	}
+
 	// Parse dates or use defaults
 	var startDate, endDate time.Time
 	var err error
@@ -42,7 +67,7 @@ func (h *GetGPSDataHandler) Handle(ctx context.Context, req *GetGPSDataRequest)
 	} else {
 		startDate, err = time.Parse("2006-01-02", req.StartDate)
 		if err != nil {
-			zap.L().Error("Failed to parse start_date", zap.Error(err))
+			log.FromContext(ctx).Error("Failed to parse start_date", zap.Error(err))
 			startDate = time.Now().Truncate(24 * time.Hour)
 		}
 	}
@@ -54,33 +79,37 @@ func (h *GetGPSDataHandler) Handle(ctx context.Context, req *GetGPSDataRequest)
 	} else {
 		endDate, err = time.Parse("2006-01-02", req.EndDate)
 		if err != nil {
-			zap.L().Error("Failed to parse end_date", zap.Error(err))
+			log.FromContext(ctx).Error("Failed to parse end_date", zap.Error(err))
 			endDate = time.Now()
 		} else {
 			// Set to end of day
 			endDate = time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 23, 59, 59, 999999999, endDate.Location())
 		}
 	}
-	zap.L().Info("Fetching GPS data",
+	log.FromContext(ctx).Info("Fetching GPS data",
 		zap.String("device_id", req.DeviceID),
 		zap.Time("start_date", startDate),
 		zap.Time("end_date", endDate),
+		zap.Int("limit", limit),
 	)
 
-	gpsData, err := h.repository.GetGPSDataByDateRange(ctx, req.DeviceID, startDate, endDate)
+	gpsData, err := h.repository.GetGPSDataByDateRange(ctx, req.DeviceID, startDate, endDate, limit)
 	if err != nil {
-		zap.L().Error("Failed to fetch GPS data", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to fetch GPS data", zap.Error(err))
 		return nil, err
 	}
 
-	// Convert to response format with proper timestamp formatting
-	responseData := make([]domain.GPSDataResponse, len(gpsData))
-	for i, data := range gpsData {
-		responseData[i] = data.ToResponse()
+	var rejected []domain.GPSData
+	if req.FilterOutliers {
+		gpsData, rejected = domain.FilterOutliers(gpsData, h.maxSpeedKmh)
 	}
 
+	// Convert to response format with proper timestamp formatting, speed and heading
+	responseData := domain.ToResponsesWithDerived(gpsData)
+
 	return &GetGPSDataResponse{
-		Data:  responseData,
-		Count: len(responseData),
+		Data:     responseData,
+		Count:    len(responseData),
+		Rejected: len(rejected),
 	}, nil
 }
@@ -0,0 +1,30 @@
+package gps
+
+import (
+	"context"
+	"errors"
+	apperrors "microservicetest/pkg/errors"
+	"testing"
+)
+
+func TestGetGPSDataHandler_RejectsInvalidLimit(t *testing.T) {
+	handler := NewGetGPSDataHandler(nil, 100, 300)
+
+	for _, limit := range []int{-1, 101} {
+		_, err := handler.Handle(context.Background(), &GetGPSDataRequest{DeviceID: "gps-1", Limit: limit})
+
+		if !errors.Is(err, apperrors.ErrInvalidInput) {
+			t.Errorf("Expected ErrInvalidInput for limit %d, got %v", limit, err)
+		}
+	}
+}
+
+func TestGetGPSDataHandler_RequiresDeviceID(t *testing.T) {
+	handler := NewGetGPSDataHandler(nil, 100, 300)
+
+	_, err := handler.Handle(context.Background(), &GetGPSDataRequest{})
+
+	if !errors.Is(err, apperrors.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput, got %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package healthcheck
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Readiness statuses, overall and per dependency
+const (
+	StatusReady       = "ready"       // Every dependency is up
+	StatusDegraded    = "degraded"    // Only soft dependencies are down
+	StatusUnavailable = "unavailable" // A hard dependency is down, answered with 503
+	DependencyUp      = "up"
+	DependencyDown    = "down"
+)
+
+// Check reports whether a dependency can serve requests right now. It should
+// do a cheap real operation, not just dial, and return once ctx is done.
+type Check func(ctx context.Context) error
+
+// Dependency is a named check. A hard dependency being down makes the
+// instance unready; a soft one only degrades it.
+type Dependency struct {
+	Name  string
+	Check Check
+	Hard  bool
+}
+
+type ReadinessRequest struct {
+}
+
+type ReadinessResponse struct {
+	Status       string             `json:"status"`
+	Dependencies []DependencyStatus `json:"dependencies"`
+}
+
+type DependencyStatus struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Hard      bool   `json:"hard"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+type ReadinessHandler struct {
+	dependencies []Dependency
+	timeout      time.Duration
+}
+
+// NewReadinessHandler checks the dependencies in parallel, giving each at most timeout
+func NewReadinessHandler(dependencies []Dependency, timeout time.Duration) *ReadinessHandler {
+	return &ReadinessHandler{
+		dependencies: dependencies,
+		timeout:      timeout,
+	}
+}
+
+// Handle answers the readiness probe. The body lists every dependency; the
+// status is 503 while a hard dependency is down so the instance leaves the
+// load balancer until it recovers.
+func (h *ReadinessHandler) Handle(ctx *fiber.Ctx, req *ReadinessRequest) (*ReadinessResponse, error) {
+	res := &ReadinessResponse{
+		Status:       StatusReady,
+		Dependencies: h.check(ctx.UserContext()),
+	}
+
+	for _, dep := range res.Dependencies {
+		if dep.Status == DependencyUp {
+			continue
+		}
+		if dep.Hard {
+			res.Status = StatusUnavailable
+			break
+		}
+		res.Status = StatusDegraded
+	}
+
+	if res.Status == StatusUnavailable {
+		ctx.Status(fiber.StatusServiceUnavailable)
+	}
+	return res, nil
+}
+
+// check runs every check at once and returns their results in dependency order
+func (h *ReadinessHandler) check(ctx context.Context) []DependencyStatus {
+	results := make([]DependencyStatus, len(h.dependencies))
+
+	var wg sync.WaitGroup
+	for i, dep := range h.dependencies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, h.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := dep.Check(checkCtx)
+			results[i] = DependencyStatus{
+				Name:      dep.Name,
+				Status:    DependencyUp,
+				Hard:      dep.Hard,
+				LatencyMs: time.Since(start).Milliseconds(),
+			}
+			if err != nil {
+				results[i].Status = DependencyDown
+				results[i].Error = err.Error()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,105 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func newReadinessApp(handler *ReadinessHandler) *fiber.App {
+	app := fiber.New()
+	app.Get("/healthcheck/ready", func(c *fiber.Ctx) error {
+		res, err := handler.Handle(c, &ReadinessRequest{})
+		if err != nil {
+			return err
+		}
+		return c.JSON(res)
+	})
+	return app
+}
+
+func up(context.Context) error { return nil }
+
+func down(context.Context) error { return errors.New("connection refused") }
+
+func TestReadinessHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		dependencies   []Dependency
+		expectedCode   int
+		expectedStatus string
+	}{
+		{
+			name:           "all up",
+			dependencies:   []Dependency{{Name: "couchbase", Check: up, Hard: true}, {Name: "storage", Check: up}},
+			expectedCode:   fiber.StatusOK,
+			expectedStatus: StatusReady,
+		},
+		{
+			name:           "soft dependency down",
+			dependencies:   []Dependency{{Name: "couchbase", Check: up, Hard: true}, {Name: "storage", Check: down}},
+			expectedCode:   fiber.StatusOK,
+			expectedStatus: StatusDegraded,
+		},
+		{
+			name:           "hard dependency down",
+			dependencies:   []Dependency{{Name: "couchbase", Check: down, Hard: true}, {Name: "storage", Check: down}},
+			expectedCode:   fiber.StatusServiceUnavailable,
+			expectedStatus: StatusUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newReadinessApp(NewReadinessHandler(tt.dependencies, time.Second))
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/healthcheck/ready", nil))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if resp.StatusCode != tt.expectedCode {
+				t.Errorf("Expected status %d, got %d", tt.expectedCode, resp.StatusCode)
+			}
+
+			var body ReadinessResponse
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if body.Status != tt.expectedStatus {
+				t.Errorf("Expected status %s, got %s", tt.expectedStatus, body.Status)
+			}
+			if len(body.Dependencies) != len(tt.dependencies) {
+				t.Fatalf("Expected %d dependencies, got %d", len(tt.dependencies), len(body.Dependencies))
+			}
+			for i, dep := range body.Dependencies {
+				if dep.Name != tt.dependencies[i].Name || dep.Hard != tt.dependencies[i].Hard {
+					t.Errorf("Expected dependency %d to be %s, got %+v", i, tt.dependencies[i].Name, dep)
+				}
+				if (dep.Status == DependencyDown) != (dep.Error != "") {
+					t.Errorf("Expected an error exactly when %s is down, got %+v", dep.Name, dep)
+				}
+			}
+		})
+	}
+}
+
+func TestReadinessHandler_Timeout(t *testing.T) {
+	hanging := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	app := newReadinessApp(NewReadinessHandler([]Dependency{{Name: "couchbase", Check: hanging, Hard: true}}, 10*time.Millisecond))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/healthcheck/ready", nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != fiber.StatusServiceUnavailable {
+		t.Errorf("Expected a hanging hard dependency to answer 503, got %d", resp.StatusCode)
+	}
+}
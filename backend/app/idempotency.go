@@ -0,0 +1,26 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// IdempotencyRecord is the stored outcome of a request made with an idempotency key
+type IdempotencyRecord struct {
+	Key         string          `json:"key"`
+	RequestHash string          `json:"request_hash"` // Detects the same key reused with a different payload
+	StatusCode  int             `json:"status_code"`
+	Response    json.RawMessage `json:"response"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// IdempotencyStore keeps request outcomes for replay. Keys are shared across
+// features, so callers namespace them (e.g. "vehicles:" + header value).
+type IdempotencyStore interface {
+	// Get returns the stored record and whether one exists for key
+	Get(ctx context.Context, key string) (*IdempotencyRecord, bool, error)
+	// Save stores record until ttl elapses. Saving an existing key fails with
+	// ErrResourceExists so only the first of two racing requests wins.
+	Save(ctx context.Context, record IdempotencyRecord, ttl time.Duration) error
+}
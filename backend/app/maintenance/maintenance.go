@@ -0,0 +1,73 @@
+package maintenance
+
+import (
+	"context"
+	"microservicetest/app"
+	apperrors "microservicetest/pkg/errors"
+	"sync/atomic"
+)
+
+// Mode holds the runtime maintenance toggle shared by the middleware and the admin endpoint
+type Mode struct {
+	enabled atomic.Bool
+}
+
+func NewMode(enabled bool) *Mode {
+	mode := &Mode{}
+	mode.enabled.Store(enabled)
+	return mode
+}
+
+// Enabled reports whether the service is currently in maintenance mode
+func (m *Mode) Enabled() bool {
+	return m.enabled.Load()
+}
+
+// Set flips maintenance mode on or off
+func (m *Mode) Set(enabled bool) {
+	m.enabled.Store(enabled)
+}
+
+type GetMaintenanceRequest struct {
+}
+
+type SetMaintenanceRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+type MaintenanceResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+type GetMaintenanceHandler struct {
+	mode *Mode
+}
+
+func NewGetMaintenanceHandler(mode *Mode) *GetMaintenanceHandler {
+	return &GetMaintenanceHandler{mode: mode}
+}
+
+func (h *GetMaintenanceHandler) Handle(ctx context.Context, req *GetMaintenanceRequest) (*MaintenanceResponse, error) {
+	return &MaintenanceResponse{Enabled: h.mode.Enabled()}, nil
+}
+
+type SetMaintenanceHandler struct {
+	mode *Mode
+}
+
+func NewSetMaintenanceHandler(mode *Mode) *SetMaintenanceHandler {
+	return &SetMaintenanceHandler{mode: mode}
+}
+
+// Handle flips the toggle for a calling service only, as it takes the whole
+// API offline
+func (h *SetMaintenanceHandler) Handle(ctx context.Context, req *SetMaintenanceRequest) (*MaintenanceResponse, error) {
+	if _, ok := app.ServiceFromContext(ctx); !ok {
+		return nil, apperrors.ErrUnauthorized.WithDetails(map[string]string{
+			"header": "X-API-Key",
+		})
+	}
+
+	h.mode.Set(req.Enabled)
+	return &MaintenanceResponse{Enabled: h.mode.Enabled()}, nil
+}
@@ -0,0 +1,29 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"microservicetest/app"
+	apperrors "microservicetest/pkg/errors"
+	"testing"
+)
+
+func TestSetMaintenanceHandler_RequiresService(t *testing.T) {
+	mode := NewMode(false)
+	handler := NewSetMaintenanceHandler(mode)
+
+	if _, err := handler.Handle(context.Background(), &SetMaintenanceRequest{Enabled: true}); !errors.Is(err, apperrors.ErrUnauthorized) {
+		t.Errorf("Expected %s without an API key, got %v", apperrors.ErrUnauthorized.Code, err)
+	}
+	if mode.Enabled() {
+		t.Fatal("Expected maintenance mode to stay off")
+	}
+
+	resp, err := handler.Handle(app.WithService(context.Background(), "ops"), &SetMaintenanceRequest{Enabled: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !resp.Enabled || !mode.Enabled() {
+		t.Error("Expected maintenance mode to be on")
+	}
+}
@@ -0,0 +1,16 @@
+package app
+
+import "context"
+
+type serviceContextKey struct{}
+
+// WithService attaches the identity of an authenticated calling service to ctx
+func WithService(ctx context.Context, service string) context.Context {
+	return context.WithValue(ctx, serviceContextKey{}, service)
+}
+
+// ServiceFromContext returns the calling service, if the request came in with a valid API key
+func ServiceFromContext(ctx context.Context) (string, bool) {
+	service, ok := ctx.Value(serviceContextKey{}).(string)
+	return service, ok
+}
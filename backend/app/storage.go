@@ -3,10 +3,39 @@ package app
 import (
 	"context"
 	"io"
+	"time"
 )
 
 type Storage interface {
 	Upload(ctx context.Context, file io.Reader, filename string, contentType string) (string, error)
 	Download(ctx context.Context, filename string) ([]byte, string, error)
 	Remove(ctx context.Context, filename string) error
+	// PresignUpload returns a short-lived URL clients can PUT the file to directly
+	PresignUpload(ctx context.Context, filename string) (*PresignedUpload, error)
+	// PresignRead returns a short-lived read-only URL for a stored file, so
+	// private files can be fetched without making the container public
+	PresignRead(ctx context.Context, filename string) (url string, expiresAt time.Time, err error)
+	// StatBlob reads the size and content type of a stored file without
+	// downloading it; exists is false when there is no such file
+	StatBlob(ctx context.Context, filename string) (size int64, contentType string, exists bool, err error)
+	// URL returns the unsigned URL of a stored file
+	URL(filename string) string
+	// ListBlobs returns every stored file, for reconciling the container with
+	// the records that reference it
+	ListBlobs(ctx context.Context) ([]BlobInfo, error)
+}
+
+// BlobInfo describes a stored file as listed by ListBlobs
+type BlobInfo struct {
+	Name         string
+	Size         int64
+	LastModified time.Time
+}
+
+// PresignedUpload is a write-only URL for a single file
+type PresignedUpload struct {
+	UploadURL string            // URL to PUT the bytes to, carries the signature
+	FileURL   string            // URL of the file once uploaded, without the signature
+	Headers   map[string]string // Headers the PUT must send
+	ExpiresAt time.Time
 }
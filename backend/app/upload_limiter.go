@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"io"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/metrics"
+	"time"
+)
+
+// uploadRetryAfter is the Retry-After sent when the upload queue is full
+const uploadRetryAfter = 5 * time.Second
+
+// errUploadsBusy is returned when every upload slot is taken and the queue is full
+var errUploadsBusy = apperrors.ErrServiceUnavailable.WithDetails(map[string]string{
+	"dependency": "blob_storage",
+	"reason":     "too many concurrent uploads",
+}).WithRetryAfter(uploadRetryAfter)
+
+// LimitedStorage caps the number of concurrent uploads to the wrapped Storage.
+// Uploads beyond the limit wait in a bounded queue until a slot frees up or
+// their context ends; once the queue is full they fail fast with
+// ErrServiceUnavailable. Every other Storage call passes straight through.
+type LimitedStorage struct {
+	Storage
+	slots   chan struct{}
+	waiting chan struct{}
+}
+
+// NewLimitedStorage allows maxConcurrent uploads at once with up to maxQueued waiting
+func NewLimitedStorage(storage Storage, maxConcurrent, maxQueued int) *LimitedStorage {
+	return &LimitedStorage{
+		Storage: storage,
+		slots:   make(chan struct{}, maxConcurrent),
+		waiting: make(chan struct{}, maxQueued),
+	}
+}
+
+func (s *LimitedStorage) Upload(ctx context.Context, file io.Reader, filename string, contentType string) (string, error) {
+	if err := s.acquire(ctx); err != nil {
+		return "", err
+	}
+	defer s.release()
+
+	return s.Storage.Upload(ctx, file, filename, contentType)
+}
+
+func (s *LimitedStorage) acquire(ctx context.Context) error {
+	select {
+	case s.slots <- struct{}{}:
+		metrics.UploadsInFlight.Add(1)
+		return nil
+	default:
+	}
+
+	select {
+	case s.waiting <- struct{}{}:
+	default:
+		metrics.UploadsRejected.Add(1)
+		return errUploadsBusy
+	}
+	metrics.UploadQueueDepth.Add(1)
+	defer func() {
+		<-s.waiting
+		metrics.UploadQueueDepth.Add(-1)
+	}()
+
+	select {
+	case s.slots <- struct{}{}:
+		metrics.UploadsInFlight.Add(1)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *LimitedStorage) release() {
+	<-s.slots
+	metrics.UploadsInFlight.Add(-1)
+}
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"io"
+	apperrors "microservicetest/pkg/errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// blockingStorage holds every upload until release is closed
+type blockingStorage struct {
+	Storage
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingStorage) Upload(ctx context.Context, file io.Reader, filename string, contentType string) (string, error) {
+	s.started <- struct{}{}
+	<-s.release
+	return "https://account.blob.core.windows.net/documents/" + filename, nil
+}
+
+func TestLimitedStorage_QueuesThenRejects(t *testing.T) {
+	inner := &blockingStorage{started: make(chan struct{}, 3), release: make(chan struct{})}
+	storage := NewLimitedStorage(inner, 1, 1)
+
+	results := make(chan error, 2)
+	upload := func() {
+		_, err := storage.Upload(context.Background(), strings.NewReader("data"), "file", "text/plain")
+		results <- err
+	}
+
+	// First upload takes the only slot, the second waits in the queue
+	go upload()
+	<-inner.started
+	go upload()
+	for len(storage.waiting) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	_, err := storage.Upload(context.Background(), strings.NewReader("data"), "file", "text/plain")
+	if !errors.Is(err, apperrors.ErrServiceUnavailable) {
+		t.Fatalf("Expected ErrServiceUnavailable with a full queue, got %v", err)
+	}
+
+	close(inner.release)
+	for i := 0; i < 2; i++ {
+		if err := <-results; err != nil {
+			t.Errorf("Expected queued uploads to succeed, got %v", err)
+		}
+	}
+}
+
+func TestLimitedStorage_QueuedUploadHonoursContext(t *testing.T) {
+	inner := &blockingStorage{started: make(chan struct{}, 1), release: make(chan struct{})}
+	defer close(inner.release)
+	storage := NewLimitedStorage(inner, 1, 1)
+
+	go storage.Upload(context.Background(), strings.NewReader("data"), "file", "text/plain")
+	<-inner.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := storage.Upload(ctx, strings.NewReader("data"), "file", "text/plain")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the queued upload to give up with its context, got %v", err)
+	}
+	if len(storage.waiting) != 0 {
+		t.Errorf("Expected the queue to be empty, got %d", len(storage.waiting))
+	}
+}
@@ -1,127 +1,254 @@
 package vehicle
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"microservicetest/app"
 	"microservicetest/domain"
 	apperrors "microservicetest/pkg/errors"
-	"strconv"
+	"microservicetest/pkg/log"
+	"microservicetest/pkg/timeutil"
+	"microservicetest/pkg/validator"
+	"mime/multipart"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
+// errStorageUnavailable is returned by handlers that need Blob Storage when it
+// could not be initialized at startup
+var errStorageUnavailable = apperrors.ErrServiceUnavailable.WithDetails(map[string]string{
+	"dependency": "blob_storage",
+})
+
+// blobCleanupTimeout bounds the removal of a blob whose document failed to persist
+const blobCleanupTimeout = 10 * time.Second
+
+// AddDocumentRequest is the multipart form of a document upload; the file
+// itself comes in the "file" part
 type AddDocumentRequest struct {
-	VehicleID string `param:"id" validate:"required"`
+	VehicleID      string `param:"id" validate:"required"`
+	Type           string `form:"type" validate:"required,documenttype"`
+	Name           string `form:"name" validate:"required,min=1,max=100"`
+	Description    string `form:"description" validate:"max=1000"`
+	FileName       string `form:"file_name" validate:"max=255"`
+	FileSize       int64  `form:"file_size" validate:"omitempty,gt=0"` // Bytes, the upload's own size when omitted
+	UploadedBy     string `form:"uploaded_by" validate:"max=100"`
+	ExpiryDate     string `form:"expiry_date" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"` // RFC 3339
+	IssuedDate     string `form:"issued_date" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"` // RFC 3339
+	IssuedBy       string `form:"issued_by" validate:"max=100"`
+	DocumentNumber string `form:"document_number" validate:"max=50"`
 }
 
 type AddDocumentResponse struct {
 	DocumentID string    `json:"document_id"`
 	UploadedAt time.Time `json:"uploaded_at"`
+	Warnings   []string  `json:"warnings,omitempty"`
+	vehicleID  string
+}
+
+// HTTPStatus answers 201 Created, see response.StatusCoder
+func (r *AddDocumentResponse) HTTPStatus() int {
+	return fiber.StatusCreated
+}
+
+// Location is the URL of the new document, see response.Locator
+func (r *AddDocumentResponse) Location() string {
+	return "/vehicles/" + r.vehicleID + "/documents/" + r.DocumentID
 }
 
 type AddDocumentHandler struct {
 	repository     Repository
 	storageService app.Storage
+	quotaBytes     int64 // Storage quota per vehicle, 0 means unlimited
+	auditLog       app.AuditLog
 }
 
-func NewAddDocumentHandler(repository Repository, storageService app.Storage) *AddDocumentHandler {
+func NewAddDocumentHandler(repository Repository, storageService app.Storage, quotaBytes int64, auditLog app.AuditLog) *AddDocumentHandler {
 	return &AddDocumentHandler{
 		repository:     repository,
 		storageService: storageService,
+		quotaBytes:     quotaBytes,
+		auditLog:       auditLog,
 	}
 }
 
 func (h *AddDocumentHandler) Handle(ctx *fiber.Ctx, req *AddDocumentRequest) (*AddDocumentResponse, error) {
-	vehicleID := ctx.Params("id") // param:"id" mapping
-	docType := ctx.FormValue("type")
-	name := ctx.FormValue("name")
-	description := ctx.FormValue("description")
-	fileName := ctx.FormValue("file_name")
-	fileSizeStr := ctx.FormValue("file_size")
-	mimeType := ctx.FormValue("mime_type")
-	uploadedBy := ctx.FormValue("uploaded_by")
-	expiryDateStr := ctx.FormValue("expiry_date")
-	issuedDateStr := ctx.FormValue("issued_date")
-	issuedBy := ctx.FormValue("issued_by")
-	documentNumber := ctx.FormValue("document_number")
-
-	_, err := h.repository.GetVehicle(ctx.UserContext(), vehicleID)
+	if h.storageService == nil {
+		return nil, errStorageUnavailable
+	}
+
+	req.VehicleID = ctx.Params("id")
+
+	fileHeader, err := validateDocumentForm(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	fileHeader, err := ctx.FormFile("file")
+	expiryDate, err := timeutil.ParseOptionalRFC3339("expiry_date", req.ExpiryDate)
 	if err != nil {
-		return nil, apperrors.ErrInternalServer.WithCause(err)
+		return nil, err
+	}
+	issuedDate, err := timeutil.ParseOptionalRFC3339("issued_date", req.IssuedDate)
+	if err != nil {
+		return nil, err
 	}
+
+	now := time.Now()
+	if err := validateDocumentDates(issuedDate, expiryDate, now); err != nil {
+		return nil, err
+	}
+
+	vehicle, err := h.repository.GetVehicle(ctx.UserContext(), req.VehicleID)
+	if err != nil {
+		return nil, err
+	}
+
 	file, err := fileHeader.Open()
 	if err != nil {
 		return nil, apperrors.ErrInternalServer.WithCause(err)
 	}
 	defer file.Close()
 
-	fileSize, err := strconv.ParseInt(fileSizeStr, 10, 64)
+	mimeType, ext, err := checkFileType(file)
+	if err != nil {
+		return nil, err
+	}
+	checksum, err := fileChecksum(file)
 	if err != nil {
+		return nil, err
+	}
+
+	fileSize := req.FileSize
+	if fileSize == 0 {
 		fileSize = fileHeader.Size
 	}
+	if err := checkStorageQuota(vehicle, h.quotaBytes, fileSize); err != nil {
+		return nil, err
+	}
+
+	documentID := domain.GenerateDocumentID()
+	audit, err := beginAudit(ctx.UserContext(), h.auditLog, app.AuditEntry{
+		VehicleID: req.VehicleID,
+		Action:    app.AuditActionAddDocument,
+		Actor:     auditActor(ctx.UserContext(), req.UploadedBy),
+		Changes:   itemsChange("documents", nil, []string{documentID}),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer audit.abort(ctx.UserContext())
 
 	filenameUUID, _ := uuid.NewUUID()
+	blobName := filenameUUID.String() + ext
 
-	fileURL, err := h.storageService.Upload(ctx.UserContext(), file, filenameUUID.String(), mimeType)
+	fileURL, err := h.storageService.Upload(ctx.UserContext(), file, blobName, mimeType)
+	if errors.Is(err, apperrors.ErrServiceUnavailable) {
+		// Upload limit reached, keep the 503 and its Retry-After
+		return nil, err
+	}
 	if err != nil {
 		return nil, apperrors.ErrInternalServer.WithCause(err)
 	}
 
-	var expiryDate, issuedDate *time.Time
-	if expiryDateStr != "" {
-		t, err := time.Parse(time.RFC3339, expiryDateStr)
-		if err != nil {
-			return nil, apperrors.ErrInvalidFormat.WithDetails(map[string]string{
-				"field":   "expiry_date",
-				"message": "must be in RFC3339 format",
-			})
-		}
-		expiryDate = &t
-	}
-	if issuedDateStr != "" {
-		t, err := time.Parse(time.RFC3339, issuedDateStr)
-		if err != nil {
-			return nil, apperrors.ErrInvalidFormat.WithDetails(map[string]string{
-				"field":   "issued_date",
-				"message": "must be in RFC3339 format",
-			})
-		}
-		issuedDate = &t
-	}
-
-	now := time.Now()
 	document := domain.Document{
-		ID:             domain.GenerateDocumentID(),
-		Type:           domain.DocumentType(docType),
-		Name:           name,
-		Description:    description,
+		ID:             documentID,
+		Type:           domain.DocumentType(req.Type),
+		Name:           req.Name,
+		Description:    req.Description,
 		FileURL:        fileURL,
-		FileName:       fileName,
+		FileName:       req.FileName,
 		FileSize:       fileSize,
 		MimeType:       mimeType,
-		IssuedBy:       issuedBy,
-		DocumentNumber: documentNumber,
+		Checksum:       checksum,
+		IssuedBy:       req.IssuedBy,
+		DocumentNumber: req.DocumentNumber,
 		UploadedAt:     now,
-		UploadedBy:     uploadedBy,
+		UploadedBy:     req.UploadedBy,
 		ExpiryDate:     expiryDate,
 		IssuedDate:     issuedDate,
 		IsVerified:     false,
 	}
 
-	if err := h.repository.AddDocument(ctx.UserContext(), vehicleID, document); err != nil {
-		return nil, apperrors.ErrDatabaseQuery.WithCause(err).WithDetails(map[string]string{
-			"operation": "add_document",
-		})
+	if err := h.repository.AddDocument(ctx.UserContext(), req.VehicleID, document); err != nil {
+		removeUnreferencedBlob(ctx.UserContext(), h.storageService, blobName, err)
+		return nil, err
 	}
 
 	return &AddDocumentResponse{
 		DocumentID: document.ID,
 		UploadedAt: document.UploadedAt,
+		Warnings:   audit.complete(ctx.UserContext()),
+		vehicleID:  req.VehicleID,
 	}, nil
 }
+
+// removeUnreferencedBlob deletes a blob just uploaded for a document that
+// failed to persist, so it is not left behind. The removal gets its own
+// deadline, as the request's may be what failed the write. When the write's
+// outcome is unknown the document may reference the blob, so it is kept for
+// the reconcile job to decide.
+func removeUnreferencedBlob(ctx context.Context, storage app.Storage, blobName string, persistErr error) {
+	logger := log.FromContext(ctx)
+	if isAmbiguousWrite(persistErr) {
+		logger.Warn("Keeping uploaded blob, the document may have been stored",
+			zap.String("filename", blobName),
+			zap.Error(persistErr))
+		return
+	}
+
+	cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), blobCleanupTimeout)
+	defer cancel()
+	if err := storage.Remove(cleanupCtx, blobName); err != nil {
+		logger.Error("Failed to remove orphaned blob",
+			zap.String("filename", blobName),
+			zap.Error(err))
+	}
+}
+
+// isAmbiguousWrite reports whether a failed write may still have been applied
+func isAmbiguousWrite(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, context.Canceled) ||
+		errors.Is(err, apperrors.ErrWriteAmbiguous) ||
+		apperrors.GetErrorType(err) == apperrors.ErrorTypeTimeout
+}
+
+// validateDocumentForm checks every form field and the file part before
+// anything is looked up or uploaded, reporting all invalid fields at once under
+// details.fields
+func validateDocumentForm(ctx *fiber.Ctx, req *AddDocumentRequest) (*multipart.FileHeader, error) {
+	fields, err := validator.FieldErrors(req, "form")
+	if err != nil {
+		return nil, apperrors.ErrInternalServer.WithCause(err)
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		fields["file"] = "file is required"
+	} else if _, invalid := fields["file_size"]; !invalid && req.FileSize != 0 && req.FileSize != fileHeader.Size {
+		fields["file_size"] = fmt.Sprintf("file_size must match the uploaded file's %d bytes", fileHeader.Size)
+	}
+
+	if len(fields) > 0 {
+		return nil, apperrors.ErrInvalidInput.WithDetails(map[string]any{
+			"fields": fields,
+		})
+	}
+	return fileHeader, nil
+}
+
+// validateDocumentDates rejects an issue date in the future and an expiry date
+// that is not after the issue date
+func validateDocumentDates(issuedDate, expiryDate *time.Time, now time.Time) error {
+	if issuedDate != nil && issuedDate.After(now) {
+		return apperrors.NewUnprocessableError("issued_date", "must not be in the future")
+	}
+	if issuedDate != nil && expiryDate != nil && !expiryDate.After(*issuedDate) {
+		return apperrors.NewUnprocessableError("expiry_date", "must be after issued_date")
+	}
+	return nil
+}
@@ -0,0 +1,423 @@
+package vehicle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/response"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAddDocumentHandler_StorageUnavailable(t *testing.T) {
+	repo := &MockRepository{}
+	handler := NewAddDocumentHandler(repo, nil, 0, nil)
+
+	app := fiber.New()
+	app.Post("/vehicles/:id/documents", func(c *fiber.Ctx) error {
+		_, err := handler.Handle(c, &AddDocumentRequest{})
+		if !errors.Is(err, apperrors.ErrServiceUnavailable) {
+			t.Errorf("Expected ErrServiceUnavailable, got %v", err)
+		}
+		return nil
+	})
+
+	if _, err := app.Test(httptest.NewRequest("POST", "/vehicles/VEH_1/documents", nil)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestAddDocumentHandler_InvalidDates(t *testing.T) {
+	cases := map[string]struct {
+		issuedDate string
+		expiryDate string
+		field      string
+	}{
+		"issued in the future":  {issuedDate: "2099-01-01T00:00:00Z", field: "issued_date"},
+		"expiry before issue":   {issuedDate: "2024-06-01T00:00:00Z", expiryDate: "2024-01-01T00:00:00Z", field: "expiry_date"},
+		"expiry equal to issue": {issuedDate: "2024-06-01T00:00:00Z", expiryDate: "2024-06-01T00:00:00Z", field: "expiry_date"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			added := false
+			repo := &MockRepository{
+				GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+					return &domain.Vehicle{ID: id}, nil
+				},
+				AddDocumentFunc: func(ctx context.Context, vehicleID string, document domain.Document) error {
+					added = true
+					return nil
+				},
+			}
+			storage := &MockStorage{Blobs: map[string][]byte{}}
+
+			app := fiber.New()
+			app.Post("/vehicles/:id/documents", func(c *fiber.Ctx) error {
+				var req AddDocumentRequest
+				if err := c.BodyParser(&req); err != nil {
+					return err
+				}
+				res, err := NewAddDocumentHandler(repo, storage, 0, nil).Handle(c, &req)
+				if err != nil {
+					return apperrors.HandleError(c, err)
+				}
+				return c.JSON(res)
+			})
+
+			body := &bytes.Buffer{}
+			form := multipart.NewWriter(body)
+			form.WriteField("type", "registration")
+			form.WriteField("name", "Registration")
+			form.WriteField("issued_date", tc.issuedDate)
+			if tc.expiryDate != "" {
+				form.WriteField("expiry_date", tc.expiryDate)
+			}
+			part, _ := form.CreateFormFile("file", "registration.pdf")
+			part.Write([]byte("%PDF"))
+			form.Close()
+
+			req := httptest.NewRequest("POST", "/vehicles/VEH_1/documents", body)
+			req.Header.Set("Content-Type", form.FormDataContentType())
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if resp.StatusCode != fiber.StatusUnprocessableEntity {
+				t.Fatalf("Expected status 422, got %d", resp.StatusCode)
+			}
+
+			var errResp struct {
+				Error struct {
+					Details map[string]string `json:"details"`
+				} `json:"error"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if errResp.Error.Details["field"] != tc.field {
+				t.Errorf("Expected error on %s, got %v", tc.field, errResp.Error.Details)
+			}
+			if added || len(storage.Blobs) != 0 {
+				t.Error("Expected nothing to be uploaded or stored")
+			}
+		})
+	}
+}
+
+func TestAddDocumentHandler_InvalidFormFields(t *testing.T) {
+	valid := map[string]string{"type": "registration", "name": "Registration"}
+	with := func(field, value string) map[string]string {
+		fields := map[string]string{field: value}
+		for k, v := range valid {
+			if k != field {
+				fields[k] = v
+			}
+		}
+		return fields
+	}
+
+	cases := map[string]struct {
+		fields map[string]string
+		noFile bool
+		want   map[string]string // Invalid field to the start of its message
+	}{
+		"missing type":       {fields: with("type", ""), want: map[string]string{"type": "type is required"}},
+		"unknown type":       {fields: with("type", "passport"), want: map[string]string{"type": "type must be one of"}},
+		"missing name":       {fields: with("name", ""), want: map[string]string{"name": "name is required"}},
+		"name too long":      {fields: with("name", strings.Repeat("a", 101)), want: map[string]string{"name": "name must be at most 100"}},
+		"negative file size": {fields: with("file_size", "-5"), want: map[string]string{"file_size": "file_size must be greater than 0"}},
+		"file size mismatch": {fields: with("file_size", "999"), want: map[string]string{"file_size": "file_size must match the uploaded file's 4 bytes"}},
+		"expiry not RFC3339": {fields: with("expiry_date", "2025-01-01"), want: map[string]string{"expiry_date": "expiry_date must match the layout"}},
+		"issued not RFC3339": {fields: with("issued_date", "01/02/2024"), want: map[string]string{"issued_date": "issued_date must match the layout"}},
+		"missing file":       {fields: valid, noFile: true, want: map[string]string{"file": "file is required"}},
+		"several at once": {
+			fields: map[string]string{"file_size": "-1"},
+			noFile: true,
+			want:   map[string]string{"type": "type is required", "name": "name is required", "file_size": "file_size must be greater than 0", "file": "file is required"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fetched := false
+			repo := &MockRepository{
+				GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+					fetched = true
+					return &domain.Vehicle{ID: id}, nil
+				},
+			}
+			storage := &MockStorage{Blobs: map[string][]byte{}}
+
+			app := fiber.New()
+			app.Post("/vehicles/:id/documents", func(c *fiber.Ctx) error {
+				var req AddDocumentRequest
+				if err := c.BodyParser(&req); err != nil {
+					return err
+				}
+				res, err := NewAddDocumentHandler(repo, storage, 0, nil).Handle(c, &req)
+				if err != nil {
+					return apperrors.HandleError(c, err)
+				}
+				return c.JSON(res)
+			})
+
+			body := &bytes.Buffer{}
+			form := multipart.NewWriter(body)
+			for field, value := range tc.fields {
+				form.WriteField(field, value)
+			}
+			if !tc.noFile {
+				part, _ := form.CreateFormFile("file", "registration.pdf")
+				part.Write([]byte("%PDF"))
+			}
+			form.Close()
+
+			req := httptest.NewRequest("POST", "/vehicles/VEH_1/documents", body)
+			req.Header.Set("Content-Type", form.FormDataContentType())
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if resp.StatusCode != fiber.StatusBadRequest {
+				t.Fatalf("Expected status 400, got %d", resp.StatusCode)
+			}
+
+			var errResp struct {
+				Error struct {
+					Details struct {
+						Fields map[string]string `json:"fields"`
+					} `json:"details"`
+				} `json:"error"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			got := errResp.Error.Details.Fields
+			if len(got) != len(tc.want) {
+				t.Errorf("Expected errors on %d fields, got %v", len(tc.want), got)
+			}
+			for field, want := range tc.want {
+				if !strings.HasPrefix(got[field], want) {
+					t.Errorf("Expected %s error starting with %q, got %q", field, want, got[field])
+				}
+			}
+			if fetched || len(storage.Blobs) != 0 {
+				t.Error("Expected the request to be rejected before any lookup or upload")
+			}
+		})
+	}
+}
+
+func TestAddDocumentHandler_Created(t *testing.T) {
+	repo := &MockRepository{
+		GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+			return &domain.Vehicle{ID: id}, nil
+		},
+	}
+	storage := &MockStorage{Blobs: map[string][]byte{}}
+
+	app := fiber.New()
+	app.Post("/vehicles/:id/documents", func(c *fiber.Ctx) error {
+		var req AddDocumentRequest
+		if err := c.BodyParser(&req); err != nil {
+			return err
+		}
+		res, err := NewAddDocumentHandler(repo, storage, 0, nil).Handle(c, &req)
+		if err != nil {
+			return apperrors.HandleError(c, err)
+		}
+		return response.Send(c, res)
+	})
+
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	form.WriteField("type", "registration")
+	form.WriteField("name", "Registration")
+	part, _ := form.CreateFormFile("file", "registration.pdf")
+	part.Write([]byte("%PDF-1.7"))
+	form.Close()
+
+	req := httptest.NewRequest("POST", "/vehicles/VEH_1/documents", body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != fiber.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", resp.StatusCode)
+	}
+
+	var res AddDocumentResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if want := "/vehicles/VEH_1/documents/" + res.DocumentID; resp.Header.Get("Location") != want {
+		t.Errorf("Expected Location %q, got %q", want, resp.Header.Get("Location"))
+	}
+}
+
+func TestAddDocumentHandler_RemovesBlobWhenPersistFails(t *testing.T) {
+	storage := &MockStorage{Blobs: map[string][]byte{}}
+	uploaded := ""
+	repo := &MockRepository{
+		GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+			return &domain.Vehicle{ID: id}, nil
+		},
+		AddDocumentFunc: func(ctx context.Context, vehicleID string, document domain.Document) error {
+			uploaded = document.FileURL
+			return apperrors.NewDatabaseError("add_document", errors.New("timeout"))
+		},
+	}
+
+	app := fiber.New()
+	app.Post("/vehicles/:id/documents", func(c *fiber.Ctx) error {
+		var req AddDocumentRequest
+		if err := c.BodyParser(&req); err != nil {
+			return err
+		}
+		res, err := NewAddDocumentHandler(repo, storage, 0, nil).Handle(c, &req)
+		if err != nil {
+			return apperrors.HandleError(c, err)
+		}
+		return c.JSON(res)
+	})
+
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	form.WriteField("type", "registration")
+	form.WriteField("name", "Registration")
+	part, _ := form.CreateFormFile("file", "registration.pdf")
+	part.Write([]byte("%PDF-1.7"))
+	form.Close()
+
+	req := httptest.NewRequest("POST", "/vehicles/VEH_1/documents", body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != fiber.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", resp.StatusCode)
+	}
+	if uploaded == "" {
+		t.Fatal("Expected the file to be uploaded before the document was persisted")
+	}
+	if len(storage.Blobs) != 0 {
+		t.Errorf("Expected the uploaded blob to be removed, still stored: %v", storage.Blobs)
+	}
+}
+
+// deadlineStorage is a MockStorage whose Remove fails once ctx is done, like a real client
+type deadlineStorage struct {
+	*MockStorage
+}
+
+func (s deadlineStorage) Remove(ctx context.Context, filename string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.MockStorage.Remove(ctx, filename)
+}
+
+func TestRemoveUnreferencedBlob(t *testing.T) {
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	tests := []struct {
+		name    string
+		err     error
+		removed bool
+	}{
+		{"failed write", apperrors.NewDatabaseError("add_document", errors.New("not stored")), true},
+		{"write timeout", apperrors.ErrRequestTimeout.WithCause(errors.New("timed out")), false},
+		{"request deadline", fmt.Errorf("add_document: %w", context.DeadlineExceeded), false},
+		{"ambiguous durability", apperrors.ErrWriteAmbiguous.WithCause(errors.New("durability ambiguous")), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := deadlineStorage{&MockStorage{Blobs: map[string][]byte{"blob-1": []byte("%PDF-1.7")}}}
+
+			// The request deadline has passed, which must not stop the cleanup
+			removeUnreferencedBlob(expired, storage, "blob-1", tt.err)
+
+			if _, kept := storage.Blobs["blob-1"]; kept == tt.removed {
+				t.Errorf("Expected removed=%v, blobs %v", tt.removed, storage.Blobs)
+			}
+		})
+	}
+}
+
+func TestAddDocumentHandler_QuotaCountsThumbnails(t *testing.T) {
+	storage := &MockStorage{Blobs: map[string][]byte{}}
+	repo := &MockRepository{
+		GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+			// 1010 bytes of pictures only reach the quota with the thumbnail
+			return &domain.Vehicle{ID: id, Pictures: []domain.Picture{{FileSize: 1000, ThumbnailSize: 10}}}, nil
+		},
+		AddDocumentFunc: func(ctx context.Context, vehicleID string, document domain.Document) error {
+			t.Error("Expected no document to be added over the quota")
+			return nil
+		},
+	}
+
+	app := fiber.New()
+	app.Post("/vehicles/:id/documents", func(c *fiber.Ctx) error {
+		var req AddDocumentRequest
+		if err := c.BodyParser(&req); err != nil {
+			return err
+		}
+		res, err := NewAddDocumentHandler(repo, storage, 1012, nil).Handle(c, &req)
+		if err != nil {
+			return apperrors.HandleError(c, err)
+		}
+		return c.JSON(res)
+	})
+
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	form.WriteField("type", "registration")
+	form.WriteField("name", "Registration")
+	part, _ := form.CreateFormFile("file", "registration.pdf")
+	part.Write([]byte("%PDF-1.7"))
+	form.Close()
+
+	req := httptest.NewRequest("POST", "/vehicles/VEH_1/documents", body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != fiber.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413, got %d", resp.StatusCode)
+	}
+	if len(storage.Blobs) != 0 {
+		t.Errorf("Expected nothing uploaded, got %d blobs", len(storage.Blobs))
+	}
+}
+
+func TestValidateDocumentDates(t *testing.T) {
+	now := time.Now()
+	issued := now.AddDate(-1, 0, 0)
+	expiry := now.AddDate(1, 0, 0)
+
+	if err := validateDocumentDates(&issued, &expiry, now); err != nil {
+		t.Errorf("Expected valid dates to pass, got %v", err)
+	}
+	if err := validateDocumentDates(nil, &issued, now); err != nil {
+		t.Errorf("Expected an expiry without issue date to pass, got %v", err)
+	}
+	if err := validateDocumentDates(&now, nil, now); err != nil {
+		t.Errorf("Expected a document issued now to pass, got %v", err)
+	}
+}
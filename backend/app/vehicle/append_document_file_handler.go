@@ -0,0 +1,111 @@
+package vehicle
+
+import (
+	"errors"
+	"microservicetest/app"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type AppendDocumentFileRequest struct {
+	VehicleID  string `param:"id" validate:"required"`
+	DocumentID string `param:"doc_id" validate:"required"`
+}
+
+type AppendDocumentFileResponse struct {
+	DocumentID string              `json:"document_id"`
+	File       domain.DocumentFile `json:"file"`
+	Warnings   []string            `json:"warnings,omitempty"`
+}
+
+type AppendDocumentFileHandler struct {
+	repository     Repository
+	storageService app.Storage
+	quotaBytes     int64 // Storage quota per vehicle, 0 means unlimited
+	auditLog       app.AuditLog
+}
+
+func NewAppendDocumentFileHandler(repository Repository, storageService app.Storage, quotaBytes int64, auditLog app.AuditLog) *AppendDocumentFileHandler {
+	return &AppendDocumentFileHandler{
+		repository:     repository,
+		storageService: storageService,
+		quotaBytes:     quotaBytes,
+		auditLog:       auditLog,
+	}
+}
+
+// Handle uploads the multipart "file" and adds it as the next page of the document
+func (h *AppendDocumentFileHandler) Handle(ctx *fiber.Ctx, req *AppendDocumentFileRequest) (*AppendDocumentFileResponse, error) {
+	if h.storageService == nil {
+		return nil, errStorageUnavailable
+	}
+
+	vehicleID := ctx.Params("id")
+	documentID := ctx.Params("doc_id")
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		return nil, apperrors.NewValidationError("file", "is required")
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, apperrors.ErrInternalServer.WithCause(err)
+	}
+	defer file.Close()
+
+	mimeType, ext, err := checkFileType(file)
+	if err != nil {
+		return nil, err
+	}
+	checksum, err := fileChecksum(file)
+	if err != nil {
+		return nil, err
+	}
+	fileName := ctx.FormValue("file_name", fileHeader.Filename)
+
+	if err := checkVehicleStorageQuota(ctx.UserContext(), h.repository, vehicleID, h.quotaBytes, fileHeader.Size); err != nil {
+		return nil, err
+	}
+
+	audit, err := beginAudit(ctx.UserContext(), h.auditLog, app.AuditEntry{
+		VehicleID: vehicleID,
+		Action:    app.AuditActionAppendDocumentFile,
+		Actor:     auditActor(ctx.UserContext(), ""),
+		Changes:   itemsChange("documents", nil, []string{documentID}),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer audit.abort(ctx.UserContext())
+
+	blobName := uuid.NewString() + ext
+	fileURL, err := h.storageService.Upload(ctx.UserContext(), file, blobName, mimeType)
+	if errors.Is(err, apperrors.ErrServiceUnavailable) {
+		// Upload limit reached, keep the 503 and its Retry-After
+		return nil, err
+	}
+	if err != nil {
+		return nil, apperrors.ErrInternalServer.WithCause(err)
+	}
+
+	appended, err := h.repository.AppendFileToDocument(ctx.UserContext(), vehicleID, documentID, domain.DocumentFile{
+		URL:      fileURL,
+		FileName: fileName,
+		FileSize: fileHeader.Size,
+		MimeType: mimeType,
+		Checksum: checksum,
+	})
+	if err != nil {
+		removeUnreferencedBlob(ctx.UserContext(), h.storageService, blobName, err)
+		return nil, err
+	}
+
+	return &AppendDocumentFileResponse{
+		DocumentID: documentID,
+		File:       appended,
+		Warnings:   audit.complete(ctx.UserContext()),
+	}, nil
+}
@@ -0,0 +1,109 @@
+package vehicle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func newAppendDocumentFileApp(handler *AppendDocumentFileHandler) *fiber.App {
+	app := fiber.New()
+	app.Post("/vehicles/:id/documents/:doc_id/files", func(c *fiber.Ctx) error {
+		res, err := handler.Handle(c, &AppendDocumentFileRequest{})
+		if err != nil {
+			return apperrors.HandleError(c, err)
+		}
+		return c.JSON(res)
+	})
+	return app
+}
+
+func postPage(t *testing.T, app *fiber.App) int {
+	t.Helper()
+
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	part, _ := form.CreateFormFile("file", "scan-2.pdf")
+	part.Write([]byte("%PDF-page two"))
+	form.Close()
+
+	req := httptest.NewRequest("POST", "/vehicles/VEH_1/documents/DOC_1/files", body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if resp.StatusCode == fiber.StatusOK {
+		var res AppendDocumentFileResponse
+		if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if res.File.Page != 2 {
+			t.Errorf("Expected page 2, got %d", res.File.Page)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestAppendDocumentFileHandler(t *testing.T) {
+	storage := &MockStorage{Blobs: map[string][]byte{}}
+	var appended domain.DocumentFile
+	mockRepo := &MockRepository{
+		AppendFileToDocumentFunc: func(ctx context.Context, vehicleID string, documentID string, file domain.DocumentFile) (domain.DocumentFile, error) {
+			appended = file
+			file.Page = 2
+			return file, nil
+		},
+	}
+
+	if status := postPage(t, newAppendDocumentFileApp(NewAppendDocumentFileHandler(mockRepo, storage, 0, nil))); status != fiber.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if appended.FileName != "scan-2.pdf" || appended.FileSize != 13 || len(storage.Blobs) != 1 {
+		t.Errorf("Expected the uploaded file to be appended, got %+v with %d blobs", appended, len(storage.Blobs))
+	}
+}
+
+func TestAppendDocumentFileHandler_UnknownDocumentRemovesBlob(t *testing.T) {
+	storage := &MockStorage{Blobs: map[string][]byte{}}
+	mockRepo := &MockRepository{
+		AppendFileToDocumentFunc: func(ctx context.Context, vehicleID string, documentID string, file domain.DocumentFile) (domain.DocumentFile, error) {
+			return domain.DocumentFile{}, apperrors.NewNotFoundError("document", documentID)
+		},
+	}
+
+	if status := postPage(t, newAppendDocumentFileApp(NewAppendDocumentFileHandler(mockRepo, storage, 0, nil))); status != fiber.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", status)
+	}
+	if len(storage.Blobs) != 0 {
+		t.Errorf("Expected the orphaned blob to be removed, got %d blobs", len(storage.Blobs))
+	}
+}
+
+func TestAppendDocumentFileHandler_QuotaExceeded(t *testing.T) {
+	storage := &MockStorage{Blobs: map[string][]byte{}}
+	mockRepo := &MockRepository{
+		GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+			return &domain.Vehicle{ID: id, Documents: []domain.Document{{ID: "DOC_1", FileSize: 1000}}}, nil
+		},
+		AppendFileToDocumentFunc: func(ctx context.Context, vehicleID string, documentID string, file domain.DocumentFile) (domain.DocumentFile, error) {
+			t.Error("Expected nothing to be appended over the quota")
+			return file, nil
+		},
+	}
+
+	if status := postPage(t, newAppendDocumentFileApp(NewAppendDocumentFileHandler(mockRepo, storage, 1010, nil))); status != fiber.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413, got %d", status)
+	}
+	if len(storage.Blobs) != 0 {
+		t.Errorf("Expected nothing uploaded, got %d blobs", len(storage.Blobs))
+	}
+}
@@ -0,0 +1,230 @@
+package vehicle
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"microservicetest/app"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/log"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// archiveStreamTimeout bounds the blob downloads done while streaming an archive
+const archiveStreamTimeout = 5 * time.Minute
+
+type GetVehicleArchiveRequest struct {
+	ID string `param:"id" validate:"required"`
+}
+
+// ArchiveManifest lists what was and was not written to a vehicle archive
+type ArchiveManifest struct {
+	VehicleID   string            `json:"vehicle_id"`
+	GeneratedAt time.Time         `json:"generated_at"`
+	Files       []string          `json:"files"`
+	Checksums   map[string]string `json:"checksums"` // SHA-256 of each file written, by name
+	Missing     []ArchiveEntry    `json:"missing"`
+}
+
+// ArchiveEntry describes a blob that could not be added to the archive, because
+// it could not be downloaded or did not match the checksum recorded at upload
+type ArchiveEntry struct {
+	Kind     string `json:"kind"` // document or picture
+	ID       string `json:"id"`
+	FileName string `json:"file_name"`
+	Page     int    `json:"page,omitempty"` // Set for document pages
+	Error    string `json:"error"`
+}
+
+type GetVehicleArchiveHandler struct {
+	repository     Repository
+	storageService app.Storage
+}
+
+func NewGetVehicleArchiveHandler(repository Repository, storageService app.Storage) *GetVehicleArchiveHandler {
+	return &GetVehicleArchiveHandler{
+		repository:     repository,
+		storageService: storageService,
+	}
+}
+
+// Handle streams a ZIP with vehicle.json, every document and picture blob and a
+// manifest.json naming the blobs that could not be fetched or failed their
+// checksum. Entries are deflated, each with its CRC-32. Only backend services
+// calling with an API key may export, as the archive holds every stored file.
+func (h *GetVehicleArchiveHandler) Handle(ctx *fiber.Ctx, req *GetVehicleArchiveRequest) error {
+	if _, ok := app.ServiceFromContext(ctx.UserContext()); !ok {
+		return apperrors.ErrUnauthorized.WithDetails(map[string]string{
+			"header": "X-API-Key",
+		})
+	}
+	if h.storageService == nil {
+		return errStorageUnavailable
+	}
+
+	vehicleID := ctx.Params("id")
+
+	vehicle, err := h.repository.GetVehicle(ctx.UserContext(), vehicleID)
+	if err != nil {
+		return err
+	}
+
+	// The stream writer runs after Handle returns, once the request context is
+	// already cancelled, so downloads get their own deadline
+	streamCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx.UserContext()), archiveStreamTimeout)
+
+	ctx.Set(fiber.HeaderContentType, "application/zip")
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"vehicle-%s.zip\"", vehicle.ID))
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+		if err := h.writeArchive(streamCtx, w, vehicle); err != nil {
+			log.FromContext(streamCtx).Error("Failed to stream vehicle archive",
+				zap.String("vehicle_id", vehicle.ID),
+				zap.Error(err),
+			)
+		}
+	})
+
+	return nil
+}
+
+func (h *GetVehicleArchiveHandler) writeArchive(ctx context.Context, w *bufio.Writer, vehicle *domain.Vehicle) error {
+	zw := zip.NewWriter(w)
+	manifest := ArchiveManifest{
+		VehicleID:   vehicle.ID,
+		GeneratedAt: time.Now(),
+		Files:       []string{},
+		Checksums:   map[string]string{},
+		Missing:     []ArchiveEntry{},
+	}
+	names := make(map[string]struct{})
+
+	if err := writeJSONEntry(zw, "vehicle.json", vehicle); err != nil {
+		return err
+	}
+
+	for _, doc := range vehicle.Documents {
+		for _, file := range doc.AllFiles() {
+			name := archiveName(names, "documents", doc.ID, file.FileName)
+			checksum, err := writeBlobEntry(ctx, h.storageService, zw, name, file.URL, file.Checksum)
+			if err != nil {
+				manifest.Missing = append(manifest.Missing, ArchiveEntry{Kind: "document", ID: doc.ID, FileName: file.FileName, Page: file.Page, Error: err.Error()})
+				continue
+			}
+			manifest.Files = append(manifest.Files, name)
+			manifest.Checksums[name] = checksum
+		}
+	}
+
+	for _, pic := range vehicle.Pictures {
+		name := archiveName(names, "pictures", pic.ID, pic.FileName)
+		checksum, err := writeBlobEntry(ctx, h.storageService, zw, name, pic.URL, pic.Checksum)
+		if err != nil {
+			manifest.Missing = append(manifest.Missing, ArchiveEntry{Kind: "picture", ID: pic.ID, FileName: pic.FileName, Error: err.Error()})
+			continue
+		}
+		manifest.Files = append(manifest.Files, name)
+		manifest.Checksums[name] = checksum
+	}
+
+	if err := writeJSONEntry(zw, "manifest.json", manifest); err != nil {
+		return err
+	}
+
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// writeBlobEntry downloads the blob behind fileURL and checks it against the
+// recorded checksum before creating the entry, so a missing or corrupted blob
+// leaves no file in the archive. It returns the checksum of what was written.
+func writeBlobEntry(ctx context.Context, storage app.Storage, zw *zip.Writer, name, fileURL, checksum string) (string, error) {
+	data, err := downloadFile(ctx, storage, fileURL)
+	if err != nil {
+		return "", err
+	}
+	if err := verifyChecksum(data, checksum); err != nil {
+		return "", err
+	}
+
+	f, err := zw.Create(name)
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Write(data); err != nil {
+		return "", err
+	}
+	return domain.Checksum(data), nil
+}
+
+func writeJSONEntry(zw *zip.Writer, name string, v any) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// archiveName returns dir/fileName, falling back to the ID when the name is
+// empty and prefixing it with the ID when the name is already taken. Pages of
+// one document can share a name too, those get a counter before the extension.
+func archiveName(taken map[string]struct{}, dir, id, fileName string) string {
+	base := path.Base(strings.ReplaceAll(fileName, "\\", "/"))
+	if base == "." || base == "/" {
+		base = id
+	}
+
+	name := dir + "/" + base
+	if _, ok := taken[name]; ok {
+		name = dir + "/" + id + "-" + base
+	}
+	ext := path.Ext(base)
+	for n := 2; ; n++ {
+		if _, ok := taken[name]; !ok {
+			break
+		}
+		name = fmt.Sprintf("%s/%s-%s-%d%s", dir, id, strings.TrimSuffix(base, ext), n, ext)
+	}
+
+	taken[name] = struct{}{}
+	return name
+}
+
+// blobNameFromURL returns the blob name, the last path segment of a stored file URL
+func blobNameFromURL(fileURL string) (string, error) {
+	parsedURL, err := url.Parse(fileURL)
+	if err != nil {
+		return "", err
+	}
+
+	pathParts := strings.Split(parsedURL.Path, "/")
+	blobName := pathParts[len(pathParts)-1]
+	if blobName == "" {
+		return "", fmt.Errorf("no blob name in file URL %q", fileURL)
+	}
+	return blobName, nil
+}
+
+// downloadFile downloads the blob behind a stored file URL
+func downloadFile(ctx context.Context, storage app.Storage, fileURL string) ([]byte, error) {
+	blobName, err := blobNameFromURL(fileURL)
+	if err != nil {
+		return nil, err
+	}
+	data, _, err := storage.Download(ctx, blobName)
+	return data, err
+}
@@ -0,0 +1,297 @@
+package vehicle
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"microservicetest/app"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MockStorage is an in-memory implementation of app.Storage keyed by blob name
+type MockStorage struct {
+	Blobs    map[string][]byte
+	Modified map[string]time.Time // Last modified time per blob, zero when not set
+	mu       sync.Mutex
+}
+
+func (m *MockStorage) Upload(ctx context.Context, file io.Reader, filename string, contentType string) (string, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Blobs[filename] = data
+	return "https://account.blob.core.windows.net/documents/" + filename, nil
+}
+
+func (m *MockStorage) Download(ctx context.Context, filename string) ([]byte, string, error) {
+	data, ok := m.Blobs[filename]
+	if !ok {
+		return nil, "", errors.New("blob not found")
+	}
+	return data, "application/octet-stream", nil
+}
+
+func (m *MockStorage) Remove(ctx context.Context, filename string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.Blobs, filename)
+	return nil
+}
+
+func (m *MockStorage) PresignUpload(ctx context.Context, filename string) (*app.PresignedUpload, error) {
+	return &app.PresignedUpload{
+		UploadURL: "https://account.blob.core.windows.net/documents/" + filename + "?sig=test",
+		FileURL:   "https://account.blob.core.windows.net/documents/" + filename,
+		ExpiresAt: time.Now().Add(15 * time.Minute),
+	}, nil
+}
+
+func (m *MockStorage) PresignRead(ctx context.Context, filename string) (string, time.Time, error) {
+	return "https://account.blob.core.windows.net/documents/" + filename + "?sig=read", time.Now().Add(15 * time.Minute), nil
+}
+
+func (m *MockStorage) StatBlob(ctx context.Context, filename string) (int64, string, bool, error) {
+	data, ok := m.Blobs[filename]
+	if !ok {
+		return 0, "", false, nil
+	}
+	return int64(len(data)), "application/pdf", true, nil
+}
+
+func (m *MockStorage) URL(filename string) string {
+	return "https://account.blob.core.windows.net/documents/" + filename
+}
+
+func (m *MockStorage) ListBlobs(ctx context.Context) ([]app.BlobInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var blobs []app.BlobInfo
+	for name, data := range m.Blobs {
+		blobs = append(blobs, app.BlobInfo{Name: name, Size: int64(len(data)), LastModified: m.Modified[name]})
+	}
+	return blobs, nil
+}
+
+// newArchiveApp serves the handler to the given service, or to an
+// unauthenticated client when service is empty
+func newArchiveApp(handler *GetVehicleArchiveHandler, service string) *fiber.App {
+	ctx := context.Background()
+	if service != "" {
+		ctx = app.WithService(ctx, service)
+	}
+
+	app := fiber.New()
+	app.Get("/vehicles/:id/archive", func(c *fiber.Ctx) error {
+		c.SetUserContext(ctx)
+		if err := handler.Handle(c, &GetVehicleArchiveRequest{}); err != nil {
+			return apperrors.HandleError(c, err)
+		}
+		return nil
+	})
+	return app
+}
+
+func TestGetVehicleArchiveHandler_StreamsZip(t *testing.T) {
+	vehicle := &domain.Vehicle{
+		ID:  "VEH_1",
+		VIN: "1HGBH41JXMN109186",
+		Documents: []domain.Document{
+			{ID: "DOC_1", FileName: "policy.pdf", FileURL: "https://account.blob.core.windows.net/documents/blob-1"},
+			{ID: "DOC_2", FileName: "policy.pdf", FileURL: "https://account.blob.core.windows.net/documents/blob-2"},
+			{ID: "DOC_3", FileName: "lost.pdf", FileURL: "https://account.blob.core.windows.net/documents/blob-3"},
+		},
+		Pictures: []domain.Picture{
+			{ID: "PIC_1", FileName: "front.jpg", URL: "https://account.blob.core.windows.net/documents/blob-4"},
+		},
+	}
+	repo := &MockRepository{
+		GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+			return vehicle, nil
+		},
+	}
+	storage := &MockStorage{Blobs: map[string][]byte{
+		"blob-1": []byte("first"),
+		"blob-2": []byte("second"),
+		"blob-4": []byte("picture"),
+	}}
+	app := newArchiveApp(NewGetVehicleArchiveHandler(repo, storage), "backoffice")
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/vehicles/VEH_1/archive", nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if ct := resp.Header.Get(fiber.HeaderContentType); ct != "application/zip" {
+		t.Errorf("Expected application/zip, got %s", ct)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read body: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatalf("Expected a valid zip, got %v", err)
+	}
+
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", f.Name, err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+
+	expected := map[string]string{
+		"documents/policy.pdf":       "first",
+		"documents/DOC_2-policy.pdf": "second",
+		"pictures/front.jpg":         "picture",
+	}
+	for name, content := range expected {
+		if files[name] != content {
+			t.Errorf("Expected %s to contain %q, got %q", name, content, files[name])
+		}
+	}
+	if _, ok := files["documents/lost.pdf"]; ok {
+		t.Error("Expected missing blob to be left out of the archive")
+	}
+
+	var archived domain.Vehicle
+	if err := json.Unmarshal([]byte(files["vehicle.json"]), &archived); err != nil || archived.VIN != vehicle.VIN {
+		t.Errorf("Expected vehicle.json with VIN %s, got %v (%v)", vehicle.VIN, archived.VIN, err)
+	}
+
+	var manifest ArchiveManifest
+	if err := json.Unmarshal([]byte(files["manifest.json"]), &manifest); err != nil {
+		t.Fatalf("Failed to decode manifest: %v", err)
+	}
+	if len(manifest.Missing) != 1 || manifest.Missing[0].ID != "DOC_3" {
+		t.Errorf("Expected DOC_3 to be listed as missing, got %+v", manifest.Missing)
+	}
+}
+
+func TestGetVehicleArchiveHandler_FlagsChecksumMismatch(t *testing.T) {
+	vehicle := &domain.Vehicle{
+		ID: "VEH_1",
+		Documents: []domain.Document{
+			{ID: "DOC_1", FileName: "intact.pdf", FileURL: "https://account.blob.core.windows.net/documents/blob-1", Checksum: domain.Checksum([]byte("first"))},
+			{ID: "DOC_2", FileName: "corrupt.pdf", FileURL: "https://account.blob.core.windows.net/documents/blob-2", Checksum: domain.Checksum([]byte("original"))},
+			{ID: "DOC_3", FileName: "legacy.pdf", FileURL: "https://account.blob.core.windows.net/documents/blob-3"},
+		},
+	}
+	repo := &MockRepository{
+		GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+			return vehicle, nil
+		},
+	}
+	storage := &MockStorage{Blobs: map[string][]byte{
+		"blob-1": []byte("first"),
+		"blob-2": []byte("truncated"),
+		"blob-3": []byte("third"),
+	}}
+	app := newArchiveApp(NewGetVehicleArchiveHandler(repo, storage), "backoffice")
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/vehicles/VEH_1/archive", nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatalf("Expected a valid zip, got %v", err)
+	}
+
+	var manifest ArchiveManifest
+	names := make(map[string]bool)
+	for _, f := range zr.File {
+		names[f.Name] = true
+		if f.Method != zip.Deflate {
+			t.Errorf("Expected %s to be deflated", f.Name)
+		}
+		if f.Name == "manifest.json" {
+			rc, _ := f.Open()
+			err := json.NewDecoder(rc).Decode(&manifest)
+			rc.Close()
+			if err != nil {
+				t.Fatalf("Failed to decode manifest: %v", err)
+			}
+		}
+	}
+
+	if names["documents/corrupt.pdf"] {
+		t.Error("Expected the corrupted file to be left out of the archive")
+	}
+	if !names["documents/intact.pdf"] || !names["documents/legacy.pdf"] {
+		t.Error("Expected the intact file and the file without a checksum to be archived")
+	}
+	if len(manifest.Missing) != 1 || manifest.Missing[0].ID != "DOC_2" || !strings.Contains(manifest.Missing[0].Error, "checksum mismatch") {
+		t.Errorf("Expected DOC_2 to be flagged with a checksum mismatch, got %+v", manifest.Missing)
+	}
+	if manifest.Checksums["documents/legacy.pdf"] != domain.Checksum([]byte("third")) {
+		t.Errorf("Expected the manifest to record the checksum of each written file, got %+v", manifest.Checksums)
+	}
+}
+
+func TestGetVehicleArchiveHandler_RequiresService(t *testing.T) {
+	repo := &MockRepository{
+		GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+			t.Error("Expected no vehicle to be read without an API key")
+			return nil, nil
+		},
+	}
+	app := newArchiveApp(NewGetVehicleArchiveHandler(repo, &MockStorage{}), "")
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/vehicles/VEH_1/archive", nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != fiber.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get(fiber.HeaderContentType); ct == "application/zip" {
+		t.Error("Expected no archive to be streamed")
+	}
+}
+
+func TestArchiveName(t *testing.T) {
+	taken := make(map[string]struct{})
+
+	tests := []struct {
+		id       string
+		fileName string
+		expected string
+	}{
+		{"DOC_1", "policy.pdf", "documents/policy.pdf"},
+		{"DOC_2", "policy.pdf", "documents/DOC_2-policy.pdf"},
+		{"DOC_3", "../../etc/passwd", "documents/passwd"},
+		{"DOC_4", `..\..\evil.exe`, "documents/evil.exe"},
+		{"DOC_5", "", "documents/DOC_5"},
+		// Three pages of one document with the same name
+		{"DOC_6", "scan.jpg", "documents/scan.jpg"},
+		{"DOC_6", "scan.jpg", "documents/DOC_6-scan.jpg"},
+		{"DOC_6", "scan.jpg", "documents/DOC_6-scan-2.jpg"},
+		{"DOC_6", "scan.jpg", "documents/DOC_6-scan-3.jpg"},
+	}
+
+	for _, tt := range tests {
+		if name := archiveName(taken, "documents", tt.id, tt.fileName); name != tt.expected {
+			t.Errorf("archiveName(%q, %q) = %q, expected %q", tt.id, tt.fileName, name, tt.expected)
+		}
+	}
+}
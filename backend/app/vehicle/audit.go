@@ -0,0 +1,122 @@
+package vehicle
+
+import (
+	"context"
+	"encoding/json"
+	"microservicetest/app"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/log"
+	"microservicetest/pkg/metrics"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// auditFailedWarning is returned when the change was stored but its audit
+// entry was not
+const auditFailedWarning = "the change was saved but could not be recorded in the audit log"
+
+// auditAbortTimeout bounds marking an entry failed, which may happen after the
+// request context has ended
+const auditAbortTimeout = 10 * time.Second
+
+// errAuditUnavailable refuses a change whose entry a fail-closed audit log could not store
+var errAuditUnavailable = apperrors.ErrServiceUnavailable.WithDetails(map[string]string{
+	"dependency": "audit_log",
+})
+
+// auditRecord is the audit entry of one change. Handlers call beginAudit
+// before making the change, defer abort, and call complete once it is stored.
+type auditRecord struct {
+	auditLog  app.AuditLog
+	entry     app.AuditEntry
+	pending   bool // A fail-closed audit log holds the entry as pending
+	completed bool
+}
+
+// beginAudit starts the entry for a change about to be made, with a new ID
+// and the current time. With an app.FailClosedAuditLog the entry is written as
+// pending first and a failed write refuses the change; other audit logs write
+// nothing until complete. Handlers built without an audit log record nothing.
+func beginAudit(ctx context.Context, auditLog app.AuditLog, entry app.AuditEntry) (*auditRecord, error) {
+	record := &auditRecord{auditLog: auditLog, entry: entry}
+	if auditLog == nil {
+		return record, nil
+	}
+
+	record.entry.ID = uuid.NewString()
+	record.entry.OccurredAt = time.Now()
+	if _, failClosed := auditLog.(app.FailClosedAuditLog); !failClosed {
+		return record, nil
+	}
+
+	record.entry.Status = app.AuditStatusPending
+	if err := auditLog.Record(ctx, record.entry); err != nil {
+		log.FromContext(ctx).Error("Failed to write audit entry, refusing the change", append(app.AuditEntryFields(record.entry), zap.Error(err))...)
+		return nil, errAuditUnavailable.WithCause(err)
+	}
+	record.pending = true
+	return record, nil
+}
+
+// complete writes the entry of a change that was stored. The change cannot be
+// undone by then, so a failed write fails nothing: the entry is logged in
+// full, counted in audit_entries_dropped and returned as a warning for the
+// response. With a fail-closed audit log the pending entry stays behind.
+func (r *auditRecord) complete(ctx context.Context) []string {
+	r.completed = true
+	if r.auditLog == nil {
+		return nil
+	}
+
+	r.entry.Status = ""
+	if err := r.auditLog.Record(ctx, r.entry); err != nil {
+		metrics.AuditEntriesDropped.Add(1)
+		log.FromContext(ctx).Error("Failed to write audit entry", append(app.AuditEntryFields(r.entry), zap.Error(err))...)
+		return []string{auditFailedWarning}
+	}
+	return nil
+}
+
+// abort marks a pending entry failed when its change was not made. It is
+// deferred right after beginAudit and does nothing once complete has run.
+func (r *auditRecord) abort(ctx context.Context) {
+	if !r.pending || r.completed {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), auditAbortTimeout)
+	defer cancel()
+
+	r.entry.Status = app.AuditStatusFailed
+	if err := r.auditLog.Record(ctx, r.entry); err != nil {
+		log.FromContext(ctx).Error("Failed to mark audit entry failed, it stays pending", append(app.AuditEntryFields(r.entry), zap.Error(err))...)
+	}
+}
+
+// auditActor is the actor named by the request or, when it names none, the
+// calling service
+func auditActor(ctx context.Context, named string) string {
+	if named != "" {
+		return named
+	}
+	if service, ok := app.ServiceFromContext(ctx); ok {
+		return service
+	}
+	return "unknown"
+}
+
+// itemsChange records the IDs of the items added to or removed from one of
+// the vehicle's lists, such as its documents or pictures
+func itemsChange(field string, removed, added []string) []domain.FieldChange {
+	change := domain.FieldChange{Field: field, Old: json.RawMessage("null"), New: json.RawMessage("null")}
+	if removed != nil {
+		change.Old, _ = json.Marshal(removed)
+	}
+	if added != nil {
+		change.New, _ = json.Marshal(added)
+	}
+	return []domain.FieldChange{change}
+}
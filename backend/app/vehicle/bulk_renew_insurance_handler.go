@@ -0,0 +1,122 @@
+package vehicle
+
+import (
+	"context"
+	"microservicetest/app"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/response"
+	"microservicetest/pkg/validator"
+	"sync"
+	"time"
+)
+
+// bulkRenewWorkers bounds the renewals written to Couchbase at once
+const bulkRenewWorkers = 8
+
+type InsuranceRenewal struct {
+	VehicleID     string        `json:"vehicle_id" validate:"required"`
+	NewEndDate    time.Time     `json:"new_end_date" validate:"required"`
+	PolicyNumber  string        `json:"policy_number" validate:"max=50"` // Empty keeps the current policy number
+	PremiumAmount *domain.Money `json:"premium_amount"`                  // Nil keeps the current premium
+}
+
+type BulkRenewInsuranceRequest struct {
+	Renewals  []InsuranceRenewal `json:"renewals" validate:"required,min=1,max=100,dive"`
+	RenewedBy string             `json:"renewed_by" validate:"required"`
+}
+
+type BulkRenewInsuranceResponse struct {
+	Results   []response.ItemResult `json:"results"` // In request order, the ID is the vehicle ID
+	Succeeded int                   `json:"succeeded"`
+	Failed    int                   `json:"failed"`
+	Warnings  []string              `json:"warnings,omitempty"` // Renewals stored without an audit entry, by vehicle ID
+}
+
+func (r *BulkRenewInsuranceResponse) ItemResults() []response.ItemResult {
+	return r.Results
+}
+
+type BulkRenewInsuranceHandler struct {
+	repository Repository
+	auditLog   app.AuditLog
+}
+
+func NewBulkRenewInsuranceHandler(repository Repository, auditLog app.AuditLog) *BulkRenewInsuranceHandler {
+	return &BulkRenewInsuranceHandler{
+		repository: repository,
+		auditLog:   auditLog,
+	}
+}
+
+func (h *BulkRenewInsuranceHandler) Handle(ctx context.Context, req *BulkRenewInsuranceRequest) (*BulkRenewInsuranceResponse, error) {
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.ErrInvalidInput.WithDetails(map[string]string{
+			"validation": err.Error(),
+		})
+	}
+
+	results := make([]response.ItemResult, len(req.Renewals))
+	warnings := make([][]string, len(req.Renewals))
+	indexes := make(chan int)
+	now := time.Now()
+
+	var wg sync.WaitGroup
+	for range min(bulkRenewWorkers, len(req.Renewals)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i], warnings[i] = h.renew(ctx, i, req.Renewals[i], req.RenewedBy, now)
+			}
+		}()
+	}
+	for i := range req.Renewals {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	res := &BulkRenewInsuranceResponse{Results: results}
+	for i, result := range results {
+		for _, warning := range warnings[i] {
+			res.Warnings = append(res.Warnings, result.ID+": "+warning)
+		}
+		if result.Status == response.ItemSucceeded {
+			res.Succeeded++
+		} else {
+			res.Failed++
+		}
+	}
+	return res, nil
+}
+
+// renew renews one vehicle's insurance and records it in the audit log,
+// returning the warning of an entry that could not be written
+func (h *BulkRenewInsuranceHandler) renew(ctx context.Context, index int, renewal InsuranceRenewal, renewedBy string, now time.Time) (response.ItemResult, []string) {
+	result := response.ItemResult{Index: index, ID: renewal.VehicleID}
+
+	var audit *auditRecord
+	err := ctx.Err()
+	if err == nil && !renewal.NewEndDate.After(now) {
+		err = apperrors.NewUnprocessableError("new_end_date", "must be in the future")
+	}
+	if err == nil {
+		audit, err = beginAudit(ctx, h.auditLog, app.AuditEntry{
+			VehicleID: renewal.VehicleID,
+			Action:    app.AuditActionRenewInsurance,
+			Actor:     renewedBy,
+		})
+	}
+	if err == nil {
+		defer audit.abort(ctx)
+		err = h.repository.RenewInsurance(ctx, renewal.VehicleID, renewal.PolicyNumber, renewal.NewEndDate, renewal.PremiumAmount, renewedBy)
+	}
+	if err == nil {
+		result.Status = response.ItemSucceeded
+		return result, audit.complete(ctx)
+	}
+
+	result.Status, result.Error = response.ItemFailed, apperrors.NewItemError(err)
+	return result, nil
+}
@@ -0,0 +1,143 @@
+package vehicle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/response"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestBulkRenewInsuranceHandler_PerItemResults(t *testing.T) {
+	var mu sync.Mutex
+	renewed := map[string]string{}
+	mockRepo := &MockRepository{
+		RenewInsuranceFunc: func(ctx context.Context, vehicleID string, policyNumber string, endDate time.Time, premium *domain.Money, renewedBy string) error {
+			switch vehicleID {
+			case "VEH_MISSING":
+				return apperrors.NewNotFoundError("vehicle", vehicleID)
+			case "VEH_LATER":
+				return apperrors.NewUnprocessableError("new_end_date", "new end date is not after the current end date")
+			}
+			mu.Lock()
+			renewed[vehicleID] = policyNumber
+			mu.Unlock()
+			return nil
+		},
+	}
+	handler := NewBulkRenewInsuranceHandler(mockRepo, nil)
+
+	nextYear := time.Now().AddDate(1, 0, 0)
+	resp, err := handler.Handle(context.Background(), &BulkRenewInsuranceRequest{
+		RenewedBy: "insurer-sync",
+		Renewals: []InsuranceRenewal{
+			{VehicleID: "VEH_1", NewEndDate: nextYear, PolicyNumber: "POL-2"},
+			{VehicleID: "VEH_MISSING", NewEndDate: nextYear},
+			{VehicleID: "VEH_PAST", NewEndDate: time.Now().AddDate(0, 0, -1)},
+			{VehicleID: "VEH_LATER", NewEndDate: nextYear},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if resp.Succeeded != 1 || resp.Failed != 3 {
+		t.Errorf("Expected 1 succeeded and 3 failed, got %d and %d", resp.Succeeded, resp.Failed)
+	}
+
+	expected := []struct {
+		id, status, code, message string
+	}{
+		{"VEH_1", response.ItemSucceeded, "", ""},
+		{"VEH_MISSING", response.ItemFailed, "RESOURCE_NOT_FOUND", apperrors.ErrResourceNotFound.Message},
+		{"VEH_PAST", response.ItemFailed, "UNPROCESSABLE_ENTITY", "must be in the future"},
+		{"VEH_LATER", response.ItemFailed, "UNPROCESSABLE_ENTITY", "new end date is not after the current end date"},
+	}
+	for i, want := range expected {
+		got := resp.Results[i]
+		code, message := "", ""
+		if got.Error != nil {
+			code, message = got.Error.Code, got.Error.Message
+		}
+		if got.Index != i || got.ID != want.id || got.Status != want.status || code != want.code || message != want.message {
+			t.Errorf("Expected result %d to be %+v, got %+v", i, want, got)
+		}
+	}
+	if response.BatchStatus(resp.ItemResults()) != fiber.StatusMultiStatus {
+		t.Error("Expected a partially failed batch to be sent as 207 Multi-Status")
+	}
+
+	if _, ok := renewed["VEH_PAST"]; ok {
+		t.Error("Expected a past end date not to reach the repository")
+	}
+	if renewed["VEH_1"] != "POL-2" {
+		t.Errorf("Expected VEH_1 renewed with POL-2, got %q", renewed["VEH_1"])
+	}
+}
+
+func TestBulkRenewInsuranceHandler_BatchLimit(t *testing.T) {
+	handler := NewBulkRenewInsuranceHandler(&MockRepository{}, nil)
+
+	renewals := make([]InsuranceRenewal, 101)
+	for i := range renewals {
+		renewals[i] = InsuranceRenewal{VehicleID: fmt.Sprintf("VEH_%d", i), NewEndDate: time.Now().AddDate(1, 0, 0)}
+	}
+
+	_, err := handler.Handle(context.Background(), &BulkRenewInsuranceRequest{RenewedBy: "insurer-sync", Renewals: renewals})
+	if !errors.Is(err, apperrors.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for 101 renewals, got %v", err)
+	}
+}
+
+func TestRenewInsurance_EndDateMustMoveForward(t *testing.T) {
+	current := time.Now().AddDate(0, 6, 0)
+	vehicle := &domain.Vehicle{Insurance: domain.InsuranceInfo{PolicyNumber: "POL-1", EndDate: current}}
+
+	if err := vehicle.RenewInsurance("", current.AddDate(0, 0, -1), nil); err == nil {
+		t.Error("Expected an earlier end date to be rejected")
+	}
+
+	if err := vehicle.RenewInsurance("", current.AddDate(1, 0, 0), nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if vehicle.Insurance.PolicyNumber != "POL-1" || !vehicle.Insurance.IsActive {
+		t.Errorf("Expected policy POL-1 kept and active, got %+v", vehicle.Insurance)
+	}
+}
+
+func TestBulkRenewInsuranceHandler_PremiumCurrency(t *testing.T) {
+	var premium *domain.Money
+	handler := NewBulkRenewInsuranceHandler(&MockRepository{
+		RenewInsuranceFunc: func(ctx context.Context, vehicleID string, policyNumber string, endDate time.Time, p *domain.Money, renewedBy string) error {
+			premium = p
+			return nil
+		},
+	}, nil)
+	renew := func(currency string) error {
+		_, err := handler.Handle(context.Background(), &BulkRenewInsuranceRequest{
+			RenewedBy: "insurer-sync",
+			Renewals: []InsuranceRenewal{{
+				VehicleID:     "VEH_1",
+				NewEndDate:    time.Now().AddDate(1, 0, 0),
+				PremiumAmount: &domain.Money{AmountMinor: 1250000, Currency: currency},
+			}},
+		})
+		return err
+	}
+
+	if err := renew("XYZ"); !errors.Is(err, apperrors.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for a non ISO 4217 currency, got %v", err)
+	}
+	if err := renew("EUR"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if premium == nil || premium.AmountMinor != 1250000 || premium.Currency != "EUR" {
+		t.Errorf("Expected the premium passed to the repository, got %+v", premium)
+	}
+}
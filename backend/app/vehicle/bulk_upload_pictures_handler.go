@@ -0,0 +1,416 @@
+package vehicle
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"microservicetest/app"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/log"
+	"microservicetest/pkg/response"
+	"microservicetest/pkg/validator"
+	"mime/multipart"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxBulkPictures bounds the files of one bulk upload
+	maxBulkPictures = 50
+	// bulkPictureWorkers bounds the pictures uploaded to Blob Storage at once
+	bulkPictureWorkers = 4
+)
+
+type BulkUploadPicturesRequest struct {
+	VehicleID string `param:"id" validate:"required"`
+}
+
+// PictureUploadResult reports one file of a bulk upload, in the order the
+// files were sent. The ID is the ID of the added picture.
+type PictureUploadResult struct {
+	response.ItemResult
+	FileName string `json:"file_name"`
+	Type     string `json:"type"`
+}
+
+type BulkUploadPicturesResponse struct {
+	Results       []PictureUploadResult `json:"results"`
+	Uploaded      int                   `json:"uploaded"`
+	Failed        int                   `json:"failed"`
+	MainPictureID string                `json:"main_picture_id,omitempty"`
+	Warnings      []string              `json:"warnings,omitempty"`
+}
+
+func (r *BulkUploadPicturesResponse) ItemResults() []response.ItemResult {
+	results := make([]response.ItemResult, len(r.Results))
+	for i, result := range r.Results {
+		results[i] = result.ItemResult
+	}
+	return results
+}
+
+type BulkUploadPicturesHandler struct {
+	repository Repository
+	storage    app.Storage
+	quotaBytes int64 // Storage quota per vehicle, 0 means unlimited
+	auditLog   app.AuditLog
+}
+
+func NewBulkUploadPicturesHandler(repository Repository, storage app.Storage, quotaBytes int64, auditLog app.AuditLog) *BulkUploadPicturesHandler {
+	return &BulkUploadPicturesHandler{
+		repository: repository,
+		storage:    storage,
+		quotaBytes: quotaBytes,
+		auditLog:   auditLog,
+	}
+}
+
+// preparedPicture is a decoded picture ready to be uploaded
+type preparedPicture struct {
+	data      []byte
+	thumbnail []byte
+	mimeType  string
+	ext       string // Canonical extension of mimeType
+	width     int
+	height    int
+}
+
+// Handle uploads every file part of the form with the type part at the same
+// position. Files that fail are reported in the results; the others are added
+// to the vehicle in a single write, after which the main picture is picked once.
+func (h *BulkUploadPicturesHandler) Handle(ctx *fiber.Ctx, req *BulkUploadPicturesRequest) (*BulkUploadPicturesResponse, error) {
+	if h.storage == nil {
+		return nil, errStorageUnavailable
+	}
+
+	req.VehicleID = ctx.Params("id")
+
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.ErrInvalidInput.WithDetails(map[string]string{
+			"validation": err.Error(),
+		})
+	}
+
+	form, err := ctx.MultipartForm()
+	if err != nil {
+		return nil, apperrors.NewValidationError("file", "expected a multipart form")
+	}
+	files := form.File["file"]
+	types := form.Value["type"]
+	if len(files) == 0 {
+		return nil, apperrors.NewValidationError("file", "at least one file is required")
+	}
+	if len(files) > maxBulkPictures {
+		return nil, apperrors.NewValidationError("file", fmt.Sprintf("at most %d files per request", maxBulkPictures))
+	}
+	if len(types) != len(files) {
+		return nil, apperrors.NewValidationError("type", "one type is required per file")
+	}
+	uploadedBy := ctx.FormValue("uploaded_by")
+
+	userCtx := ctx.UserContext()
+	vehicle, err := h.repository.GetVehicle(userCtx, req.VehicleID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]PictureUploadResult, len(files))
+	prepared := make([]*preparedPicture, len(files))
+	var uploadBytes int64
+	for i, fileHeader := range files {
+		results[i] = PictureUploadResult{
+			ItemResult: response.ItemResult{Index: i},
+			FileName:   fileHeader.Filename,
+			Type:       types[i],
+		}
+		prepared[i], err = preparePicture(fileHeader, types[i])
+		if err != nil {
+			var appErr *apperrors.AppError
+			if !errors.As(err, &appErr) {
+				err = apperrors.NewValidationError("file", err.Error())
+			}
+			results[i].Status, results[i].Error = response.ItemFailed, apperrors.NewItemError(err)
+			continue
+		}
+		uploadBytes += int64(len(prepared[i].data) + len(prepared[i].thumbnail))
+	}
+
+	if err := checkStorageQuota(vehicle, h.quotaBytes, uploadBytes); err != nil {
+		return nil, err
+	}
+
+	// The added pictures are only known once stored, the entry is completed
+	// with them. Nothing is recorded when none could be added.
+	audit, err := beginAudit(userCtx, h.auditLog, app.AuditEntry{
+		VehicleID: req.VehicleID,
+		Action:    app.AuditActionAddPictures,
+		Actor:     auditActor(userCtx, uploadedBy),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer audit.abort(userCtx)
+
+	now := time.Now()
+	pictureID := domain.GeneratePictureID()
+	pictures := make([]*domain.Picture, len(files))
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(bulkPictureWorkers, len(files)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				picture := domain.Picture{
+					ID:         pictureID + "_" + strconv.Itoa(i+1),
+					Type:       domain.PictureType(types[i]),
+					FileName:   files[i].Filename,
+					UploadedAt: now,
+					UploadedBy: uploadedBy,
+					SortOrder:  len(vehicle.Pictures) + i,
+				}
+				if err := h.upload(userCtx, prepared[i], &picture); err != nil {
+					results[i].Status, results[i].Error = response.ItemFailed, apperrors.NewItemError(err)
+					continue
+				}
+				pictures[i] = &picture
+			}
+		}()
+	}
+	for i := range files {
+		if prepared[i] != nil {
+			indexes <- i
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	var added []domain.Picture
+	for _, picture := range pictures {
+		if picture != nil {
+			added = append(added, *picture)
+		}
+	}
+
+	res := &BulkUploadPicturesResponse{Results: results}
+	if len(added) > 0 {
+		updated, err := h.repository.AddPictures(userCtx, req.VehicleID, added)
+		if err != nil {
+			h.removeBlobs(userCtx, req.VehicleID, added)
+			return nil, err
+		}
+		if main := updated.GetMainPicture(); main != nil {
+			res.MainPictureID = main.ID
+		}
+
+		ids := make([]string, len(added))
+		for i, picture := range added {
+			ids[i] = picture.ID
+		}
+		audit.entry.Changes = itemsChange("pictures", nil, ids)
+		res.Warnings = audit.complete(userCtx)
+	}
+
+	for i := range results {
+		if pictures[i] != nil {
+			results[i].Status, results[i].ID = response.ItemSucceeded, pictures[i].ID
+			res.Uploaded++
+		} else {
+			res.Failed++
+		}
+	}
+
+	return res, nil
+}
+
+// preparePicture checks the picture type, sniffs the content type of the file
+// (the declared one is ignored), checks its dimensions against the minimum of
+// its type and renders its thumbnail. Allowed formats that cannot be decoded,
+// such as HEIC, are kept as they are, without a thumbnail; when their
+// dimensions cannot be read either they are only accepted for types without
+// a minimum.
+func preparePicture(fileHeader *multipart.FileHeader, picType string) (*preparedPicture, error) {
+	if !domain.IsValidPictureType(picType) {
+		return nil, fmt.Errorf("type %q is not a known picture type", picType)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	mimeType := sniffMimeType(data)
+	ext, err := allowedFileType(mimeType)
+	if err != nil {
+		return nil, fmt.Errorf("content type %q is not accepted", mimeType)
+	}
+	if !strings.HasPrefix(mimeType, "image/") {
+		return nil, fmt.Errorf("content type %q is not an image", mimeType)
+	}
+
+	width, height, err := pictureDimensions(data, mimeType)
+	if err != nil {
+		return nil, err
+	}
+	if width == 0 {
+		if minimum, ok := domain.MinPictureResolution(domain.PictureType(picType)); ok {
+			return nil, apperrors.ErrPictureResolutionTooLow.WithDetails(map[string]any{
+				"type":     picType,
+				"required": minimum,
+				"reason":   "the dimensions of the file cannot be read",
+			})
+		}
+	} else if err := checkPictureResolution(domain.PictureType(picType), width, height); err != nil {
+		return nil, err
+	}
+
+	if !pictureMimeTypes[mimeType] {
+		return &preparedPicture{data: data, mimeType: mimeType, ext: ext, width: width, height: height}, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("file is not a readable image")
+	}
+
+	thumbnail, err := makeThumbnail(img, thumbnailMaxSide)
+	if err != nil {
+		return nil, err
+	}
+
+	return &preparedPicture{
+		data:      data,
+		thumbnail: thumbnail,
+		mimeType:  mimeType,
+		ext:       ext,
+		width:     img.Bounds().Dx(),
+		height:    img.Bounds().Dy(),
+	}, nil
+}
+
+// pictureDimensions reads the width and height of a picture from its header,
+// without decoding it. They are 0 for a format it cannot read them from.
+func pictureDimensions(data []byte, mimeType string) (width, height int, err error) {
+	switch {
+	case pictureMimeTypes[mimeType]:
+		config, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return 0, 0, errors.New("file is not a readable image")
+		}
+		return config.Width, config.Height, nil
+	case mimeType == "image/heic" || mimeType == "image/heif":
+		width, height = heifDimensions(data)
+		return width, height, nil
+	}
+	return 0, 0, nil
+}
+
+// heifDimensions reads the size of a HEIF image from its ispe (image spatial
+// extents) properties. Photos are often stored as a grid of tiles, each with
+// its own ispe next to the one of the whole image, so the largest is taken.
+func heifDimensions(data []byte) (width, height int) {
+	for i := 0; ; {
+		n := bytes.Index(data[i:], []byte("ispe"))
+		if n < 0 {
+			return width, height
+		}
+		i += n + 4
+		// Box type, then a version and flags word, then width and height
+		if i+12 > len(data) {
+			return width, height
+		}
+		w := int(binary.BigEndian.Uint32(data[i+4:]))
+		h := int(binary.BigEndian.Uint32(data[i+8:]))
+		if w*h > width*height {
+			width, height = w, h
+		}
+	}
+}
+
+// checkPictureResolution rejects a picture smaller than the minimum of its type,
+// reporting both sizes
+func checkPictureResolution(picType domain.PictureType, width, height int) error {
+	if picType.MeetsMinResolution(width, height) {
+		return nil
+	}
+	minimum, _ := domain.MinPictureResolution(picType)
+	return apperrors.ErrPictureResolutionTooLow.WithDetails(map[string]any{
+		"type":     picType,
+		"required": minimum,
+		"actual":   domain.Resolution{Width: width, Height: height},
+	})
+}
+
+// upload stores the picture and its thumbnail and fills in their URLs and the
+// file details. A picture whose thumbnail fails is removed again.
+func (h *BulkUploadPicturesHandler) upload(ctx context.Context, prepared *preparedPicture, picture *domain.Picture) error {
+	filename := uuid.NewString()
+
+	fileURL, err := h.storage.Upload(ctx, bytes.NewReader(prepared.data), filename+prepared.ext, prepared.mimeType)
+	if err != nil {
+		return err
+	}
+	var thumbnailURL string
+	if prepared.thumbnail != nil {
+		thumbnailURL, err = h.storage.Upload(ctx, bytes.NewReader(prepared.thumbnail), filename+"-thumb.jpg", "image/jpeg")
+	}
+	if err != nil {
+		if removeErr := h.storage.Remove(ctx, filename+prepared.ext); removeErr != nil {
+			log.FromContext(ctx).Error("Failed to delete picture blob from storage",
+				zap.String("picture_id", picture.ID),
+				zap.String("url", fileURL),
+				zap.Error(removeErr))
+		}
+		return err
+	}
+
+	picture.URL = fileURL
+	picture.ThumbnailURL = thumbnailURL
+	picture.FileSize = int64(len(prepared.data))
+	picture.ThumbnailSize = int64(len(prepared.thumbnail))
+	picture.MimeType = prepared.mimeType
+	picture.Checksum = domain.Checksum(prepared.data)
+	picture.Width = prepared.width
+	picture.Height = prepared.height
+	return nil
+}
+
+// removeBlobs deletes the files of pictures that could not be added to the
+// vehicle. A blob left behind is only logged.
+func (h *BulkUploadPicturesHandler) removeBlobs(ctx context.Context, vehicleID string, pictures []domain.Picture) {
+	for _, pic := range pictures {
+		for _, fileURL := range []string{pic.URL, pic.ThumbnailURL} {
+			if fileURL == "" {
+				continue
+			}
+			blobName, err := blobNameFromURL(fileURL)
+			if err == nil {
+				err = h.storage.Remove(ctx, blobName)
+			}
+			if err != nil {
+				log.FromContext(ctx).Error("Failed to delete picture blob from storage",
+					zap.String("vehicle_id", vehicleID),
+					zap.String("picture_id", pic.ID),
+					zap.String("url", fileURL),
+					zap.Error(err))
+			}
+		}
+	}
+}
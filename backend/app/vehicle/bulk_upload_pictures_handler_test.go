@@ -0,0 +1,297 @@
+package vehicle
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"image"
+	"image/png"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/response"
+	"mime/multipart"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type bulkPicturePart struct {
+	fileName    string
+	contentType string
+	picType     string
+	data        []byte
+}
+
+func pngImage(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// webpImage is a 1x1 lossless WebP
+var webpImage, _ = base64.StdEncoding.DecodeString("UklGRhoAAABXRUJQVlA4TA0AAAAvAAAAEAcQERGIiP4HAA==")
+
+// heicImage is the ftyp box an iPhone photo starts with; HEIC is not decoded
+var heicImage = []byte("\x00\x00\x00\x18ftypheic\x00\x00\x00\x00mif1heic")
+
+// webpHeader is a lossless WebP header of the given size, enough for
+// image.DecodeConfig but not for decoding
+func webpHeader(width, height int) []byte {
+	bits := uint32(width-1) | uint32(height-1)<<14
+	vp8l := []byte{0x2f, byte(bits), byte(bits >> 8), byte(bits >> 16), byte(bits >> 24), 0, 0, 0}
+	chunk := append([]byte("VP8L"), binary.LittleEndian.AppendUint32(nil, uint32(len(vp8l)))...)
+	chunk = append(chunk, vp8l...)
+	riff := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(4+len(chunk)))...)
+	return append(append(riff, "WEBP"...), chunk...)
+}
+
+// heicWithSize is a HEIC ftyp box followed by an ispe property of the given size
+func heicWithSize(width, height int) []byte {
+	ispe := append([]byte("\x00\x00\x00\x14ispe\x00\x00\x00\x00"), binary.BigEndian.AppendUint32(nil, uint32(width))...)
+	return append(append(heicImage, ispe...), binary.BigEndian.AppendUint32(nil, uint32(height))...)
+}
+
+func postBulkPictures(t *testing.T, handler *BulkUploadPicturesHandler, parts []bulkPicturePart) (int, *BulkUploadPicturesResponse) {
+	t.Helper()
+
+	app := fiber.New()
+	app.Post("/vehicles/:id/pictures/bulk", func(c *fiber.Ctx) error {
+		res, err := handler.Handle(c, &BulkUploadPicturesRequest{})
+		if err != nil {
+			return apperrors.HandleError(c, err)
+		}
+		return response.Send(c, res)
+	})
+
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	for _, part := range parts {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", `form-data; name="file"; filename="`+part.fileName+`"`)
+		header.Set("Content-Type", part.contentType)
+		w, _ := form.CreatePart(header)
+		w.Write(part.data)
+		form.WriteField("type", part.picType)
+	}
+	form.WriteField("uploaded_by", "appraiser-1")
+	form.Close()
+
+	req := httptest.NewRequest("POST", "/vehicles/VEH_1/pictures/bulk", body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK && resp.StatusCode != fiber.StatusMultiStatus {
+		return resp.StatusCode, nil
+	}
+
+	var res BulkUploadPicturesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return resp.StatusCode, &res
+}
+
+func TestBulkUploadPicturesHandler(t *testing.T) {
+	var added []domain.Picture
+	repo := &MockRepository{
+		GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+			return &domain.Vehicle{ID: id}, nil
+		},
+		AddPicturesFunc: func(ctx context.Context, vehicleID string, pictures []domain.Picture) (*domain.Vehicle, error) {
+			added = pictures
+			vehicle := &domain.Vehicle{ID: vehicleID}
+			return vehicle, vehicle.AddPictures(pictures)
+		},
+	}
+	storage := &MockStorage{Blobs: map[string][]byte{}}
+	handler := NewBulkUploadPicturesHandler(repo, storage, 0, nil)
+
+	status, res := postBulkPictures(t, handler, []bulkPicturePart{
+		{"front.png", "image/png", "exterior_front", pngImage(t, 1600, 1200)},
+		{"notes.txt", "text/plain", "other", []byte("not a picture")},
+		{"dash.png", "image/png", "dashboard", pngImage(t, 800, 600)},
+		{"fake.jpg", "image/jpeg", "engine", []byte("%PDF-1.7 not a picture")},
+	})
+	if status != fiber.StatusMultiStatus {
+		t.Fatalf("Expected status 207, got %d", status)
+	}
+
+	if res.Uploaded != 2 || res.Failed != 2 {
+		t.Fatalf("Expected 2 uploaded and 2 failed, got %+v", res)
+	}
+	expectedStatus := []string{response.ItemSucceeded, response.ItemFailed, response.ItemSucceeded, response.ItemFailed}
+	for i, result := range res.Results {
+		if result.Status != expectedStatus[i] {
+			t.Errorf("Expected %s to be %s, got %+v", result.FileName, expectedStatus[i], result)
+		}
+	}
+	if len(storage.Blobs) != 4 {
+		t.Errorf("Expected 2 pictures and 2 thumbnails stored, got %d blobs", len(storage.Blobs))
+	}
+
+	if len(added) != 2 {
+		t.Fatalf("Expected 2 pictures added in one write, got %d", len(added))
+	}
+	front := added[0]
+	if front.Width != 1600 || front.Height != 1200 || front.MimeType != "image/png" || !strings.HasSuffix(front.URL, ".png") || front.UploadedBy != "appraiser-1" {
+		t.Errorf("Unexpected picture %+v", front)
+	}
+	if res.MainPictureID != front.ID || res.Results[0].ID != front.ID {
+		t.Errorf("Expected %s to be main, got %s", front.ID, res.MainPictureID)
+	}
+
+	thumbnailBlob := storage.Blobs[front.ThumbnailURL[len("https://account.blob.core.windows.net/documents/"):]]
+	if front.ThumbnailSize != int64(len(thumbnailBlob)) {
+		t.Errorf("Expected the thumbnail size %d to be recorded, got %d", len(thumbnailBlob), front.ThumbnailSize)
+	}
+	thumbnail, _, err := image.DecodeConfig(bytes.NewReader(thumbnailBlob))
+	if err != nil {
+		t.Fatalf("Expected a decodable thumbnail, got %v", err)
+	}
+	if thumbnail.Width != thumbnailMaxSide || thumbnail.Height != 240 {
+		t.Errorf("Expected a 320x240 thumbnail, got %dx%d", thumbnail.Width, thumbnail.Height)
+	}
+}
+
+func TestBulkUploadPicturesHandler_QuotaExceeded(t *testing.T) {
+	repo := &MockRepository{
+		GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+			return &domain.Vehicle{ID: id, Documents: []domain.Document{{FileSize: 1000}}}, nil
+		},
+	}
+	storage := &MockStorage{Blobs: map[string][]byte{}}
+	handler := NewBulkUploadPicturesHandler(repo, storage, 1024, nil)
+
+	status, _ := postBulkPictures(t, handler, []bulkPicturePart{
+		{"front.png", "image/png", "exterior_front", pngImage(t, 200, 200)},
+	})
+
+	if status != fiber.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413, got %d", status)
+	}
+	if len(storage.Blobs) != 0 {
+		t.Errorf("Expected nothing uploaded, got %d blobs", len(storage.Blobs))
+	}
+}
+
+func TestBulkUploadPicturesHandler_MinResolution(t *testing.T) {
+	repo := &MockRepository{
+		GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+			return &domain.Vehicle{ID: id}, nil
+		},
+		AddPicturesFunc: func(ctx context.Context, vehicleID string, pictures []domain.Picture) (*domain.Vehicle, error) {
+			vehicle := &domain.Vehicle{ID: vehicleID}
+			return vehicle, vehicle.AddPictures(pictures)
+		},
+	}
+	handler := NewBulkUploadPicturesHandler(repo, &MockStorage{Blobs: map[string][]byte{}}, 0, nil)
+
+	status, res := postBulkPictures(t, handler, []bulkPicturePart{
+		{"dent.png", "image/png", "damage", pngImage(t, 640, 480)},
+		{"misc.png", "image/png", "other", pngImage(t, 64, 48)},
+	})
+	if status != fiber.StatusMultiStatus {
+		t.Fatalf("Expected status 207, got %d", status)
+	}
+
+	dent, misc := res.Results[0], res.Results[1]
+	if dent.Status != response.ItemFailed || misc.Status != response.ItemSucceeded {
+		t.Fatalf("Expected the small damage photo to fail and the other to upload, got %+v", res.Results)
+	}
+	if dent.Error == nil || dent.Error.Code != "PICTURE_RESOLUTION_TOO_LOW" {
+		t.Fatalf("Expected PICTURE_RESOLUTION_TOO_LOW, got %+v", dent.Error)
+	}
+	details, _ := dent.Error.Details.(map[string]any)
+	required, _ := details["required"].(map[string]any)
+	actual, _ := details["actual"].(map[string]any)
+	if required["width"] != 1024.0 || required["height"] != 768.0 || actual["width"] != 640.0 || actual["height"] != 480.0 {
+		t.Errorf("Expected the required and actual dimensions in the details, got %v", dent.Error.Details)
+	}
+}
+
+func TestBulkUploadPicturesHandler_WebPAndHEIC(t *testing.T) {
+	var added []domain.Picture
+	repo := &MockRepository{
+		GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+			return &domain.Vehicle{ID: id}, nil
+		},
+		AddPicturesFunc: func(ctx context.Context, vehicleID string, pictures []domain.Picture) (*domain.Vehicle, error) {
+			added = pictures
+			vehicle := &domain.Vehicle{ID: vehicleID}
+			return vehicle, vehicle.AddPictures(pictures)
+		},
+	}
+	storage := &MockStorage{Blobs: map[string][]byte{}}
+	handler := NewBulkUploadPicturesHandler(repo, storage, 0, nil)
+
+	// Clients often send both as application/octet-stream
+	status, res := postBulkPictures(t, handler, []bulkPicturePart{
+		{"front.webp", "application/octet-stream", "exterior_front", webpImage},
+		{"IMG_0001.HEIC", "application/octet-stream", "exterior_back", heicImage},
+	})
+	if status != fiber.StatusOK || res.Uploaded != 2 {
+		t.Fatalf("Expected both pictures to be uploaded, got %d: %+v", status, res)
+	}
+	if len(added) != 2 {
+		t.Fatalf("Expected 2 pictures, got %+v", added)
+	}
+
+	webp, heic := added[0], added[1]
+	if webp.MimeType != "image/webp" || webp.ThumbnailURL == "" || webp.Width != 1 || webp.Height != 1 {
+		t.Errorf("Expected a decoded WebP picture with a thumbnail, got %+v", webp)
+	}
+	if heic.MimeType != "image/heic" || !strings.HasSuffix(heic.URL, ".heic") || heic.ThumbnailURL != "" {
+		t.Errorf("Expected a HEIC picture stored as is without a thumbnail, got %+v", heic)
+	}
+	if len(storage.Blobs) != 3 {
+		t.Errorf("Expected the two pictures and the WebP thumbnail to be stored, got %d blobs", len(storage.Blobs))
+	}
+}
+
+func TestBulkUploadPicturesHandler_MinResolutionWithoutDecoding(t *testing.T) {
+	var added []domain.Picture
+	repo := &MockRepository{
+		GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+			return &domain.Vehicle{ID: id}, nil
+		},
+		AddPicturesFunc: func(ctx context.Context, vehicleID string, pictures []domain.Picture) (*domain.Vehicle, error) {
+			added = pictures
+			vehicle := &domain.Vehicle{ID: vehicleID}
+			return vehicle, vehicle.AddPictures(pictures)
+		},
+	}
+	handler := NewBulkUploadPicturesHandler(repo, &MockStorage{Blobs: map[string][]byte{}}, 0, nil)
+
+	status, res := postBulkPictures(t, handler, []bulkPicturePart{
+		{"dent.webp", "image/webp", "damage", webpHeader(10, 10)},
+		{"dent.heic", "image/heic", "damage", heicWithSize(640, 480)},
+		{"scratch.heic", "image/heic", "damage", heicImage},
+		{"crash.heic", "image/heic", "accident", heicWithSize(4032, 3024)},
+		{"misc.heic", "image/heic", "other", heicImage},
+	})
+	if status != fiber.StatusMultiStatus {
+		t.Fatalf("Expected status 207, got %d", status)
+	}
+
+	for i, result := range res.Results[:3] {
+		if result.Status != response.ItemFailed || result.Error == nil || result.Error.Code != "PICTURE_RESOLUTION_TOO_LOW" {
+			t.Errorf("Expected %s to fail with PICTURE_RESOLUTION_TOO_LOW, got %+v", res.Results[i].FileName, result)
+		}
+	}
+	if res.Uploaded != 2 || len(added) != 2 {
+		t.Fatalf("Expected the large HEIC and the one without a minimum to upload, got %+v", res.Results)
+	}
+	if added[0].Width != 4032 || added[0].Height != 3024 {
+		t.Errorf("Expected the HEIC dimensions to be recorded, got %dx%d", added[0].Width, added[0].Height)
+	}
+}
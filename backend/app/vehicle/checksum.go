@@ -0,0 +1,35 @@
+package vehicle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+)
+
+// fileChecksum reads an uploaded file to its end for its domain.Checksum and
+// rewinds it for the upload
+func fileChecksum(file io.ReadSeeker) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", apperrors.ErrInternalServer.WithCause(err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", apperrors.ErrInternalServer.WithCause(err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// verifyChecksum compares downloaded bytes with the checksum recorded at
+// upload. Files stored before checksums have none and pass.
+func verifyChecksum(data []byte, expected string) error {
+	if expected == "" {
+		return nil
+	}
+	if actual := domain.Checksum(data); actual != expected {
+		return fmt.Errorf("checksum mismatch: recorded %s, downloaded %s", expected, actual)
+	}
+	return nil
+}
@@ -2,11 +2,14 @@ package vehicle
 
 import (
 	"context"
+	"microservicetest/app"
 	"microservicetest/domain"
 	apperrors "microservicetest/pkg/errors"
 	"microservicetest/pkg/validator"
 	"strings"
 	"time"
+
+	"github.com/gofiber/fiber/v2"
 )
 
 type CreateVehicleRequest struct {
@@ -20,9 +23,12 @@ type CreateVehicleRequest struct {
 	OwnerName    string  `json:"owner_name" validate:"required,min=1,max=100"`
 	OwnerEmail   string  `json:"owner_email" validate:"required,email"`
 	OwnerPhone   string  `json:"owner_phone" validate:"omitempty,min=10,max=20"`
-	Transmission string  `json:"transmission" validate:"omitempty,oneof=manual automatic cvt"`
-	FuelType     string  `json:"fuel_type" validate:"required,oneof=gasoline diesel electric hybrid lpg cng"`
+	Transmission string  `json:"transmission" validate:"omitempty,transmission"`
+	FuelType     string  `json:"fuel_type" validate:"required,fueltype"`
 	Mileage      int     `json:"mileage" validate:"omitempty,gte=0"`
+	Engine       domain.EngineInfo   `json:"engine"`
+	Battery      *domain.BatteryInfo `json:"battery"` // Electric and hybrid vehicles only
+	Metadata     map[string]string `json:"metadata"`
 	CreatedBy    string  `json:"created_by" validate:"required"`
 }
 
@@ -30,23 +36,36 @@ type CreateVehicleResponse struct {
 	ID        string    `json:"id"`
 	VIN       string    `json:"vin"`
 	CreatedAt time.Time `json:"created_at"`
+	Warnings  []string  `json:"warnings,omitempty"`
+}
+
+// HTTPStatus answers 201 Created, see response.StatusCoder
+func (r *CreateVehicleResponse) HTTPStatus() int {
+	return fiber.StatusCreated
+}
+
+// Location is the URL of the new vehicle, see response.Locator
+func (r *CreateVehicleResponse) Location() string {
+	return "/vehicles/" + r.ID
 }
 
 type CreateVehicleHandler struct {
-	repository Repository
+	repository     Repository
+	duplicatePlate DuplicatePlatePolicy
+	auditLog       app.AuditLog
 }
 
-func NewCreateVehicleHandler(repository Repository) *CreateVehicleHandler {
+func NewCreateVehicleHandler(repository Repository, duplicatePlate DuplicatePlatePolicy, auditLog app.AuditLog) *CreateVehicleHandler {
 	return &CreateVehicleHandler{
-		repository: repository,
+		repository:     repository,
+		duplicatePlate: duplicatePlate,
+		auditLog:       auditLog,
 	}
 }
 
 func (h *CreateVehicleHandler) Handle(ctx context.Context, req *CreateVehicleRequest) (*CreateVehicleResponse, error) {
-	if err := validator.Validate(req); err != nil {
-		return nil, apperrors.ErrInvalidInput.WithDetails(map[string]string{
-			"validation": err.Error(),
-		})
+	if err := h.validate(req); err != nil {
+		return nil, err
 	}
 
 	// Check if vehicle with VIN already exists
@@ -58,40 +77,117 @@ func (h *CreateVehicleHandler) Handle(ctx context.Context, req *CreateVehicleReq
 		})
 	}
 
-	now := time.Now()
-	vehicle := &domain.Vehicle{
-		ID:           domain.GenerateVehicleID(),
-		VIN:          strings.ToUpper(strings.TrimSpace(req.VIN)),
-		Make:         strings.TrimSpace(req.Make),
-		Model:        strings.TrimSpace(req.Model),
-		Year:         req.Year,
-		Color:        strings.TrimSpace(req.Color),
-		LicensePlate: strings.ToUpper(strings.TrimSpace(req.LicensePlate)),
-		OwnerID:      req.OwnerID,
-		OwnerName:    strings.TrimSpace(req.OwnerName),
-		OwnerEmail:   strings.ToLower(strings.TrimSpace(req.OwnerEmail)),
-		OwnerPhone:   strings.TrimSpace(req.OwnerPhone),
-		Transmission: req.Transmission,
-		FuelType:     domain.FuelType(req.FuelType),
-		Mileage:      req.Mileage,
-		Status:       domain.VehicleStatusActive,
-		Documents:    make([]domain.Document, 0),
-		Pictures:     make([]domain.Picture, 0),
-		CreatedAt:    now,
-		UpdatedAt:    now,
-		CreatedBy:    req.CreatedBy,
-		UpdatedBy:    req.CreatedBy,
+	vehicle, warnings, err := h.build(ctx, req)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := h.repository.CreateVehicle(ctx, vehicle); err != nil {
-		return nil, apperrors.ErrDatabaseQuery.WithCause(err).WithDetails(map[string]string{
-			"operation": "create_vehicle",
-		})
+	auditWarnings, err := h.create(ctx, vehicle)
+	if err != nil {
+		return nil, err
 	}
 
 	return &CreateVehicleResponse{
 		ID:        vehicle.ID,
 		VIN:       vehicle.VIN,
 		CreatedAt: vehicle.CreatedAt,
+		Warnings:  append(warnings, auditWarnings...),
 	}, nil
-}
\ No newline at end of file
+}
+
+// validate normalizes the request, so validation and the duplicate lookup see
+// the stored form, and checks it
+func (h *CreateVehicleHandler) validate(req *CreateVehicleRequest) error {
+	req.normalize()
+
+	if err := validator.Validate(req); err != nil {
+		return apperrors.ErrInvalidInput.WithDetails(map[string]string{
+			"validation": err.Error(),
+		})
+	}
+	if err := domain.ValidateMetadata(req.Metadata); err != nil {
+		return apperrors.NewValidationError("metadata", err.Error())
+	}
+	return nil
+}
+
+// build turns a validated request into the vehicle to store, checking the
+// business rules that need the vehicle or other vehicles of the owner
+func (h *CreateVehicleHandler) build(ctx context.Context, req *CreateVehicleRequest) (*domain.Vehicle, []string, error) {
+	vehicle := newVehicleFromRequest(req)
+	if err := vehicle.ValidatePowertrain(); err != nil {
+		return nil, nil, apperrors.NewUnprocessableError("engine", err.Error())
+	}
+
+	warnings, err := checkDuplicatePlate(ctx, h.repository, h.duplicatePlate, req.OwnerID, req.LicensePlate, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	return vehicle, warnings, nil
+}
+
+// create stores the vehicle and records it in the audit log, returning the
+// warning of an entry that could not be written
+func (h *CreateVehicleHandler) create(ctx context.Context, vehicle *domain.Vehicle) ([]string, error) {
+	audit, err := beginAudit(ctx, h.auditLog, app.AuditEntry{
+		VehicleID: vehicle.ID,
+		Action:    app.AuditActionCreate,
+		Actor:     vehicle.CreatedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer audit.abort(ctx)
+
+	if err := h.repository.CreateVehicle(ctx, vehicle); err != nil {
+		return nil, apperrors.ErrDatabaseQuery.WithCause(err).WithDetails(map[string]string{
+			"operation": "create_vehicle",
+		})
+	}
+
+	return audit.complete(ctx), nil
+}
+
+// normalize trims the free-text fields and applies the canonical casing
+func (req *CreateVehicleRequest) normalize() {
+	req.VIN = strings.ToUpper(strings.TrimSpace(req.VIN))
+	req.Make = strings.TrimSpace(req.Make)
+	req.Model = strings.TrimSpace(req.Model)
+	req.Color = strings.TrimSpace(req.Color)
+	req.LicensePlate = domain.NormalizeLicensePlate(req.LicensePlate)
+	req.OwnerName = strings.TrimSpace(req.OwnerName)
+	req.OwnerEmail = strings.ToLower(strings.TrimSpace(req.OwnerEmail))
+	req.OwnerPhone = strings.TrimSpace(req.OwnerPhone)
+}
+
+// newVehicleFromRequest builds a vehicle from a normalized create request
+func newVehicleFromRequest(req *CreateVehicleRequest) *domain.Vehicle {
+	now := time.Now()
+	return &domain.Vehicle{
+		ID:             domain.GenerateVehicleID(),
+		VIN:            req.VIN,
+		Make:           req.Make,
+		Model:          req.Model,
+		Year:           req.Year,
+		Color:          req.Color,
+		LicensePlate:   req.LicensePlate,
+		OwnerID:        req.OwnerID,
+		OwnerName:      req.OwnerName,
+		OwnerEmail:     req.OwnerEmail,
+		OwnerPhone:     req.OwnerPhone,
+		Transmission:   req.Transmission,
+		FuelType:       domain.FuelType(req.FuelType),
+		Mileage:        req.Mileage,
+		Engine:         req.Engine,
+		Battery:        req.Battery,
+		Metadata:       req.Metadata,
+		Status:         domain.VehicleStatusActive,
+		Documents:      make([]domain.Document, 0),
+		Pictures:       make([]domain.Picture, 0),
+		ServiceRecords: make([]domain.ServiceRecord, 0),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		CreatedBy:      req.CreatedBy,
+		UpdatedBy:      req.CreatedBy,
+	}
+}
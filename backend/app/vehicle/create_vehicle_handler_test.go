@@ -16,13 +16,34 @@ type MockRepository struct {
 	CreateVehicleFunc       func(ctx context.Context, vehicle *domain.Vehicle) error
 	UpdateVehicleFunc       func(ctx context.Context, vehicle *domain.Vehicle) error
 	DeleteVehicleFunc       func(ctx context.Context, id string) error
-	GetVehiclesByOwnerFunc  func(ctx context.Context, ownerID string) ([]*domain.Vehicle, error)
-	SearchVehiclesFunc      func(ctx context.Context, criteria map[string]interface{}) ([]*domain.Vehicle, error)
+	GetVehiclesByOwnerFunc  func(ctx context.Context, ownerID string, filter OwnerVehicleFilter) ([]*domain.Vehicle, int, error)
+	SearchVehiclesFunc      func(ctx context.Context, filter VehicleSearchFilter) ([]*domain.Vehicle, int, error)
 	GetVehiclesWithExpiredInsuranceFunc func(ctx context.Context) ([]*domain.Vehicle, error)
 	GetVehiclesWithExpiringInsuranceFunc func(ctx context.Context, days int) ([]*domain.Vehicle, error)
 	UpdateInsuranceFunc     func(ctx context.Context, vehicleID string, insurance domain.InsuranceInfo) error
 	AddDocumentFunc         func(ctx context.Context, vehicleID string, document domain.Document) error
 	AddPictureFunc          func(ctx context.Context, vehicleID string, picture domain.Picture) error
+	GetDocumentsFunc        func(ctx context.Context, vehicleID string, filter DocumentFilter) ([]domain.Document, error)
+	DeleteDocumentFunc      func(ctx context.Context, vehicleID string, documentID string, unmodifiedSince time.Time) error
+	UpsertVehicleByVINFunc  func(ctx context.Context, vehicle *domain.Vehicle) (*domain.Vehicle, bool, error)
+	GetOwnerDocumentsFunc   func(ctx context.Context, ownerID string, filter OwnerDocumentFilter) ([]OwnerDocument, int, error)
+	ExpireVerificationsFunc func(ctx context.Context) ([]VerificationExpiry, error)
+	AddServiceRecordFunc func(ctx context.Context, vehicleID string, record domain.ServiceRecord) error
+	GetServiceRecordsFunc func(ctx context.Context, vehicleID string) ([]domain.ServiceRecord, error)
+	RestoreVehicleFunc func(ctx context.Context, id string, restoredBy string) (*domain.Vehicle, error)
+	SetMainPictureFunc func(ctx context.Context, vehicleID string, pictureID string) error
+	DeletePicturesByTypeFunc func(ctx context.Context, vehicleID string, picType domain.PictureType) (*domain.Vehicle, []domain.Picture, error)
+	GetVehiclesByLicensePlateFunc func(ctx context.Context, plate string) ([]*domain.Vehicle, error)
+	RenewInsuranceFunc func(ctx context.Context, vehicleID string, policyNumber string, endDate time.Time, premium *domain.Money, renewedBy string) error
+	GetVehiclesByVINsFunc func(ctx context.Context, vins []string) (map[string]*domain.Vehicle, []string, error)
+	AppendFileToDocumentFunc func(ctx context.Context, vehicleID string, documentID string, file domain.DocumentFile) (domain.DocumentFile, error)
+	ReportStolenFunc func(ctx context.Context, vehicleID string, report domain.TheftReport, document domain.Document) (*domain.Vehicle, error)
+	GetRecentVehiclesByOwnerFunc func(ctx context.Context, ownerID string, by string, limit int) ([]VehicleSummary, error)
+	FindActiveVehicleByOwnerPlateFunc func(ctx context.Context, ownerID string, plate string, excludeID string) (string, bool, error)
+	AddPicturesFunc func(ctx context.Context, vehicleID string, pictures []domain.Picture) (*domain.Vehicle, error)
+	RefreshStatusFlagsFunc func(ctx context.Context) (int, error)
+	ReferencedFileURLsFunc func(ctx context.Context) ([]string, error)
+	OwnerExistsFunc func(ctx context.Context, ownerID string) (bool, error)
 }
 
 func (m *MockRepository) GetVehicle(ctx context.Context, id string) (*domain.Vehicle, error) {
@@ -46,11 +67,22 @@ func (m *MockRepository) CreateVehicle(ctx context.Context, vehicle *domain.Vehi
 	return nil
 }
 
-func (m *MockRepository) UpdateVehicle(ctx context.Context, vehicle *domain.Vehicle) error {
+// UpdateVehicle applies update to the vehicle from GetVehicle and hands the
+// result to UpdateVehicleFunc, like a write that wins its CAS on the first try
+func (m *MockRepository) UpdateVehicle(ctx context.Context, id string, update func(vehicle *domain.Vehicle) error) (*domain.Vehicle, error) {
+	vehicle, err := m.GetVehicle(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := update(vehicle); err != nil {
+		return nil, err
+	}
 	if m.UpdateVehicleFunc != nil {
-		return m.UpdateVehicleFunc(ctx, vehicle)
+		if err := m.UpdateVehicleFunc(ctx, vehicle); err != nil {
+			return nil, err
+		}
 	}
-	return nil
+	return vehicle, nil
 }
 
 func (m *MockRepository) DeleteVehicle(ctx context.Context, id string) error {
@@ -60,18 +92,18 @@ func (m *MockRepository) DeleteVehicle(ctx context.Context, id string) error {
 	return nil
 }
 
-func (m *MockRepository) GetVehiclesByOwner(ctx context.Context, ownerID string) ([]*domain.Vehicle, error) {
+func (m *MockRepository) GetVehiclesByOwner(ctx context.Context, ownerID string, filter OwnerVehicleFilter) ([]*domain.Vehicle, int, error) {
 	if m.GetVehiclesByOwnerFunc != nil {
-		return m.GetVehiclesByOwnerFunc(ctx, ownerID)
+		return m.GetVehiclesByOwnerFunc(ctx, ownerID, filter)
 	}
-	return nil, nil
+	return nil, 0, nil
 }
 
-func (m *MockRepository) SearchVehicles(ctx context.Context, criteria map[string]interface{}) ([]*domain.Vehicle, error) {
+func (m *MockRepository) SearchVehicles(ctx context.Context, filter VehicleSearchFilter) ([]*domain.Vehicle, int, error) {
 	if m.SearchVehiclesFunc != nil {
-		return m.SearchVehiclesFunc(ctx, criteria)
+		return m.SearchVehiclesFunc(ctx, filter)
 	}
-	return nil, nil
+	return nil, 0, nil
 }
 
 func (m *MockRepository) GetVehiclesWithExpiredInsurance(ctx context.Context) ([]*domain.Vehicle, error) {
@@ -109,6 +141,153 @@ func (m *MockRepository) AddPicture(ctx context.Context, vehicleID string, pictu
 	return nil
 }
 
+func (m *MockRepository) GetDocuments(ctx context.Context, vehicleID string, filter DocumentFilter) ([]domain.Document, error) {
+	if m.GetDocumentsFunc != nil {
+		return m.GetDocumentsFunc(ctx, vehicleID, filter)
+	}
+	return nil, nil
+}
+
+func (m *MockRepository) DeleteDocument(ctx context.Context, vehicleID string, documentID string, unmodifiedSince time.Time) error {
+	if m.DeleteDocumentFunc != nil {
+		return m.DeleteDocumentFunc(ctx, vehicleID, documentID, unmodifiedSince)
+	}
+	return nil
+}
+
+func (m *MockRepository) UpsertVehicleByVIN(ctx context.Context, vehicle *domain.Vehicle) (*domain.Vehicle, bool, error) {
+	if m.UpsertVehicleByVINFunc != nil {
+		return m.UpsertVehicleByVINFunc(ctx, vehicle)
+	}
+	return vehicle, true, nil
+}
+
+func (m *MockRepository) GetOwnerDocuments(ctx context.Context, ownerID string, filter OwnerDocumentFilter) ([]OwnerDocument, int, error) {
+	if m.GetOwnerDocumentsFunc != nil {
+		return m.GetOwnerDocumentsFunc(ctx, ownerID, filter)
+	}
+	return nil, 0, nil
+}
+
+func (m *MockRepository) ExpireVerifications(ctx context.Context) ([]VerificationExpiry, error) {
+	if m.ExpireVerificationsFunc != nil {
+		return m.ExpireVerificationsFunc(ctx)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockRepository) DeletePicturesByType(ctx context.Context, vehicleID string, picType domain.PictureType) (*domain.Vehicle, []domain.Picture, error) {
+	if m.DeletePicturesByTypeFunc != nil {
+		return m.DeletePicturesByTypeFunc(ctx, vehicleID, picType)
+	}
+	return &domain.Vehicle{ID: vehicleID}, nil, nil
+}
+
+func (m *MockRepository) AddServiceRecord(ctx context.Context, vehicleID string, record domain.ServiceRecord) error {
+	if m.AddServiceRecordFunc != nil {
+		return m.AddServiceRecordFunc(ctx, vehicleID, record)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockRepository) GetServiceRecords(ctx context.Context, vehicleID string) ([]domain.ServiceRecord, error) {
+	if m.GetServiceRecordsFunc != nil {
+		return m.GetServiceRecordsFunc(ctx, vehicleID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockRepository) RestoreVehicle(ctx context.Context, id string, restoredBy string) (*domain.Vehicle, error) {
+	if m.RestoreVehicleFunc != nil {
+		return m.RestoreVehicleFunc(ctx, id, restoredBy)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockRepository) SetMainPicture(ctx context.Context, vehicleID string, pictureID string) error {
+	if m.SetMainPictureFunc != nil {
+		return m.SetMainPictureFunc(ctx, vehicleID, pictureID)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockRepository) RenewInsurance(ctx context.Context, vehicleID string, policyNumber string, endDate time.Time, premium *domain.Money, renewedBy string) error {
+	if m.RenewInsuranceFunc != nil {
+		return m.RenewInsuranceFunc(ctx, vehicleID, policyNumber, endDate, premium, renewedBy)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockRepository) GetVehiclesByLicensePlate(ctx context.Context, plate string) ([]*domain.Vehicle, error) {
+	if m.GetVehiclesByLicensePlateFunc != nil {
+		return m.GetVehiclesByLicensePlateFunc(ctx, plate)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockRepository) GetVehiclesByVINs(ctx context.Context, vins []string) (map[string]*domain.Vehicle, []string, error) {
+	if m.GetVehiclesByVINsFunc != nil {
+		return m.GetVehiclesByVINsFunc(ctx, vins)
+	}
+	return nil, nil, errors.New("not implemented")
+}
+
+func (m *MockRepository) AppendFileToDocument(ctx context.Context, vehicleID string, documentID string, file domain.DocumentFile) (domain.DocumentFile, error) {
+	if m.AppendFileToDocumentFunc != nil {
+		return m.AppendFileToDocumentFunc(ctx, vehicleID, documentID, file)
+	}
+	return domain.DocumentFile{}, errors.New("not implemented")
+}
+
+func (m *MockRepository) ReportStolen(ctx context.Context, vehicleID string, report domain.TheftReport, document domain.Document) (*domain.Vehicle, error) {
+	if m.ReportStolenFunc != nil {
+		return m.ReportStolenFunc(ctx, vehicleID, report, document)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockRepository) GetRecentVehiclesByOwner(ctx context.Context, ownerID string, by string, limit int) ([]VehicleSummary, error) {
+	if m.GetRecentVehiclesByOwnerFunc != nil {
+		return m.GetRecentVehiclesByOwnerFunc(ctx, ownerID, by, limit)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockRepository) FindActiveVehicleByOwnerPlate(ctx context.Context, ownerID string, plate string, excludeID string) (string, bool, error) {
+	if m.FindActiveVehicleByOwnerPlateFunc != nil {
+		return m.FindActiveVehicleByOwnerPlateFunc(ctx, ownerID, plate, excludeID)
+	}
+	return "", false, nil
+}
+
+func (m *MockRepository) AddPictures(ctx context.Context, vehicleID string, pictures []domain.Picture) (*domain.Vehicle, error) {
+	if m.AddPicturesFunc != nil {
+		return m.AddPicturesFunc(ctx, vehicleID, pictures)
+	}
+	return &domain.Vehicle{ID: vehicleID, Pictures: pictures}, nil
+}
+
+func (m *MockRepository) RefreshStatusFlags(ctx context.Context) (int, error) {
+	if m.RefreshStatusFlagsFunc != nil {
+		return m.RefreshStatusFlagsFunc(ctx)
+	}
+	return 0, nil
+}
+
+func (m *MockRepository) OwnerExists(ctx context.Context, ownerID string) (bool, error) {
+	if m.OwnerExistsFunc != nil {
+		return m.OwnerExistsFunc(ctx, ownerID)
+	}
+	return true, nil
+}
+
+func (m *MockRepository) ReferencedFileURLs(ctx context.Context) ([]string, error) {
+	if m.ReferencedFileURLsFunc != nil {
+		return m.ReferencedFileURLsFunc(ctx)
+	}
+	return nil, nil
+}
+
 func TestCreateVehicleHandler_Success(t *testing.T) {
 	mockRepo := &MockRepository{
 		GetVehicleByVINFunc: func(ctx context.Context, vin string) (*domain.Vehicle, error) {
@@ -119,7 +298,7 @@ func TestCreateVehicleHandler_Success(t *testing.T) {
 		},
 	}
 
-	handler := NewCreateVehicleHandler(mockRepo)
+	handler := NewCreateVehicleHandler(mockRepo, DuplicatePlateReject, nil)
 
 	req := &CreateVehicleRequest{
 		VIN:          "1HGBH41JXMN109186",
@@ -163,7 +342,7 @@ func TestCreateVehicleHandler_Success(t *testing.T) {
 
 func TestCreateVehicleHandler_ValidationError_MissingVIN(t *testing.T) {
 	mockRepo := &MockRepository{}
-	handler := NewCreateVehicleHandler(mockRepo)
+	handler := NewCreateVehicleHandler(mockRepo, DuplicatePlateReject, nil)
 
 	req := &CreateVehicleRequest{
 		Make:       "Toyota",
@@ -194,7 +373,7 @@ func TestCreateVehicleHandler_ValidationError_MissingVIN(t *testing.T) {
 
 func TestCreateVehicleHandler_ValidationError_InvalidVINLength(t *testing.T) {
 	mockRepo := &MockRepository{}
-	handler := NewCreateVehicleHandler(mockRepo)
+	handler := NewCreateVehicleHandler(mockRepo, DuplicatePlateReject, nil)
 
 	req := &CreateVehicleRequest{
 		VIN:        "SHORT",
@@ -217,7 +396,7 @@ func TestCreateVehicleHandler_ValidationError_InvalidVINLength(t *testing.T) {
 
 func TestCreateVehicleHandler_ValidationError_InvalidEmail(t *testing.T) {
 	mockRepo := &MockRepository{}
-	handler := NewCreateVehicleHandler(mockRepo)
+	handler := NewCreateVehicleHandler(mockRepo, DuplicatePlateReject, nil)
 
 	req := &CreateVehicleRequest{
 		VIN:        "1HGBH41JXMN109186",
@@ -238,6 +417,93 @@ func TestCreateVehicleHandler_ValidationError_InvalidEmail(t *testing.T) {
 	}
 }
 
+func TestCreateVehicleHandler_ValidationError_MetadataKey(t *testing.T) {
+	mockRepo := &MockRepository{}
+	handler := NewCreateVehicleHandler(mockRepo, DuplicatePlateReject, nil)
+
+	req := &CreateVehicleRequest{
+		VIN:        "1HGBH41JXMN109186",
+		Make:       "Toyota",
+		Model:      "Camry",
+		Year:       2023,
+		OwnerID:    "owner-123",
+		OwnerName:  "John Doe",
+		OwnerEmail: "john@example.com",
+		FuelType:   "gasoline",
+		Metadata:   map[string]string{"parking spot": "B2"},
+		CreatedBy:  "admin-user",
+	}
+
+	_, err := handler.Handle(context.Background(), req)
+
+	if !errors.Is(err, apperrors.ErrInvalidInput) {
+		t.Fatalf("Expected ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestCreateVehicleHandler_Powertrain(t *testing.T) {
+	tests := []struct {
+		name     string
+		fuelType string
+		engine   domain.EngineInfo
+		battery  *domain.BatteryInfo
+		wantErr  bool
+	}{
+		{"electric with battery", "electric", domain.EngineInfo{Horsepower: 283}, &domain.BatteryInfo{CapacityKwh: 75, RangeKm: 500}, false},
+		{"electric with displacement", "electric", domain.EngineInfo{Displacement: 2.0, Cylinders: 4}, nil, true},
+		{"gasoline with engine", "gasoline", domain.EngineInfo{Displacement: 2.5, Cylinders: 4}, nil, false},
+		{"gasoline with battery", "gasoline", domain.EngineInfo{}, &domain.BatteryInfo{CapacityKwh: 75}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created *domain.Vehicle
+			mockRepo := &MockRepository{
+				CreateVehicleFunc: func(ctx context.Context, vehicle *domain.Vehicle) error {
+					created = vehicle
+					return nil
+				},
+			}
+			handler := NewCreateVehicleHandler(mockRepo, DuplicatePlateReject, nil)
+
+			req := &CreateVehicleRequest{
+				VIN:        "1HGBH41JXMN109186",
+				Make:       "Tesla",
+				Model:      "Model 3",
+				Year:       2023,
+				OwnerID:    "owner-123",
+				OwnerName:  "John Doe",
+				OwnerEmail: "john@example.com",
+				FuelType:   tt.fuelType,
+				Engine:     tt.engine,
+				Battery:    tt.battery,
+				CreatedBy:  "admin-user",
+			}
+
+			_, err := handler.Handle(context.Background(), req)
+
+			if tt.wantErr {
+				if !errors.Is(err, apperrors.ErrUnprocessableEntity) {
+					t.Fatalf("Expected ErrUnprocessableEntity, got %v", err)
+				}
+				if created != nil {
+					t.Error("Expected vehicle not to be stored")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if created.Engine != tt.engine {
+				t.Errorf("Expected engine %+v, got %+v", tt.engine, created.Engine)
+			}
+			if (created.Battery == nil) != (tt.battery == nil) {
+				t.Errorf("Expected battery %+v, got %+v", tt.battery, created.Battery)
+			}
+		})
+	}
+}
+
 func TestCreateVehicleHandler_DuplicateVIN(t *testing.T) {
 	existingVehicle := &domain.Vehicle{
 		ID:  "VEH_123",
@@ -250,7 +516,7 @@ func TestCreateVehicleHandler_DuplicateVIN(t *testing.T) {
 		},
 	}
 
-	handler := NewCreateVehicleHandler(mockRepo)
+	handler := NewCreateVehicleHandler(mockRepo, DuplicatePlateReject, nil)
 
 	req := &CreateVehicleRequest{
 		VIN:        "1HGBH41JXMN109186",
@@ -290,7 +556,7 @@ func TestCreateVehicleHandler_DatabaseError(t *testing.T) {
 		},
 	}
 
-	handler := NewCreateVehicleHandler(mockRepo)
+	handler := NewCreateVehicleHandler(mockRepo, DuplicatePlateReject, nil)
 
 	req := &CreateVehicleRequest{
 		VIN:        "1HGBH41JXMN109186",
@@ -324,7 +590,7 @@ func TestCreateVehicleHandler_DataNormalization(t *testing.T) {
 		},
 	}
 
-	handler := NewCreateVehicleHandler(mockRepo)
+	handler := NewCreateVehicleHandler(mockRepo, DuplicatePlateReject, nil)
 
 	req := &CreateVehicleRequest{
 		VIN:          "  1hgbh41jxmn109186  ",
@@ -2,7 +2,10 @@ package vehicle
 
 import (
 	"microservicetest/app"
+	"microservicetest/pkg/log"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
@@ -14,58 +17,86 @@ type DeleteDocumentRequest struct {
 }
 
 type DeleteDocumentResponse struct {
-	Message string `json:"message"`
+	Message  string   `json:"message"`
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 type DeleteDocumentHandler struct {
 	repository Repository
 	storage    app.Storage
+	auditLog   app.AuditLog
 }
 
-func NewDeleteDocumentHandler(repository Repository, storage app.Storage) *DeleteDocumentHandler {
+func NewDeleteDocumentHandler(repository Repository, storage app.Storage, auditLog app.AuditLog) *DeleteDocumentHandler {
 	return &DeleteDocumentHandler{
 		repository: repository,
 		storage:    storage,
+		auditLog:   auditLog,
 	}
 }
 
 func (h *DeleteDocumentHandler) Handle(ctx *fiber.Ctx, req *DeleteDocumentRequest) (*DeleteDocumentResponse, error) {
+	if h.storage == nil {
+		return nil, errStorageUnavailable
+	}
+
 	vehicleID := ctx.Params("id")
 	documentID := ctx.Params("doc_id")
 
-	// Get vehicle to find document FileURL
+	// An invalid date is ignored, as RFC 9110 requires for If-Unmodified-Since
+	var unmodifiedSince time.Time
+	if header := ctx.Get(fiber.HeaderIfUnmodifiedSince); header != "" {
+		unmodifiedSince, _ = http.ParseTime(header)
+	}
+
+	// Get vehicle to find the document's files
 	vehicle, err := h.repository.GetVehicle(ctx.UserContext(), vehicleID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Find document and extract blob filename
-	var blobFilename string
+	// Find document and extract blob filenames, one per page
+	var blobFilenames []string
 	for _, doc := range vehicle.Documents {
 		if doc.ID == documentID {
-			parts := strings.Split(doc.FileURL, "/")
-			if len(parts) > 0 {
-				blobFilename = parts[len(parts)-1]
+			for _, file := range doc.AllFiles() {
+				parts := strings.Split(file.URL, "/")
+				if blobFilename := parts[len(parts)-1]; blobFilename != "" {
+					blobFilenames = append(blobFilenames, blobFilename)
+				}
 			}
 			break
 		}
 	}
 
+	audit, err := beginAudit(ctx.UserContext(), h.auditLog, app.AuditEntry{
+		VehicleID: vehicleID,
+		Action:    app.AuditActionDeleteDocument,
+		Actor:     auditActor(ctx.UserContext(), ""),
+		Changes:   itemsChange("documents", []string{documentID}, nil),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer audit.abort(ctx.UserContext())
+
 	// Delete from database
-	if err := h.repository.DeleteDocument(ctx.UserContext(), vehicleID, documentID); err != nil {
+	if err := h.repository.DeleteDocument(ctx.UserContext(), vehicleID, documentID, unmodifiedSince); err != nil {
 		return nil, err
 	}
+	warnings := audit.complete(ctx.UserContext())
 
-	// Delete from Azure Blob Storage if we found the filename
-	if blobFilename != "" {
+	// Delete from Azure Blob Storage
+	for _, blobFilename := range blobFilenames {
 		if err := h.storage.Remove(ctx.UserContext(), blobFilename); err != nil {
-			zap.L().Error("Failed to delete blob from storage",
+			log.FromContext(ctx.UserContext()).Error("Failed to delete blob from storage",
 				zap.String("filename", blobFilename),
 				zap.Error(err))
 		}
 	}
 
 	return &DeleteDocumentResponse{
-		Message: "Document deleted successfully",
+		Message:  "Document deleted successfully",
+		Warnings: warnings,
 	}, nil
 }
@@ -0,0 +1,110 @@
+package vehicle
+
+import (
+	"microservicetest/app"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/log"
+	"microservicetest/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type DeletePicturesRequest struct {
+	VehicleID string `param:"id" validate:"required"`
+	Type      string `query:"type" validate:"required,picturetype"`
+}
+
+type DeletePicturesResponse struct {
+	Removed       int      `json:"removed"`
+	MainPictureID string   `json:"main_picture_id,omitempty"` // Empty when no pictures are left
+	Warnings      []string `json:"warnings,omitempty"`
+}
+
+type DeletePicturesHandler struct {
+	repository Repository
+	storage    app.Storage
+	auditLog   app.AuditLog
+}
+
+func NewDeletePicturesHandler(repository Repository, storage app.Storage, auditLog app.AuditLog) *DeletePicturesHandler {
+	return &DeletePicturesHandler{
+		repository: repository,
+		storage:    storage,
+		auditLog:   auditLog,
+	}
+}
+
+// Handle removes every picture of one type and their blobs. Only backend
+// services calling with an API key may, as the files cannot be recovered.
+func (h *DeletePicturesHandler) Handle(ctx *fiber.Ctx, req *DeletePicturesRequest) (*DeletePicturesResponse, error) {
+	if _, ok := app.ServiceFromContext(ctx.UserContext()); !ok {
+		return nil, apperrors.ErrUnauthorized.WithDetails(map[string]string{
+			"header": "X-API-Key",
+		})
+	}
+	if h.storage == nil {
+		return nil, errStorageUnavailable
+	}
+
+	req.VehicleID = ctx.Params("id")
+
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.ErrInvalidInput.WithDetails(map[string]string{
+			"validation": err.Error(),
+		})
+	}
+
+	// The removed pictures are only known once they are gone, the entry is
+	// completed with them. Nothing is recorded when there were none.
+	audit, err := beginAudit(ctx.UserContext(), h.auditLog, app.AuditEntry{
+		VehicleID: req.VehicleID,
+		Action:    app.AuditActionDeletePictures,
+		Actor:     auditActor(ctx.UserContext(), ""),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer audit.abort(ctx.UserContext())
+
+	vehicle, removed, err := h.repository.DeletePicturesByType(ctx.UserContext(), req.VehicleID, domain.PictureType(req.Type))
+	if err != nil {
+		return nil, err
+	}
+
+	// The pictures are gone from the vehicle already, a blob left behind is only logged
+	for _, pic := range removed {
+		for _, fileURL := range []string{pic.URL, pic.ThumbnailURL} {
+			if fileURL == "" {
+				continue
+			}
+			blobName, err := blobNameFromURL(fileURL)
+			if err == nil {
+				err = h.storage.Remove(ctx.UserContext(), blobName)
+			}
+			if err != nil {
+				log.FromContext(ctx.UserContext()).Error("Failed to delete picture blob from storage",
+					zap.String("vehicle_id", req.VehicleID),
+					zap.String("picture_id", pic.ID),
+					zap.String("url", fileURL),
+					zap.Error(err))
+			}
+		}
+	}
+
+	res := &DeletePicturesResponse{Removed: len(removed)}
+	if main := vehicle.GetMainPicture(); main != nil {
+		res.MainPictureID = main.ID
+	}
+	if len(removed) > 0 {
+		ids := make([]string, len(removed))
+		for i, pic := range removed {
+			ids[i] = pic.ID
+		}
+		audit.entry.Changes = itemsChange("pictures", ids, nil)
+		res.Warnings = audit.complete(ctx.UserContext())
+	}
+
+	return res, nil
+}
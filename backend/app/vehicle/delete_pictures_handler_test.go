@@ -0,0 +1,119 @@
+package vehicle
+
+import (
+	"context"
+	"encoding/json"
+	"microservicetest/app"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// newDeletePicturesApp serves the handler to the given service, or to an
+// unauthenticated client when service is empty
+func newDeletePicturesApp(handler *DeletePicturesHandler, service string) *fiber.App {
+	ctx := context.Background()
+	if service != "" {
+		ctx = app.WithService(ctx, service)
+	}
+
+	app := fiber.New()
+	app.Delete("/vehicles/:id/pictures", func(c *fiber.Ctx) error {
+		c.SetUserContext(ctx)
+		var req DeletePicturesRequest
+		if err := c.QueryParser(&req); err != nil {
+			return err
+		}
+		res, err := handler.Handle(c, &req)
+		if err != nil {
+			return apperrors.HandleError(c, err)
+		}
+		return c.JSON(res)
+	})
+	return app
+}
+
+func TestDeletePicturesHandler_RemovesBlobs(t *testing.T) {
+	repo := &MockRepository{
+		DeletePicturesByTypeFunc: func(ctx context.Context, vehicleID string, picType domain.PictureType) (*domain.Vehicle, []domain.Picture, error) {
+			if vehicleID != "VEH_1" || picType != domain.PictureTypeAccident {
+				t.Errorf("Unexpected call for %s, %s", vehicleID, picType)
+			}
+			vehicle := &domain.Vehicle{
+				ID:       vehicleID,
+				Pictures: []domain.Picture{{ID: "PIC_3", IsMain: true}},
+			}
+			removed := []domain.Picture{
+				{ID: "PIC_1", URL: "https://account.blob.core.windows.net/documents/blob-1", ThumbnailURL: "https://account.blob.core.windows.net/documents/thumb-1"},
+				{ID: "PIC_2", URL: "https://account.blob.core.windows.net/documents/blob-2"},
+			}
+			return vehicle, removed, nil
+		},
+	}
+	storage := &MockStorage{Blobs: map[string][]byte{
+		"blob-1":  []byte("a"),
+		"thumb-1": []byte("b"),
+		"blob-2":  []byte("c"),
+		"blob-3":  []byte("d"),
+	}}
+	app := newDeletePicturesApp(NewDeletePicturesHandler(repo, storage, nil), "backoffice")
+
+	resp, err := app.Test(httptest.NewRequest("DELETE", "/vehicles/VEH_1/pictures?type=accident", nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var body DeletePicturesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.Removed != 2 || body.MainPictureID != "PIC_3" {
+		t.Errorf("Expected 2 removed and main PIC_3, got %+v", body)
+	}
+	if len(storage.Blobs) != 1 {
+		t.Errorf("Expected only blob-3 to remain, got %v", storage.Blobs)
+	}
+}
+
+func TestDeletePicturesHandler_InvalidType(t *testing.T) {
+	repo := &MockRepository{}
+	app := newDeletePicturesApp(NewDeletePicturesHandler(repo, &MockStorage{}, nil), "backoffice")
+
+	for _, query := range []string{"", "?type=selfie"} {
+		resp, err := app.Test(httptest.NewRequest("DELETE", "/vehicles/VEH_1/pictures"+query, nil))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if resp.StatusCode != fiber.StatusBadRequest {
+			t.Errorf("Expected status 400 for %q, got %d", query, resp.StatusCode)
+		}
+	}
+}
+
+func TestDeletePicturesHandler_RequiresService(t *testing.T) {
+	repo := &MockRepository{
+		DeletePicturesByTypeFunc: func(ctx context.Context, vehicleID string, picType domain.PictureType) (*domain.Vehicle, []domain.Picture, error) {
+			t.Error("Expected no pictures to be deleted without an API key")
+			return nil, nil, nil
+		},
+	}
+	storage := &MockStorage{Blobs: map[string][]byte{"blob-1": []byte("a")}}
+	app := newDeletePicturesApp(NewDeletePicturesHandler(repo, storage, nil), "")
+
+	resp, err := app.Test(httptest.NewRequest("DELETE", "/vehicles/VEH_1/pictures?type=accident", nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != fiber.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", resp.StatusCode)
+	}
+	if len(storage.Blobs) != 1 {
+		t.Errorf("Expected the blob to be kept, got %v", storage.Blobs)
+	}
+}
@@ -0,0 +1,59 @@
+package vehicle
+
+import (
+	"context"
+	"microservicetest/app"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/validator"
+)
+
+type DeleteVehicleRequest struct {
+	ID string `json:"id" param:"id" validate:"required"`
+}
+
+type DeleteVehicleResponse struct {
+	Message  string   `json:"message"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+type DeleteVehicleHandler struct {
+	repository Repository
+	auditLog   app.AuditLog
+}
+
+func NewDeleteVehicleHandler(repository Repository, auditLog app.AuditLog) *DeleteVehicleHandler {
+	return &DeleteVehicleHandler{
+		repository: repository,
+		auditLog:   auditLog,
+	}
+}
+
+// Handle soft deletes the vehicle. Documents and pictures are kept so the vehicle can be restored.
+func (h *DeleteVehicleHandler) Handle(ctx context.Context, req *DeleteVehicleRequest) (*DeleteVehicleResponse, error) {
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.ErrInvalidInput.WithDetails(map[string]string{
+			"validation": err.Error(),
+		})
+	}
+
+	// The request names no actor, record the calling service when there is one
+	audit, err := beginAudit(ctx, h.auditLog, app.AuditEntry{
+		VehicleID: req.ID,
+		Action:    app.AuditActionDelete,
+		Actor:     auditActor(ctx, ""),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer audit.abort(ctx)
+
+	if err := h.repository.DeleteVehicle(ctx, req.ID); err != nil {
+		return nil, err
+	}
+	warnings := audit.complete(ctx)
+
+	return &DeleteVehicleResponse{
+		Message:  "Vehicle deleted successfully",
+		Warnings: warnings,
+	}, nil
+}
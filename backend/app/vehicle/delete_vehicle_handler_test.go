@@ -0,0 +1,41 @@
+package vehicle
+
+import (
+	"context"
+	"errors"
+	apperrors "microservicetest/pkg/errors"
+	"testing"
+)
+
+func TestDeleteVehicleHandler(t *testing.T) {
+	var deleted string
+	mockRepo := &MockRepository{
+		DeleteVehicleFunc: func(ctx context.Context, id string) error {
+			deleted = id
+			return nil
+		},
+	}
+	handler := NewDeleteVehicleHandler(mockRepo, nil)
+
+	if _, err := handler.Handle(context.Background(), &DeleteVehicleRequest{ID: "VEH_1"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if deleted != "VEH_1" {
+		t.Errorf("Expected VEH_1 to be deleted, got %q", deleted)
+	}
+}
+
+func TestDeleteVehicleHandler_NotFound(t *testing.T) {
+	mockRepo := &MockRepository{
+		DeleteVehicleFunc: func(ctx context.Context, id string) error {
+			return apperrors.NewNotFoundError("vehicle", id)
+		},
+	}
+	handler := NewDeleteVehicleHandler(mockRepo, nil)
+
+	_, err := handler.Handle(context.Background(), &DeleteVehicleRequest{ID: "VEH_1"})
+
+	if !errors.Is(err, apperrors.ErrResourceNotFound) {
+		t.Errorf("Expected ErrResourceNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+package vehicle
+
+import (
+	"microservicetest/app"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/log"
+	"microservicetest/pkg/validator"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Large documents skip the API: the client asks for an upload URL, PUTs the
+// file straight to Blob Storage and then completes the upload with the metadata.
+// The placeholder ID is the blob name, so completing needs no server-side state.
+
+type CreateDocumentUploadRequest struct {
+	VehicleID string `param:"id" validate:"required"`
+}
+
+type CreateDocumentUploadResponse struct {
+	PlaceholderID string            `json:"placeholder_id"`
+	UploadURL     string            `json:"upload_url"`
+	Headers       map[string]string `json:"headers,omitempty"` // Send these with the PUT
+	ExpiresAt     time.Time         `json:"expires_at"`
+}
+
+type CreateDocumentUploadHandler struct {
+	repository     Repository
+	storageService app.Storage
+}
+
+func NewCreateDocumentUploadHandler(repository Repository, storageService app.Storage) *CreateDocumentUploadHandler {
+	return &CreateDocumentUploadHandler{
+		repository:     repository,
+		storageService: storageService,
+	}
+}
+
+func (h *CreateDocumentUploadHandler) Handle(ctx *fiber.Ctx, req *CreateDocumentUploadRequest) (*CreateDocumentUploadResponse, error) {
+	if h.storageService == nil {
+		return nil, errStorageUnavailable
+	}
+
+	req.VehicleID = ctx.Params("id")
+
+	if _, err := h.repository.GetVehicle(ctx.UserContext(), req.VehicleID); err != nil {
+		return nil, err
+	}
+
+	placeholderID := uuid.New().String()
+
+	upload, err := h.storageService.PresignUpload(ctx.UserContext(), placeholderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CreateDocumentUploadResponse{
+		PlaceholderID: placeholderID,
+		UploadURL:     upload.UploadURL,
+		Headers:       upload.Headers,
+		ExpiresAt:     upload.ExpiresAt,
+	}, nil
+}
+
+type CompleteDocumentUploadRequest struct {
+	VehicleID      string     `param:"id" validate:"required"`
+	PlaceholderID  string     `param:"placeholder" validate:"required,uuid"`
+	Type           string     `json:"type" validate:"required,documenttype"`
+	Name           string     `json:"name"`
+	Description    string     `json:"description"`
+	FileName       string     `json:"file_name"`
+	UploadedBy     string     `json:"uploaded_by"`
+	ExpiryDate     *time.Time `json:"expiry_date"`
+	IssuedDate     *time.Time `json:"issued_date"`
+	IssuedBy       string     `json:"issued_by"`
+	DocumentNumber string     `json:"document_number"`
+}
+
+type CompleteDocumentUploadHandler struct {
+	repository     Repository
+	storageService app.Storage
+	quotaBytes     int64 // Storage quota per vehicle, 0 means unlimited
+	auditLog       app.AuditLog
+}
+
+func NewCompleteDocumentUploadHandler(repository Repository, storageService app.Storage, quotaBytes int64, auditLog app.AuditLog) *CompleteDocumentUploadHandler {
+	return &CompleteDocumentUploadHandler{
+		repository:     repository,
+		storageService: storageService,
+		quotaBytes:     quotaBytes,
+		auditLog:       auditLog,
+	}
+}
+
+func (h *CompleteDocumentUploadHandler) Handle(ctx *fiber.Ctx, req *CompleteDocumentUploadRequest) (*AddDocumentResponse, error) {
+	if h.storageService == nil {
+		return nil, errStorageUnavailable
+	}
+
+	req.VehicleID = ctx.Params("id")
+	req.PlaceholderID = ctx.Params("placeholder")
+
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.ErrInvalidInput.WithDetails(map[string]string{
+			"validation": err.Error(),
+		})
+	}
+	if err := validateDocumentDates(req.IssuedDate, req.ExpiryDate, time.Now()); err != nil {
+		return nil, err
+	}
+
+	vehicle, err := h.repository.GetVehicle(ctx.UserContext(), req.VehicleID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, doc := range vehicle.Documents {
+		if blobName, err := blobNameFromURL(doc.FileURL); err == nil && blobName == req.PlaceholderID {
+			return nil, apperrors.NewConflictError("document", "upload already completed as "+doc.ID)
+		}
+	}
+
+	// Size and content type come from the stored file, not the client. The
+	// content type stored with the blob is the one the client sent with its
+	// PUT, so it is sniffed from the bytes instead.
+	size, _, exists, err := h.storageService.StatBlob(ctx.UserContext(), req.PlaceholderID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, apperrors.NewUnprocessableError("placeholder_id", "no file has been uploaded for this placeholder")
+	}
+	data, _, err := h.storageService.Download(ctx.UserContext(), req.PlaceholderID)
+	if err != nil {
+		return nil, err
+	}
+	contentType := sniffMimeType(data[:min(len(data), sniffLen)])
+	if _, err := allowedFileType(contentType); err != nil {
+		return nil, err
+	}
+	if err := checkStorageQuota(vehicle, h.quotaBytes, size); err != nil {
+		// The file is already stored; as it will not be added, do not keep it
+		if removeErr := h.storageService.Remove(ctx.UserContext(), req.PlaceholderID); removeErr != nil {
+			log.FromContext(ctx.UserContext()).Error("Failed to remove orphaned blob",
+				zap.String("filename", req.PlaceholderID),
+				zap.Error(removeErr))
+		}
+		return nil, err
+	}
+
+	documentID := domain.GenerateDocumentID()
+	audit, err := beginAudit(ctx.UserContext(), h.auditLog, app.AuditEntry{
+		VehicleID: req.VehicleID,
+		Action:    app.AuditActionAddDocument,
+		Actor:     auditActor(ctx.UserContext(), req.UploadedBy),
+		Changes:   itemsChange("documents", nil, []string{documentID}),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer audit.abort(ctx.UserContext())
+
+	document := domain.Document{
+		ID:             documentID,
+		Type:           domain.DocumentType(req.Type),
+		Name:           req.Name,
+		Description:    req.Description,
+		FileURL:        h.storageService.URL(req.PlaceholderID),
+		FileName:       req.FileName,
+		FileSize:       size,
+		MimeType:       contentType,
+		IssuedBy:       req.IssuedBy,
+		DocumentNumber: req.DocumentNumber,
+		UploadedAt:     time.Now(),
+		UploadedBy:     req.UploadedBy,
+		ExpiryDate:     req.ExpiryDate,
+		IssuedDate:     req.IssuedDate,
+	}
+
+	if err := h.repository.AddDocument(ctx.UserContext(), req.VehicleID, document); err != nil {
+		return nil, err
+	}
+
+	return &AddDocumentResponse{
+		DocumentID: document.ID,
+		UploadedAt: document.UploadedAt,
+		Warnings:   audit.complete(ctx.UserContext()),
+		vehicleID:  req.VehicleID,
+	}, nil
+}
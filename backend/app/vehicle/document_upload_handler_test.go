@@ -0,0 +1,188 @@
+package vehicle
+
+import (
+	"context"
+	"encoding/json"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func newDocumentUploadApp(create *CreateDocumentUploadHandler, complete *CompleteDocumentUploadHandler) *fiber.App {
+	app := fiber.New()
+	app.Post("/vehicles/:id/documents/upload-url", func(c *fiber.Ctx) error {
+		res, err := create.Handle(c, &CreateDocumentUploadRequest{})
+		if err != nil {
+			return apperrors.HandleError(c, err)
+		}
+		return c.JSON(res)
+	})
+	app.Post("/vehicles/:id/documents/:placeholder/complete", func(c *fiber.Ctx) error {
+		var req CompleteDocumentUploadRequest
+		if err := c.BodyParser(&req); err != nil {
+			return err
+		}
+		res, err := complete.Handle(c, &req)
+		if err != nil {
+			return apperrors.HandleError(c, err)
+		}
+		return c.JSON(res)
+	})
+	return app
+}
+
+func TestDocumentUpload_PresignAndComplete(t *testing.T) {
+	vehicle := &domain.Vehicle{ID: "VEH_1"}
+	var added domain.Document
+	repo := &MockRepository{
+		GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+			return vehicle, nil
+		},
+		AddDocumentFunc: func(ctx context.Context, vehicleID string, document domain.Document) error {
+			added = document
+			return nil
+		},
+	}
+	storage := &MockStorage{Blobs: map[string][]byte{}}
+	app := newDocumentUploadApp(NewCreateDocumentUploadHandler(repo, storage), NewCompleteDocumentUploadHandler(repo, storage, 0, nil))
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/vehicles/VEH_1/documents/upload-url", nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var upload CreateDocumentUploadResponse
+	if err := json.NewDecoder(resp.Body).Decode(&upload); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if upload.PlaceholderID == "" || !strings.Contains(upload.UploadURL, upload.PlaceholderID) {
+		t.Fatalf("Expected an upload URL for the placeholder, got %+v", upload)
+	}
+
+	complete := func() int {
+		req := httptest.NewRequest("POST", "/vehicles/VEH_1/documents/"+upload.PlaceholderID+"/complete",
+			strings.NewReader(`{"type":"registration","name":"Registration","file_name":"reg.pdf","file_size":1}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	// The client has not uploaded the file yet
+	if status := complete(); status != fiber.StatusUnprocessableEntity {
+		t.Errorf("Expected status 422 before the upload, got %d", status)
+	}
+
+	storage.Blobs[upload.PlaceholderID] = []byte("%PDF-1.7")
+
+	if status := complete(); status != fiber.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if added.FileSize != 8 || added.MimeType != "application/pdf" || !strings.HasSuffix(added.FileURL, "/"+upload.PlaceholderID) {
+		t.Errorf("Expected document metadata from the stored file, got %+v", added)
+	}
+
+	// Completing the same placeholder twice is a conflict
+	vehicle.Documents = append(vehicle.Documents, added)
+	if status := complete(); status != fiber.StatusConflict {
+		t.Errorf("Expected status 409 for the second completion, got %d", status)
+	}
+}
+
+func TestCompleteDocumentUpload_InvalidPlaceholder(t *testing.T) {
+	repo := &MockRepository{}
+	app := newDocumentUploadApp(NewCreateDocumentUploadHandler(repo, &MockStorage{}), NewCompleteDocumentUploadHandler(repo, &MockStorage{}, 0, nil))
+
+	req := httptest.NewRequest("POST", "/vehicles/VEH_1/documents/not-a-uuid/complete", strings.NewReader(`{"type":"registration"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestCompleteDocumentUpload_IssuedInFuture(t *testing.T) {
+	repo := &MockRepository{}
+	app := newDocumentUploadApp(NewCreateDocumentUploadHandler(repo, &MockStorage{}), NewCompleteDocumentUploadHandler(repo, &MockStorage{}, 0, nil))
+
+	body := `{"type":"registration","issued_date":"2099-01-01T00:00:00Z"}`
+	req := httptest.NewRequest("POST", "/vehicles/VEH_1/documents/6f1c2a9e-3b4d-4e5f-8a7b-9c0d1e2f3a4b/complete", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != fiber.StatusUnprocessableEntity {
+		t.Errorf("Expected status 422, got %d", resp.StatusCode)
+	}
+}
+
+func TestCompleteDocumentUpload_QuotaExceeded(t *testing.T) {
+	repo := &MockRepository{
+		GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+			return &domain.Vehicle{ID: id, Pictures: []domain.Picture{{FileSize: 1000, ThumbnailSize: 20}}}, nil
+		},
+		AddDocumentFunc: func(ctx context.Context, vehicleID string, document domain.Document) error {
+			t.Error("Expected no document to be added over the quota")
+			return nil
+		},
+	}
+	placeholderID := "6f1c2a9e-3b4d-4e5f-8a7b-9c0d1e2f3a4b"
+	storage := &MockStorage{Blobs: map[string][]byte{placeholderID: []byte("%PDF-1.7")}}
+	app := newDocumentUploadApp(NewCreateDocumentUploadHandler(repo, storage), NewCompleteDocumentUploadHandler(repo, storage, 1024, nil))
+
+	req := httptest.NewRequest("POST", "/vehicles/VEH_1/documents/"+placeholderID+"/complete", strings.NewReader(`{"type":"registration","name":"Registration"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var body apperrors.ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusRequestEntityTooLarge || body.Error.Code != apperrors.ErrStorageQuotaExceeded.Code {
+		t.Errorf("Expected the completion to fail over the quota with 413, got %d: %+v", resp.StatusCode, body.Error)
+	}
+	if len(storage.Blobs) != 0 {
+		t.Error("Expected the uploaded file to be removed")
+	}
+}
+
+func TestCompleteDocumentUpload_SniffsStoredFile(t *testing.T) {
+	repo := &MockRepository{
+		GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+			return &domain.Vehicle{ID: id}, nil
+		},
+		AddDocumentFunc: func(ctx context.Context, vehicleID string, document domain.Document) error {
+			t.Error("Expected no document to be added for an unaccepted file")
+			return nil
+		},
+	}
+	// The mock reports every blob as application/pdf, as a client could claim
+	placeholderID := "6f1c2a9e-3b4d-4e5f-8a7b-9c0d1e2f3a4b"
+	storage := &MockStorage{Blobs: map[string][]byte{placeholderID: []byte("MZ\x90\x00\x03\x00\x00\x00")}}
+	app := newDocumentUploadApp(NewCreateDocumentUploadHandler(repo, storage), NewCompleteDocumentUploadHandler(repo, storage, 0, nil))
+
+	req := httptest.NewRequest("POST", "/vehicles/VEH_1/documents/"+placeholderID+"/complete", strings.NewReader(`{"type":"registration","name":"Registration"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var body apperrors.ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusUnsupportedMediaType || body.Error.Code != apperrors.ErrUnsupportedMediaType.Code {
+		t.Errorf("Expected the stored bytes to be rejected with 415, got %d: %+v", resp.StatusCode, body.Error)
+	}
+}
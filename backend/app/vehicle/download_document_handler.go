@@ -1,10 +1,13 @@
 package vehicle
 
 import (
+	"archive/zip"
+	"bytes"
+	"fmt"
 	"microservicetest/app"
+	"microservicetest/domain"
 	apperrors "microservicetest/pkg/errors"
-	"net/url"
-	"strings"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -12,6 +15,7 @@ import (
 type DownloadDocumentRequest struct {
 	VehicleID  string `param:"id" validate:"required"`
 	DocumentID string `param:"doc_id" validate:"required"`
+	Page       int    `query:"page"` // 1-based; without it a multi-file document comes as a ZIP of all pages
 }
 
 type DownloadDocumentHandler struct {
@@ -27,6 +31,13 @@ func NewDownloadDocumentHandler(repository Repository, storageService app.Storag
 }
 
 func (h *DownloadDocumentHandler) Handle(ctx *fiber.Ctx, req *DownloadDocumentRequest) error {
+	if h.storageService == nil {
+		return errStorageUnavailable
+	}
+
+	// ParamsParser does not read param tags
+	req.VehicleID = ctx.Params("id")
+	req.DocumentID = ctx.Params("doc_id")
 
 	// Get vehicle
 	vehicle, err := h.repository.GetVehicle(ctx.UserContext(), req.VehicleID)
@@ -35,23 +46,10 @@ func (h *DownloadDocumentHandler) Handle(ctx *fiber.Ctx, req *DownloadDocumentRe
 	}
 
 	// Find document
-	var document *struct {
-		FileURL  string
-		FileName string
-		MimeType string
-	}
-
-	for _, doc := range vehicle.Documents {
-		if doc.ID == req.DocumentID {
-			document = &struct {
-				FileURL  string
-				FileName string
-				MimeType string
-			}{
-				FileURL:  doc.FileURL,
-				FileName: doc.FileName,
-				MimeType: doc.MimeType,
-			}
+	var document *domain.Document
+	for i := range vehicle.Documents {
+		if vehicle.Documents[i].ID == req.DocumentID {
+			document = &vehicle.Documents[i]
 			break
 		}
 	}
@@ -63,16 +61,27 @@ func (h *DownloadDocumentHandler) Handle(ctx *fiber.Ctx, req *DownloadDocumentRe
 		})
 	}
 
+	files := document.AllFiles()
+	if req.Page > 0 {
+		if req.Page > len(files) {
+			return apperrors.NewNotFoundError("document_page", strconv.Itoa(req.Page))
+		}
+		files = files[req.Page-1 : req.Page]
+	}
+	if len(files) == 0 {
+		return apperrors.NewNotFoundError("document_file", req.DocumentID)
+	}
+	if len(files) > 1 {
+		return h.sendPages(ctx, document.ID, files)
+	}
+	file := files[0]
+
 	// Extract filename from URL
-	parsedURL, err := url.Parse(document.FileURL)
+	blobFilename, err := blobNameFromURL(file.URL)
 	if err != nil {
 		return apperrors.ErrInternalServer.WithCause(err)
 	}
 
-	// Get the last part of the path (filename)
-	pathParts := strings.Split(parsedURL.Path, "/")
-	blobFilename := pathParts[len(pathParts)-1]
-
 	// Download from Azure Blob
 	data, contentType, err := h.storageService.Download(ctx.UserContext(), blobFilename)
 	if err != nil {
@@ -82,14 +91,38 @@ func (h *DownloadDocumentHandler) Handle(ctx *fiber.Ctx, req *DownloadDocumentRe
 	}
 
 	// Use stored content type if available, otherwise use downloaded one
-	if document.MimeType != "" {
-		contentType = document.MimeType
+	if file.MimeType != "" {
+		contentType = file.MimeType
 	}
 
 	// Set headers
 	ctx.Set("Content-Type", contentType)
-	ctx.Set("Content-Disposition", "attachment; filename=\""+document.FileName+"\"")
+	ctx.Set("Content-Disposition", "attachment; filename=\""+attachmentFilename(file.FileName, contentType)+"\"")
 
 	// Send file
 	return ctx.Send(data)
 }
+
+// sendPages sends every page of a multi-file document as one ZIP
+func (h *DownloadDocumentHandler) sendPages(ctx *fiber.Ctx, documentID string, files []domain.DocumentFile) error {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	names := make(map[string]struct{})
+
+	for _, file := range files {
+		name := archiveName(names, "pages", fmt.Sprintf("page-%d", file.Page), file.FileName)
+		if _, err := writeBlobEntry(ctx.UserContext(), h.storageService, zw, name, file.URL, file.Checksum); err != nil {
+			return apperrors.ErrInternalServer.WithCause(err).WithDetails(map[string]string{
+				"operation": "download_blob",
+				"page":      strconv.Itoa(file.Page),
+			})
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return apperrors.ErrInternalServer.WithCause(err)
+	}
+
+	ctx.Set(fiber.HeaderContentType, "application/zip")
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"document-%s.zip\"", documentID))
+	return ctx.Send(buf.Bytes())
+}
@@ -0,0 +1,85 @@
+package vehicle
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func newDownloadApp(handler *DownloadDocumentHandler) *fiber.App {
+	app := fiber.New()
+	app.Get("/vehicles/:id/documents/:doc_id/download", func(c *fiber.Ctx) error {
+		var req DownloadDocumentRequest
+		if err := c.ParamsParser(&req); err != nil {
+			return err
+		}
+		if err := c.QueryParser(&req); err != nil {
+			return err
+		}
+		if err := handler.Handle(c, &req); err != nil {
+			return apperrors.HandleError(c, err)
+		}
+		return nil
+	})
+	return app
+}
+
+func TestDownloadDocumentHandler_MultiFile(t *testing.T) {
+	storage := &MockStorage{Blobs: map[string][]byte{"blob-1": []byte("page one"), "blob-2": []byte("page two")}}
+	mockRepo := &MockRepository{
+		GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+			return &domain.Vehicle{ID: id, Documents: []domain.Document{{
+				ID:       "DOC_1",
+				FileURL:  "https://account.blob.core.windows.net/documents/blob-1",
+				FileName: "scan.pdf",
+				Files: []domain.DocumentFile{
+					{URL: "https://account.blob.core.windows.net/documents/blob-1", FileName: "scan.pdf", MimeType: "application/pdf", Page: 1},
+					{URL: "https://account.blob.core.windows.net/documents/blob-2", FileName: "scan.pdf", MimeType: "application/pdf", Page: 2},
+				},
+			}}}, nil
+		},
+	}
+	app := newDownloadApp(NewDownloadDocumentHandler(mockRepo, storage))
+
+	// Without a page every file comes back in a ZIP, same-named pages kept apart
+	resp, err := app.Test(httptest.NewRequest("GET", "/vehicles/VEH_1/documents/DOC_1/download", nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK || resp.Header.Get(fiber.HeaderContentType) != "application/zip" {
+		t.Fatalf("Expected a ZIP with status 200, got %d %s", resp.StatusCode, resp.Header.Get(fiber.HeaderContentType))
+	}
+	body, _ := io.ReadAll(resp.Body)
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatalf("Expected a valid ZIP, got %v", err)
+	}
+	if len(zr.File) != 2 || zr.File[0].Name != "pages/scan.pdf" || zr.File[1].Name != "pages/page-2-scan.pdf" {
+		t.Errorf("Expected both pages in the ZIP, got %d entries", len(zr.File))
+	}
+
+	// A single page comes back as is
+	resp, err = app.Test(httptest.NewRequest("GET", "/vehicles/VEH_1/documents/DOC_1/download?page=2", nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	if string(body) != "page two" || resp.Header.Get(fiber.HeaderContentType) != "application/pdf" {
+		t.Errorf("Expected page two as a PDF, got %q %s", body, resp.Header.Get(fiber.HeaderContentType))
+	}
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/vehicles/VEH_1/documents/DOC_1/download?page=3", nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != fiber.StatusNotFound {
+		t.Errorf("Expected status 404 for a missing page, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,68 @@
+package vehicle
+
+import (
+	"context"
+	"microservicetest/app"
+	"microservicetest/domain"
+	"microservicetest/pkg/log"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// EventDocumentVerificationExpired is published once per document whose verification lapsed
+const EventDocumentVerificationExpired = "document.verification_expired"
+
+// VerificationExpiry lists the documents of one vehicle whose verification was revoked
+type VerificationExpiry struct {
+	VehicleID string
+	OwnerID   string
+	Documents []domain.Document
+}
+
+// ExpireVerificationsJob revokes verifications of expired documents and notifies owners
+type ExpireVerificationsJob struct {
+	repository Repository
+	publisher  app.EventPublisher
+}
+
+func NewExpireVerificationsJob(repository Repository, publisher app.EventPublisher) *ExpireVerificationsJob {
+	return &ExpireVerificationsJob{
+		repository: repository,
+		publisher:  publisher,
+	}
+}
+
+// Run is meant to be scheduled periodically. A failed event is logged and does not
+// undo the revocation, the document state is the source of truth.
+func (j *ExpireVerificationsJob) Run(ctx context.Context) error {
+	expiries, err := j.repository.ExpireVerifications(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, expiry := range expiries {
+		for _, doc := range expiry.Documents {
+			event := app.Event{
+				Type:       EventDocumentVerificationExpired,
+				VehicleID:  expiry.VehicleID,
+				OwnerID:    expiry.OwnerID,
+				OccurredAt: time.Now(),
+				Data: map[string]string{
+					"document_id":   doc.ID,
+					"document_type": string(doc.Type),
+					"note":          doc.VerificationNote,
+				},
+			}
+			if err := j.publisher.Publish(ctx, event); err != nil {
+				log.FromContext(ctx).Error("Failed to publish verification expiry",
+					zap.String("vehicle_id", expiry.VehicleID),
+					zap.String("document_id", doc.ID),
+					zap.Error(err),
+				)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package vehicle
+
+import (
+	"context"
+	"errors"
+	"microservicetest/app"
+	"microservicetest/domain"
+	"testing"
+)
+
+// MockPublisher records published events
+type MockPublisher struct {
+	Events []app.Event
+	Err    error
+}
+
+func (m *MockPublisher) Publish(ctx context.Context, event app.Event) error {
+	m.Events = append(m.Events, event)
+	return m.Err
+}
+
+func TestExpireVerificationsJob_PublishesPerDocument(t *testing.T) {
+	mockRepo := &MockRepository{
+		ExpireVerificationsFunc: func(ctx context.Context) ([]VerificationExpiry, error) {
+			return []VerificationExpiry{
+				{VehicleID: "VEH_1", OwnerID: "OWNER_1", Documents: []domain.Document{
+					{ID: "DOC_1", Type: domain.DocumentTypeInspection},
+					{ID: "DOC_2", Type: domain.DocumentTypeRegistration},
+				}},
+			}, nil
+		},
+	}
+	// A failing publisher must not fail the job
+	publisher := &MockPublisher{Err: errors.New("broker down")}
+
+	if err := NewExpireVerificationsJob(mockRepo, publisher).Run(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(publisher.Events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(publisher.Events))
+	}
+	event := publisher.Events[0]
+	if event.Type != EventDocumentVerificationExpired || event.OwnerID != "OWNER_1" || event.Data["document_id"] != "DOC_1" {
+		t.Errorf("Unexpected event %+v", event)
+	}
+}
+
+func TestExpireVerificationsJob_RepositoryError(t *testing.T) {
+	mockRepo := &MockRepository{
+		ExpireVerificationsFunc: func(ctx context.Context) ([]VerificationExpiry, error) {
+			return nil, errors.New("query failed")
+		},
+	}
+	publisher := &MockPublisher{}
+
+	if err := NewExpireVerificationsJob(mockRepo, publisher).Run(context.Background()); err == nil {
+		t.Fatal("Expected error")
+	}
+	if len(publisher.Events) != 0 {
+		t.Errorf("Expected no events, got %d", len(publisher.Events))
+	}
+}
@@ -0,0 +1,94 @@
+package vehicle
+
+import (
+	"io"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// sniffLen is how many leading bytes content sniffing looks at
+const sniffLen = 512
+
+// heifBrands maps the ftyp brands of HEIF images, which iPhones take photos
+// as, to their MIME type. http.DetectContentType does not know them.
+var heifBrands = map[string]string{
+	"heic": "image/heic",
+	"heix": "image/heic",
+	"heim": "image/heic",
+	"heis": "image/heic",
+	"mif1": "image/heif",
+	"heif": "image/heif",
+}
+
+// sniffMimeType detects the MIME type of a file from its leading bytes,
+// without parameters such as charset
+func sniffMimeType(head []byte) string {
+	if len(head) >= 12 && string(head[4:8]) == "ftyp" {
+		if mimeType, ok := heifBrands[string(head[8:12])]; ok {
+			return mimeType
+		}
+	}
+	mimeType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	return mimeType
+}
+
+// checkFileType sniffs the MIME type of an upload and returns it with its
+// canonical extension, or ErrUnsupportedMediaType when it is not accepted.
+// The file is rewound afterwards.
+func checkFileType(file io.ReadSeeker) (mimeType, ext string, err error) {
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", "", apperrors.ErrInternalServer.WithCause(err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", "", apperrors.ErrInternalServer.WithCause(err)
+	}
+
+	mimeType = sniffMimeType(head[:n])
+	ext, err = allowedFileType(mimeType)
+	return mimeType, ext, err
+}
+
+// allowedFileType returns the canonical extension of mimeType, or
+// ErrUnsupportedMediaType listing the accepted types
+func allowedFileType(mimeType string) (string, error) {
+	ext, ok := domain.FileExtension(mimeType)
+	if !ok {
+		return "", apperrors.ErrUnsupportedMediaType.WithDetails(map[string]string{
+			"mime_type": mimeType,
+			"allowed":   strings.Join(domain.AllowedMimeTypes(), ", "),
+		})
+	}
+	return ext, nil
+}
+
+// attachmentFilename makes a stored file name safe for a Content-Disposition
+// header and gives it the canonical extension of its MIME type
+func attachmentFilename(fileName, mimeType string) string {
+	name := strings.Map(func(r rune) rune {
+		if r < 0x20 || r == '"' || r == '\\' || r == '/' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, fileName)
+	if name == "" {
+		name = "file"
+	}
+
+	ext, ok := domain.FileExtension(mimeType)
+	if !ok {
+		return name
+	}
+	if current := filepath.Ext(name); current != "" && !strings.EqualFold(current, ext) {
+		name = strings.TrimSuffix(name, current)
+	}
+	if !strings.EqualFold(filepath.Ext(name), ext) {
+		name += ext
+	}
+	return name
+}
@@ -0,0 +1,82 @@
+package vehicle
+
+import (
+	"bytes"
+	"errors"
+	apperrors "microservicetest/pkg/errors"
+	"testing"
+)
+
+func TestCheckFileType(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  []byte
+		mimeType string
+		ext      string
+	}{
+		{"pdf", []byte("%PDF-1.7\n%âãÏÓ"), "application/pdf", ".pdf"},
+		{"jpeg", []byte("\xFF\xD8\xFF\xE0\x00\x10JFIF"), "image/jpeg", ".jpg"},
+		{"png", []byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR"), "image/png", ".png"},
+		{"gif", []byte("GIF89a\x01\x00\x01\x00"), "image/gif", ".gif"},
+		{"webp", []byte("RIFF\x24\x00\x00\x00WEBPVP8 "), "image/webp", ".webp"},
+		{"heic", []byte("\x00\x00\x00\x18ftypheic\x00\x00\x00\x00mif1heic"), "image/heic", ".heic"},
+		{"heif", []byte("\x00\x00\x00\x10ftypmif1\x00\x00\x00\x00"), "image/heif", ".heif"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := bytes.NewReader(tt.content)
+
+			mimeType, ext, err := checkFileType(file)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if mimeType != tt.mimeType || ext != tt.ext {
+				t.Errorf("Expected %s %s, got %s %s", tt.mimeType, tt.ext, mimeType, ext)
+			}
+			if file.Len() != len(tt.content) {
+				t.Error("Expected the file to be rewound")
+			}
+		})
+	}
+}
+
+func TestCheckFileType_Unsupported(t *testing.T) {
+	for name, content := range map[string][]byte{
+		"html":  []byte("<!DOCTYPE html><html></html>"),
+		"text":  []byte("just some notes"),
+		"zip":   []byte("PK\x03\x04\x14\x00"),
+		"empty": {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := checkFileType(bytes.NewReader(content)); !errors.Is(err, apperrors.ErrUnsupportedMediaType) {
+				t.Errorf("Expected ErrUnsupportedMediaType, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAttachmentFilename(t *testing.T) {
+	tests := []struct {
+		fileName string
+		mimeType string
+		expected string
+	}{
+		{"scan.pdf", "application/pdf", "scan.pdf"},
+		{"scan.PDF", "application/pdf", "scan.PDF"},
+		{"scan", "application/pdf", "scan.pdf"},
+		{"photo.jpeg", "image/jpeg", "photo.jpg"},
+		{"invoice.pdf.exe", "application/pdf", "invoice.pdf"},
+		{`a"b\\c/d.png`, "image/png", "abcd.png"},
+		{"", "image/gif", "file.gif"},
+		{"notes.txt", "text/plain", "notes.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fileName, func(t *testing.T) {
+			if got := attachmentFilename(tt.fileName, tt.mimeType); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package vehicle
+
+import (
+	"context"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/validator"
+)
+
+type GetComplianceRequest struct {
+	ID string `json:"id" param:"id" validate:"required"`
+}
+
+// GetComplianceResponse tells whether a vehicle has the documents its current status requires
+type GetComplianceResponse struct {
+	VehicleID string                `json:"vehicle_id"`
+	Status    domain.VehicleStatus  `json:"status"`
+	Compliant bool                  `json:"compliant"`
+	Required  []domain.DocumentType `json:"required"`
+	Missing   []domain.DocumentType `json:"missing"`
+}
+
+type GetComplianceHandler struct {
+	repository Repository
+}
+
+func NewGetComplianceHandler(repository Repository) *GetComplianceHandler {
+	return &GetComplianceHandler{
+		repository: repository,
+	}
+}
+
+func (h *GetComplianceHandler) Handle(ctx context.Context, req *GetComplianceRequest) (*GetComplianceResponse, error) {
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.ErrInvalidInput.WithDetails(map[string]string{
+			"validation": err.Error(),
+		})
+	}
+
+	vehicle, err := h.repository.GetVehicle(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	required := domain.StatusRequiredDocuments(vehicle.Status)
+	if required == nil {
+		required = []domain.DocumentType{}
+	}
+	missing := vehicle.MissingDocumentsForStatus(vehicle.Status)
+
+	return &GetComplianceResponse{
+		VehicleID: vehicle.ID,
+		Status:    vehicle.Status,
+		Compliant: len(missing) == 0,
+		Required:  required,
+		Missing:   missing,
+	}, nil
+}
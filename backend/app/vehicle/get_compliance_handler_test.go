@@ -0,0 +1,45 @@
+package vehicle
+
+import (
+	"context"
+	"microservicetest/domain"
+	"slices"
+	"testing"
+)
+
+func TestGetComplianceHandler(t *testing.T) {
+	status := domain.VehicleStatusSold
+	mockRepo := &MockRepository{
+		GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+			return &domain.Vehicle{
+				ID:     id,
+				Status: status,
+				Documents: []domain.Document{
+					{ID: "DOC_1", Type: domain.DocumentTypeTitle},
+				},
+			}, nil
+		},
+	}
+	handler := NewGetComplianceHandler(mockRepo)
+
+	resp, err := handler.Handle(context.Background(), &GetComplianceRequest{ID: "VEH_1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.Compliant || resp.Status != domain.VehicleStatusSold {
+		t.Errorf("Expected a sold vehicle without purchase agreement to be non-compliant, got %+v", resp)
+	}
+	if expected := []domain.DocumentType{domain.DocumentTypePurchaseAgreement}; !slices.Equal(resp.Missing, expected) {
+		t.Errorf("Expected missing %v, got %v", expected, resp.Missing)
+	}
+
+	// Statuses without requirements are always compliant
+	status = domain.VehicleStatusActive
+	resp, err = handler.Handle(context.Background(), &GetComplianceRequest{ID: "VEH_1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !resp.Compliant || len(resp.Required) != 0 || len(resp.Missing) != 0 {
+		t.Errorf("Expected an active vehicle to be compliant, got %+v", resp)
+	}
+}
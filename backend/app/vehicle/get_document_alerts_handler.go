@@ -0,0 +1,86 @@
+package vehicle
+
+import (
+	"context"
+	"math"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/validator"
+	"time"
+)
+
+const defaultDocumentAlertDays = 30
+
+type GetDocumentAlertsRequest struct {
+	ID   string `json:"id" param:"id" validate:"required"`
+	Days *int   `query:"days"` // See lookaheadDays, defaults to defaultDocumentAlertDays
+}
+
+type DocumentAlert struct {
+	DocumentResponse
+	DaysUntilExpiry int `json:"days_until_expiry"` // Negative once expired
+}
+
+type GetDocumentAlertsResponse struct {
+	Expired  []DocumentAlert `json:"expired"`
+	Expiring []DocumentAlert `json:"expiring"`
+	Days     int             `json:"days"`
+}
+
+type GetDocumentAlertsHandler struct {
+	repository Repository
+}
+
+func NewGetDocumentAlertsHandler(repository Repository) *GetDocumentAlertsHandler {
+	return &GetDocumentAlertsHandler{
+		repository: repository,
+	}
+}
+
+func (h *GetDocumentAlertsHandler) Handle(ctx context.Context, req *GetDocumentAlertsRequest) (*GetDocumentAlertsResponse, error) {
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.ErrInvalidInput.WithDetails(map[string]string{
+			"validation": err.Error(),
+		})
+	}
+
+	days, err := lookaheadDays("days", req.Days, defaultDocumentAlertDays)
+	if err != nil {
+		return nil, err
+	}
+
+	vehicle, err := h.repository.GetVehicle(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	return &GetDocumentAlertsResponse{
+		Expired:  newDocumentAlerts(vehicle.GetExpiredDocuments(), now),
+		Expiring: newDocumentAlerts(vehicle.GetExpiringDocuments(days), now),
+		Days:     days,
+	}, nil
+}
+
+// newDocumentAlerts converts documents with an expiry date to alerts
+func newDocumentAlerts(docs []domain.Document, now time.Time) []DocumentAlert {
+	alerts := make([]DocumentAlert, 0, len(docs))
+	for _, doc := range docs {
+		alerts = append(alerts, DocumentAlert{
+			DocumentResponse: newDocumentResponse(doc, now),
+			DaysUntilExpiry:  daysUntil(*doc.ExpiryDate, now),
+		})
+	}
+	return alerts
+}
+
+// daysUntil counts started days until t, rounding away from now so a
+// document expiring later today is 1 day out and one expired this morning is -1
+func daysUntil(t time.Time, now time.Time) int {
+	days := t.Sub(now).Hours() / 24
+	if days >= 0 {
+		return int(math.Ceil(days))
+	}
+	return int(math.Floor(days))
+}
@@ -1,6 +1,8 @@
 package vehicle
 
 import (
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -18,36 +20,49 @@ type DocumentFilter struct {
 type GetDocumentsRequest struct {
 	VehicleID string `param:"id" validate:"required"`
 	// Query filters
-	Type           string `query:"type" validate:"omitempty,oneof=insurance_policy insurance_card registration title inspection emission_test purchase_agreement service_record warranty receipt accident_report other"`
-	IsVerified     string `query:"is_verified"`     // "true", "false", or empty
-	IsExpired      string `query:"is_expired"`      // "true", "false", or empty
+	Type           string `query:"type"`        // Checked against domain.IsValidDocumentType
+	IsVerified     string `query:"is_verified"` // "true", "false", or empty
+	IsExpired      string `query:"is_expired"`  // "true", "false", or empty
 	UploadedBy     string `query:"uploaded_by"`
 	IssuedBy       string `query:"issued_by"`
 	DocumentNumber string `query:"document_number"`
+	GroupBy        string `query:"group_by"` // "type" nests the documents under their type
 }
 
+// DocumentGroupByType is the group_by value that nests documents under their type
+const DocumentGroupByType = "type"
+
 type DocumentResponse struct {
-	ID             string     `json:"id"`
-	Type           string     `json:"type"`
-	Name           string     `json:"name"`
-	Description    string     `json:"description,omitempty"`
-	FileURL        string     `json:"file_url"`
-	FileName       string     `json:"file_name"`
-	FileSize       int64      `json:"file_size"`
-	MimeType       string     `json:"mime_type"`
-	IssuedBy       string     `json:"issued_by,omitempty"`
-	DocumentNumber string     `json:"document_number,omitempty"`
-	UploadedAt     time.Time  `json:"uploaded_at"`
-	UploadedBy     string     `json:"uploaded_by,omitempty"`
-	ExpiryDate     *time.Time `json:"expiry_date,omitempty"`
-	IssuedDate     *time.Time `json:"issued_date,omitempty"`
-	IsVerified     bool       `json:"is_verified"`
-	IsExpired      bool       `json:"is_expired"`
+	ID             string                `json:"id"`
+	Type           string                `json:"type"`
+	Name           string                `json:"name"`
+	Description    string                `json:"description,omitempty"`
+	FileURL        string                `json:"file_url"`
+	FileName       string                `json:"file_name"`
+	FileSize       int64                 `json:"file_size"`
+	MimeType       string                `json:"mime_type"`
+	Files          []domain.DocumentFile `json:"files,omitempty"` // Pages of a multi-file document
+	IssuedBy       string                `json:"issued_by,omitempty"`
+	DocumentNumber string                `json:"document_number,omitempty"`
+	UploadedAt     time.Time             `json:"uploaded_at"`
+	UploadedBy     string                `json:"uploaded_by,omitempty"`
+	ExpiryDate     *time.Time            `json:"expiry_date,omitempty"`
+	IssuedDate     *time.Time            `json:"issued_date,omitempty"`
+	IsVerified     bool                  `json:"is_verified"`
+	IsExpired      bool                  `json:"is_expired"`
 }
 
+// GetDocumentsResponse carries either the flat Documents list or, with
+// group_by=type, the documents in Groups keyed by type
 type GetDocumentsResponse struct {
+	Documents []DocumentResponse       `json:"documents,omitzero"`
+	Groups    map[string]DocumentGroup `json:"groups,omitzero"`
+	Total     int                      `json:"total"`
+}
+
+type DocumentGroup struct {
+	Count     int                `json:"count"`
 	Documents []DocumentResponse `json:"documents"`
-	Total     int                `json:"total"`
 }
 
 type GetDocumentsHandler struct {
@@ -63,6 +78,13 @@ func NewGetDocumentsHandler(repository Repository) *GetDocumentsHandler {
 func (h *GetDocumentsHandler) Handle(ctx *fiber.Ctx, req *GetDocumentsRequest) (*GetDocumentsResponse, error) {
 	vehicleID := ctx.Params("id")
 
+	if req.Type != "" && !domain.IsValidDocumentType(req.Type) {
+		return nil, apperrors.NewValidationError("type", "must be a known document type")
+	}
+	if req.GroupBy != "" && req.GroupBy != DocumentGroupByType {
+		return nil, apperrors.NewValidationError("group_by", "must be type")
+	}
+
 	// Verify vehicle exists
 	_, err := h.repository.GetVehicle(ctx.UserContext(), vehicleID)
 	if err != nil {
@@ -99,27 +121,16 @@ func (h *GetDocumentsHandler) Handle(ctx *fiber.Ctx, req *GetDocumentsRequest) (
 	// Convert to response format
 	documents := make([]DocumentResponse, 0, len(docs))
 	now := time.Now()
-	
+
 	for _, doc := range docs {
-		isExpired := doc.ExpiryDate != nil && doc.ExpiryDate.Before(now)
-		documents = append(documents, DocumentResponse{
-			ID:             doc.ID,
-			Type:           string(doc.Type),
-			Name:           doc.Name,
-			Description:    doc.Description,
-			FileURL:        doc.FileURL,
-			FileName:       doc.FileName,
-			FileSize:       doc.FileSize,
-			MimeType:       doc.MimeType,
-			IssuedBy:       doc.IssuedBy,
-			DocumentNumber: doc.DocumentNumber,
-			UploadedAt:     doc.UploadedAt,
-			UploadedBy:     doc.UploadedBy,
-			ExpiryDate:     doc.ExpiryDate,
-			IssuedDate:     doc.IssuedDate,
-			IsVerified:     doc.IsVerified,
-			IsExpired:      isExpired,
-		})
+		documents = append(documents, newDocumentResponse(doc, now))
+	}
+
+	if req.GroupBy == DocumentGroupByType {
+		return &GetDocumentsResponse{
+			Groups: groupDocumentsByType(documents),
+			Total:  len(documents),
+		}, nil
 	}
 
 	return &GetDocumentsResponse{
@@ -127,3 +138,39 @@ func (h *GetDocumentsHandler) Handle(ctx *fiber.Ctx, req *GetDocumentsRequest) (
 		Total:     len(documents),
 	}, nil
 }
+
+// groupDocumentsByType nests documents under their type, keeping their order
+// within a type. Only types with at least one document get a group.
+func groupDocumentsByType(documents []DocumentResponse) map[string]DocumentGroup {
+	groups := make(map[string]DocumentGroup)
+	for _, doc := range documents {
+		group := groups[doc.Type]
+		group.Documents = append(group.Documents, doc)
+		group.Count++
+		groups[doc.Type] = group
+	}
+	return groups
+}
+
+// newDocumentResponse converts a document to its API view, computing IsExpired against now
+func newDocumentResponse(doc domain.Document, now time.Time) DocumentResponse {
+	return DocumentResponse{
+		ID:             doc.ID,
+		Type:           string(doc.Type),
+		Name:           doc.Name,
+		Description:    doc.Description,
+		FileURL:        doc.FileURL,
+		FileName:       doc.FileName,
+		FileSize:       doc.FileSize,
+		MimeType:       doc.MimeType,
+		Files:          doc.Files,
+		IssuedBy:       doc.IssuedBy,
+		DocumentNumber: doc.DocumentNumber,
+		UploadedAt:     doc.UploadedAt,
+		UploadedBy:     doc.UploadedBy,
+		ExpiryDate:     doc.ExpiryDate,
+		IssuedDate:     doc.IssuedDate,
+		IsVerified:     doc.IsVerified,
+		IsExpired:      doc.ExpiryDate != nil && doc.ExpiryDate.Before(now),
+	}
+}
@@ -0,0 +1,88 @@
+package vehicle
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestGetDocumentsHandler_GroupBy(t *testing.T) {
+	repo := &MockRepository{
+		GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+			return &domain.Vehicle{ID: id}, nil
+		},
+		GetDocumentsFunc: func(ctx context.Context, vehicleID string, filter DocumentFilter) ([]domain.Document, error) {
+			return []domain.Document{
+				{ID: "DOC_1", Type: domain.DocumentTypeRegistration},
+				{ID: "DOC_2", Type: domain.DocumentTypeInsurancePolicy},
+				{ID: "DOC_3", Type: domain.DocumentTypeRegistration},
+			}, nil
+		},
+	}
+	handler := NewGetDocumentsHandler(repo)
+
+	app := fiber.New()
+	app.Get("/vehicles/:id/documents", func(c *fiber.Ctx) error {
+		req := &GetDocumentsRequest{}
+		if err := c.QueryParser(req); err != nil {
+			return err
+		}
+		res, err := handler.Handle(c, req)
+		if err != nil {
+			return apperrors.HandleError(c, err)
+		}
+		return c.JSON(res)
+	})
+
+	get := func(query string) (int, map[string]json.RawMessage) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/vehicles/VEH_1/documents"+query, nil))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		var fields map[string]json.RawMessage
+		json.Unmarshal(body, &fields)
+		return resp.StatusCode, fields
+	}
+
+	status, flat := get("")
+	if status != fiber.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if _, ok := flat["groups"]; ok {
+		t.Error("Expected no groups in the flat shape")
+	}
+	var documents []DocumentResponse
+	if err := json.Unmarshal(flat["documents"], &documents); err != nil || len(documents) != 3 {
+		t.Errorf("Expected 3 flat documents, got %s", flat["documents"])
+	}
+
+	status, grouped := get("?group_by=type")
+	if status != fiber.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if _, ok := grouped["documents"]; ok {
+		t.Error("Expected no flat documents in the grouped shape")
+	}
+	var groups map[string]DocumentGroup
+	if err := json.Unmarshal(grouped["groups"], &groups); err != nil {
+		t.Fatalf("Failed to decode groups: %v", err)
+	}
+	registration := groups[string(domain.DocumentTypeRegistration)]
+	if registration.Count != 2 || registration.Documents[0].ID != "DOC_1" || registration.Documents[1].ID != "DOC_3" {
+		t.Errorf("Expected DOC_1 and DOC_3 under registration, got %+v", registration)
+	}
+	if groups[string(domain.DocumentTypeInsurancePolicy)].Count != 1 || len(groups) != 2 {
+		t.Errorf("Expected one insurance_policy group and no others, got %+v", groups)
+	}
+
+	if status, _ := get("?group_by=owner"); status != fiber.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown group_by, got %d", status)
+	}
+}
@@ -0,0 +1,70 @@
+package vehicle
+
+import (
+	"context"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/validator"
+)
+
+type GetDocumentSummaryRequest struct {
+	ID string `json:"id" param:"id" validate:"required"`
+}
+
+type DocumentTypeSummary struct {
+	Count     int   `json:"count"`
+	TotalSize int64 `json:"total_size"` // Bytes
+}
+
+type GetDocumentSummaryResponse struct {
+	Types           map[domain.DocumentType]DocumentTypeSummary `json:"types"`
+	Total           int                                         `json:"total"`
+	MissingRequired []domain.DocumentType                       `json:"missing_required"`
+	Completeness    float64                                     `json:"completeness"` // Share of required types on file, 0 to 1
+}
+
+type GetDocumentSummaryHandler struct {
+	repository Repository
+}
+
+func NewGetDocumentSummaryHandler(repository Repository) *GetDocumentSummaryHandler {
+	return &GetDocumentSummaryHandler{
+		repository: repository,
+	}
+}
+
+func (h *GetDocumentSummaryHandler) Handle(ctx context.Context, req *GetDocumentSummaryRequest) (*GetDocumentSummaryResponse, error) {
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.ErrInvalidInput.WithDetails(map[string]string{
+			"validation": err.Error(),
+		})
+	}
+
+	vehicle, err := h.repository.GetVehicle(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	types := make(map[domain.DocumentType]DocumentTypeSummary)
+	for _, doc := range vehicle.Documents {
+		if _, done := types[doc.Type]; done {
+			continue
+		}
+
+		summary := DocumentTypeSummary{}
+		for _, typed := range vehicle.GetDocumentsByType(doc.Type) {
+			summary.Count++
+			summary.TotalSize += typed.FileSize
+		}
+		types[doc.Type] = summary
+	}
+
+	completeness, missing := vehicle.DocumentCompleteness()
+
+	return &GetDocumentSummaryResponse{
+		Types:           types,
+		Total:           len(vehicle.Documents),
+		MissingRequired: missing,
+		Completeness:    completeness,
+	}, nil
+}
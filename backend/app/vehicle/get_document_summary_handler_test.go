@@ -0,0 +1,47 @@
+package vehicle
+
+import (
+	"context"
+	"microservicetest/domain"
+	"slices"
+	"testing"
+)
+
+func TestGetDocumentSummaryHandler(t *testing.T) {
+	mockRepo := &MockRepository{
+		GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+			return &domain.Vehicle{
+				ID: id,
+				Documents: []domain.Document{
+					{ID: "DOC_1", Type: domain.DocumentTypeRegistration, FileSize: 100},
+					{ID: "DOC_2", Type: domain.DocumentTypeServiceRecord, FileSize: 200},
+					{ID: "DOC_3", Type: domain.DocumentTypeServiceRecord, FileSize: 300},
+				},
+			}, nil
+		},
+	}
+	handler := NewGetDocumentSummaryHandler(mockRepo)
+
+	resp, err := handler.Handle(context.Background(), &GetDocumentSummaryRequest{ID: "VEH_1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if resp.Total != 3 {
+		t.Errorf("Expected total 3, got %d", resp.Total)
+	}
+	if got := resp.Types[domain.DocumentTypeServiceRecord]; got.Count != 2 || got.TotalSize != 500 {
+		t.Errorf("Expected 2 service records of 500 bytes, got %+v", got)
+	}
+	if got := resp.Types[domain.DocumentTypeRegistration]; got.Count != 1 || got.TotalSize != 100 {
+		t.Errorf("Expected 1 registration of 100 bytes, got %+v", got)
+	}
+
+	expectedMissing := []domain.DocumentType{domain.DocumentTypeInsurancePolicy, domain.DocumentTypeInspection}
+	if !slices.Equal(resp.MissingRequired, expectedMissing) {
+		t.Errorf("Expected missing %v, got %v", expectedMissing, resp.MissingRequired)
+	}
+	if resp.Completeness != 1.0/3 {
+		t.Errorf("Expected completeness 1/3, got %v", resp.Completeness)
+	}
+}
@@ -0,0 +1,149 @@
+package vehicle
+
+import (
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/pagination"
+	"microservicetest/pkg/validator"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const defaultOwnerDocumentsLimit = 50
+
+// OwnerDocumentFilter selects documents across all vehicles of an owner
+type OwnerDocumentFilter struct {
+	Type               string
+	ExpiringWithinDays int    // 0 means no expiry filter
+	Order              string // Expiry date order: "asc" or "desc"
+	Limit              int
+	Offset             int
+	After              *pagination.Cursor // Continue after this document instead of skipping Offset, see ownerDocumentCursor
+}
+
+// OwnerDocument is a document annotated with the vehicle it belongs to
+type OwnerDocument struct {
+	domain.Document
+	VehicleID string `json:"vehicle_id"`
+	VIN       string `json:"vin"`
+}
+
+type GetOwnerDocumentsRequest struct {
+	OwnerID            string `param:"owner_id" validate:"required"`
+	Type               string `query:"type" validate:"omitempty,documenttype"`
+	ExpiringWithinDays *int   `query:"expiring_within_days"` // See lookaheadDays, no expiry filter when not sent
+	Order              string `query:"order" validate:"omitempty,oneof=asc desc"`
+	Limit              int    `query:"limit" validate:"gte=0,lte=100"`
+	Offset             int    `query:"offset" validate:"gte=0"`
+	Cursor             string `query:"cursor"` // next_cursor of the previous page
+}
+
+type OwnerDocumentResponse struct {
+	DocumentResponse
+	VehicleID string `json:"vehicle_id"`
+	VIN       string `json:"vin"`
+}
+
+type GetOwnerDocumentsResponse struct {
+	Documents  []OwnerDocumentResponse `json:"documents"`
+	Total      int                     `json:"total"`
+	Limit      int                     `json:"limit"`
+	Offset     int                     `json:"offset"`
+	NextCursor string                  `json:"next_cursor,omitempty"` // Set while a full page was returned
+}
+
+type GetOwnerDocumentsHandler struct {
+	repository           Repository
+	unknownOwnerNotFound bool
+}
+
+// NewGetOwnerDocumentsHandler answers an owner no vehicle has ever had with 404
+// when unknownOwnerNotFound is set and with an empty list otherwise
+func NewGetOwnerDocumentsHandler(repository Repository, unknownOwnerNotFound bool) *GetOwnerDocumentsHandler {
+	return &GetOwnerDocumentsHandler{
+		repository:           repository,
+		unknownOwnerNotFound: unknownOwnerNotFound,
+	}
+}
+
+func (h *GetOwnerDocumentsHandler) Handle(ctx *fiber.Ctx, req *GetOwnerDocumentsRequest) (*GetOwnerDocumentsResponse, error) {
+	req.OwnerID = ctx.Params("owner_id")
+
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.ErrInvalidInput.WithDetails(map[string]string{
+			"validation": err.Error(),
+		})
+	}
+
+	expiringWithinDays, err := lookaheadDays("expiring_within_days", req.ExpiringWithinDays, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := OwnerDocumentFilter{
+		Type:               req.Type,
+		ExpiringWithinDays: expiringWithinDays,
+		Order:              req.Order,
+		Limit:              req.Limit,
+		Offset:             req.Offset,
+	}
+	if filter.Order == "" {
+		filter.Order = "asc"
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultOwnerDocumentsLimit
+	}
+	if req.Cursor != "" {
+		after, err := decodeCursor(req.Cursor, req.Offset, func(value any) bool {
+			_, ok := value.(float64)
+			return ok || value == nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		filter.After = after
+	}
+
+	docs, total, err := h.repository.GetOwnerDocuments(ctx.UserContext(), req.OwnerID, filter)
+	if err != nil {
+		return nil, err
+	}
+	if total == 0 && h.unknownOwnerNotFound {
+		if err := checkOwnerKnown(ctx.UserContext(), h.repository, req.OwnerID); err != nil {
+			return nil, err
+		}
+	}
+
+	documents := make([]OwnerDocumentResponse, 0, len(docs))
+	now := time.Now()
+
+	for _, doc := range docs {
+		documents = append(documents, OwnerDocumentResponse{
+			DocumentResponse: newDocumentResponse(doc.Document, now),
+			VehicleID:        doc.VehicleID,
+			VIN:              doc.VIN,
+		})
+	}
+
+	resp := &GetOwnerDocumentsResponse{
+		Documents: documents,
+		Total:     total,
+		Limit:     filter.Limit,
+		Offset:    filter.Offset,
+	}
+	if len(docs) > 0 && len(docs) == filter.Limit {
+		resp.NextCursor = ownerDocumentCursor(docs[len(docs)-1].Document).Encode()
+	}
+	return resp, nil
+}
+
+// ownerDocumentCursor is the keyset position of a document in GetOwnerDocuments,
+// which orders by expiry date in milliseconds and then ID, documents without
+// an expiry date last
+func ownerDocumentCursor(doc domain.Document) pagination.Cursor {
+	if doc.ExpiryDate == nil {
+		return pagination.Cursor{ID: doc.ID}
+	}
+	return pagination.Cursor{Value: float64(doc.ExpiryDate.UnixMilli()), ID: doc.ID}
+}
@@ -0,0 +1,89 @@
+package vehicle
+
+import (
+	"context"
+	"encoding/json"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func newOwnerDocumentsApp(handler *GetOwnerDocumentsHandler) *fiber.App {
+	app := fiber.New()
+	app.Get("/owners/:owner_id/documents", func(c *fiber.Ctx) error {
+		var req GetOwnerDocumentsRequest
+		if err := c.QueryParser(&req); err != nil {
+			return err
+		}
+		res, err := handler.Handle(c, &req)
+		if err != nil {
+			return apperrors.HandleError(c, err)
+		}
+		return c.JSON(res)
+	})
+	return app
+}
+
+func TestGetOwnerDocumentsHandler_Defaults(t *testing.T) {
+	var gotOwner string
+	var gotFilter OwnerDocumentFilter
+	mockRepo := &MockRepository{
+		GetOwnerDocumentsFunc: func(ctx context.Context, ownerID string, filter OwnerDocumentFilter) ([]OwnerDocument, int, error) {
+			gotOwner, gotFilter = ownerID, filter
+			return []OwnerDocument{
+				{Document: domain.Document{ID: "DOC_1", Type: domain.DocumentTypeInspection}, VehicleID: "VEH_1", VIN: "1HGBH41JXMN109186"},
+			}, 7, nil
+		},
+	}
+	app := newOwnerDocumentsApp(NewGetOwnerDocumentsHandler(mockRepo, false))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/owners/OWNER_1/documents?type=inspection&expiring_within_days=30", nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	if gotOwner != "OWNER_1" {
+		t.Errorf("Expected owner OWNER_1, got %s", gotOwner)
+	}
+	expected := OwnerDocumentFilter{Type: "inspection", ExpiringWithinDays: 30, Order: "asc", Limit: defaultOwnerDocumentsLimit}
+	if gotFilter != expected {
+		t.Errorf("Expected filter %+v, got %+v", expected, gotFilter)
+	}
+
+	var body GetOwnerDocumentsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.Total != 7 || len(body.Documents) != 1 {
+		t.Fatalf("Expected total 7 with 1 document, got %d with %d", body.Total, len(body.Documents))
+	}
+	if body.Documents[0].VehicleID != "VEH_1" || body.Documents[0].VIN != "1HGBH41JXMN109186" {
+		t.Errorf("Expected document annotated with its vehicle, got %+v", body.Documents[0])
+	}
+}
+
+func TestGetOwnerDocumentsHandler_InvalidQuery(t *testing.T) {
+	mockRepo := &MockRepository{
+		GetOwnerDocumentsFunc: func(ctx context.Context, ownerID string, filter OwnerDocumentFilter) ([]OwnerDocument, int, error) {
+			t.Error("Expected repository not to be called")
+			return nil, 0, nil
+		},
+	}
+	app := newOwnerDocumentsApp(NewGetOwnerDocumentsHandler(mockRepo, false))
+
+	for _, query := range []string{"type=junk", "order=sideways", "limit=500", "offset=-1", "expiring_within_days=-5", "expiring_within_days=0", "expiring_within_days=1000"} {
+		resp, err := app.Test(httptest.NewRequest("GET", "/owners/OWNER_1/documents?"+query, nil))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if resp.StatusCode != fiber.StatusBadRequest {
+			t.Errorf("Expected status 400 for %q, got %d", query, resp.StatusCode)
+		}
+	}
+}
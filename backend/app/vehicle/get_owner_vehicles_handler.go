@@ -0,0 +1,154 @@
+package vehicle
+
+import (
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/pagination"
+	"microservicetest/pkg/validator"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// metadataQueryPrefix marks a metadata filter in the query string, e.g. ?metadata.department=sales
+const metadataQueryPrefix = "metadata."
+
+// OwnerVehicleFilter pages through an owner's vehicles, keeping only those
+// whose metadata holds every given key-value pair and, when set, whose stored
+// insurance and document status match
+type OwnerVehicleFilter struct {
+	Limit           int
+	Offset          int
+	After           *pagination.Cursor // Continue after this vehicle instead of skipping Offset, see ownerVehicleCursor
+	Metadata        map[string]string
+	InsuranceStatus domain.InsuranceStatus
+	DocumentStatus  domain.DocumentStatus
+}
+
+type GetOwnerVehiclesRequest struct {
+	OwnerID         string `param:"owner_id" validate:"required"`
+	Limit           int    `query:"limit" validate:"gte=0,lte=100"`
+	Offset          int    `query:"offset" validate:"gte=0"`
+	Cursor          string `query:"cursor"` // next_cursor of the previous page
+	InsuranceStatus string `query:"insurance_status" validate:"omitempty,insurancestatus"`
+	DocumentStatus  string `query:"document_status" validate:"omitempty,documentstatus"`
+}
+
+type GetOwnerVehiclesResponse struct {
+	Vehicles   []*domain.Vehicle `json:"vehicles"`
+	Count      int               `json:"count"` // Vehicles on this page
+	Total      int               `json:"total"` // Vehicles the owner has
+	Limit      int               `json:"limit"`
+	Offset     int               `json:"offset"`
+	NextCursor string            `json:"next_cursor,omitempty"` // Set while a full page was returned
+}
+
+type GetOwnerVehiclesHandler struct {
+	repository           Repository
+	defaultLimit         int
+	unknownOwnerNotFound bool
+}
+
+// NewGetOwnerVehiclesHandler answers an owner no vehicle has ever had with 404
+// when unknownOwnerNotFound is set and with an empty list otherwise
+func NewGetOwnerVehiclesHandler(repository Repository, defaultLimit int, unknownOwnerNotFound bool) *GetOwnerVehiclesHandler {
+	return &GetOwnerVehiclesHandler{
+		repository:           repository,
+		defaultLimit:         defaultLimit,
+		unknownOwnerNotFound: unknownOwnerNotFound,
+	}
+}
+
+func (h *GetOwnerVehiclesHandler) Handle(ctx *fiber.Ctx, req *GetOwnerVehiclesRequest) (*GetOwnerVehiclesResponse, error) {
+	req.OwnerID = ctx.Params("owner_id")
+
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.ErrInvalidInput.WithDetails(map[string]string{
+			"validation": err.Error(),
+		})
+	}
+
+	filter := OwnerVehicleFilter{
+		Limit:           req.Limit,
+		Offset:          req.Offset,
+		Metadata:        metadataFilter(ctx),
+		InsuranceStatus: domain.InsuranceStatus(req.InsuranceStatus),
+		DocumentStatus:  domain.DocumentStatus(req.DocumentStatus),
+	}
+	if filter.Limit == 0 {
+		filter.Limit = h.defaultLimit
+	}
+	if req.Cursor != "" {
+		after, err := decodeCursor(req.Cursor, req.Offset, func(value any) bool {
+			_, ok := value.(string)
+			return ok
+		})
+		if err != nil {
+			return nil, err
+		}
+		filter.After = after
+	}
+	// The filter keys end up in the query, so they follow the same rules as stored metadata
+	if err := domain.ValidateMetadata(filter.Metadata); err != nil {
+		return nil, apperrors.NewValidationError("metadata", err.Error())
+	}
+
+	vehicles, total, err := h.repository.GetVehiclesByOwner(ctx.UserContext(), req.OwnerID, filter)
+	if err != nil {
+		return nil, err
+	}
+	if total == 0 && h.unknownOwnerNotFound {
+		if err := checkOwnerKnown(ctx.UserContext(), h.repository, req.OwnerID); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := &GetOwnerVehiclesResponse{
+		Vehicles: vehicles,
+		Count:    len(vehicles),
+		Total:    total,
+		Limit:    filter.Limit,
+		Offset:   filter.Offset,
+	}
+	if len(vehicles) > 0 && len(vehicles) == filter.Limit {
+		resp.NextCursor = ownerVehicleCursor(vehicles[len(vehicles)-1]).Encode()
+	}
+	return resp, nil
+}
+
+// ownerVehicleCursor is the keyset position of a vehicle in GetVehiclesByOwner,
+// which orders by created_at descending and then ID. The timestamp keeps the
+// form it is stored in, so the repository can compare it as is.
+func ownerVehicleCursor(vehicle *domain.Vehicle) pagination.Cursor {
+	return pagination.Cursor{Value: vehicle.CreatedAt.Format(time.RFC3339Nano), ID: vehicle.ID}
+}
+
+// decodeCursor parses the cursor query parameter. It replaces the offset, so
+// both together are rejected, as are cursors whose sort value validValue refuses.
+func decodeCursor(raw string, offset int, validValue func(value any) bool) (*pagination.Cursor, error) {
+	if offset != 0 {
+		return nil, apperrors.NewValidationError("cursor", "cannot be combined with offset")
+	}
+	after, err := pagination.Decode(raw)
+	if err != nil || !validValue(after.Value) {
+		return nil, apperrors.NewValidationError("cursor", "is invalid")
+	}
+	return &after, nil
+}
+
+// metadataFilter collects the metadata.<key>=value query parameters
+func metadataFilter(ctx *fiber.Ctx) map[string]string {
+	var filter map[string]string
+	for name, value := range ctx.Queries() {
+		key, ok := strings.CutPrefix(name, metadataQueryPrefix)
+		if !ok {
+			continue
+		}
+		if filter == nil {
+			filter = make(map[string]string)
+		}
+		filter[key] = value
+	}
+	return filter
+}
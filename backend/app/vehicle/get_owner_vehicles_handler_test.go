@@ -0,0 +1,287 @@
+package vehicle
+
+import (
+	"context"
+	"encoding/json"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/pagination"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func newOwnerVehiclesApp(handler *GetOwnerVehiclesHandler) *fiber.App {
+	app := fiber.New()
+	app.Get("/owners/:owner_id/vehicles", func(c *fiber.Ctx) error {
+		var req GetOwnerVehiclesRequest
+		if err := c.QueryParser(&req); err != nil {
+			return err
+		}
+		res, err := handler.Handle(c, &req)
+		if err != nil {
+			return apperrors.HandleError(c, err)
+		}
+		return c.JSON(res)
+	})
+	return app
+}
+
+func TestGetOwnerVehiclesHandler_CountAndTotal(t *testing.T) {
+	var gotOwner string
+	var gotFilter OwnerVehicleFilter
+	mockRepo := &MockRepository{
+		GetVehiclesByOwnerFunc: func(ctx context.Context, ownerID string, filter OwnerVehicleFilter) ([]*domain.Vehicle, int, error) {
+			gotOwner, gotFilter = ownerID, filter
+			return []*domain.Vehicle{{ID: "VEH_1"}, {ID: "VEH_2"}}, 12, nil
+		},
+	}
+	app := newOwnerVehiclesApp(NewGetOwnerVehiclesHandler(mockRepo, 20, false))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/owners/OWNER_1/vehicles?offset=10", nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	if gotOwner != "OWNER_1" {
+		t.Errorf("Expected owner OWNER_1, got %s", gotOwner)
+	}
+	if expected := (OwnerVehicleFilter{Limit: 20, Offset: 10}); !reflect.DeepEqual(gotFilter, expected) {
+		t.Errorf("Expected filter %+v, got %+v", expected, gotFilter)
+	}
+
+	var body GetOwnerVehiclesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.Count != 2 || body.Total != 12 {
+		t.Errorf("Expected count 2 and total 12, got count %d and total %d", body.Count, body.Total)
+	}
+	if body.Limit != 20 || body.Offset != 10 {
+		t.Errorf("Expected limit 20 and offset 10, got %d and %d", body.Limit, body.Offset)
+	}
+}
+
+func TestGetOwnerVehiclesHandler_LimitTooLarge(t *testing.T) {
+	app := newOwnerVehiclesApp(NewGetOwnerVehiclesHandler(&MockRepository{}, 20, false))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/owners/OWNER_1/vehicles?limit=101", nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestGetOwnerVehiclesHandler_MetadataFilter(t *testing.T) {
+	var gotFilter OwnerVehicleFilter
+	mockRepo := &MockRepository{
+		GetVehiclesByOwnerFunc: func(ctx context.Context, ownerID string, filter OwnerVehicleFilter) ([]*domain.Vehicle, int, error) {
+			gotFilter = filter
+			return []*domain.Vehicle{}, 0, nil
+		},
+	}
+	app := newOwnerVehiclesApp(NewGetOwnerVehiclesHandler(mockRepo, 20, false))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/owners/OWNER_1/vehicles?metadata.department=sales&metadata.cost_center=CC_42&limit=5", nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	expected := map[string]string{"department": "sales", "cost_center": "CC_42"}
+	if !reflect.DeepEqual(gotFilter.Metadata, expected) {
+		t.Errorf("Expected metadata filter %v, got %v", expected, gotFilter.Metadata)
+	}
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/owners/OWNER_1/vehicles?metadata.bad%20key=x", nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid metadata key, got %d", resp.StatusCode)
+	}
+}
+
+func TestGetOwnerVehiclesHandler_Cursor(t *testing.T) {
+	createdAt := time.Date(2026, 3, 1, 12, 0, 0, 500, time.UTC)
+	var gotFilter OwnerVehicleFilter
+	mockRepo := &MockRepository{
+		GetVehiclesByOwnerFunc: func(ctx context.Context, ownerID string, filter OwnerVehicleFilter) ([]*domain.Vehicle, int, error) {
+			gotFilter = filter
+			return []*domain.Vehicle{{ID: "VEH_1"}, {ID: "VEH_2", CreatedAt: createdAt}}, 5, nil
+		},
+	}
+	app := newOwnerVehiclesApp(NewGetOwnerVehiclesHandler(mockRepo, 20, false))
+
+	// A full page hands out the position of its last vehicle
+	resp, err := app.Test(httptest.NewRequest("GET", "/owners/OWNER_1/vehicles?limit=2", nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var body GetOwnerVehiclesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.NextCursor == "" {
+		t.Fatal("Expected a next cursor after a full page")
+	}
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/owners/OWNER_1/vehicles?limit=2&cursor="+body.NextCursor, nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	expected := &pagination.Cursor{Value: "2026-03-01T12:00:00.0000005Z", ID: "VEH_2"}
+	if !reflect.DeepEqual(gotFilter.After, expected) {
+		t.Errorf("Expected cursor %+v, got %+v", expected, gotFilter.After)
+	}
+
+	for _, query := range []string{
+		"cursor=not-a-cursor",
+		"cursor=" + (pagination.Cursor{Value: float64(1), ID: "VEH_2"}).Encode(),
+		"offset=2&cursor=" + body.NextCursor,
+	} {
+		resp, err := app.Test(httptest.NewRequest("GET", "/owners/OWNER_1/vehicles?"+query, nil))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if resp.StatusCode != fiber.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", query, resp.StatusCode)
+		}
+	}
+}
+
+func TestGetOwnerVehiclesHandler_StatusFilters(t *testing.T) {
+	var gotFilter OwnerVehicleFilter
+	mockRepo := &MockRepository{
+		GetVehiclesByOwnerFunc: func(ctx context.Context, ownerID string, filter OwnerVehicleFilter) ([]*domain.Vehicle, int, error) {
+			gotFilter = filter
+			return []*domain.Vehicle{}, 0, nil
+		},
+	}
+	app := newOwnerVehiclesApp(NewGetOwnerVehiclesHandler(mockRepo, 20, false))
+
+	for _, status := range domain.AllInsuranceStatuses() {
+		gotFilter = OwnerVehicleFilter{}
+		resp, err := app.Test(httptest.NewRequest("GET", "/owners/OWNER_1/vehicles?insurance_status="+string(status), nil))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if resp.StatusCode != fiber.StatusOK {
+			t.Errorf("Expected status 200 for insurance_status=%s, got %d", status, resp.StatusCode)
+		}
+		if gotFilter.InsuranceStatus != status || gotFilter.DocumentStatus != "" {
+			t.Errorf("Expected insurance status %s only, got %+v", status, gotFilter)
+		}
+	}
+
+	for _, status := range domain.AllDocumentStatuses() {
+		gotFilter = OwnerVehicleFilter{}
+		resp, err := app.Test(httptest.NewRequest("GET", "/owners/OWNER_1/vehicles?document_status="+string(status), nil))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if resp.StatusCode != fiber.StatusOK {
+			t.Errorf("Expected status 200 for document_status=%s, got %d", status, resp.StatusCode)
+		}
+		if gotFilter.DocumentStatus != status || gotFilter.InsuranceStatus != "" {
+			t.Errorf("Expected document status %s only, got %+v", status, gotFilter)
+		}
+	}
+
+	// Both combine with each other and with paging
+	resp, err := app.Test(httptest.NewRequest("GET", "/owners/OWNER_1/vehicles?insurance_status=expiring_soon&document_status=has_expired&limit=5&offset=5", nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	expected := OwnerVehicleFilter{Limit: 5, Offset: 5, InsuranceStatus: "expiring_soon", DocumentStatus: "has_expired"}
+	if !reflect.DeepEqual(gotFilter, expected) {
+		t.Errorf("Expected filter %+v, got %+v", expected, gotFilter)
+	}
+
+	for _, query := range []string{"insurance_status=lapsed", "document_status=expired"} {
+		resp, err := app.Test(httptest.NewRequest("GET", "/owners/OWNER_1/vehicles?"+query, nil))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if resp.StatusCode != fiber.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", query, resp.StatusCode)
+		}
+	}
+}
+
+func TestGetOwnerVehiclesHandler_UnknownOwner(t *testing.T) {
+	tests := []struct {
+		name                 string
+		unknownOwnerNotFound bool
+		ownerExists          bool
+		expected             int
+	}{
+		{"unknown owner with the feature off", false, false, fiber.StatusOK},
+		{"unknown owner", true, false, fiber.StatusNotFound},
+		{"known owner without vehicles", true, true, fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checked := false
+			mockRepo := &MockRepository{
+				GetVehiclesByOwnerFunc: func(ctx context.Context, ownerID string, filter OwnerVehicleFilter) ([]*domain.Vehicle, int, error) {
+					return []*domain.Vehicle{}, 0, nil
+				},
+				OwnerExistsFunc: func(ctx context.Context, ownerID string) (bool, error) {
+					checked = true
+					return tt.ownerExists, nil
+				},
+			}
+			app := newOwnerVehiclesApp(NewGetOwnerVehiclesHandler(mockRepo, 20, tt.unknownOwnerNotFound))
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/owners/OWNER_X/vehicles", nil))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if resp.StatusCode != tt.expected {
+				t.Errorf("Expected status %d, got %d", tt.expected, resp.StatusCode)
+			}
+			if checked != tt.unknownOwnerNotFound {
+				t.Errorf("Expected the owner check to run only with the feature on, ran: %v", checked)
+			}
+		})
+	}
+}
+
+func TestGetOwnerVehiclesHandler_SkipsOwnerCheckWhenFound(t *testing.T) {
+	mockRepo := &MockRepository{
+		GetVehiclesByOwnerFunc: func(ctx context.Context, ownerID string, filter OwnerVehicleFilter) ([]*domain.Vehicle, int, error) {
+			return []*domain.Vehicle{{ID: "VEH_1"}}, 1, nil
+		},
+		OwnerExistsFunc: func(ctx context.Context, ownerID string) (bool, error) {
+			t.Error("Expected no owner check when vehicles were found")
+			return false, nil
+		},
+	}
+	app := newOwnerVehiclesApp(NewGetOwnerVehiclesHandler(mockRepo, 20, true))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/owners/OWNER_1/vehicles", nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+}
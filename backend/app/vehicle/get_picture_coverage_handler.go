@@ -0,0 +1,51 @@
+package vehicle
+
+import (
+	"context"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/validator"
+)
+
+type GetPictureCoverageRequest struct {
+	ID string `json:"id" param:"id" validate:"required"`
+}
+
+type GetPictureCoverageResponse struct {
+	Required     []domain.PictureType `json:"required"`
+	Present      []domain.PictureType `json:"present"`
+	Missing      []domain.PictureType `json:"missing"`
+	Completeness float64              `json:"completeness"` // Share of required types with a picture, 0 to 1
+}
+
+type GetPictureCoverageHandler struct {
+	repository Repository
+}
+
+func NewGetPictureCoverageHandler(repository Repository) *GetPictureCoverageHandler {
+	return &GetPictureCoverageHandler{
+		repository: repository,
+	}
+}
+
+func (h *GetPictureCoverageHandler) Handle(ctx context.Context, req *GetPictureCoverageRequest) (*GetPictureCoverageResponse, error) {
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.ErrInvalidInput.WithDetails(map[string]string{
+			"validation": err.Error(),
+		})
+	}
+
+	vehicle, err := h.repository.GetVehicle(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	completeness, present, missing := vehicle.PictureCoverage()
+
+	return &GetPictureCoverageResponse{
+		Required:     domain.RequiredPictureTypes,
+		Present:      present,
+		Missing:      missing,
+		Completeness: completeness,
+	}, nil
+}
@@ -0,0 +1,45 @@
+package vehicle
+
+import (
+	"context"
+	"microservicetest/domain"
+	"slices"
+	"testing"
+)
+
+func TestGetPictureCoverageHandler(t *testing.T) {
+	defer func(required []domain.PictureType) { domain.RequiredPictureTypes = required }(domain.RequiredPictureTypes)
+	if err := domain.SetRequiredPictureTypes("exterior_front", "interior_front", "engine", "trunk"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	mockRepo := &MockRepository{
+		GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+			return &domain.Vehicle{
+				ID: id,
+				Pictures: []domain.Picture{
+					{ID: "PIC_1", Type: domain.PictureTypeExteriorFront, IsMain: true},
+					{ID: "PIC_2", Type: domain.PictureTypeEngine},
+					{ID: "PIC_3", Type: domain.PictureTypeDamage},
+				},
+			}, nil
+		},
+	}
+	handler := NewGetPictureCoverageHandler(mockRepo)
+
+	resp, err := handler.Handle(context.Background(), &GetPictureCoverageRequest{ID: "VEH_1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if resp.Completeness != 0.5 {
+		t.Errorf("Expected completeness 0.5, got %v", resp.Completeness)
+	}
+	expectedMissing := []domain.PictureType{domain.PictureTypeInteriorFront, domain.PictureTypeTrunk}
+	if !slices.Equal(resp.Missing, expectedMissing) {
+		t.Errorf("Expected missing %v, got %v", expectedMissing, resp.Missing)
+	}
+	if len(resp.Required) != 4 || len(resp.Present) != 2 {
+		t.Errorf("Expected 4 required and 2 present, got %v and %v", resp.Required, resp.Present)
+	}
+}
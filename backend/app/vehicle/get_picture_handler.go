@@ -0,0 +1,90 @@
+package vehicle
+
+import (
+	"context"
+	"microservicetest/app"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/validator"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type GetPictureRequest struct {
+	VehicleID string `param:"id" validate:"required"`
+	PictureID string `param:"pic_id" validate:"required"`
+}
+
+// GetPictureResponse is the stored picture plus read-only URLs that work while
+// the container stays private
+type GetPictureResponse struct {
+	domain.Picture
+	SignedURL          string    `json:"signed_url"`
+	SignedThumbnailURL string    `json:"signed_thumbnail_url,omitempty"` // Empty when the picture has no thumbnail
+	ExpiresAt          time.Time `json:"expires_at"`
+}
+
+type GetPictureHandler struct {
+	repository Repository
+	storage    app.Storage
+}
+
+func NewGetPictureHandler(repository Repository, storage app.Storage) *GetPictureHandler {
+	return &GetPictureHandler{
+		repository: repository,
+		storage:    storage,
+	}
+}
+
+func (h *GetPictureHandler) Handle(ctx *fiber.Ctx, req *GetPictureRequest) (*GetPictureResponse, error) {
+	if h.storage == nil {
+		return nil, errStorageUnavailable
+	}
+
+	req.VehicleID = ctx.Params("id")
+	req.PictureID = ctx.Params("pic_id")
+
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.ErrInvalidInput.WithDetails(map[string]string{
+			"validation": err.Error(),
+		})
+	}
+
+	vehicle, err := h.repository.GetVehicle(ctx.UserContext(), req.VehicleID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pic := range vehicle.Pictures {
+		if pic.ID != req.PictureID {
+			continue
+		}
+
+		res := &GetPictureResponse{Picture: pic}
+		res.SignedURL, res.ExpiresAt, err = h.signURL(ctx.UserContext(), pic.URL)
+		if err != nil {
+			return nil, err
+		}
+		if pic.ThumbnailURL != "" {
+			res.SignedThumbnailURL, _, err = h.signURL(ctx.UserContext(), pic.ThumbnailURL)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return res, nil
+	}
+
+	return nil, apperrors.NewNotFoundError("picture", req.PictureID)
+}
+
+// signURL swaps a stored container URL for a read-only signed one
+func (h *GetPictureHandler) signURL(ctx context.Context, fileURL string) (string, time.Time, error) {
+	blobName, err := blobNameFromURL(fileURL)
+	if err != nil {
+		return "", time.Time{}, apperrors.ErrInternalServer.WithCause(err).WithDetails(map[string]string{
+			"operation": "sign_picture_url",
+		})
+	}
+	return h.storage.PresignRead(ctx, blobName)
+}
@@ -0,0 +1,79 @@
+package vehicle
+
+import (
+	"context"
+	"encoding/json"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func newGetPictureApp(handler *GetPictureHandler) *fiber.App {
+	app := fiber.New()
+	app.Get("/vehicles/:id/pictures/:pic_id", func(c *fiber.Ctx) error {
+		res, err := handler.Handle(c, &GetPictureRequest{})
+		if err != nil {
+			return apperrors.HandleError(c, err)
+		}
+		return c.JSON(res)
+	})
+	return app
+}
+
+func TestGetPictureHandler_Found(t *testing.T) {
+	mockRepo := &MockRepository{
+		GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+			return &domain.Vehicle{ID: id, Pictures: []domain.Picture{
+				{ID: "PIC_1", Type: domain.PictureTypeExteriorFront, URL: "https://account.blob.core.windows.net/documents/front.jpg",
+					ThumbnailURL: "https://account.blob.core.windows.net/documents/front-thumb.jpg"},
+				{ID: "PIC_2", Type: domain.PictureTypeExteriorBack, URL: "https://account.blob.core.windows.net/documents/rear.jpg"},
+			}}, nil
+		},
+	}
+	app := newGetPictureApp(NewGetPictureHandler(mockRepo, &MockStorage{}))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/vehicles/VEH_1/pictures/PIC_1", nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var body GetPictureResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.ID != "PIC_1" || body.Type != domain.PictureTypeExteriorFront {
+		t.Errorf("Expected PIC_1 front, got %s %s", body.ID, body.Type)
+	}
+	if body.SignedURL != "https://account.blob.core.windows.net/documents/front.jpg?sig=read" {
+		t.Errorf("Expected a signed picture URL, got %s", body.SignedURL)
+	}
+	if body.SignedThumbnailURL != "https://account.blob.core.windows.net/documents/front-thumb.jpg?sig=read" {
+		t.Errorf("Expected a signed thumbnail URL, got %s", body.SignedThumbnailURL)
+	}
+	if body.ExpiresAt.IsZero() {
+		t.Error("Expected the signed URL expiry")
+	}
+}
+
+func TestGetPictureHandler_NotFound(t *testing.T) {
+	mockRepo := &MockRepository{
+		GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+			return &domain.Vehicle{ID: id}, nil
+		},
+	}
+	app := newGetPictureApp(NewGetPictureHandler(mockRepo, &MockStorage{}))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/vehicles/VEH_1/pictures/PIC_9", nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != fiber.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,90 @@
+package vehicle
+
+import (
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/validator"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Orders accepted by GET /owners/:owner_id/vehicles/recent
+const (
+	RecentByUpdated = "updated"
+	RecentByCreated = "created"
+)
+
+const defaultRecentVehiclesLimit = 10
+
+// VehicleSummary is the compact form of a vehicle used in activity feeds
+type VehicleSummary struct {
+	ID           string               `json:"id"`
+	VIN          string               `json:"vin"`
+	Make         string               `json:"make"`
+	Model        string               `json:"model"`
+	Year         int                  `json:"year"`
+	LicensePlate string               `json:"license_plate"`
+	Status       domain.VehicleStatus `json:"status"`
+	CreatedAt    time.Time            `json:"created_at"`
+	UpdatedAt    time.Time            `json:"updated_at"`
+	UpdatedBy    string               `json:"updated_by"`
+}
+
+type GetRecentVehiclesRequest struct {
+	OwnerID string `param:"owner_id" validate:"required"`
+	By      string `query:"by" validate:"omitempty,oneof=updated created"`
+	Limit   int    `query:"limit" validate:"gte=0,lte=50"`
+}
+
+type GetRecentVehiclesResponse struct {
+	Vehicles []VehicleSummary `json:"vehicles"`
+	By       string           `json:"by"`
+}
+
+type GetRecentVehiclesHandler struct {
+	repository           Repository
+	unknownOwnerNotFound bool
+}
+
+// NewGetRecentVehiclesHandler answers an owner no vehicle has ever had with 404
+// when unknownOwnerNotFound is set and with an empty list otherwise
+func NewGetRecentVehiclesHandler(repository Repository, unknownOwnerNotFound bool) *GetRecentVehiclesHandler {
+	return &GetRecentVehiclesHandler{
+		repository:           repository,
+		unknownOwnerNotFound: unknownOwnerNotFound,
+	}
+}
+
+// Handle lists the owner's most recently updated or created vehicles, newest first
+func (h *GetRecentVehiclesHandler) Handle(ctx *fiber.Ctx, req *GetRecentVehiclesRequest) (*GetRecentVehiclesResponse, error) {
+	req.OwnerID = ctx.Params("owner_id")
+
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.ErrInvalidInput.WithDetails(map[string]string{
+			"validation": err.Error(),
+		})
+	}
+
+	if req.By == "" {
+		req.By = RecentByUpdated
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultRecentVehiclesLimit
+	}
+
+	vehicles, err := h.repository.GetRecentVehiclesByOwner(ctx.UserContext(), req.OwnerID, req.By, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(vehicles) == 0 && h.unknownOwnerNotFound {
+		if err := checkOwnerKnown(ctx.UserContext(), h.repository, req.OwnerID); err != nil {
+			return nil, err
+		}
+	}
+
+	return &GetRecentVehiclesResponse{
+		Vehicles: vehicles,
+		By:       req.By,
+	}, nil
+}
@@ -0,0 +1,68 @@
+package vehicle
+
+import (
+	"context"
+	apperrors "microservicetest/pkg/errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestGetRecentVehiclesHandler(t *testing.T) {
+	var gotOwner, gotBy string
+	var gotLimit int
+	mockRepo := &MockRepository{
+		GetRecentVehiclesByOwnerFunc: func(ctx context.Context, ownerID string, by string, limit int) ([]VehicleSummary, error) {
+			gotOwner, gotBy, gotLimit = ownerID, by, limit
+			return []VehicleSummary{{ID: "VEH_1"}}, nil
+		},
+	}
+	handler := NewGetRecentVehiclesHandler(mockRepo, false)
+
+	app := fiber.New()
+	app.Get("/owners/:owner_id/vehicles/recent", func(c *fiber.Ctx) error {
+		var req GetRecentVehiclesRequest
+		if err := c.QueryParser(&req); err != nil {
+			return err
+		}
+		res, err := handler.Handle(c, &req)
+		if err != nil {
+			return apperrors.HandleError(c, err)
+		}
+		return c.JSON(res)
+	})
+
+	tests := []struct {
+		query     string
+		status    int
+		wantBy    string
+		wantLimit int
+	}{
+		{"", fiber.StatusOK, RecentByUpdated, 10},
+		{"?by=created&limit=5", fiber.StatusOK, RecentByCreated, 5},
+		{"?by=mileage", fiber.StatusBadRequest, "", 0},
+		{"?limit=51", fiber.StatusBadRequest, "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			gotOwner, gotBy, gotLimit = "", "", 0
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/owners/OWNER_1/vehicles/recent"+tt.query, nil))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if resp.StatusCode != tt.status {
+				t.Fatalf("Expected status %d, got %d", tt.status, resp.StatusCode)
+			}
+			if tt.status != fiber.StatusOK {
+				return
+			}
+
+			if gotOwner != "OWNER_1" || gotBy != tt.wantBy || gotLimit != tt.wantLimit {
+				t.Errorf("Expected OWNER_1 by %s limit %d, got %s by %s limit %d", tt.wantBy, tt.wantLimit, gotOwner, gotBy, gotLimit)
+			}
+		})
+	}
+}
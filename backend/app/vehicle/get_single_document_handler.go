@@ -0,0 +1,42 @@
+package vehicle
+
+import (
+	apperrors "microservicetest/pkg/errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type GetSingleDocumentRequest struct {
+	VehicleID  string `param:"id" validate:"required"`
+	DocumentID string `param:"doc_id" validate:"required"`
+}
+
+type GetSingleDocumentHandler struct {
+	repository Repository
+}
+
+func NewGetSingleDocumentHandler(repository Repository) *GetSingleDocumentHandler {
+	return &GetSingleDocumentHandler{
+		repository: repository,
+	}
+}
+
+func (h *GetSingleDocumentHandler) Handle(ctx *fiber.Ctx, req *GetSingleDocumentRequest) (*DocumentResponse, error) {
+	req.VehicleID = ctx.Params("id")
+	req.DocumentID = ctx.Params("doc_id")
+
+	vehicle, err := h.repository.GetVehicle(ctx.UserContext(), req.VehicleID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, doc := range vehicle.Documents {
+		if doc.ID == req.DocumentID {
+			response := newDocumentResponse(doc, time.Now())
+			return &response, nil
+		}
+	}
+
+	return nil, apperrors.NewNotFoundError("document", req.DocumentID)
+}
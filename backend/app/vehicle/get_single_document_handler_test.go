@@ -0,0 +1,74 @@
+package vehicle
+
+import (
+	"context"
+	"encoding/json"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func newSingleDocumentApp(handler *GetSingleDocumentHandler) *fiber.App {
+	app := fiber.New()
+	app.Get("/vehicles/:id/documents/:doc_id", func(c *fiber.Ctx) error {
+		res, err := handler.Handle(c, &GetSingleDocumentRequest{})
+		if err != nil {
+			return apperrors.HandleError(c, err)
+		}
+		return c.JSON(res)
+	})
+	return app
+}
+
+func TestGetSingleDocumentHandler_Found(t *testing.T) {
+	expired := time.Now().AddDate(0, 0, -1)
+	mockRepo := &MockRepository{
+		GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+			return &domain.Vehicle{ID: id, Documents: []domain.Document{
+				{ID: "DOC_1", Type: domain.DocumentTypeRegistration},
+				{ID: "DOC_2", Type: domain.DocumentTypeInsurancePolicy, ExpiryDate: &expired, IsVerified: true},
+			}}, nil
+		},
+	}
+	app := newSingleDocumentApp(NewGetSingleDocumentHandler(mockRepo))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/vehicles/VEH_1/documents/DOC_2", nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var body DocumentResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.ID != "DOC_2" || body.Type != "insurance_policy" {
+		t.Errorf("Expected DOC_2 insurance_policy, got %s %s", body.ID, body.Type)
+	}
+	if !body.IsVerified || !body.IsExpired {
+		t.Errorf("Expected verified and expired, got verified=%v expired=%v", body.IsVerified, body.IsExpired)
+	}
+}
+
+func TestGetSingleDocumentHandler_NotFound(t *testing.T) {
+	mockRepo := &MockRepository{
+		GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+			return &domain.Vehicle{ID: id}, nil
+		},
+	}
+	app := newSingleDocumentApp(NewGetSingleDocumentHandler(mockRepo))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/vehicles/VEH_1/documents/DOC_404", nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != fiber.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", resp.StatusCode)
+	}
+}
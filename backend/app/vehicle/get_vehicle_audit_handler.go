@@ -0,0 +1,102 @@
+package vehicle
+
+import (
+	"context"
+	"microservicetest/app"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/validator"
+	"time"
+)
+
+const defaultAuditLimit = 50
+
+func init() {
+	validator.RegisterEnum("auditaction", app.AllAuditActions)
+}
+
+type GetVehicleAuditRequest struct {
+	ID     string `param:"id" validate:"required"`
+	Action string `query:"action" validate:"omitempty,auditaction"`
+	Actor  string `query:"actor"`
+	From   string `query:"from"` // RFC 3339, inclusive
+	To     string `query:"to"`   // RFC 3339, exclusive
+	Limit  int    `query:"limit" validate:"gte=0,lte=100"`
+	Offset int    `query:"offset" validate:"gte=0"`
+}
+
+type GetVehicleAuditResponse struct {
+	Entries []app.AuditEntry `json:"entries"`
+	Total   int              `json:"total"`
+	Limit   int              `json:"limit"`
+	Offset  int              `json:"offset"`
+}
+
+type GetVehicleAuditHandler struct {
+	auditLog app.AuditLog
+}
+
+func NewGetVehicleAuditHandler(auditLog app.AuditLog) *GetVehicleAuditHandler {
+	return &GetVehicleAuditHandler{
+		auditLog: auditLog,
+	}
+}
+
+// Handle lists a vehicle's change history, newest first. Only backend services
+// calling with an API key may read it.
+func (h *GetVehicleAuditHandler) Handle(ctx context.Context, req *GetVehicleAuditRequest) (*GetVehicleAuditResponse, error) {
+	if _, ok := app.ServiceFromContext(ctx); !ok {
+		return nil, apperrors.ErrUnauthorized.WithDetails(map[string]string{
+			"header": "X-API-Key",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.ErrInvalidInput.WithDetails(map[string]string{
+			"validation": err.Error(),
+		})
+	}
+
+	filter := app.AuditFilter{
+		Action: req.Action,
+		Actor:  req.Actor,
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultAuditLimit
+	}
+
+	var err error
+	if filter.From, err = parseAuditTime("from", req.From); err != nil {
+		return nil, err
+	}
+	if filter.To, err = parseAuditTime("to", req.To); err != nil {
+		return nil, err
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return nil, apperrors.NewValidationError("to", "must be after from")
+	}
+
+	entries, total, err := h.auditLog.List(ctx, req.ID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetVehicleAuditResponse{
+		Entries: entries,
+		Total:   total,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+	}, nil
+}
+
+func parseAuditTime(field string, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, apperrors.NewValidationError(field, "must be an RFC 3339 timestamp")
+	}
+	return t, nil
+}
@@ -0,0 +1,303 @@
+package vehicle
+
+import (
+	"context"
+	"errors"
+	"microservicetest/app"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"testing"
+	"time"
+)
+
+// MockAuditLog keeps recorded entries in memory
+type MockAuditLog struct {
+	Entries   []app.AuditEntry
+	RecordErr error
+	ListFunc  func(ctx context.Context, vehicleID string, filter app.AuditFilter) ([]app.AuditEntry, int, error)
+}
+
+func (m *MockAuditLog) Record(ctx context.Context, entry app.AuditEntry) error {
+	if m.RecordErr != nil {
+		return m.RecordErr
+	}
+	m.Entries = append(m.Entries, entry)
+	return nil
+}
+
+func (m *MockAuditLog) List(ctx context.Context, vehicleID string, filter app.AuditFilter) ([]app.AuditEntry, int, error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx, vehicleID, filter)
+	}
+	return m.Entries, len(m.Entries), nil
+}
+
+func TestGetVehicleAuditHandler_Filters(t *testing.T) {
+	var gotVehicleID string
+	var gotFilter app.AuditFilter
+	auditLog := &MockAuditLog{
+		ListFunc: func(ctx context.Context, vehicleID string, filter app.AuditFilter) ([]app.AuditEntry, int, error) {
+			gotVehicleID, gotFilter = vehicleID, filter
+			return []app.AuditEntry{{ID: "a1"}}, 1, nil
+		},
+	}
+	handler := NewGetVehicleAuditHandler(auditLog)
+	ctx := app.WithService(context.Background(), "compliance")
+
+	resp, err := handler.Handle(ctx, &GetVehicleAuditRequest{
+		ID:     "VEH_1",
+		Action: app.AuditActionUpdate,
+		Actor:  "alice",
+		From:   "2026-01-01T00:00:00Z",
+		To:     "2026-02-01T00:00:00Z",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := app.AuditFilter{
+		Action: app.AuditActionUpdate,
+		Actor:  "alice",
+		From:   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:     time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		Limit:  defaultAuditLimit,
+	}
+	if gotVehicleID != "VEH_1" || gotFilter != expected {
+		t.Errorf("Expected VEH_1 with %+v, got %s with %+v", expected, gotVehicleID, gotFilter)
+	}
+	if resp.Total != 1 || len(resp.Entries) != 1 {
+		t.Errorf("Expected one entry, got %+v", resp)
+	}
+}
+
+func TestGetVehicleAuditHandler_Rejects(t *testing.T) {
+	handler := NewGetVehicleAuditHandler(&MockAuditLog{})
+	service := app.WithService(context.Background(), "compliance")
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		req  GetVehicleAuditRequest
+		want error
+	}{
+		{"without a service", context.Background(), GetVehicleAuditRequest{ID: "VEH_1"}, apperrors.ErrUnauthorized},
+		{"unknown action", service, GetVehicleAuditRequest{ID: "VEH_1", Action: "rename"}, apperrors.ErrInvalidInput},
+		{"bad time", service, GetVehicleAuditRequest{ID: "VEH_1", From: "yesterday"}, apperrors.ErrInvalidInput},
+		{"empty range", service, GetVehicleAuditRequest{ID: "VEH_1", From: "2026-02-01T00:00:00Z", To: "2026-01-01T00:00:00Z"}, apperrors.ErrInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := handler.Handle(tt.ctx, &tt.req); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestGetVehicleAuditHandler_AcceptsEveryAction(t *testing.T) {
+	handler := NewGetVehicleAuditHandler(&MockAuditLog{})
+	service := app.WithService(context.Background(), "compliance")
+
+	for _, action := range app.AllAuditActions() {
+		if _, err := handler.Handle(service, &GetVehicleAuditRequest{ID: "VEH_1", Action: action}); err != nil {
+			t.Errorf("Expected action %q to be accepted, got %v", action, err)
+		}
+	}
+}
+
+func TestUpdateVehicleHandler_RecordsAudit(t *testing.T) {
+	mockRepo := &MockRepository{
+		GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+			return &domain.Vehicle{ID: id, OwnerID: "owner-123", Color: "red", Mileage: 100}, nil
+		},
+		UpdateVehicleFunc: func(ctx context.Context, vehicle *domain.Vehicle) error {
+			return nil
+		},
+	}
+	auditLog := &MockAuditLog{}
+	handler := NewUpdateVehicleHandler(mockRepo, DuplicatePlateReject, auditLog, nil)
+	color, mileage := "blue", 100
+
+	if _, err := handler.Handle(context.Background(), &UpdateVehicleRequest{ID: "VEH_1", Color: &color, Mileage: &mileage, UpdatedBy: "alice"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(auditLog.Entries) != 1 {
+		t.Fatalf("Expected 1 audit entry, got %d", len(auditLog.Entries))
+	}
+	entry := auditLog.Entries[0]
+	if entry.Action != app.AuditActionUpdate || entry.Actor != "alice" || entry.VehicleID != "VEH_1" {
+		t.Errorf("Unexpected entry %+v", entry)
+	}
+	if len(entry.Changes) != 1 || entry.Changes[0].Field != "color" {
+		t.Errorf("Expected only the color change, got %+v", entry.Changes)
+	}
+}
+
+func TestUpdateVehicleHandler_AuditStoreUnavailable(t *testing.T) {
+	var stored *domain.Vehicle
+	newRepo := func() *MockRepository {
+		stored = nil
+		return &MockRepository{
+			GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+				return &domain.Vehicle{ID: id, OwnerID: "owner-123", Color: "red"}, nil
+			},
+			UpdateVehicleFunc: func(ctx context.Context, vehicle *domain.Vehicle) error {
+				stored = vehicle
+				return nil
+			},
+		}
+	}
+	store := &MockAuditLog{RecordErr: apperrors.NewDatabaseError("record_audit_entry", errors.New("connection refused"))}
+	color := "blue"
+	req := func() *UpdateVehicleRequest {
+		return &UpdateVehicleRequest{ID: "VEH_1", Color: &color, UpdatedBy: "alice"}
+	}
+
+	// Fail open: the update succeeds and the failure is only logged
+	asyncLog := app.NewAsyncAuditLog(store, 10)
+	resp, err := NewUpdateVehicleHandler(newRepo(), DuplicatePlateReject, asyncLog, nil).Handle(context.Background(), req())
+	asyncLog.Close(time.Second)
+	if err != nil {
+		t.Fatalf("Expected the update to succeed, got %v", err)
+	}
+	if resp.Vehicle.Color != "blue" || len(resp.Warnings) != 0 {
+		t.Errorf("Expected the update to be applied without warnings, got %+v", resp)
+	}
+
+	// Written after the change: the update is stored, so it succeeds with a warning
+	resp, err = NewUpdateVehicleHandler(newRepo(), DuplicatePlateReject, store, nil).Handle(context.Background(), req())
+	if err != nil {
+		t.Fatalf("Expected the stored update to succeed, got %v", err)
+	}
+	if stored == nil || stored.Color != "blue" {
+		t.Errorf("Expected the update to be stored, got %+v", stored)
+	}
+	if len(resp.Warnings) != 1 || resp.Warnings[0] != auditFailedWarning {
+		t.Errorf("Expected the audit failure as a warning, got %v", resp.Warnings)
+	}
+
+	// Fail closed: the update is refused and nothing is stored
+	failClosed := app.FailClosedAuditLog{AuditLog: store}
+	_, err = NewUpdateVehicleHandler(newRepo(), DuplicatePlateReject, failClosed, nil).Handle(context.Background(), req())
+	if !errors.Is(err, apperrors.ErrServiceUnavailable) {
+		t.Fatalf("Expected 503 when the audit store is down, got %v", err)
+	}
+	if stored != nil {
+		t.Errorf("Expected the update not to be stored, got %+v", stored)
+	}
+}
+
+func TestUpdateVehicleHandler_FailClosedAuditEntries(t *testing.T) {
+	newRepo := func(updateErr error) *MockRepository {
+		return &MockRepository{
+			GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+				return &domain.Vehicle{ID: id, OwnerID: "owner-123", Color: "red"}, nil
+			},
+			UpdateVehicleFunc: func(ctx context.Context, vehicle *domain.Vehicle) error {
+				return updateErr
+			},
+		}
+	}
+	color := "blue"
+	req := &UpdateVehicleRequest{ID: "VEH_1", Color: &color, UpdatedBy: "alice"}
+
+	tests := []struct {
+		name      string
+		updateErr error
+		statuses  []string
+	}{
+		{"stored", nil, []string{app.AuditStatusPending, ""}},
+		{"not stored", apperrors.ErrDatabaseQuery, []string{app.AuditStatusPending, app.AuditStatusFailed}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &MockAuditLog{}
+			handler := NewUpdateVehicleHandler(newRepo(tt.updateErr), DuplicatePlateReject, app.FailClosedAuditLog{AuditLog: store}, nil)
+			if _, err := handler.Handle(context.Background(), req); !errors.Is(err, tt.updateErr) {
+				t.Fatalf("Expected error %v, got %v", tt.updateErr, err)
+			}
+
+			if len(store.Entries) != len(tt.statuses) {
+				t.Fatalf("Expected %d writes, got %+v", len(tt.statuses), store.Entries)
+			}
+			for i, entry := range store.Entries {
+				if entry.ID != store.Entries[0].ID || entry.Status != tt.statuses[i] {
+					t.Errorf("Expected write %d of one entry with status %q, got %+v", i, tt.statuses[i], entry)
+				}
+			}
+			if tt.updateErr == nil && (len(store.Entries[1].Changes) != 1 || store.Entries[1].Changes[0].Field != "color") {
+				t.Errorf("Expected the completed entry to list the color change, got %+v", store.Entries[1].Changes)
+			}
+		})
+	}
+}
+
+func TestCreateVehicleHandler_AuditStoreUnavailable(t *testing.T) {
+	var stored *domain.Vehicle
+	mockRepo := &MockRepository{
+		GetVehicleByVINFunc: func(ctx context.Context, vin string) (*domain.Vehicle, error) {
+			return nil, apperrors.ErrResourceNotFound
+		},
+		CreateVehicleFunc: func(ctx context.Context, vehicle *domain.Vehicle) error {
+			stored = vehicle
+			return nil
+		},
+	}
+	store := &MockAuditLog{RecordErr: apperrors.NewDatabaseError("record_audit_entry", errors.New("connection refused"))}
+
+	resp, err := NewCreateVehicleHandler(mockRepo, DuplicatePlateReject, store).Handle(context.Background(), &CreateVehicleRequest{
+		VIN:        "1HGBH41JXMN109186",
+		Make:       "Honda",
+		Model:      "Civic",
+		Year:       2021,
+		OwnerID:    "owner-123",
+		OwnerName:  "Ayşe Yılmaz",
+		OwnerEmail: "ayse@example.com",
+		FuelType:   "gasoline",
+		CreatedBy:  "alice",
+	})
+	if err != nil {
+		t.Fatalf("Expected the stored vehicle to be reported as created, got %v", err)
+	}
+	if stored == nil || resp.ID != stored.ID {
+		t.Errorf("Expected the created vehicle %+v to be stored, got %+v", resp, stored)
+	}
+	if len(resp.Warnings) != 1 || resp.Warnings[0] != auditFailedWarning {
+		t.Errorf("Expected the audit failure as a warning, got %v", resp.Warnings)
+	}
+}
+
+func TestCreateVehicleHandler_FailClosedAuditStoreUnavailable(t *testing.T) {
+	created := false
+	mockRepo := &MockRepository{
+		GetVehicleByVINFunc: func(ctx context.Context, vin string) (*domain.Vehicle, error) {
+			return nil, apperrors.ErrResourceNotFound
+		},
+		CreateVehicleFunc: func(ctx context.Context, vehicle *domain.Vehicle) error {
+			created = true
+			return nil
+		},
+	}
+	store := &MockAuditLog{RecordErr: apperrors.NewDatabaseError("record_audit_entry", errors.New("connection refused"))}
+
+	_, err := NewCreateVehicleHandler(mockRepo, DuplicatePlateReject, app.FailClosedAuditLog{AuditLog: store}).Handle(context.Background(), &CreateVehicleRequest{
+		VIN:        "1HGBH41JXMN109186",
+		Make:       "Honda",
+		Model:      "Civic",
+		Year:       2021,
+		OwnerID:    "owner-123",
+		OwnerName:  "Ayşe Yılmaz",
+		OwnerEmail: "ayse@example.com",
+		FuelType:   "gasoline",
+		CreatedBy:  "alice",
+	})
+	if !errors.Is(err, apperrors.ErrServiceUnavailable) {
+		t.Fatalf("Expected 503 when the audit store is down, got %v", err)
+	}
+	if created {
+		t.Error("Expected the vehicle not to be created")
+	}
+}
@@ -2,17 +2,18 @@ package vehicle
 
 import (
 	"context"
-	"microservicetest/domain"
 	apperrors "microservicetest/pkg/errors"
 	"microservicetest/pkg/validator"
 )
 
 type GetVehicleRequest struct {
-	ID string `json:"id" param:"id" validate:"required"`
+	ID     string `json:"id" param:"id" validate:"required"`
+	Fields string `query:"fields"` // Comma separated top-level fields, e.g. vin,make,model,insurance
 }
 
 type GetVehicleResponse struct {
-	Vehicle *domain.Vehicle `json:"vehicle"`
+	// *domain.Vehicle, or a map holding only the fields asked for with ?fields=
+	Vehicle any `json:"vehicle"`
 }
 
 type GetVehicleHandler struct {
@@ -32,10 +33,24 @@ func (h *GetVehicleHandler) Handle(ctx context.Context, req *GetVehicleRequest)
 		})
 	}
 
+	fields, err := parseFields(req.Fields)
+	if err != nil {
+		return nil, err
+	}
+
 	vehicle, err := h.repository.GetVehicle(ctx, req.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	return &GetVehicleResponse{Vehicle: vehicle}, nil
+	if len(fields) == 0 {
+		return &GetVehicleResponse{Vehicle: vehicle}, nil
+	}
+
+	selected, err := selectFields(vehicle, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetVehicleResponse{Vehicle: selected}, nil
 }
\ No newline at end of file
@@ -0,0 +1,80 @@
+package vehicle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"testing"
+)
+
+func newGetVehicleMock() *MockRepository {
+	return &MockRepository{
+		GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+			return &domain.Vehicle{
+				ID:        id,
+				VIN:       "1HGBH41JXMN109186",
+				Make:      "Toyota",
+				Model:     "Camry",
+				Insurance: domain.InsuranceInfo{Provider: "Acme"},
+			}, nil
+		},
+	}
+}
+
+func TestGetVehicleHandler_FullObjectByDefault(t *testing.T) {
+	handler := NewGetVehicleHandler(newGetVehicleMock())
+
+	resp, err := handler.Handle(context.Background(), &GetVehicleRequest{ID: "VEH_1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, ok := resp.Vehicle.(*domain.Vehicle); !ok {
+		t.Errorf("Expected the full vehicle, got %T", resp.Vehicle)
+	}
+}
+
+func TestGetVehicleHandler_Fields(t *testing.T) {
+	handler := NewGetVehicleHandler(newGetVehicleMock())
+
+	resp, err := handler.Handle(context.Background(), &GetVehicleRequest{ID: "VEH_1", Fields: "vin, make,insurance"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	data, _ := json.Marshal(resp)
+	var body struct {
+		Vehicle map[string]json.RawMessage `json:"vehicle"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if len(body.Vehicle) != 3 {
+		t.Errorf("Expected 3 fields, got %v", body.Vehicle)
+	}
+	if string(body.Vehicle["vin"]) != `"1HGBH41JXMN109186"` {
+		t.Errorf("Expected vin, got %s", body.Vehicle["vin"])
+	}
+	if _, ok := body.Vehicle["model"]; ok {
+		t.Error("Expected model to be left out")
+	}
+}
+
+func TestGetVehicleHandler_UnknownField(t *testing.T) {
+	mockRepo := &MockRepository{
+		GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+			t.Error("Expected repository not to be called")
+			return nil, nil
+		},
+	}
+	handler := NewGetVehicleHandler(mockRepo)
+
+	_, err := handler.Handle(context.Background(), &GetVehicleRequest{ID: "VEH_1", Fields: "vin,password"})
+
+	if !errors.Is(err, apperrors.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput, got %v", err)
+	}
+}
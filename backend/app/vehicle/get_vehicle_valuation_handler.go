@@ -0,0 +1,46 @@
+package vehicle
+
+import (
+	"context"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/validator"
+)
+
+type GetVehicleValuationRequest struct {
+	ID string `json:"id" param:"id" validate:"required"`
+}
+
+type GetVehicleValuationResponse struct {
+	VehicleID string `json:"vehicle_id"`
+	Valuation
+}
+
+type GetVehicleValuationHandler struct {
+	repository Repository
+	model      ValuationModel
+}
+
+func NewGetVehicleValuationHandler(repository Repository, model ValuationModel) *GetVehicleValuationHandler {
+	return &GetVehicleValuationHandler{
+		repository: repository,
+		model:      model,
+	}
+}
+
+func (h *GetVehicleValuationHandler) Handle(ctx context.Context, req *GetVehicleValuationRequest) (*GetVehicleValuationResponse, error) {
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.ErrInvalidInput.WithDetails(map[string]string{
+			"validation": err.Error(),
+		})
+	}
+
+	vehicle, err := h.repository.GetVehicle(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetVehicleValuationResponse{
+		VehicleID: vehicle.ID,
+		Valuation: h.model.Estimate(vehicle),
+	}, nil
+}
@@ -0,0 +1,37 @@
+package vehicle
+
+import (
+	"context"
+	"errors"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"testing"
+	"time"
+)
+
+func TestGetVehicleValuationHandler(t *testing.T) {
+	mockRepo := &MockRepository{
+		GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+			if id != "VEH_1" {
+				return nil, apperrors.ErrResourceNotFound
+			}
+			return &domain.Vehicle{ID: id, Year: time.Now().Year() - 1, Mileage: 10000}, nil
+		},
+	}
+	handler := NewGetVehicleValuationHandler(mockRepo, DepreciationModel{BasePrice: 20000, AnnualDepreciation: 0.1, MileageDepreciation: 0.05, ResidualFloor: 0.1})
+
+	resp, err := handler.Handle(context.Background(), &GetVehicleValuationRequest{ID: "VEH_1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.VehicleID != "VEH_1" || resp.EstimatedValue != 17100 {
+		t.Errorf("Expected VEH_1 valued at 17100, got %s at %v", resp.VehicleID, resp.EstimatedValue)
+	}
+	if resp.Factors.MileageFactor != 0.95 || resp.Factors.Model != "depreciation" {
+		t.Errorf("Unexpected factors %+v", resp.Factors)
+	}
+
+	if _, err := handler.Handle(context.Background(), &GetVehicleValuationRequest{ID: "VEH_2"}); !errors.Is(err, apperrors.ErrResourceNotFound) {
+		t.Errorf("Expected ErrResourceNotFound, got %v", err)
+	}
+}
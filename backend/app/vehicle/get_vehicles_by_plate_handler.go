@@ -0,0 +1,51 @@
+package vehicle
+
+import (
+	"context"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/validator"
+)
+
+type GetVehiclesByPlateRequest struct {
+	Plate string `json:"plate" param:"plate" validate:"required,max=20"`
+}
+
+type GetVehiclesByPlateResponse struct {
+	// Newest first; a reissued plate matches every vehicle that carried it
+	Vehicles []*domain.Vehicle `json:"vehicles"`
+	Count    int               `json:"count"`
+}
+
+type GetVehiclesByPlateHandler struct {
+	repository Repository
+}
+
+func NewGetVehiclesByPlateHandler(repository Repository) *GetVehiclesByPlateHandler {
+	return &GetVehiclesByPlateHandler{
+		repository: repository,
+	}
+}
+
+func (h *GetVehiclesByPlateHandler) Handle(ctx context.Context, req *GetVehiclesByPlateRequest) (*GetVehiclesByPlateResponse, error) {
+	req.Plate = domain.NormalizeLicensePlate(req.Plate)
+
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.ErrInvalidInput.WithDetails(map[string]string{
+			"validation": err.Error(),
+		})
+	}
+
+	vehicles, err := h.repository.GetVehiclesByLicensePlate(ctx, req.Plate)
+	if err != nil {
+		return nil, err
+	}
+	if len(vehicles) == 0 {
+		return nil, apperrors.NewNotFoundError("vehicle", req.Plate)
+	}
+
+	return &GetVehiclesByPlateResponse{
+		Vehicles: vehicles,
+		Count:    len(vehicles),
+	}, nil
+}
@@ -0,0 +1,55 @@
+package vehicle
+
+import (
+	"context"
+	"errors"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"testing"
+)
+
+func TestGetVehiclesByPlateHandler_NormalizesPlate(t *testing.T) {
+	var gotPlate string
+	mockRepo := &MockRepository{
+		GetVehiclesByLicensePlateFunc: func(ctx context.Context, plate string) ([]*domain.Vehicle, error) {
+			gotPlate = plate
+			return []*domain.Vehicle{{ID: "VEH_NEW"}, {ID: "VEH_OLD"}}, nil
+		},
+	}
+	handler := NewGetVehiclesByPlateHandler(mockRepo)
+
+	resp, err := handler.Handle(context.Background(), &GetVehiclesByPlateRequest{Plate: "  34 abc 123 "})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if gotPlate != "34 ABC 123" {
+		t.Errorf("Expected plate 34 ABC 123, got %q", gotPlate)
+	}
+	if resp.Count != 2 || resp.Vehicles[0].ID != "VEH_NEW" {
+		t.Errorf("Expected 2 vehicles newest first, got %+v", resp)
+	}
+}
+
+func TestGetVehiclesByPlateHandler_NotFound(t *testing.T) {
+	mockRepo := &MockRepository{
+		GetVehiclesByLicensePlateFunc: func(ctx context.Context, plate string) ([]*domain.Vehicle, error) {
+			return []*domain.Vehicle{}, nil
+		},
+	}
+	handler := NewGetVehiclesByPlateHandler(mockRepo)
+
+	_, err := handler.Handle(context.Background(), &GetVehiclesByPlateRequest{Plate: "34ABC123"})
+	if !errors.Is(err, apperrors.ErrResourceNotFound) {
+		t.Errorf("Expected ErrResourceNotFound, got %v", err)
+	}
+}
+
+func TestGetVehiclesByPlateHandler_BlankPlate(t *testing.T) {
+	handler := NewGetVehiclesByPlateHandler(&MockRepository{})
+
+	_, err := handler.Handle(context.Background(), &GetVehiclesByPlateRequest{Plate: "   "})
+	if !errors.Is(err, apperrors.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput, got %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package vehicle
+
+import (
+	"context"
+	"fmt"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/validator"
+	"slices"
+	"strings"
+)
+
+type GetVehiclesByVINsRequest struct {
+	VINs []string `json:"vins" validate:"required,min=1,max=100"`
+}
+
+type GetVehiclesByVINsResponse struct {
+	Vehicles map[string]*domain.Vehicle `json:"vehicles"`  // Keyed by normalized VIN
+	NotFound []string                   `json:"not_found"` // In request order
+}
+
+type GetVehiclesByVINsHandler struct {
+	repository Repository
+}
+
+func NewGetVehiclesByVINsHandler(repository Repository) *GetVehiclesByVINsHandler {
+	return &GetVehiclesByVINsHandler{
+		repository: repository,
+	}
+}
+
+func (h *GetVehiclesByVINsHandler) Handle(ctx context.Context, req *GetVehiclesByVINsRequest) (*GetVehiclesByVINsResponse, error) {
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.ErrInvalidInput.WithDetails(map[string]string{
+			"validation": err.Error(),
+		})
+	}
+
+	vins, err := normalizeVINs(req.VINs)
+	if err != nil {
+		return nil, err
+	}
+
+	vehicles, notFound, err := h.repository.GetVehiclesByVINs(ctx, vins)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetVehiclesByVINsResponse{
+		Vehicles: vehicles,
+		NotFound: notFound,
+	}, nil
+}
+
+// normalizeVINs uppercases and trims the VINs the way create stores them and drops duplicates
+func normalizeVINs(raw []string) ([]string, error) {
+	vins := make([]string, 0, len(raw))
+	for _, vin := range raw {
+		vin = strings.ToUpper(strings.TrimSpace(vin))
+		if len(vin) != 17 {
+			return nil, apperrors.NewValidationError("vins", fmt.Sprintf("%q must be exactly 17 characters", vin))
+		}
+		if !slices.Contains(vins, vin) {
+			vins = append(vins, vin)
+		}
+	}
+	return vins, nil
+}
@@ -0,0 +1,63 @@
+package vehicle
+
+import (
+	"context"
+	"errors"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"reflect"
+	"testing"
+)
+
+func TestGetVehiclesByVINsHandler_NormalizesAndDedupes(t *testing.T) {
+	var gotVINs []string
+	mockRepo := &MockRepository{
+		GetVehiclesByVINsFunc: func(ctx context.Context, vins []string) (map[string]*domain.Vehicle, []string, error) {
+			gotVINs = vins
+			return map[string]*domain.Vehicle{"1HGBH41JXMN109186": {ID: "VEH_1"}}, []string{"WBADT43452G296706"}, nil
+		},
+	}
+	handler := NewGetVehiclesByVINsHandler(mockRepo)
+
+	resp, err := handler.Handle(context.Background(), &GetVehiclesByVINsRequest{
+		VINs: []string{" 1hgbh41jxmn109186", "WBADT43452G296706", "1HGBH41JXMN109186 "},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if expected := []string{"1HGBH41JXMN109186", "WBADT43452G296706"}; !reflect.DeepEqual(gotVINs, expected) {
+		t.Errorf("Expected VINs %v, got %v", expected, gotVINs)
+	}
+	if resp.Vehicles["1HGBH41JXMN109186"] == nil || len(resp.NotFound) != 1 {
+		t.Errorf("Expected one vehicle and one VIN not found, got %+v", resp)
+	}
+}
+
+func TestGetVehiclesByVINsHandler_Validation(t *testing.T) {
+	handler := NewGetVehiclesByVINsHandler(&MockRepository{})
+
+	tooMany := make([]string, 101)
+	for i := range tooMany {
+		tooMany[i] = "1HGBH41JXMN109186"
+	}
+
+	tests := []struct {
+		name string
+		vins []string
+	}{
+		{"empty", nil},
+		{"too many", tooMany},
+		{"short VIN", []string{"1HGBH41JXMN10918"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := handler.Handle(context.Background(), &GetVehiclesByVINsRequest{VINs: tt.vins})
+
+			if !errors.Is(err, apperrors.ErrInvalidInput) {
+				t.Errorf("Expected ErrInvalidInput, got %v", err)
+			}
+		})
+	}
+}
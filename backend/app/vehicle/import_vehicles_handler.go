@@ -0,0 +1,261 @@
+package vehicle
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/response"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxImportRows bounds the data rows one import reads; the rest of a larger
+// file is reported as not imported
+const maxImportRows = 1000
+
+// importColumns sets a CreateVehicleRequest field from a CSV column. Columns
+// are named like the JSON fields; metadata.<key> columns fill metadata.
+var importColumns = map[string]func(req *CreateVehicleRequest, value string) error{
+	"vin":           func(req *CreateVehicleRequest, value string) error { req.VIN = value; return nil },
+	"make":          func(req *CreateVehicleRequest, value string) error { req.Make = value; return nil },
+	"model":         func(req *CreateVehicleRequest, value string) error { req.Model = value; return nil },
+	"year":          func(req *CreateVehicleRequest, value string) error { return parseIntColumn(&req.Year, value) },
+	"color":         func(req *CreateVehicleRequest, value string) error { req.Color = value; return nil },
+	"license_plate": func(req *CreateVehicleRequest, value string) error { req.LicensePlate = value; return nil },
+	"owner_id":      func(req *CreateVehicleRequest, value string) error { req.OwnerID = value; return nil },
+	"owner_name":    func(req *CreateVehicleRequest, value string) error { req.OwnerName = value; return nil },
+	"owner_email":   func(req *CreateVehicleRequest, value string) error { req.OwnerEmail = value; return nil },
+	"owner_phone":   func(req *CreateVehicleRequest, value string) error { req.OwnerPhone = value; return nil },
+	"transmission":  func(req *CreateVehicleRequest, value string) error { req.Transmission = value; return nil },
+	"fuel_type":     func(req *CreateVehicleRequest, value string) error { req.FuelType = value; return nil },
+	"mileage":       func(req *CreateVehicleRequest, value string) error { return parseIntColumn(&req.Mileage, value) },
+}
+
+// requiredImportColumns must be in the header, rows cannot be valid without them
+var requiredImportColumns = []string{"vin", "make", "model", "year", "owner_id", "owner_name", "owner_email", "fuel_type"}
+
+type ImportVehiclesRequest struct {
+	CreatedBy string `form:"created_by"`
+	DryRun    bool   `query:"dry_run"` // Validate every row without creating anything
+}
+
+// ImportRowResult reports one data row. Index counts data rows from 0, Row is
+// the line in the file, the header being line 1. ID is the created vehicle or,
+// for skipped rows, the vehicle that already has the VIN.
+type ImportRowResult struct {
+	response.ItemResult
+	Row int    `json:"row"`
+	VIN string `json:"vin,omitempty"`
+}
+
+type ImportVehiclesResponse struct {
+	DryRun  bool              `json:"dry_run"`
+	Created int               `json:"created"` // In a dry run, the rows that would be created
+	Skipped int               `json:"skipped"` // Rows whose VIN exists already
+	Failed  int               `json:"failed"`
+	Rows    []ImportRowResult `json:"rows"`
+}
+
+func (r *ImportVehiclesResponse) ItemResults() []response.ItemResult {
+	results := make([]response.ItemResult, len(r.Rows))
+	for i, row := range r.Rows {
+		results[i] = row.ItemResult
+	}
+	return results
+}
+
+type ImportVehiclesHandler struct {
+	creator *CreateVehicleHandler
+}
+
+// NewImportVehiclesHandler imports through creator, so every row gets the
+// validation, duplicate checks and audit entry of POST /vehicles
+func NewImportVehiclesHandler(creator *CreateVehicleHandler) *ImportVehiclesHandler {
+	return &ImportVehiclesHandler{
+		creator: creator,
+	}
+}
+
+// Handle creates a vehicle per row of the uploaded CSV ("file"), reading it a
+// row at a time. The header row names the columns. Rows are independent: a
+// bad row is reported and the import goes on.
+func (h *ImportVehiclesHandler) Handle(ctx *fiber.Ctx, req *ImportVehiclesRequest) (*ImportVehiclesResponse, error) {
+	if req.CreatedBy == "" {
+		return nil, apperrors.NewValidationError("created_by", "is required")
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		return nil, apperrors.NewValidationError("file", "a CSV file is required")
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, apperrors.ErrInternalServer.WithCause(err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, apperrors.NewValidationError("file", "expected a header row")
+	}
+	columns, err := importHeader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ImportVehiclesResponse{DryRun: req.DryRun, Rows: []ImportRowResult{}}
+	seenVINs := make(map[string]struct{})
+	for index := 0; ; index++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			res.add(failedRow(index, parseErr.StartLine, "", apperrors.NewValidationError("row", parseErr.Err.Error())))
+			if errors.Is(err, csv.ErrFieldCount) {
+				continue
+			}
+			// Past a quoting error the rows cannot be told apart reliably
+			break
+		}
+		if err != nil {
+			return nil, apperrors.ErrInternalServer.WithCause(err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		if index == maxImportRows {
+			res.add(failedRow(index, line, "", apperrors.NewValidationError("file",
+				fmt.Sprintf("at most %d rows are imported at once; this row and the ones after it were not read", maxImportRows))))
+			break
+		}
+
+		res.add(h.importRow(ctx.UserContext(), index, line, columns, record, req, seenVINs))
+	}
+
+	return res, nil
+}
+
+func (r *ImportVehiclesResponse) add(row ImportRowResult) {
+	switch row.Status {
+	case response.ItemSucceeded:
+		r.Created++
+	case response.ItemSkipped:
+		r.Skipped++
+	default:
+		r.Failed++
+	}
+	r.Rows = append(r.Rows, row)
+}
+
+// importRow validates one record and, unless it is a dry run, creates the vehicle
+func (h *ImportVehiclesHandler) importRow(ctx context.Context, index, line int, columns []string, record []string, req *ImportVehiclesRequest, seenVINs map[string]struct{}) ImportRowResult {
+	createReq := &CreateVehicleRequest{CreatedBy: req.CreatedBy}
+	for i, column := range columns {
+		if err := setImportColumn(createReq, column, strings.TrimSpace(record[i])); err != nil {
+			return failedRow(index, line, createReq.VIN, err)
+		}
+	}
+
+	if err := h.creator.validate(createReq); err != nil {
+		return failedRow(index, line, createReq.VIN, err)
+	}
+
+	result := ImportRowResult{ItemResult: response.ItemResult{Index: index, Status: response.ItemSkipped}, Row: line, VIN: createReq.VIN}
+	if _, seen := seenVINs[createReq.VIN]; seen {
+		return result
+	}
+	seenVINs[createReq.VIN] = struct{}{}
+
+	existing, err := h.creator.repository.GetVehicleByVIN(ctx, createReq.VIN)
+	if err == nil && existing != nil {
+		result.ID = existing.ID
+		return result
+	}
+
+	vehicle, _, err := h.creator.build(ctx, createReq)
+	if err != nil {
+		return failedRow(index, line, createReq.VIN, err)
+	}
+	if !req.DryRun {
+		// A failed audit entry is logged, the row is still created
+		if _, err := h.creator.create(ctx, vehicle); err != nil {
+			return failedRow(index, line, createReq.VIN, err)
+		}
+		result.ID = vehicle.ID
+	}
+
+	result.Status = response.ItemSucceeded
+	return result
+}
+
+// importHeader normalizes the header row, rejecting unknown and duplicate
+// columns and a header missing required ones
+func importHeader(header []string) ([]string, error) {
+	columns := make([]string, len(header))
+	seen := make(map[string]struct{}, len(header))
+	for i, name := range header {
+		column := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, ok := importColumns[column]; !ok && !strings.HasPrefix(column, metadataQueryPrefix) {
+			return nil, apperrors.NewValidationError("header", fmt.Sprintf("unknown column %q", name))
+		}
+		if _, ok := seen[column]; ok {
+			return nil, apperrors.NewValidationError("header", fmt.Sprintf("column %q appears twice", name))
+		}
+		seen[column] = struct{}{}
+		columns[i] = column
+	}
+
+	for _, required := range requiredImportColumns {
+		if _, ok := seen[required]; !ok {
+			return nil, apperrors.NewValidationError("header", fmt.Sprintf("missing column %q", required))
+		}
+	}
+	return columns, nil
+}
+
+// setImportColumn sets the field of a column. Empty cells are left unset,
+// for metadata too.
+func setImportColumn(req *CreateVehicleRequest, column, value string) error {
+	if value == "" {
+		return nil
+	}
+	if key, ok := strings.CutPrefix(column, metadataQueryPrefix); ok {
+		if req.Metadata == nil {
+			req.Metadata = make(map[string]string)
+		}
+		req.Metadata[key] = value
+		return nil
+	}
+	if err := importColumns[column](req, value); err != nil {
+		return apperrors.NewValidationError(column, err.Error())
+	}
+	return nil
+}
+
+func parseIntColumn(field *int, value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return errors.New("must be a whole number")
+	}
+	*field = n
+	return nil
+}
+
+func failedRow(index, line int, vin string, err error) ImportRowResult {
+	return ImportRowResult{
+		ItemResult: response.ItemResult{Index: index, Status: response.ItemFailed, Error: apperrors.NewItemError(err)},
+		Row:        line,
+		VIN:        vin,
+	}
+}
@@ -0,0 +1,180 @@
+package vehicle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/response"
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const importCSV = `vin,make,model,year,owner_id,owner_name,owner_email,fuel_type,license_plate,metadata.department
+1HGBH41JXMN109186,Honda,Civic,2021,OWNER_1,Ayşe Yılmaz,ayse@example.com,gasoline,34 abc 123,sales
+2HGBH41JXMN109187,Honda,Accord,twenty,OWNER_1,Ayşe Yılmaz,ayse@example.com,gasoline,,
+3HGBH41JXMN109188,Tesla,Model 3,2023,OWNER_2,Mehmet Demir,mehmet@example.com,electric,,
+1HGBH41JXMN109186,Honda,Civic,2021,OWNER_1,Ayşe Yılmaz,ayse@example.com,gasoline,,
+`
+
+func postImport(t *testing.T, handler *ImportVehiclesHandler, csv string, query string) (int, *ImportVehiclesResponse) {
+	t.Helper()
+
+	app := fiber.New()
+	app.Post("/vehicles/import", func(c *fiber.Ctx) error {
+		var req ImportVehiclesRequest
+		if err := c.BodyParser(&req); err != nil {
+			return err
+		}
+		if err := c.QueryParser(&req); err != nil {
+			return err
+		}
+		res, err := handler.Handle(c, &req)
+		if err != nil {
+			return apperrors.HandleError(c, err)
+		}
+		return response.Send(c, res)
+	})
+
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	w, _ := form.CreateFormFile("file", "vehicles.csv")
+	w.Write([]byte(csv))
+	form.WriteField("created_by", "importer")
+	form.Close()
+
+	req := httptest.NewRequest("POST", "/vehicles/import"+query, body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var res ImportVehiclesResponse
+	json.NewDecoder(resp.Body).Decode(&res)
+	return resp.StatusCode, &res
+}
+
+func TestImportVehiclesHandler(t *testing.T) {
+	var created []*domain.Vehicle
+	mockRepo := &MockRepository{
+		GetVehicleByVINFunc: func(ctx context.Context, vin string) (*domain.Vehicle, error) {
+			if vin == "3HGBH41JXMN109188" {
+				return &domain.Vehicle{ID: "VEH_EXISTING", VIN: vin}, nil
+			}
+			return nil, apperrors.ErrResourceNotFound
+		},
+		CreateVehicleFunc: func(ctx context.Context, vehicle *domain.Vehicle) error {
+			created = append(created, vehicle)
+			return nil
+		},
+	}
+	handler := NewImportVehiclesHandler(NewCreateVehicleHandler(mockRepo, DuplicatePlateReject, nil))
+
+	status, res := postImport(t, handler, importCSV, "")
+	if status != fiber.StatusMultiStatus {
+		t.Errorf("Expected status 207 with a failed row, got %d", status)
+	}
+	if res.Created != 1 || res.Skipped != 2 || res.Failed != 1 || len(res.Rows) != 4 {
+		t.Fatalf("Expected 1 created, 2 skipped and 1 failed, got %+v", res)
+	}
+
+	if len(created) != 1 {
+		t.Fatalf("Expected 1 vehicle to be created, got %d", len(created))
+	}
+	vehicle := created[0]
+	if vehicle.LicensePlate != "34 ABC 123" || vehicle.Metadata["department"] != "sales" || vehicle.CreatedBy != "importer" {
+		t.Errorf("Expected the row's plate, metadata and importer, got %+v", vehicle)
+	}
+	if res.Rows[0].ID != vehicle.ID || res.Rows[0].Row != 2 {
+		t.Errorf("Expected row 2 to report the created vehicle, got %+v", res.Rows[0])
+	}
+
+	if row := res.Rows[1]; row.Status != response.ItemFailed || row.Row != 3 || row.Error == nil {
+		t.Errorf("Expected row 3 to fail on its year, got %+v", row)
+	}
+	if row := res.Rows[2]; row.Status != response.ItemSkipped || row.ID != "VEH_EXISTING" {
+		t.Errorf("Expected row 4 to be skipped as existing, got %+v", row)
+	}
+	if row := res.Rows[3]; row.Status != response.ItemSkipped || row.Row != 5 {
+		t.Errorf("Expected row 5 to be skipped as a repeat within the file, got %+v", row)
+	}
+}
+
+func TestImportVehiclesHandler_UniqueIDs(t *testing.T) {
+	// Like the bucket, the repository rejects a second vehicle with the same ID
+	ids := make(map[string]struct{})
+	mockRepo := &MockRepository{
+		CreateVehicleFunc: func(ctx context.Context, vehicle *domain.Vehicle) error {
+			if _, ok := ids[vehicle.ID]; ok {
+				return apperrors.ErrResourceExists
+			}
+			ids[vehicle.ID] = struct{}{}
+			return nil
+		},
+	}
+	handler := NewImportVehiclesHandler(NewCreateVehicleHandler(mockRepo, DuplicatePlateReject, nil))
+
+	csv := "vin,make,model,year,owner_id,owner_name,owner_email,fuel_type\n"
+	for i := range 20 {
+		csv += fmt.Sprintf("1HGBH41JXMN1%05d,Honda,Civic,2021,OWNER_1,Ayşe Yılmaz,ayse@example.com,gasoline\n", i)
+	}
+
+	status, res := postImport(t, handler, csv, "")
+	if status != fiber.StatusOK || res.Created != 20 || res.Failed != 0 {
+		t.Errorf("Expected all 20 rows to be created, got status %d with %+v", status, res)
+	}
+	if len(ids) != 20 {
+		t.Errorf("Expected 20 distinct vehicle IDs, got %d", len(ids))
+	}
+}
+
+func TestImportVehiclesHandler_DryRun(t *testing.T) {
+	mockRepo := &MockRepository{
+		CreateVehicleFunc: func(ctx context.Context, vehicle *domain.Vehicle) error {
+			t.Error("Expected a dry run to create nothing")
+			return nil
+		},
+	}
+	handler := NewImportVehiclesHandler(NewCreateVehicleHandler(mockRepo, DuplicatePlateReject, nil))
+
+	_, res := postImport(t, handler, importCSV, "?dry_run=true")
+	if !res.DryRun || res.Created != 2 || res.Skipped != 1 || res.Failed != 1 {
+		t.Errorf("Expected 2 valid, 1 repeated and 1 failed row, got %+v", res)
+	}
+	if res.Rows[0].ID != "" {
+		t.Errorf("Expected no ID in a dry run, got %s", res.Rows[0].ID)
+	}
+}
+
+func TestImportVehiclesHandler_Header(t *testing.T) {
+	handler := NewImportVehiclesHandler(NewCreateVehicleHandler(&MockRepository{}, DuplicatePlateReject, nil))
+
+	for name, csv := range map[string]string{
+		"unknown column":  "vin,make,model,year,owner_id,owner_name,owner_email,fuel_type,wheels\n",
+		"missing column":  "vin,make,model,year,owner_id,owner_name,fuel_type\n",
+		"repeated column": "vin,make,model,year,owner_id,owner_name,owner_email,fuel_type,VIN\n",
+		"empty file":      "",
+	} {
+		if status, _ := postImport(t, handler, csv, ""); status != fiber.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", name, status)
+		}
+	}
+}
+
+func TestImportVehiclesHandler_MalformedRow(t *testing.T) {
+	handler := NewImportVehiclesHandler(NewCreateVehicleHandler(&MockRepository{}, DuplicatePlateReject, nil))
+
+	csv := "vin,make,model,year,owner_id,owner_name,owner_email,fuel_type\n" +
+		"1HGBH41JXMN109186,Honda,Civic\n" +
+		"2HGBH41JXMN109187,Honda,Accord,2020,OWNER_1,Ayşe Yılmaz,ayse@example.com,diesel\n"
+	_, res := postImport(t, handler, csv, "")
+	if res.Failed != 1 || res.Created != 1 || res.Rows[0].Row != 2 {
+		t.Errorf("Expected the short row to fail and the next one to be imported, got %+v", res)
+	}
+}
@@ -0,0 +1,51 @@
+package vehicle
+
+import (
+	"context"
+	"fmt"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/log"
+
+	"go.uber.org/zap"
+)
+
+// DuplicatePlatePolicy decides what happens when an owner's vehicle is given a
+// plate another of the owner's active vehicles already carries
+type DuplicatePlatePolicy string
+
+const (
+	DuplicatePlateReject DuplicatePlatePolicy = "reject" // Fail with ErrResourceExists
+	DuplicatePlateWarn   DuplicatePlatePolicy = "warn"   // Save and return a warning, for fleets that reissue plates
+	DuplicatePlateAllow  DuplicatePlatePolicy = "allow"  // Skip the check
+)
+
+// checkDuplicatePlate looks for another active vehicle of the owner with the
+// normalized plate, ignoring excludeID. Depending on policy it returns a
+// conflict or the warning to pass back to the client.
+func checkDuplicatePlate(ctx context.Context, repository Repository, policy DuplicatePlatePolicy, ownerID string, plate string, excludeID string) ([]string, error) {
+	if plate == "" || policy == DuplicatePlateAllow {
+		return nil, nil
+	}
+
+	existingID, found, err := repository.FindActiveVehicleByOwnerPlate(ctx, ownerID, plate, excludeID)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, nil
+	}
+
+	if policy == DuplicatePlateWarn {
+		log.FromContext(ctx).Warn("License plate already used by another vehicle of the owner",
+			zap.String("owner_id", ownerID),
+			zap.String("license_plate", plate),
+			zap.String("existing_vehicle_id", existingID))
+		return []string{fmt.Sprintf("license plate %s is already used by vehicle %s of this owner", plate, existingID)}, nil
+	}
+
+	return nil, apperrors.ErrResourceExists.WithDetails(map[string]string{
+		"resource":            "vehicle",
+		"license_plate":       plate,
+		"existing_vehicle_id": existingID,
+	})
+}
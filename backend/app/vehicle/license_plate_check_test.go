@@ -0,0 +1,111 @@
+package vehicle
+
+import (
+	"context"
+	"errors"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"testing"
+)
+
+func newDuplicatePlateRepo(created *bool) *MockRepository {
+	return &MockRepository{
+		GetVehicleByVINFunc: func(ctx context.Context, vin string) (*domain.Vehicle, error) {
+			return nil, apperrors.ErrResourceNotFound
+		},
+		FindActiveVehicleByOwnerPlateFunc: func(ctx context.Context, ownerID string, plate string, excludeID string) (string, bool, error) {
+			return "VEH_EXISTING", ownerID == "owner-123" && plate == "ABC123" && excludeID != "VEH_EXISTING", nil
+		},
+		CreateVehicleFunc: func(ctx context.Context, vehicle *domain.Vehicle) error {
+			*created = true
+			return nil
+		},
+	}
+}
+
+func newDuplicatePlateRequest(plate string) *CreateVehicleRequest {
+	return &CreateVehicleRequest{
+		VIN:          "1HGBH41JXMN109186",
+		Make:         "Toyota",
+		Model:        "Camry",
+		Year:         2023,
+		LicensePlate: plate,
+		OwnerID:      "owner-123",
+		OwnerName:    "John Doe",
+		OwnerEmail:   "john@example.com",
+		FuelType:     "gasoline",
+		CreatedBy:    "admin-user",
+	}
+}
+
+func TestCreateVehicleHandler_DuplicatePlate(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       DuplicatePlatePolicy
+		plate        string
+		wantConflict bool
+		wantWarnings int
+	}{
+		{"rejected", DuplicatePlateReject, " abc123 ", true, 0},
+		{"warned", DuplicatePlateWarn, "ABC123", false, 1},
+		{"allowed", DuplicatePlateAllow, "ABC123", false, 0},
+		{"plate not in use", DuplicatePlateReject, "XYZ789", false, 0},
+		{"no plate", DuplicatePlateReject, "", false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := false
+			handler := NewCreateVehicleHandler(newDuplicatePlateRepo(&created), tt.policy, nil)
+
+			resp, err := handler.Handle(context.Background(), newDuplicatePlateRequest(tt.plate))
+
+			if tt.wantConflict {
+				if !errors.Is(err, apperrors.ErrResourceExists) {
+					t.Errorf("Expected ErrResourceExists, got %v", err)
+				}
+				if created {
+					t.Error("Expected the vehicle not to be created")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !created {
+				t.Error("Expected the vehicle to be created")
+			}
+			if len(resp.Warnings) != tt.wantWarnings {
+				t.Errorf("Expected %d warnings, got %v", tt.wantWarnings, resp.Warnings)
+			}
+		})
+	}
+}
+
+func TestUpdateVehicleHandler_DuplicatePlate(t *testing.T) {
+	updated := false
+	mockRepo := newDuplicatePlateRepo(new(bool))
+	mockRepo.GetVehicleFunc = func(ctx context.Context, id string) (*domain.Vehicle, error) {
+		return &domain.Vehicle{ID: id, OwnerID: "owner-123", LicensePlate: "OLD1"}, nil
+	}
+	mockRepo.UpdateVehicleFunc = func(ctx context.Context, vehicle *domain.Vehicle) error {
+		updated = true
+		return nil
+	}
+	handler := NewUpdateVehicleHandler(mockRepo, DuplicatePlateReject, nil, nil)
+	plate := "abc123"
+
+	_, err := handler.Handle(context.Background(), &UpdateVehicleRequest{ID: "VEH_OTHER", LicensePlate: &plate, UpdatedBy: "admin-user"})
+	if !errors.Is(err, apperrors.ErrResourceExists) || updated {
+		t.Errorf("Expected ErrResourceExists without an update, got %v", err)
+	}
+
+	// The vehicle keeping its own plate is not a duplicate
+	resp, err := handler.Handle(context.Background(), &UpdateVehicleRequest{ID: "VEH_EXISTING", LicensePlate: &plate, UpdatedBy: "admin-user"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !updated || resp.Vehicle.LicensePlate != "ABC123" {
+		t.Errorf("Expected the plate to be saved, got %+v", resp.Vehicle)
+	}
+}
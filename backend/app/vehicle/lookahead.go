@@ -0,0 +1,32 @@
+package vehicle
+
+import (
+	"fmt"
+	apperrors "microservicetest/pkg/errors"
+)
+
+// maxLookaheadDays bounds every expiry lookahead query parameter
+var maxLookaheadDays = 365
+
+// SetMaxLookaheadDays replaces the bound of expiry lookaheads. Like the domain
+// setters it must only be called at startup.
+func SetMaxLookaheadDays(days int) error {
+	if days < 1 {
+		return fmt.Errorf("max lookahead days must be positive, got %d", days)
+	}
+	maxLookaheadDays = days
+	return nil
+}
+
+// lookaheadDays checks an expiry lookahead query parameter, the number of days
+// ahead to look for expiring items. A parameter that was not sent takes
+// defaultDays, one that was must be between 1 and the configured maximum.
+func lookaheadDays(field string, days *int, defaultDays int) (int, error) {
+	if days == nil {
+		return defaultDays, nil
+	}
+	if *days < 1 || *days > maxLookaheadDays {
+		return 0, apperrors.NewValidationError(field, fmt.Sprintf("must be between 1 and %d", maxLookaheadDays))
+	}
+	return *days, nil
+}
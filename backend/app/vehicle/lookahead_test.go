@@ -0,0 +1,55 @@
+package vehicle
+
+import (
+	"errors"
+	apperrors "microservicetest/pkg/errors"
+	"testing"
+)
+
+func TestLookaheadDays(t *testing.T) {
+	days := func(n int) *int { return &n }
+
+	tests := []struct {
+		name     string
+		days     *int
+		expected int
+		valid    bool
+	}{
+		{"default when not sent", nil, 30, true},
+		{"within range", days(90), 90, true},
+		{"maximum", days(365), 365, true},
+		{"zero", days(0), 0, false},
+		{"negative", days(-7), 0, false},
+		{"huge", days(100000), 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := lookaheadDays("days", tt.days, 30)
+			if tt.valid {
+				if err != nil || got != tt.expected {
+					t.Errorf("Expected %d, got %d, %v", tt.expected, got, err)
+				}
+				return
+			}
+			if !errors.Is(err, apperrors.ErrInvalidInput) {
+				t.Errorf("Expected ErrInvalidInput, got %d, %v", got, err)
+			}
+		})
+	}
+}
+
+func TestSetMaxLookaheadDays(t *testing.T) {
+	defer func(previous int) { maxLookaheadDays = previous }(maxLookaheadDays)
+
+	if err := SetMaxLookaheadDays(0); err == nil {
+		t.Error("Expected a zero maximum to be rejected")
+	}
+	if err := SetMaxLookaheadDays(730); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	n := 500
+	if got, err := lookaheadDays("days", &n, 30); err != nil || got != 500 {
+		t.Errorf("Expected the raised maximum to allow 500, got %d, %v", got, err)
+	}
+}
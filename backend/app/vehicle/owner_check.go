@@ -0,0 +1,20 @@
+package vehicle
+
+import (
+	"context"
+	apperrors "microservicetest/pkg/errors"
+)
+
+// checkOwnerKnown tells an owner without vehicles apart from an owner ID no
+// vehicle has ever had, which is answered with 404. Owner queries call it only
+// once they came back empty, so found results cost no extra query.
+func checkOwnerKnown(ctx context.Context, repository Repository, ownerID string) error {
+	known, err := repository.OwnerExists(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+	if !known {
+		return apperrors.NewNotFoundError("owner", ownerID)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package vehicle
+
+import (
+	"context"
+	"microservicetest/app"
+	apperrors "microservicetest/pkg/errors"
+)
+
+type ReconcileBlobsRequest struct {
+	DryRun *bool `query:"dry_run"` // Defaults to true, false removes the orphans
+}
+
+type ReconcileBlobsHandler struct {
+	job *ReconcileBlobsJob
+}
+
+func NewReconcileBlobsHandler(job *ReconcileBlobsJob) *ReconcileBlobsHandler {
+	return &ReconcileBlobsHandler{
+		job: job,
+	}
+}
+
+// Handle runs a reconciliation on demand. Only backend services calling with
+// an API key may, as it can delete files.
+func (h *ReconcileBlobsHandler) Handle(ctx context.Context, req *ReconcileBlobsRequest) (*BlobReconciliation, error) {
+	if _, ok := app.ServiceFromContext(ctx); !ok {
+		return nil, apperrors.ErrUnauthorized.WithDetails(map[string]string{
+			"header": "X-API-Key",
+		})
+	}
+
+	dryRun := req.DryRun == nil || *req.DryRun
+	return h.job.Reconcile(ctx, dryRun)
+}
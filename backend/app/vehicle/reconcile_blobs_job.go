@@ -0,0 +1,135 @@
+package vehicle
+
+import (
+	"context"
+	"microservicetest/app"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/log"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// OrphanBlob is a stored file no vehicle refers to
+type OrphanBlob struct {
+	Name         string    `json:"name"`
+	Size         int64     `json:"size"` // Bytes
+	LastModified time.Time `json:"last_modified"`
+}
+
+// BlobReconciliation reports one pass over the container
+type BlobReconciliation struct {
+	DryRun      bool         `json:"dry_run"`
+	Scanned     int          `json:"scanned"`  // Blobs in the container
+	InGrace     int          `json:"in_grace"` // Unreferenced blobs left alone for being newer than the grace period
+	Orphans     []OrphanBlob `json:"orphans"`  // By name
+	OrphanBytes int64        `json:"orphan_bytes"`
+	Deleted     int          `json:"deleted"`
+	Failed      int          `json:"failed"` // Orphans that could not be removed, see the logs
+}
+
+// ReconcileBlobsJob finds stored files no vehicle refers to, left behind when
+// an upload succeeded but the write recording it did not, and removes them
+type ReconcileBlobsJob struct {
+	repository    Repository
+	storage       app.Storage
+	gracePeriod   time.Duration
+	deleteOrphans bool
+}
+
+// NewReconcileBlobsJob skips blobs modified within gracePeriod, whose upload
+// may still be in flight. Scheduled runs only remove orphans when deleteOrphans is set.
+func NewReconcileBlobsJob(repository Repository, storage app.Storage, gracePeriod time.Duration, deleteOrphans bool) *ReconcileBlobsJob {
+	return &ReconcileBlobsJob{
+		repository:    repository,
+		storage:       storage,
+		gracePeriod:   gracePeriod,
+		deleteOrphans: deleteOrphans,
+	}
+}
+
+// Run is meant to be scheduled periodically
+func (j *ReconcileBlobsJob) Run(ctx context.Context) error {
+	res, err := j.Reconcile(ctx, !j.deleteOrphans)
+	if err != nil {
+		return err
+	}
+
+	log.FromContext(ctx).Info("Reconciled stored files",
+		zap.Bool("dry_run", res.DryRun),
+		zap.Int("scanned", res.Scanned),
+		zap.Int("orphans", len(res.Orphans)),
+		zap.Int64("orphan_bytes", res.OrphanBytes),
+		zap.Int("deleted", res.Deleted),
+		zap.Int("failed", res.Failed))
+	return nil
+}
+
+// Reconcile lists the container before reading the references, so a file that
+// is uploaded and recorded in between is never taken for an orphan. A dry run
+// only reports the orphans.
+func (j *ReconcileBlobsJob) Reconcile(ctx context.Context, dryRun bool) (*BlobReconciliation, error) {
+	if j.storage == nil {
+		return nil, errStorageUnavailable
+	}
+
+	blobs, err := j.storage.ListBlobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	urls, err := j.repository.ReferencedFileURLs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	referenced := make(map[string]bool, len(urls))
+	for _, fileURL := range urls {
+		blobName, err := blobNameFromURL(fileURL)
+		if err != nil {
+			// The reference could be to any blob, so none is safe to remove
+			return nil, apperrors.ErrInternalServer.WithCause(err).WithDetails(map[string]string{
+				"operation": "reconcile_blobs",
+			})
+		}
+		referenced[blobName] = true
+	}
+
+	res := &BlobReconciliation{DryRun: dryRun, Scanned: len(blobs), Orphans: []OrphanBlob{}}
+	cutoff := time.Now().Add(-j.gracePeriod)
+	for _, blob := range blobs {
+		if referenced[blob.Name] {
+			continue
+		}
+		if blob.LastModified.After(cutoff) {
+			res.InGrace++
+			continue
+		}
+		res.Orphans = append(res.Orphans, OrphanBlob{
+			Name:         blob.Name,
+			Size:         blob.Size,
+			LastModified: blob.LastModified,
+		})
+		res.OrphanBytes += blob.Size
+	}
+	sort.Slice(res.Orphans, func(a, b int) bool { return res.Orphans[a].Name < res.Orphans[b].Name })
+
+	if dryRun {
+		return res, nil
+	}
+	for _, orphan := range res.Orphans {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		if err := j.storage.Remove(ctx, orphan.Name); err != nil {
+			log.FromContext(ctx).Error("Failed to remove orphaned blob",
+				zap.String("filename", orphan.Name),
+				zap.Error(err))
+			res.Failed++
+			continue
+		}
+		res.Deleted++
+	}
+
+	return res, nil
+}
@@ -0,0 +1,122 @@
+package vehicle
+
+import (
+	"context"
+	"errors"
+	"microservicetest/app"
+	apperrors "microservicetest/pkg/errors"
+	"testing"
+	"time"
+)
+
+func newReconcileFixture() (*MockRepository, *MockStorage) {
+	old := time.Now().Add(-48 * time.Hour)
+	storage := &MockStorage{
+		Blobs: map[string][]byte{
+			"doc.pdf":       []byte("%PDF-1.7"),
+			"page2.pdf":     []byte("%PDF-1.7"),
+			"pic.jpg":       []byte("jpeg"),
+			"pic-thumb.jpg": []byte("jpeg"),
+			"orphan.pdf":    []byte("%PDF-1.7 orphan"),
+			"uploading.pdf": []byte("%PDF"),
+		},
+		Modified: map[string]time.Time{
+			"doc.pdf":       old,
+			"page2.pdf":     old,
+			"pic.jpg":       old,
+			"pic-thumb.jpg": old,
+			"orphan.pdf":    old,
+			"uploading.pdf": time.Now(),
+		},
+	}
+	repo := &MockRepository{
+		ReferencedFileURLsFunc: func(ctx context.Context) ([]string, error) {
+			return []string{
+				storage.URL("doc.pdf"),
+				storage.URL("page2.pdf"),
+				storage.URL("pic.jpg"),
+				storage.URL("pic-thumb.jpg"),
+			}, nil
+		},
+	}
+	return repo, storage
+}
+
+func TestReconcileBlobsJob_DryRunReportsOnly(t *testing.T) {
+	repo, storage := newReconcileFixture()
+
+	res, err := NewReconcileBlobsJob(repo, storage, 24*time.Hour, false).Reconcile(context.Background(), true)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.Scanned != 6 || res.InGrace != 1 {
+		t.Errorf("Expected 6 scanned and 1 in grace, got %d and %d", res.Scanned, res.InGrace)
+	}
+	if len(res.Orphans) != 1 || res.Orphans[0].Name != "orphan.pdf" {
+		t.Fatalf("Expected orphan.pdf as the only orphan, got %+v", res.Orphans)
+	}
+	if res.OrphanBytes != int64(len("%PDF-1.7 orphan")) {
+		t.Errorf("Expected orphan bytes to be counted, got %d", res.OrphanBytes)
+	}
+	if res.Deleted != 0 || len(storage.Blobs) != 6 {
+		t.Error("Expected a dry run to remove nothing")
+	}
+}
+
+func TestReconcileBlobsJob_RemovesOrphans(t *testing.T) {
+	repo, storage := newReconcileFixture()
+
+	if err := NewReconcileBlobsJob(repo, storage, 24*time.Hour, true).Run(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, ok := storage.Blobs["orphan.pdf"]; ok {
+		t.Error("Expected the orphan to be removed")
+	}
+	if _, ok := storage.Blobs["uploading.pdf"]; !ok {
+		t.Error("Expected a blob inside the grace period to be kept")
+	}
+	if len(storage.Blobs) != 5 {
+		t.Errorf("Expected the referenced blobs to be kept, got %d blobs", len(storage.Blobs))
+	}
+}
+
+func TestReconcileBlobsJob_RemovesNothingWhenReferencesFail(t *testing.T) {
+	repo, storage := newReconcileFixture()
+	repo.ReferencedFileURLsFunc = func(ctx context.Context) ([]string, error) {
+		return nil, apperrors.NewDatabaseError("find_referenced_file_urls", errors.New("timeout"))
+	}
+
+	if err := NewReconcileBlobsJob(repo, storage, 24*time.Hour, true).Run(context.Background()); err == nil {
+		t.Fatal("Expected the error to be returned")
+	}
+	if len(storage.Blobs) != 6 {
+		t.Error("Expected nothing to be removed")
+	}
+}
+
+func TestReconcileBlobsHandler(t *testing.T) {
+	repo, storage := newReconcileFixture()
+	handler := NewReconcileBlobsHandler(NewReconcileBlobsJob(repo, storage, 24*time.Hour, false))
+
+	if _, err := handler.Handle(context.Background(), &ReconcileBlobsRequest{}); !errors.Is(err, apperrors.ErrUnauthorized) {
+		t.Fatalf("Expected ErrUnauthorized without a service, got %v", err)
+	}
+
+	ctx := app.WithService(context.Background(), "ops")
+	res, err := handler.Handle(ctx, &ReconcileBlobsRequest{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !res.DryRun || len(storage.Blobs) != 6 {
+		t.Error("Expected a dry run by default")
+	}
+
+	dryRun := false
+	res, err = handler.Handle(ctx, &ReconcileBlobsRequest{DryRun: &dryRun})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.Deleted != 1 || len(storage.Blobs) != 5 {
+		t.Errorf("Expected the orphan to be removed, deleted %d", res.Deleted)
+	}
+}
@@ -0,0 +1,32 @@
+package vehicle
+
+import (
+	"context"
+	"microservicetest/pkg/log"
+
+	"go.uber.org/zap"
+)
+
+// RefreshStatusFlagsJob keeps the stored insurance and document status of
+// every vehicle current, so list queries can filter on them
+type RefreshStatusFlagsJob struct {
+	repository Repository
+}
+
+func NewRefreshStatusFlagsJob(repository Repository) *RefreshStatusFlagsJob {
+	return &RefreshStatusFlagsJob{
+		repository: repository,
+	}
+}
+
+// Run is meant to be scheduled periodically. Writes refresh the flags too, the
+// sweep catches the vehicles whose status changed only with time.
+func (j *RefreshStatusFlagsJob) Run(ctx context.Context) error {
+	refreshed, err := j.repository.RefreshStatusFlags(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.FromContext(ctx).Info("Refreshed vehicle status flags", zap.Int("vehicles", refreshed))
+	return nil
+}
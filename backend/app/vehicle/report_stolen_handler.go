@@ -0,0 +1,124 @@
+package vehicle
+
+import (
+	"context"
+	"microservicetest/app"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/log"
+	"microservicetest/pkg/validator"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// EventVehicleReportedStolen is published when a vehicle is reported stolen
+const EventVehicleReportedStolen = "vehicle.reported_stolen"
+
+type ReportStolenRequest struct {
+	ID                 string    `json:"id" param:"id" validate:"required"`
+	IncidentDate       time.Time `json:"incident_date" validate:"required"`
+	PoliceReportNumber string    `json:"police_report_number" validate:"required,max=50"`
+	Description        string    `json:"description" validate:"max=2000"`
+	ReportedBy         string    `json:"reported_by" validate:"required"`
+}
+
+type ReportStolenResponse struct {
+	VehicleID  string               `json:"vehicle_id"`
+	Status     domain.VehicleStatus `json:"status"`
+	Theft      *domain.TheftReport  `json:"theft"`
+	DocumentID string               `json:"document_id"` // The accident_report document filed with the report
+	Warnings   []string             `json:"warnings,omitempty"`
+}
+
+type ReportStolenHandler struct {
+	repository Repository
+	publisher  app.EventPublisher
+	auditLog   app.AuditLog
+}
+
+func NewReportStolenHandler(repository Repository, publisher app.EventPublisher, auditLog app.AuditLog) *ReportStolenHandler {
+	return &ReportStolenHandler{
+		repository: repository,
+		publisher:  publisher,
+		auditLog:   auditLog,
+	}
+}
+
+// Handle marks the vehicle stolen, files an accident_report document holding
+// the police report details and publishes EventVehicleReportedStolen
+func (h *ReportStolenHandler) Handle(ctx context.Context, req *ReportStolenRequest) (*ReportStolenResponse, error) {
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.ErrInvalidInput.WithDetails(map[string]string{
+			"validation": err.Error(),
+		})
+	}
+
+	now := time.Now()
+	if req.IncidentDate.After(now) {
+		return nil, apperrors.NewUnprocessableError("incident_date", "must not be in the future")
+	}
+
+	incidentDate := req.IncidentDate
+	document := domain.Document{
+		ID:             domain.GenerateDocumentID(),
+		Type:           domain.DocumentTypeAccidentReport,
+		Name:           "Theft report",
+		Description:    req.Description,
+		DocumentNumber: req.PoliceReportNumber,
+		IssuedDate:     &incidentDate,
+		UploadedAt:     now,
+		UploadedBy:     req.ReportedBy,
+	}
+	report := domain.TheftReport{
+		IncidentDate:       req.IncidentDate,
+		PoliceReportNumber: req.PoliceReportNumber,
+		Description:        req.Description,
+		ReportedAt:         now,
+		ReportedBy:         req.ReportedBy,
+	}
+
+	audit, err := beginAudit(ctx, h.auditLog, app.AuditEntry{
+		VehicleID: req.ID,
+		Action:    app.AuditActionReportStolen,
+		Actor:     req.ReportedBy,
+		Changes:   itemsChange("documents", nil, []string{document.ID}),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer audit.abort(ctx)
+
+	vehicle, err := h.repository.ReportStolen(ctx, req.ID, report, document)
+	if err != nil {
+		return nil, err
+	}
+	warnings := audit.complete(ctx)
+
+	event := app.Event{
+		Type:       EventVehicleReportedStolen,
+		VehicleID:  vehicle.ID,
+		OwnerID:    vehicle.OwnerID,
+		OccurredAt: now,
+		Data: map[string]string{
+			"incident_date":        req.IncidentDate.Format(time.RFC3339),
+			"police_report_number": req.PoliceReportNumber,
+			"reported_by":          req.ReportedBy,
+			"document_id":          document.ID,
+		},
+	}
+	if err := h.publisher.Publish(ctx, event); err != nil {
+		log.FromContext(ctx).Error("Failed to publish stolen vehicle report",
+			zap.String("vehicle_id", vehicle.ID),
+			zap.Error(err),
+		)
+	}
+
+	return &ReportStolenResponse{
+		VehicleID:  vehicle.ID,
+		Status:     vehicle.Status,
+		Theft:      vehicle.Theft,
+		DocumentID: document.ID,
+		Warnings:   warnings,
+	}, nil
+}
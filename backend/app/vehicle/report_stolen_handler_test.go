@@ -0,0 +1,71 @@
+package vehicle
+
+import (
+	"context"
+	"errors"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"testing"
+	"time"
+)
+
+func TestReportStolenHandler_PublishesEvent(t *testing.T) {
+	var gotReport domain.TheftReport
+	var gotDocument domain.Document
+	mockRepo := &MockRepository{
+		ReportStolenFunc: func(ctx context.Context, vehicleID string, report domain.TheftReport, document domain.Document) (*domain.Vehicle, error) {
+			gotReport, gotDocument = report, document
+			vehicle := &domain.Vehicle{ID: vehicleID, OwnerID: "OWNER_1", Status: domain.VehicleStatusActive}
+			return vehicle, vehicle.ReportStolen(report, document)
+		},
+	}
+	publisher := &MockPublisher{}
+	handler := NewReportStolenHandler(mockRepo, publisher, nil)
+
+	resp, err := handler.Handle(context.Background(), &ReportStolenRequest{
+		ID:                 "VEH_1",
+		IncidentDate:       time.Now().AddDate(0, 0, -2),
+		PoliceReportNumber: "PR-2026-042",
+		Description:        "Taken from the depot overnight",
+		ReportedBy:         "fleet-manager",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if resp.Status != domain.VehicleStatusStolen || resp.Theft == nil || resp.DocumentID != gotDocument.ID {
+		t.Errorf("Expected a stolen vehicle with the report document, got %+v", resp)
+	}
+	if gotDocument.Type != domain.DocumentTypeAccidentReport || gotDocument.DocumentNumber != "PR-2026-042" {
+		t.Errorf("Expected an accident_report document with the police report number, got %+v", gotDocument)
+	}
+	if gotReport.ReportedBy != "fleet-manager" {
+		t.Errorf("Expected the reporter to be recorded, got %+v", gotReport)
+	}
+
+	if len(publisher.Events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(publisher.Events))
+	}
+	if event := publisher.Events[0]; event.Type != EventVehicleReportedStolen || event.OwnerID != "OWNER_1" || event.Data["document_id"] != gotDocument.ID {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+}
+
+func TestReportStolenHandler_IncidentInFuture(t *testing.T) {
+	publisher := &MockPublisher{}
+	handler := NewReportStolenHandler(&MockRepository{}, publisher, nil)
+
+	_, err := handler.Handle(context.Background(), &ReportStolenRequest{
+		ID:                 "VEH_1",
+		IncidentDate:       time.Now().AddDate(0, 0, 1),
+		PoliceReportNumber: "PR-1",
+		ReportedBy:         "fleet-manager",
+	})
+
+	if !errors.Is(err, apperrors.ErrUnprocessableEntity) {
+		t.Errorf("Expected ErrUnprocessableEntity, got %v", err)
+	}
+	if len(publisher.Events) != 0 {
+		t.Errorf("Expected no event, got %v", publisher.Events)
+	}
+}
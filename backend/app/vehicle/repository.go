@@ -3,6 +3,7 @@ package vehicle
 import (
 	"context"
 	"microservicetest/domain"
+	"time"
 )
 
 // Repository defines the interface for vehicle data operations
@@ -10,16 +11,77 @@ type Repository interface {
 	// Basic CRUD operations
 	GetVehicle(ctx context.Context, id string) (*domain.Vehicle, error)
 	GetVehicleByVIN(ctx context.Context, vin string) (*domain.Vehicle, error)
-	GetVehiclesByOwner(ctx context.Context, ownerID string) ([]*domain.Vehicle, error)
+	// GetVehiclesByVINs looks up many VINs at once and returns the vehicles keyed
+	// by VIN plus the VINs that matched no vehicle
+	GetVehiclesByVINs(ctx context.Context, vins []string) (map[string]*domain.Vehicle, []string, error)
+	// GetVehiclesByLicensePlate returns every vehicle with the plate, newest first
+	GetVehiclesByLicensePlate(ctx context.Context, plate string) ([]*domain.Vehicle, error)
+	// GetVehiclesByOwner returns one page of an owner's vehicles, newest first,
+	// plus the total number of vehicles the owner has
+	GetVehiclesByOwner(ctx context.Context, ownerID string, filter OwnerVehicleFilter) ([]*domain.Vehicle, int, error)
+	// SearchVehicles returns one page of the fleet's vehicles matching the
+	// filter, newest first, plus the total number matching
+	SearchVehicles(ctx context.Context, filter VehicleSearchFilter) ([]*domain.Vehicle, int, error)
+	// OwnerExists reports whether any vehicle, deleted ones included, belongs or
+	// belonged to the owner. Owners have no store of their own.
+	OwnerExists(ctx context.Context, ownerID string) (bool, error)
+	// GetRecentVehiclesByOwner returns up to limit of an owner's vehicles as summaries,
+	// latest first by RecentByUpdated or RecentByCreated
+	GetRecentVehiclesByOwner(ctx context.Context, ownerID string, by string, limit int) ([]VehicleSummary, error)
+	// FindActiveVehicleByOwnerPlate reports the ID of an owner's vehicle, other than
+	// excludeID, that carries the plate and is neither deleted, sold nor scrapped
+	FindActiveVehicleByOwnerPlate(ctx context.Context, ownerID string, plate string, excludeID string) (string, bool, error)
 	CreateVehicle(ctx context.Context, vehicle *domain.Vehicle) error
-	UpdateVehicle(ctx context.Context, vehicle *domain.Vehicle) error
+	// UpdateVehicle applies update to the stored vehicle and returns it. If another
+	// write lands between the read and the write, update runs again on the new state.
+	UpdateVehicle(ctx context.Context, id string, update func(vehicle *domain.Vehicle) error) (*domain.Vehicle, error)
+	// DeleteVehicle soft deletes a vehicle; GetVehicle reports it as not found afterwards
 	DeleteVehicle(ctx context.Context, id string) error
+	// RestoreVehicle undoes DeleteVehicle and returns the restored vehicle
+	RestoreVehicle(ctx context.Context, id string, restoredBy string) (*domain.Vehicle, error)
+	UpsertVehicleByVIN(ctx context.Context, vehicle *domain.Vehicle) (*domain.Vehicle, bool, error)
+	// ReportStolen moves the vehicle to stolen, records the report and adds its
+	// document, returning the updated vehicle
+	ReportStolen(ctx context.Context, vehicleID string, report domain.TheftReport, document domain.Document) (*domain.Vehicle, error)
+	// RefreshStatusFlags re-evaluates the stored insurance and document status of
+	// every vehicle and returns how many changed
+	RefreshStatusFlags(ctx context.Context) (int, error)
+	// ReferencedFileURLs lists the URL of every stored file a vehicle refers to:
+	// document files and pages, pictures and thumbnails, deleted vehicles included
+	ReferencedFileURLs(ctx context.Context) ([]string, error)
 
 	// Document operations
 	AddDocument(ctx context.Context, vehicleID string, document domain.Document) error
 	GetDocuments(ctx context.Context, vehicleID string, filter DocumentFilter) ([]domain.Document, error)
-	DeleteDocument(ctx context.Context, vehicleID string, documentID string) error
+	// DeleteDocument removes a document. With a non-zero unmodifiedSince it fails
+	// with ErrPreconditionFailed if the vehicle changed after that time.
+	DeleteDocument(ctx context.Context, vehicleID string, documentID string, unmodifiedSince time.Time) error
+	// AppendFileToDocument adds a file as the next page of a document and
+	// returns it with its page number
+	AppendFileToDocument(ctx context.Context, vehicleID string, documentID string, file domain.DocumentFile) (domain.DocumentFile, error)
+	// GetOwnerDocuments lists documents across an owner's vehicles, ordered by
+	// expiry date, and returns the total number of matches before paging
+	GetOwnerDocuments(ctx context.Context, ownerID string, filter OwnerDocumentFilter) ([]OwnerDocument, int, error)
+	// ExpireVerifications unverifies every verified document past its expiry date
+	ExpireVerifications(ctx context.Context) ([]VerificationExpiry, error)
+
+	// Insurance operations
+	// RenewInsurance moves the insurance end date forward, optionally with a new policy number and premium
+	RenewInsurance(ctx context.Context, vehicleID string, policyNumber string, endDate time.Time, premium *domain.Money, renewedBy string) error
+
+	// Service history operations
+	AddServiceRecord(ctx context.Context, vehicleID string, record domain.ServiceRecord) error
+	// GetServiceRecords returns the vehicle's service history ordered by date
+	GetServiceRecords(ctx context.Context, vehicleID string) ([]domain.ServiceRecord, error)
 
 	// Picture operations
 	AddPicture(ctx context.Context, vehicleID string, picture domain.Picture) error
+	// AddPictures appends the pictures in one write, recomputes the main picture
+	// and returns the updated vehicle
+	AddPictures(ctx context.Context, vehicleID string, pictures []domain.Picture) (*domain.Vehicle, error)
+	// SetMainPicture makes the picture the only main picture of the vehicle
+	SetMainPicture(ctx context.Context, vehicleID string, pictureID string) error
+	// DeletePicturesByType removes all pictures of a type and returns the updated
+	// vehicle and the removed pictures
+	DeletePicturesByType(ctx context.Context, vehicleID string, picType domain.PictureType) (*domain.Vehicle, []domain.Picture, error)
 }
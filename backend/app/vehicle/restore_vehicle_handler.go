@@ -0,0 +1,98 @@
+package vehicle
+
+import (
+	"context"
+	"microservicetest/app"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/log"
+	"microservicetest/pkg/validator"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// EventVehicleRestored is the audit entry written when a soft deleted vehicle is restored
+const EventVehicleRestored = "vehicle.restored"
+
+type RestoreVehicleRequest struct {
+	ID     string `json:"id" param:"id" validate:"required"`
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+type RestoreVehicleResponse struct {
+	Vehicle  *domain.Vehicle `json:"vehicle"`
+	Warnings []string        `json:"warnings,omitempty"`
+}
+
+type RestoreVehicleHandler struct {
+	repository Repository
+	publisher  app.EventPublisher
+	auditLog   app.AuditLog
+}
+
+func NewRestoreVehicleHandler(repository Repository, publisher app.EventPublisher, auditLog app.AuditLog) *RestoreVehicleHandler {
+	return &RestoreVehicleHandler{
+		repository: repository,
+		publisher:  publisher,
+		auditLog:   auditLog,
+	}
+}
+
+// Handle undoes a soft delete. Restoring a vehicle that is not deleted is a conflict.
+// Only backend services calling with an API key may restore, and the calling
+// service is recorded as the one who restored it.
+func (h *RestoreVehicleHandler) Handle(ctx context.Context, req *RestoreVehicleRequest) (*RestoreVehicleResponse, error) {
+	service, ok := app.ServiceFromContext(ctx)
+	if !ok {
+		return nil, apperrors.ErrUnauthorized.WithDetails(map[string]string{
+			"header": "X-API-Key",
+		})
+	}
+
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.ErrInvalidInput.WithDetails(map[string]string{
+			"validation": err.Error(),
+		})
+	}
+
+	audit, err := beginAudit(ctx, h.auditLog, app.AuditEntry{
+		VehicleID: req.ID,
+		Action:    app.AuditActionRestore,
+		Actor:     service,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer audit.abort(ctx)
+
+	vehicle, err := h.repository.RestoreVehicle(ctx, req.ID, service)
+	if err != nil {
+		return nil, err
+	}
+	warnings := audit.complete(ctx)
+
+	data := map[string]string{
+		"restored_by": service,
+		"status":      string(vehicle.Status),
+	}
+	if req.Reason != "" {
+		data["reason"] = req.Reason
+	}
+
+	event := app.Event{
+		Type:       EventVehicleRestored,
+		VehicleID:  vehicle.ID,
+		OwnerID:    vehicle.OwnerID,
+		OccurredAt: time.Now(),
+		Data:       data,
+	}
+	if err := h.publisher.Publish(ctx, event); err != nil {
+		log.FromContext(ctx).Error("Failed to publish vehicle restore",
+			zap.String("vehicle_id", vehicle.ID),
+			zap.Error(err),
+		)
+	}
+
+	return &RestoreVehicleResponse{Vehicle: vehicle, Warnings: warnings}, nil
+}
@@ -0,0 +1,105 @@
+package vehicle
+
+import (
+	"context"
+	"errors"
+	"microservicetest/app"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"testing"
+)
+
+func TestRestoreVehicleHandler_WritesAuditEvent(t *testing.T) {
+	mockRepo := &MockRepository{
+		RestoreVehicleFunc: func(ctx context.Context, id string, restoredBy string) (*domain.Vehicle, error) {
+			return &domain.Vehicle{ID: id, OwnerID: "OWNER_1", Status: domain.VehicleStatusInactive, UpdatedBy: restoredBy}, nil
+		},
+	}
+	publisher := &MockPublisher{}
+	handler := NewRestoreVehicleHandler(mockRepo, publisher, nil)
+
+	ctx := app.WithService(context.Background(), "backoffice")
+	resp, err := handler.Handle(ctx, &RestoreVehicleRequest{ID: "VEH_1", Reason: "deleted by mistake"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if resp.Vehicle.Status != domain.VehicleStatusInactive {
+		t.Errorf("Expected the prior status to be kept, got %s", resp.Vehicle.Status)
+	}
+	if len(publisher.Events) != 1 {
+		t.Fatalf("Expected 1 audit event, got %d", len(publisher.Events))
+	}
+
+	event := publisher.Events[0]
+	if event.Type != EventVehicleRestored || event.VehicleID != "VEH_1" || event.OwnerID != "OWNER_1" {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+	if event.Data["restored_by"] != "backoffice" || event.Data["reason"] != "deleted by mistake" {
+		t.Errorf("Unexpected event data: %v", event.Data)
+	}
+}
+
+func TestRestoreVehicleHandler_NotDeleted(t *testing.T) {
+	mockRepo := &MockRepository{
+		RestoreVehicleFunc: func(ctx context.Context, id string, restoredBy string) (*domain.Vehicle, error) {
+			return nil, apperrors.NewConflictError("vehicle", "vehicle VEH_1 is not deleted")
+		},
+	}
+	publisher := &MockPublisher{}
+	handler := NewRestoreVehicleHandler(mockRepo, publisher, nil)
+
+	_, err := handler.Handle(app.WithService(context.Background(), "backoffice"), &RestoreVehicleRequest{ID: "VEH_1"})
+
+	if !errors.Is(err, apperrors.ErrResourceExists) {
+		t.Errorf("Expected a conflict, got %v", err)
+	}
+	if len(publisher.Events) != 0 {
+		t.Errorf("Expected no audit event, got %v", publisher.Events)
+	}
+}
+
+func TestRestoreVehicleHandler_RecordsAuditEntry(t *testing.T) {
+	mockRepo := &MockRepository{
+		RestoreVehicleFunc: func(ctx context.Context, id string, restoredBy string) (*domain.Vehicle, error) {
+			return &domain.Vehicle{ID: id, Status: domain.VehicleStatusActive}, nil
+		},
+	}
+	auditLog := &MockAuditLog{}
+	handler := NewRestoreVehicleHandler(mockRepo, &MockPublisher{}, auditLog)
+
+	resp, err := handler.Handle(app.WithService(context.Background(), "backoffice"), &RestoreVehicleRequest{ID: "VEH_1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(resp.Warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", resp.Warnings)
+	}
+	if len(auditLog.Entries) != 1 {
+		t.Fatalf("Expected 1 audit entry, got %d", len(auditLog.Entries))
+	}
+	entry := auditLog.Entries[0]
+	if entry.Action != app.AuditActionRestore || entry.VehicleID != "VEH_1" || entry.Actor != "backoffice" {
+		t.Errorf("Unexpected audit entry: %+v", entry)
+	}
+}
+
+func TestRestoreVehicleHandler_RequiresService(t *testing.T) {
+	mockRepo := &MockRepository{
+		RestoreVehicleFunc: func(ctx context.Context, id string, restoredBy string) (*domain.Vehicle, error) {
+			t.Error("Expected no restore without an API key")
+			return nil, nil
+		},
+	}
+	auditLog := &MockAuditLog{}
+	handler := NewRestoreVehicleHandler(mockRepo, &MockPublisher{}, auditLog)
+
+	_, err := handler.Handle(context.Background(), &RestoreVehicleRequest{ID: "VEH_1"})
+
+	if !errors.Is(err, apperrors.ErrUnauthorized) {
+		t.Errorf("Expected UNAUTHORIZED, got %v", err)
+	}
+	if len(auditLog.Entries) != 0 {
+		t.Errorf("Expected no audit entry, got %v", auditLog.Entries)
+	}
+}
@@ -0,0 +1,78 @@
+package vehicle
+
+import (
+	"context"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/validator"
+)
+
+const defaultSearchVehiclesLimit = 20
+
+// VehicleSearchFilter pages through the vehicles of the whole fleet whose
+// stored insurance and document status match the ones set
+type VehicleSearchFilter struct {
+	InsuranceStatus domain.InsuranceStatus
+	DocumentStatus  domain.DocumentStatus
+	Limit           int
+	Offset          int
+}
+
+// SearchVehiclesRequest needs at least one status, a search never scans the whole fleet
+type SearchVehiclesRequest struct {
+	InsuranceStatus string `query:"insurance_status" validate:"required_without=DocumentStatus,omitempty,insurancestatus"`
+	DocumentStatus  string `query:"document_status" validate:"required_without=InsuranceStatus,omitempty,documentstatus"`
+	Limit           int    `query:"limit" validate:"gte=0,lte=100"`
+	Offset          int    `query:"offset" validate:"gte=0"`
+}
+
+type SearchVehiclesResponse struct {
+	Vehicles []*domain.Vehicle `json:"vehicles"`
+	Count    int               `json:"count"` // Vehicles on this page
+	Total    int               `json:"total"` // Vehicles matching the search
+	Limit    int               `json:"limit"`
+	Offset   int               `json:"offset"`
+}
+
+type SearchVehiclesHandler struct {
+	repository Repository
+}
+
+func NewSearchVehiclesHandler(repository Repository) *SearchVehiclesHandler {
+	return &SearchVehiclesHandler{
+		repository: repository,
+	}
+}
+
+// Handle lists the vehicles of every owner by their stored status flags, for
+// fleet dashboards such as "every vehicle whose insurance expires soon"
+func (h *SearchVehiclesHandler) Handle(ctx context.Context, req *SearchVehiclesRequest) (*SearchVehiclesResponse, error) {
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.ErrInvalidInput.WithDetails(map[string]string{
+			"validation": err.Error(),
+		})
+	}
+
+	filter := VehicleSearchFilter{
+		InsuranceStatus: domain.InsuranceStatus(req.InsuranceStatus),
+		DocumentStatus:  domain.DocumentStatus(req.DocumentStatus),
+		Limit:           req.Limit,
+		Offset:          req.Offset,
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultSearchVehiclesLimit
+	}
+
+	vehicles, total, err := h.repository.SearchVehicles(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SearchVehiclesResponse{
+		Vehicles: vehicles,
+		Count:    len(vehicles),
+		Total:    total,
+		Limit:    filter.Limit,
+		Offset:   filter.Offset,
+	}, nil
+}
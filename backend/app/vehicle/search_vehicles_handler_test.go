@@ -0,0 +1,56 @@
+package vehicle
+
+import (
+	"context"
+	"errors"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"testing"
+)
+
+func TestSearchVehiclesHandler_StatusFilters(t *testing.T) {
+	var got VehicleSearchFilter
+	mockRepo := &MockRepository{
+		SearchVehiclesFunc: func(ctx context.Context, filter VehicleSearchFilter) ([]*domain.Vehicle, int, error) {
+			got = filter
+			return []*domain.Vehicle{{ID: "VEH_1"}}, 7, nil
+		},
+	}
+	handler := NewSearchVehiclesHandler(mockRepo)
+
+	resp, err := handler.Handle(context.Background(), &SearchVehiclesRequest{InsuranceStatus: "expiring_soon", DocumentStatus: "has_expired", Offset: 20})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want := VehicleSearchFilter{InsuranceStatus: "expiring_soon", DocumentStatus: "has_expired", Limit: defaultSearchVehiclesLimit, Offset: 20}
+	if got != want {
+		t.Errorf("Expected filter %+v, got %+v", want, got)
+	}
+	if resp.Count != 1 || resp.Total != 7 || resp.Limit != defaultSearchVehiclesLimit || resp.Offset != 20 {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+}
+
+func TestSearchVehiclesHandler_Validation(t *testing.T) {
+	mockRepo := &MockRepository{
+		SearchVehiclesFunc: func(ctx context.Context, filter VehicleSearchFilter) ([]*domain.Vehicle, int, error) {
+			t.Error("Expected repository not to be called")
+			return nil, 0, nil
+		},
+	}
+	handler := NewSearchVehiclesHandler(mockRepo)
+
+	for name, req := range map[string]SearchVehiclesRequest{
+		"no status":                {},
+		"unknown insurance status": {InsuranceStatus: "lapsed"},
+		"unknown document status":  {DocumentStatus: "missing"},
+		"limit over 100":           {InsuranceStatus: "expired", Limit: 101},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := handler.Handle(context.Background(), &req); !errors.Is(err, apperrors.ErrInvalidInput) {
+				t.Errorf("Expected %s, got %v", apperrors.ErrInvalidInput.Code, err)
+			}
+		})
+	}
+}
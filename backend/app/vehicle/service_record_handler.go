@@ -0,0 +1,117 @@
+package vehicle
+
+import (
+	"context"
+	"microservicetest/app"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/validator"
+	"time"
+)
+
+type AddServiceRecordRequest struct {
+	ID              string        `json:"id" param:"id" validate:"required"`
+	Date            time.Time     `json:"date" validate:"required"`
+	OdometerReading int           `json:"odometer_reading" validate:"gte=0"`
+	Cost            *domain.Money `json:"cost"` // Nil when there was no cost
+	Description     string        `json:"description" validate:"max=1000"`
+	ShopName        string        `json:"shop_name" validate:"max=100"`
+	DocumentIDs     []string      `json:"document_ids"` // Receipts and invoices already on the vehicle
+}
+
+type AddServiceRecordResponse struct {
+	ServiceRecord domain.ServiceRecord `json:"service_record"`
+	Warnings      []string             `json:"warnings,omitempty"`
+}
+
+type AddServiceRecordHandler struct {
+	repository Repository
+	auditLog   app.AuditLog
+}
+
+func NewAddServiceRecordHandler(repository Repository, auditLog app.AuditLog) *AddServiceRecordHandler {
+	return &AddServiceRecordHandler{
+		repository: repository,
+		auditLog:   auditLog,
+	}
+}
+
+func (h *AddServiceRecordHandler) Handle(ctx context.Context, req *AddServiceRecordRequest) (*AddServiceRecordResponse, error) {
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.ErrInvalidInput.WithDetails(map[string]string{
+			"validation": err.Error(),
+		})
+	}
+
+	now := time.Now()
+	if req.Date.After(now) {
+		return nil, apperrors.NewUnprocessableError("date", "must not be in the future")
+	}
+
+	record := domain.ServiceRecord{
+		ID:              domain.GenerateServiceRecordID(),
+		Date:            req.Date,
+		OdometerReading: req.OdometerReading,
+		Description:     req.Description,
+		ShopName:        req.ShopName,
+		DocumentIDs:     req.DocumentIDs,
+		CreatedAt:       now,
+	}
+	if req.Cost != nil {
+		record.Cost = *req.Cost
+	}
+	if record.DocumentIDs == nil {
+		record.DocumentIDs = []string{}
+	}
+
+	audit, err := beginAudit(ctx, h.auditLog, app.AuditEntry{
+		VehicleID: req.ID,
+		Action:    app.AuditActionAddServiceRecord,
+		Actor:     auditActor(ctx, ""),
+		Changes:   itemsChange("service_records", nil, []string{record.ID}),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer audit.abort(ctx)
+
+	if err := h.repository.AddServiceRecord(ctx, req.ID, record); err != nil {
+		return nil, err
+	}
+	warnings := audit.complete(ctx)
+
+	return &AddServiceRecordResponse{ServiceRecord: record, Warnings: warnings}, nil
+}
+
+type GetServiceRecordsRequest struct {
+	ID string `json:"id" param:"id" validate:"required"`
+}
+
+type GetServiceRecordsResponse struct {
+	ServiceRecords []domain.ServiceRecord `json:"service_records"`
+}
+
+type GetServiceRecordsHandler struct {
+	repository Repository
+}
+
+func NewGetServiceRecordsHandler(repository Repository) *GetServiceRecordsHandler {
+	return &GetServiceRecordsHandler{
+		repository: repository,
+	}
+}
+
+func (h *GetServiceRecordsHandler) Handle(ctx context.Context, req *GetServiceRecordsRequest) (*GetServiceRecordsResponse, error) {
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.ErrInvalidInput.WithDetails(map[string]string{
+			"validation": err.Error(),
+		})
+	}
+
+	records, err := h.repository.GetServiceRecords(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetServiceRecordsResponse{ServiceRecords: records}, nil
+}
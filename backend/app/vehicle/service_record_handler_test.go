@@ -0,0 +1,76 @@
+package vehicle
+
+import (
+	"context"
+	"errors"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"testing"
+	"time"
+)
+
+func TestAddServiceRecordHandler_Success(t *testing.T) {
+	var added domain.ServiceRecord
+	mockRepo := &MockRepository{
+		AddServiceRecordFunc: func(ctx context.Context, vehicleID string, record domain.ServiceRecord) error {
+			added = record
+			return nil
+		},
+	}
+	handler := NewAddServiceRecordHandler(mockRepo, nil)
+
+	resp, err := handler.Handle(context.Background(), &AddServiceRecordRequest{
+		ID:              "VEH_1",
+		Date:            time.Now().AddDate(0, -1, 0),
+		OdometerReading: 42000,
+		Cost:            &domain.Money{AmountMinor: 18990, Currency: "EUR"},
+		ShopName:        "Garage",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if added.ID == "" || added.OdometerReading != 42000 || added.DocumentIDs == nil {
+		t.Errorf("Unexpected record stored: %+v", added)
+	}
+	if added.Cost != (domain.Money{AmountMinor: 18990, Currency: "EUR"}) {
+		t.Errorf("Expected a cost of 189.90 EUR, got %+v", added.Cost)
+	}
+	if resp.ServiceRecord.ID != added.ID {
+		t.Errorf("Expected response to return the stored record, got %+v", resp.ServiceRecord)
+	}
+}
+
+func TestAddServiceRecordHandler_Validation(t *testing.T) {
+	mockRepo := &MockRepository{
+		AddServiceRecordFunc: func(ctx context.Context, vehicleID string, record domain.ServiceRecord) error {
+			t.Error("Expected repository not to be called")
+			return nil
+		},
+	}
+	handler := NewAddServiceRecordHandler(mockRepo, nil)
+	lastMonth := time.Now().AddDate(0, -1, 0)
+
+	tests := []struct {
+		name string
+		req  AddServiceRecordRequest
+		want *apperrors.AppError
+	}{
+		{"missing date", AddServiceRecordRequest{ID: "VEH_1", OdometerReading: 1000}, apperrors.ErrInvalidInput},
+		{"future date", AddServiceRecordRequest{ID: "VEH_1", Date: time.Now().AddDate(0, 0, 2)}, apperrors.ErrUnprocessableEntity},
+		{"negative odometer", AddServiceRecordRequest{ID: "VEH_1", Date: lastMonth, OdometerReading: -1}, apperrors.ErrInvalidInput},
+		{"cost without currency", AddServiceRecordRequest{ID: "VEH_1", Date: lastMonth, Cost: &domain.Money{AmountMinor: 1000}}, apperrors.ErrInvalidInput},
+		{"unknown currency", AddServiceRecordRequest{ID: "VEH_1", Date: lastMonth, Cost: &domain.Money{AmountMinor: 1000, Currency: "XYZ"}}, apperrors.ErrInvalidInput},
+		{"negative cost", AddServiceRecordRequest{ID: "VEH_1", Date: lastMonth, Cost: &domain.Money{AmountMinor: -1, Currency: "EUR"}}, apperrors.ErrInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := handler.Handle(context.Background(), &tt.req)
+
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Expected %s, got %v", tt.want.Code, err)
+			}
+		})
+	}
+}
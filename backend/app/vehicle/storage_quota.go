@@ -0,0 +1,38 @@
+package vehicle
+
+import (
+	"context"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"strconv"
+)
+
+// checkStorageQuota rejects an upload of the given bytes when it would take the
+// vehicle past quotaBytes. A quota of 0 means unlimited.
+func checkStorageQuota(vehicle *domain.Vehicle, quotaBytes, uploadBytes int64) error {
+	if quotaBytes <= 0 {
+		return nil
+	}
+	used := vehicle.StorageUsed()
+	if used+uploadBytes <= quotaBytes {
+		return nil
+	}
+	return apperrors.ErrStorageQuotaExceeded.WithDetails(map[string]string{
+		"quota_bytes":  strconv.FormatInt(quotaBytes, 10),
+		"used_bytes":   strconv.FormatInt(used, 10),
+		"upload_bytes": strconv.FormatInt(uploadBytes, 10),
+	})
+}
+
+// checkVehicleStorageQuota is checkStorageQuota for handlers that have not
+// read the vehicle. It is only read when there is a quota.
+func checkVehicleStorageQuota(ctx context.Context, repository Repository, vehicleID string, quotaBytes, uploadBytes int64) error {
+	if quotaBytes <= 0 {
+		return nil
+	}
+	vehicle, err := repository.GetVehicle(ctx, vehicleID)
+	if err != nil {
+		return err
+	}
+	return checkStorageQuota(vehicle, quotaBytes, uploadBytes)
+}
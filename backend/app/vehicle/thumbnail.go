@@ -0,0 +1,52 @@
+package vehicle
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+
+	// Decoders for the picture formats image.Decode accepts
+	_ "image/gif"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+)
+
+// thumbnailMaxSide is the longest side of a generated thumbnail in pixels
+const thumbnailMaxSide = 320
+
+// pictureMimeTypes are the picture formats image.Decode can read, so the ones
+// a thumbnail can be made of. Other allowed image types, such as HEIC, are
+// stored without one.
+var pictureMimeTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// makeThumbnail scales img down so its longest side is at most maxSide and
+// encodes it as JPEG. Smaller images keep their size.
+func makeThumbnail(img image.Image, maxSide int) ([]byte, error) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if longest := max(width, height); longest > maxSide {
+		width = max(width*maxSide/longest, 1)
+		height = max(height*maxSide/longest, 1)
+	}
+
+	// Nearest-neighbour sampling is plenty for a preview
+	thumb := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		srcY := bounds.Min.Y + y*bounds.Dy()/height
+		for x := range width {
+			thumb.Set(x, y, img.At(bounds.Min.X+x*bounds.Dx()/width, srcY))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -2,36 +2,57 @@ package vehicle
 
 import (
 	"context"
+	"fmt"
+	"microservicetest/app"
 	"microservicetest/domain"
 	apperrors "microservicetest/pkg/errors"
 	"microservicetest/pkg/validator"
+	"slices"
 	"strings"
 )
 
+// statusDocumentsRule names the required-documents check in audit overrides
+const statusDocumentsRule = "status_required_documents"
+
 type UpdateVehicleRequest struct {
-	ID           string  `json:"id" param:"id" validate:"required"`
-	Color        *string `json:"color" validate:"omitempty,max=30"`
-	LicensePlate *string `json:"license_plate" validate:"omitempty,max=20"`
-	OwnerName    *string `json:"owner_name" validate:"omitempty,min=1,max=100"`
-	OwnerEmail   *string `json:"owner_email" validate:"omitempty,email"`
-	OwnerPhone   *string `json:"owner_phone" validate:"omitempty,min=10,max=20"`
-	Transmission *string `json:"transmission" validate:"omitempty,oneof=manual automatic cvt"`
-	Mileage      *int    `json:"mileage" validate:"omitempty,gte=0"`
-	Status       *string `json:"status" validate:"omitempty,oneof=active inactive sold scrapped stolen accident"`
-	UpdatedBy    string  `json:"updated_by" validate:"required"`
+	ID           string              `json:"id" param:"id" validate:"required"`
+	Color        *string             `json:"color" validate:"omitempty,max=30"`
+	LicensePlate *string             `json:"license_plate" validate:"omitempty,max=20"`
+	OwnerName    *string             `json:"owner_name" validate:"omitempty,min=1,max=100"`
+	OwnerEmail   *string             `json:"owner_email" validate:"omitempty,email"`
+	OwnerPhone   *string             `json:"owner_phone" validate:"omitempty,min=10,max=20"`
+	Transmission *string             `json:"transmission" validate:"omitempty,transmission"`
+	Mileage      *int                `json:"mileage" validate:"omitempty,gte=0"`
+	Engine       *domain.EngineInfo  `json:"engine"`
+	Battery      *domain.BatteryInfo `json:"battery"`
+	Status       *string             `json:"status" validate:"omitempty,vehiclestatus"`
+	Metadata     map[string]string   `json:"metadata"` // Replaces all metadata; {} clears it
+	UpdatedBy    string              `json:"updated_by" validate:"required"`
+	// Services may set ?override=true to change the status even though
+	// documents the new status requires are missing; the override is audited
+	Override bool `json:"-" query:"override"`
 }
 
 type UpdateVehicleResponse struct {
-	Vehicle *domain.Vehicle `json:"vehicle"`
+	Vehicle  *domain.Vehicle `json:"vehicle"`
+	Warnings []string        `json:"warnings,omitempty"`
 }
 
 type UpdateVehicleHandler struct {
-	repository Repository
+	repository       Repository
+	duplicatePlate   DuplicatePlatePolicy
+	auditLog         app.AuditLog
+	enforcedStatuses []domain.VehicleStatus
 }
 
-func NewUpdateVehicleHandler(repository Repository) *UpdateVehicleHandler {
+// NewUpdateVehicleHandler builds the handler. A vehicle can only be moved to
+// one of enforcedStatuses while it has every document that status requires.
+func NewUpdateVehicleHandler(repository Repository, duplicatePlate DuplicatePlatePolicy, auditLog app.AuditLog, enforcedStatuses []domain.VehicleStatus) *UpdateVehicleHandler {
 	return &UpdateVehicleHandler{
-		repository: repository,
+		repository:       repository,
+		duplicatePlate:   duplicatePlate,
+		auditLog:         auditLog,
+		enforcedStatuses: enforcedStatuses,
 	}
 }
 
@@ -41,45 +62,135 @@ func (h *UpdateVehicleHandler) Handle(ctx context.Context, req *UpdateVehicleReq
 			"validation": err.Error(),
 		})
 	}
+	if err := domain.ValidateMetadata(req.Metadata); err != nil {
+		return nil, apperrors.NewValidationError("metadata", err.Error())
+	}
+	service, isService := app.ServiceFromContext(ctx)
+	if req.Override && !isService {
+		return nil, apperrors.ErrUnauthorized.WithDetails(map[string]string{
+			"header": "X-API-Key",
+		})
+	}
 
-	vehicle, err := h.repository.GetVehicle(ctx, req.ID)
+	// The changed fields are only known once the update ran, the entry is
+	// completed with them
+	audit, err := beginAudit(ctx, h.auditLog, app.AuditEntry{
+		VehicleID: req.ID,
+		Action:    app.AuditActionUpdate,
+		Actor:     req.UpdatedBy,
+	})
 	if err != nil {
 		return nil, err
 	}
+	defer audit.abort(ctx)
 
-	// Update only provided fields
-	if req.Color != nil {
-		vehicle.Color = strings.TrimSpace(*req.Color)
-	}
-	if req.LicensePlate != nil {
-		vehicle.LicensePlate = strings.ToUpper(strings.TrimSpace(*req.LicensePlate))
-	}
-	if req.OwnerName != nil {
-		vehicle.OwnerName = strings.TrimSpace(*req.OwnerName)
-	}
-	if req.OwnerEmail != nil {
-		vehicle.OwnerEmail = strings.ToLower(strings.TrimSpace(*req.OwnerEmail))
-	}
-	if req.OwnerPhone != nil {
-		vehicle.OwnerPhone = strings.TrimSpace(*req.OwnerPhone)
-	}
-	if req.Transmission != nil {
-		vehicle.Transmission = *req.Transmission
-	}
-	if req.Mileage != nil {
-		vehicle.Mileage = *req.Mileage
+	var (
+		before   domain.Vehicle
+		warnings []string
+		override *app.AuditOverride
+	)
+	// The update runs again when another write lands first, so everything it
+	// derives is reset from the freshly read vehicle on each attempt
+	vehicle, err := h.repository.UpdateVehicle(ctx, req.ID, func(vehicle *domain.Vehicle) error {
+		// Fields below are reassigned, never modified in place, so a shallow copy keeps the old values
+		before = *vehicle
+		warnings, override = nil, nil
+
+		if req.LicensePlate != nil {
+			plate := domain.NormalizeLicensePlate(*req.LicensePlate)
+			plateWarnings, err := checkDuplicatePlate(ctx, h.repository, h.duplicatePlate, vehicle.OwnerID, plate, vehicle.ID)
+			if err != nil {
+				return err
+			}
+			warnings = plateWarnings
+			vehicle.LicensePlate = plate
+		}
+
+		// Update only provided fields
+		if req.Color != nil {
+			vehicle.Color = strings.TrimSpace(*req.Color)
+		}
+		if req.OwnerName != nil {
+			vehicle.OwnerName = strings.TrimSpace(*req.OwnerName)
+		}
+		if req.OwnerEmail != nil {
+			vehicle.OwnerEmail = strings.ToLower(strings.TrimSpace(*req.OwnerEmail))
+		}
+		if req.OwnerPhone != nil {
+			vehicle.OwnerPhone = strings.TrimSpace(*req.OwnerPhone)
+		}
+		if req.Transmission != nil {
+			vehicle.Transmission = *req.Transmission
+		}
+		if req.Mileage != nil {
+			vehicle.Mileage = *req.Mileage
+		}
+		if req.Status != nil && domain.VehicleStatus(*req.Status) != vehicle.Status {
+			// A theft needs its report and document, which only report-stolen files
+			if domain.VehicleStatus(*req.Status) == domain.VehicleStatusStolen {
+				return apperrors.NewUnprocessableError("status", "report a theft with POST /vehicles/:id/report-stolen")
+			}
+			if err := vehicle.TransitionStatus(domain.VehicleStatus(*req.Status)); err != nil {
+				return apperrors.NewUnprocessableError("status", err.Error())
+			}
+		}
+		if req.Metadata != nil {
+			vehicle.Metadata = req.Metadata
+		}
+		if req.Engine != nil {
+			vehicle.Engine = *req.Engine
+		}
+		if req.Battery != nil {
+			vehicle.Battery = req.Battery
+		}
+		if err := vehicle.ValidatePowertrain(); err != nil {
+			return apperrors.NewUnprocessableError("engine", err.Error())
+		}
+
+		if vehicle.Status != before.Status {
+			missing := h.missingStatusDocuments(vehicle)
+			switch {
+			case len(missing) == 0:
+			case !req.Override:
+				return apperrors.ErrUnprocessableEntity.WithDetails(map[string]any{
+					"field":   "status",
+					"message": fmt.Sprintf("a %s vehicle needs every required document", vehicle.Status),
+					"missing": missing,
+				})
+			default:
+				override = &app.AuditOverride{Rule: statusDocumentsRule, Service: service, Skipped: missing}
+			}
+		}
+
+		vehicle.UpdateTimestamp(req.UpdatedBy)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	if req.Status != nil {
-		vehicle.Status = domain.VehicleStatus(*req.Status)
+
+	changes, err := domain.DiffVehicles(&before, vehicle)
+	if err != nil {
+		// The update is stored, only the list of changed fields is lost
+		audit.complete(ctx)
+		return nil, apperrors.ErrInternalServer.WithCause(err)
 	}
+	audit.entry.Changes, audit.entry.Override = changes, override
+	warnings = append(warnings, audit.complete(ctx)...)
 
-	vehicle.UpdateTimestamp(req.UpdatedBy)
+	return &UpdateVehicleResponse{Vehicle: vehicle, Warnings: warnings}, nil
+}
 
-	if err := h.repository.UpdateVehicle(ctx, vehicle); err != nil {
-		return nil, apperrors.ErrDatabaseQuery.WithCause(err).WithDetails(map[string]string{
-			"operation": "update_vehicle",
-		})
+// missingStatusDocuments lists the documents the vehicle's new status requires
+// but it lacks, when that status is enforced
+func (h *UpdateVehicleHandler) missingStatusDocuments(vehicle *domain.Vehicle) []string {
+	if !slices.Contains(h.enforcedStatuses, vehicle.Status) {
+		return nil
 	}
 
-	return &UpdateVehicleResponse{Vehicle: vehicle}, nil
+	var missing []string
+	for _, docType := range vehicle.MissingDocumentsForStatus(vehicle.Status) {
+		missing = append(missing, string(docType))
+	}
+	return missing
 }
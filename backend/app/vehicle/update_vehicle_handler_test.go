@@ -0,0 +1,117 @@
+package vehicle
+
+import (
+	"context"
+	"errors"
+	"microservicetest/app"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"slices"
+	"testing"
+)
+
+func TestUpdateVehicleHandler_StatusDocuments(t *testing.T) {
+	updates := 0
+	mockRepo := &MockRepository{
+		GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+			return &domain.Vehicle{ID: id, OwnerID: "owner-123", Status: domain.VehicleStatusActive, Documents: []domain.Document{
+				{ID: "DOC_1", Type: domain.DocumentTypeTitle},
+			}}, nil
+		},
+		UpdateVehicleFunc: func(ctx context.Context, vehicle *domain.Vehicle) error {
+			updates++
+			return nil
+		},
+	}
+	auditLog := &MockAuditLog{}
+	handler := NewUpdateVehicleHandler(mockRepo, DuplicatePlateReject, auditLog, []domain.VehicleStatus{domain.VehicleStatusSold})
+	sold, scrapped := string(domain.VehicleStatusSold), string(domain.VehicleStatusScrapped)
+	serviceCtx := app.WithService(context.Background(), "backoffice")
+
+	// Selling without the purchase agreement is refused
+	_, err := handler.Handle(context.Background(), &UpdateVehicleRequest{ID: "VEH_1", Status: &sold, UpdatedBy: "alice"})
+	var appErr *apperrors.AppError
+	if !errors.As(err, &appErr) || appErr.Code != apperrors.ErrUnprocessableEntity.Code {
+		t.Fatalf("Expected UNPROCESSABLE_ENTITY, got %v", err)
+	}
+	details := appErr.Details.(map[string]any)
+	if missing := details["missing"].([]string); !slices.Equal(missing, []string{"purchase_agreement"}) {
+		t.Errorf("Expected purchase_agreement to be missing, got %v", missing)
+	}
+
+	// Statuses that are not enforced are let through
+	if _, err := handler.Handle(context.Background(), &UpdateVehicleRequest{ID: "VEH_1", Status: &scrapped, UpdatedBy: "alice"}); err != nil {
+		t.Errorf("Expected no error for an unenforced status, got %v", err)
+	}
+
+	// Only services may override
+	_, err = handler.Handle(context.Background(), &UpdateVehicleRequest{ID: "VEH_1", Status: &sold, UpdatedBy: "alice", Override: true})
+	if !errors.Is(err, apperrors.ErrUnauthorized) {
+		t.Errorf("Expected UNAUTHORIZED for an override without an API key, got %v", err)
+	}
+
+	auditLog.Entries = nil
+	if _, err := handler.Handle(serviceCtx, &UpdateVehicleRequest{ID: "VEH_1", Status: &sold, UpdatedBy: "alice", Override: true}); err != nil {
+		t.Fatalf("Expected the override to be accepted, got %v", err)
+	}
+	if updates != 2 {
+		t.Errorf("Expected 2 updates, got %d", updates)
+	}
+	if len(auditLog.Entries) != 1 || auditLog.Entries[0].Override == nil {
+		t.Fatalf("Expected the override to be audited, got %+v", auditLog.Entries)
+	}
+	override := auditLog.Entries[0].Override
+	if override.Rule != statusDocumentsRule || override.Service != "backoffice" || !slices.Equal(override.Skipped, []string{"purchase_agreement"}) {
+		t.Errorf("Unexpected override %+v", override)
+	}
+}
+
+func TestUpdateVehicleHandler_StatusTransitions(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     domain.VehicleStatus
+		to       domain.VehicleStatus
+		expected error
+	}{
+		{"active to inactive", domain.VehicleStatusActive, domain.VehicleStatusInactive, nil},
+		{"stolen recovered", domain.VehicleStatusStolen, domain.VehicleStatusActive, nil},
+		{"unchanged final status", domain.VehicleStatusSold, domain.VehicleStatusSold, nil},
+		{"sold back to active", domain.VehicleStatusSold, domain.VehicleStatusActive, apperrors.ErrUnprocessableEntity},
+		{"scrapped back to active", domain.VehicleStatusScrapped, domain.VehicleStatusActive, apperrors.ErrUnprocessableEntity},
+		{"stolen without a report", domain.VehicleStatusActive, domain.VehicleStatusStolen, apperrors.ErrUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stored *domain.Vehicle
+			mockRepo := &MockRepository{
+				GetVehicleFunc: func(ctx context.Context, id string) (*domain.Vehicle, error) {
+					return &domain.Vehicle{ID: id, OwnerID: "owner-123", Status: tt.from}, nil
+				},
+				UpdateVehicleFunc: func(ctx context.Context, vehicle *domain.Vehicle) error {
+					stored = vehicle
+					return nil
+				},
+			}
+			handler := NewUpdateVehicleHandler(mockRepo, DuplicatePlateReject, &MockAuditLog{}, nil)
+			status := string(tt.to)
+
+			_, err := handler.Handle(context.Background(), &UpdateVehicleRequest{ID: "VEH_1", Status: &status, UpdatedBy: "alice"})
+			if tt.expected != nil {
+				if !errors.Is(err, tt.expected) {
+					t.Fatalf("Expected %v, got %v", tt.expected, err)
+				}
+				if stored != nil {
+					t.Errorf("Expected the vehicle not to be stored, got status %s", stored.Status)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if stored == nil || stored.Status != tt.to {
+				t.Errorf("Expected status %s to be stored, got %+v", tt.to, stored)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package vehicle
+
+import (
+	"errors"
+	"microservicetest/app"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// UpsertVehicleRequest carries the same body as a create; the VIN comes from the path
+type UpsertVehicleRequest struct {
+	CreateVehicleRequest
+}
+
+type UpsertVehicleResponse struct {
+	Vehicle  *domain.Vehicle `json:"vehicle"`
+	Created  bool            `json:"created"`
+	Warnings []string        `json:"warnings,omitempty"`
+}
+
+// HTTPStatus answers 201 Created when the upsert created the vehicle
+func (r *UpsertVehicleResponse) HTTPStatus() int {
+	if r.Created {
+		return fiber.StatusCreated
+	}
+	return fiber.StatusOK
+}
+
+// Location points a created vehicle to its canonical URL
+func (r *UpsertVehicleResponse) Location() string {
+	if !r.Created {
+		return ""
+	}
+	return "/vehicles/" + r.Vehicle.ID
+}
+
+type UpsertVehicleHandler struct {
+	repository     Repository
+	duplicatePlate DuplicatePlatePolicy
+	auditLog       app.AuditLog
+}
+
+func NewUpsertVehicleHandler(repository Repository, duplicatePlate DuplicatePlatePolicy, auditLog app.AuditLog) *UpsertVehicleHandler {
+	return &UpsertVehicleHandler{
+		repository:     repository,
+		duplicatePlate: duplicatePlate,
+		auditLog:       auditLog,
+	}
+}
+
+func (h *UpsertVehicleHandler) Handle(ctx *fiber.Ctx, req *UpsertVehicleRequest) (*UpsertVehicleResponse, error) {
+	req.VIN = ctx.Params("vin") // param:"vin" mapping
+	req.normalize()
+
+	if err := validator.Validate(req); err != nil {
+		return nil, apperrors.ErrInvalidInput.WithDetails(map[string]string{
+			"validation": err.Error(),
+		})
+	}
+	if err := domain.ValidateMetadata(req.Metadata); err != nil {
+		return nil, apperrors.NewValidationError("metadata", err.Error())
+	}
+
+	vehicle := newVehicleFromRequest(&req.CreateVehicleRequest)
+
+	// An update keeps the stored owner and fuel type, so the plate is checked
+	// against that owner's other vehicles and the powertrain against that fuel type
+	ownerID, excludeID, result := vehicle.OwnerID, "", vehicle
+	existing, err := h.repository.GetVehicleByVIN(ctx.UserContext(), vehicle.VIN)
+	switch {
+	case err == nil:
+		ownerID, excludeID = existing.OwnerID, existing.ID
+		result = existing
+		result.ApplyMutableFields(vehicle)
+	case !errors.Is(err, apperrors.ErrResourceNotFound):
+		return nil, err
+	}
+	if err := result.ValidatePowertrain(); err != nil {
+		return nil, apperrors.NewUnprocessableError("engine", err.Error())
+	}
+	warnings, err := checkDuplicatePlate(ctx.UserContext(), h.repository, h.duplicatePlate, ownerID, vehicle.LicensePlate, excludeID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The entry names what the lookup found, it is corrected should the vehicle
+	// have been created or deleted since
+	audit, err := beginAudit(ctx.UserContext(), h.auditLog, app.AuditEntry{
+		VehicleID: result.ID,
+		Action:    upsertAuditAction(excludeID == ""),
+		Actor:     req.CreatedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer audit.abort(ctx.UserContext())
+
+	vehicle, created, err := h.repository.UpsertVehicleByVIN(ctx.UserContext(), vehicle)
+	if err != nil {
+		return nil, err
+	}
+
+	audit.entry.VehicleID, audit.entry.Action = vehicle.ID, upsertAuditAction(created)
+	warnings = append(warnings, audit.complete(ctx.UserContext())...)
+
+	return &UpsertVehicleResponse{
+		Vehicle:  vehicle,
+		Created:  created,
+		Warnings: warnings,
+	}, nil
+}
+
+// upsertAuditAction records an upsert as the create or update it was
+func upsertAuditAction(created bool) string {
+	if created {
+		return app.AuditActionCreate
+	}
+	return app.AuditActionUpdate
+}
@@ -0,0 +1,213 @@
+package vehicle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"microservicetest/app"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/response"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func newUpsertVehicleApp(handler *UpsertVehicleHandler) *fiber.App {
+	app := fiber.New()
+	app.Put("/vehicles/vin/:vin", func(c *fiber.Ctx) error {
+		var req UpsertVehicleRequest
+		if err := c.BodyParser(&req); err != nil {
+			return err
+		}
+		res, err := handler.Handle(c, &req)
+		if err != nil {
+			return apperrors.HandleError(c, err)
+		}
+		return response.Send(c, res)
+	})
+	return app
+}
+
+func putVehicle(t *testing.T, app *fiber.App, body map[string]any) (*http.Response, *UpsertVehicleResponse) {
+	t.Helper()
+	payload, _ := json.Marshal(body)
+	req := httptest.NewRequest("PUT", "/vehicles/vin/1HGBH41JXMN109186", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var res UpsertVehicleResponse
+	_ = json.NewDecoder(resp.Body).Decode(&res)
+	return resp, &res
+}
+
+func upsertBody() map[string]any {
+	return map[string]any{
+		"make":        "Honda",
+		"model":       "Civic",
+		"year":        2021,
+		"owner_id":    "owner-123",
+		"owner_name":  "Ayşe Yılmaz",
+		"owner_email": "ayse@example.com",
+		"fuel_type":   "gasoline",
+		"created_by":  "alice",
+	}
+}
+
+func TestUpsertVehicleHandler_Create(t *testing.T) {
+	var upserted *domain.Vehicle
+	mockRepo := &MockRepository{
+		UpsertVehicleByVINFunc: func(ctx context.Context, vehicle *domain.Vehicle) (*domain.Vehicle, bool, error) {
+			upserted = vehicle
+			return vehicle, true, nil
+		},
+	}
+
+	resp, res := putVehicle(t, newUpsertVehicleApp(NewUpsertVehicleHandler(mockRepo, DuplicatePlateReject, nil)), upsertBody())
+
+	if resp.StatusCode != fiber.StatusCreated || !res.Created {
+		t.Fatalf("Expected 201 Created, got %d: %+v", resp.StatusCode, res)
+	}
+	if upserted == nil || upserted.VIN != "1HGBH41JXMN109186" || upserted.ID == "" || upserted.OwnerID != "owner-123" {
+		t.Fatalf("Expected a new vehicle for the VIN in the path, got %+v", upserted)
+	}
+	if location := resp.Header.Get("Location"); location != "/vehicles/"+upserted.ID {
+		t.Errorf("Expected Location /vehicles/%s, got %q", upserted.ID, location)
+	}
+}
+
+func TestUpsertVehicleHandler_Update(t *testing.T) {
+	mockRepo := &MockRepository{
+		GetVehicleByVINFunc: func(ctx context.Context, vin string) (*domain.Vehicle, error) {
+			return &domain.Vehicle{ID: "VEH_1", VIN: vin, OwnerID: "owner-123", FuelType: domain.FuelTypeGasoline}, nil
+		},
+		UpsertVehicleByVINFunc: func(ctx context.Context, vehicle *domain.Vehicle) (*domain.Vehicle, bool, error) {
+			stored := &domain.Vehicle{ID: "VEH_1", VIN: vehicle.VIN, OwnerID: "owner-123", FuelType: domain.FuelTypeGasoline}
+			stored.ApplyMutableFields(vehicle)
+			return stored, false, nil
+		},
+	}
+
+	body := upsertBody()
+	body["color"] = "Blue"
+	resp, res := putVehicle(t, newUpsertVehicleApp(NewUpsertVehicleHandler(mockRepo, DuplicatePlateReject, nil)), body)
+
+	if resp.StatusCode != fiber.StatusOK || res.Created {
+		t.Fatalf("Expected 200 OK, got %d: %+v", resp.StatusCode, res)
+	}
+	if res.Vehicle == nil || res.Vehicle.ID != "VEH_1" || res.Vehicle.Color != "Blue" {
+		t.Errorf("Expected the stored vehicle with the new color, got %+v", res.Vehicle)
+	}
+	if location := resp.Header.Get("Location"); location != "" {
+		t.Errorf("Expected no Location on update, got %q", location)
+	}
+}
+
+func TestUpsertVehicleHandler_RecordsAuditEntry(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		created bool
+		action  string
+		status  int
+	}{
+		{"create", true, app.AuditActionCreate, fiber.StatusCreated},
+		{"update", false, app.AuditActionUpdate, fiber.StatusOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := &MockRepository{
+				UpsertVehicleByVINFunc: func(ctx context.Context, vehicle *domain.Vehicle) (*domain.Vehicle, bool, error) {
+					vehicle.ID = "VEH_1"
+					return vehicle, tc.created, nil
+				},
+			}
+			auditLog := &MockAuditLog{}
+
+			resp, _ := putVehicle(t, newUpsertVehicleApp(NewUpsertVehicleHandler(mockRepo, DuplicatePlateReject, auditLog)), upsertBody())
+			if resp.StatusCode != tc.status {
+				t.Fatalf("Expected status %d, got %d", tc.status, resp.StatusCode)
+			}
+			if len(auditLog.Entries) != 1 {
+				t.Fatalf("Expected 1 audit entry, got %d", len(auditLog.Entries))
+			}
+			entry := auditLog.Entries[0]
+			if entry.Action != tc.action || entry.VehicleID != "VEH_1" || entry.Actor != "alice" {
+				t.Errorf("Unexpected audit entry: %+v", entry)
+			}
+		})
+	}
+}
+
+func TestUpsertVehicleHandler_UpdateChecksDuplicatePlate(t *testing.T) {
+	var gotOwnerID, gotExcludeID string
+	mockRepo := &MockRepository{
+		GetVehicleByVINFunc: func(ctx context.Context, vin string) (*domain.Vehicle, error) {
+			return &domain.Vehicle{ID: "VEH_1", VIN: vin, OwnerID: "owner-stored"}, nil
+		},
+		FindActiveVehicleByOwnerPlateFunc: func(ctx context.Context, ownerID string, plate string, excludeID string) (string, bool, error) {
+			gotOwnerID, gotExcludeID = ownerID, excludeID
+			return "VEH_2", true, nil
+		},
+		UpsertVehicleByVINFunc: func(ctx context.Context, vehicle *domain.Vehicle) (*domain.Vehicle, bool, error) {
+			t.Error("Expected no upsert with a duplicate plate")
+			return vehicle, false, nil
+		},
+	}
+
+	body := upsertBody()
+	body["license_plate"] = "34 ABC 123"
+	resp, _ := putVehicle(t, newUpsertVehicleApp(NewUpsertVehicleHandler(mockRepo, DuplicatePlateReject, nil)), body)
+
+	if resp.StatusCode != fiber.StatusConflict {
+		t.Errorf("Expected the duplicate plate to be rejected, got %d", resp.StatusCode)
+	}
+	if gotOwnerID != "owner-stored" || gotExcludeID != "VEH_1" {
+		t.Errorf("Expected the stored owner's other vehicles to be checked, got owner %q excluding %q", gotOwnerID, gotExcludeID)
+	}
+}
+
+func TestUpsertVehicleHandler_UpdateValidatesStoredFuelType(t *testing.T) {
+	tests := []struct {
+		name       string
+		stored     domain.FuelType
+		powertrain map[string]any
+		wantOK     bool
+	}{
+		{"engine on a stored electric vehicle", domain.FuelTypeElectric, map[string]any{"engine": map[string]any{"displacement": 1600, "cylinders": 4}}, false},
+		{"battery on a stored electric vehicle", domain.FuelTypeElectric, map[string]any{"battery": map[string]any{"capacity_kwh": 75}}, true},
+		{"battery on a stored gasoline vehicle", domain.FuelTypeGasoline, map[string]any{"fuel_type": "electric", "battery": map[string]any{"capacity_kwh": 75}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upserted := false
+			mockRepo := &MockRepository{
+				GetVehicleByVINFunc: func(ctx context.Context, vin string) (*domain.Vehicle, error) {
+					return &domain.Vehicle{ID: "VEH_1", VIN: vin, OwnerID: "owner-123", FuelType: tt.stored}, nil
+				},
+				UpsertVehicleByVINFunc: func(ctx context.Context, vehicle *domain.Vehicle) (*domain.Vehicle, bool, error) {
+					upserted = true
+					return vehicle, false, nil
+				},
+			}
+
+			body := upsertBody()
+			for key, value := range tt.powertrain {
+				body[key] = value
+			}
+			resp, _ := putVehicle(t, newUpsertVehicleApp(NewUpsertVehicleHandler(mockRepo, DuplicatePlateReject, nil)), body)
+
+			if tt.wantOK && (resp.StatusCode != fiber.StatusOK || !upserted) {
+				t.Errorf("Expected the update to be applied, got %d", resp.StatusCode)
+			}
+			if !tt.wantOK && (resp.StatusCode != fiber.StatusUnprocessableEntity || upserted) {
+				t.Errorf("Expected the powertrain to be rejected, got %d", resp.StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package vehicle
+
+import (
+	"math"
+	"microservicetest/domain"
+)
+
+const (
+	ValuationDepreciating = "depreciating"
+	ValuationAppreciating = "appreciating"
+)
+
+// ValuationModel estimates the current value of a vehicle. Implementations
+// report the factors they used so an estimate can be explained.
+type ValuationModel interface {
+	Estimate(vehicle *domain.Vehicle) Valuation
+}
+
+type Valuation struct {
+	EstimatedValue float64          `json:"estimated_value"`
+	Trend          string           `json:"trend"` // depreciating, or appreciating for vintage vehicles
+	Factors        ValuationFactors `json:"factors"`
+}
+
+type ValuationFactors struct {
+	Model         string  `json:"model"`
+	BasePrice     float64 `json:"base_price"`
+	AgeYears      int     `json:"age_years"`
+	Mileage       int     `json:"mileage"`
+	Vintage       bool    `json:"vintage"`
+	AgeFactor     float64 `json:"age_factor"`
+	MileageFactor float64 `json:"mileage_factor"`
+	VintageFactor float64 `json:"vintage_factor"`
+	Floored       bool    `json:"floored"` // The residual floor replaced the age and mileage factors
+}
+
+// DepreciationModel compounds AnnualDepreciation over the vehicle's age and
+// takes MileageDepreciation off per 10,000 km, never going below
+// ResidualFloor of the base price. Vintage vehicles stop depreciating at
+// domain.VintageAge and gain VintageAppreciation per year after that.
+type DepreciationModel struct {
+	BasePrice           float64
+	AnnualDepreciation  float64
+	MileageDepreciation float64
+	ResidualFloor       float64
+	VintageAppreciation float64
+}
+
+func (m DepreciationModel) Estimate(vehicle *domain.Vehicle) Valuation {
+	age := max(vehicle.CalculateAge(), 0)
+	vintage := vehicle.IsVintage()
+
+	depreciatingYears, vintageFactor := age, 1.0
+	if vintage {
+		depreciatingYears = domain.VintageAge
+		vintageFactor = math.Pow(1+m.VintageAppreciation, float64(age-domain.VintageAge))
+	}
+
+	ageFactor := math.Pow(1-m.AnnualDepreciation, float64(depreciatingYears))
+	mileageFactor := max(1-m.MileageDepreciation*float64(vehicle.Mileage)/10000, 0)
+
+	residual := ageFactor * mileageFactor
+	floored := residual < m.ResidualFloor
+	if floored {
+		residual = m.ResidualFloor
+	}
+
+	trend := ValuationDepreciating
+	if vintage {
+		trend = ValuationAppreciating
+	}
+
+	return Valuation{
+		EstimatedValue: math.Round(m.BasePrice*residual*vintageFactor*100) / 100,
+		Trend:          trend,
+		Factors: ValuationFactors{
+			Model:         "depreciation",
+			BasePrice:     m.BasePrice,
+			AgeYears:      age,
+			Mileage:       vehicle.Mileage,
+			Vintage:       vintage,
+			AgeFactor:     ageFactor,
+			MileageFactor: mileageFactor,
+			VintageFactor: vintageFactor,
+			Floored:       floored,
+		},
+	}
+}
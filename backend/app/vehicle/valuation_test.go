@@ -0,0 +1,48 @@
+package vehicle
+
+import (
+	"microservicetest/domain"
+	"testing"
+	"time"
+)
+
+func TestDepreciationModel_Estimate(t *testing.T) {
+	model := DepreciationModel{
+		BasePrice:           30000,
+		AnnualDepreciation:  0.15,
+		MileageDepreciation: 0.02,
+		ResidualFloor:       0.1,
+		VintageAppreciation: 0.03,
+	}
+	year := time.Now().Year()
+
+	tests := []struct {
+		name     string
+		age      int
+		mileage  int
+		expected float64
+		trend    string
+		floored  bool
+	}{
+		{"new", 0, 0, 30000, ValuationDepreciating, false},
+		{"two years with mileage", 2, 20000, 20808, ValuationDepreciating, false},
+		{"old reaches the floor", 20, 150000, 3000, ValuationDepreciating, true},
+		{"vintage appreciates", 30, 0, 3477.82, ValuationAppreciating, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valuation := model.Estimate(&domain.Vehicle{Year: year - tt.age, Mileage: tt.mileage})
+
+			if valuation.EstimatedValue != tt.expected {
+				t.Errorf("Expected value %v, got %v", tt.expected, valuation.EstimatedValue)
+			}
+			if valuation.Trend != tt.trend {
+				t.Errorf("Expected trend %s, got %s", tt.trend, valuation.Trend)
+			}
+			if valuation.Factors.Floored != tt.floored || valuation.Factors.AgeYears != tt.age {
+				t.Errorf("Unexpected factors %+v", valuation.Factors)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package vehicle
+
+import (
+	"encoding/json"
+	"fmt"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// vehicleFields is the whitelist for ?fields=, the top-level JSON names of domain.Vehicle
+var vehicleFields = jsonFieldNames(reflect.TypeOf(domain.Vehicle{}))
+
+func jsonFieldNames(t reflect.Type) map[string]struct{} {
+	names := make(map[string]struct{}, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names[name] = struct{}{}
+		}
+	}
+	return names
+}
+
+// parseFields splits a comma separated field list and checks it against the whitelist.
+// An empty list means the full object.
+func parseFields(raw string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	var fields, unknown []string
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if _, ok := vehicleFields[field]; !ok {
+			unknown = append(unknown, field)
+			continue
+		}
+		fields = append(fields, field)
+	}
+
+	if len(unknown) > 0 {
+		allowed := make([]string, 0, len(vehicleFields))
+		for name := range vehicleFields {
+			allowed = append(allowed, name)
+		}
+		sort.Strings(allowed)
+		return nil, apperrors.NewValidationError("fields", fmt.Sprintf("unknown fields %s, allowed: %s",
+			strings.Join(unknown, ","), strings.Join(allowed, ",")))
+	}
+
+	return fields, nil
+}
+
+// selectFields returns only the given top-level fields of the vehicle's JSON form
+func selectFields(vehicle *domain.Vehicle, fields []string) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(vehicle)
+	if err != nil {
+		return nil, apperrors.ErrInternalServer.WithCause(err)
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, apperrors.ErrInternalServer.WithCause(err)
+	}
+
+	selected := make(map[string]json.RawMessage, len(fields))
+	for _, field := range fields {
+		if value, ok := all[field]; ok {
+			selected[field] = value
+		}
+	}
+	return selected, nil
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"slices"
+)
+
+// FieldChange is a top-level vehicle field that differs between two versions,
+// with its old and new JSON values (null when absent)
+type FieldChange struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old"`
+	New   json.RawMessage `json:"new"`
+}
+
+// auditIgnoredFields change on every write and say nothing about what changed
+var auditIgnoredFields = []string{"updated_at", "updated_by"}
+
+// DiffVehicles compares the JSON form of two versions of a vehicle field by
+// field and returns the changes ordered by field name
+func DiffVehicles(before *Vehicle, after *Vehicle) ([]FieldChange, error) {
+	oldFields, err := jsonFields(before)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := jsonFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := slices.Sorted(maps.Keys(oldFields))
+	for field := range newFields {
+		if _, ok := oldFields[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	slices.Sort(fields)
+
+	var changes []FieldChange
+	for _, field := range fields {
+		if slices.Contains(auditIgnoredFields, field) {
+			continue
+		}
+		oldValue, newValue := oldFields[field], newFields[field]
+		if bytes.Equal(oldValue, newValue) {
+			continue
+		}
+		changes = append(changes, FieldChange{Field: field, Old: nullIfMissing(oldValue), New: nullIfMissing(newValue)})
+	}
+	return changes, nil
+}
+
+func jsonFields(vehicle *Vehicle) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(vehicle)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(data, &fields)
+	return fields, err
+}
+
+func nullIfMissing(value json.RawMessage) json.RawMessage {
+	if value == nil {
+		return json.RawMessage("null")
+	}
+	return value
+}
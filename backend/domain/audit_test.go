@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiffVehicles(t *testing.T) {
+	before := &Vehicle{ID: "VEH_1", Color: "red", Mileage: 100, UpdatedBy: "alice", UpdatedAt: time.Now()}
+	after := *before
+	after.Color = "blue"
+	after.Metadata = map[string]string{"department": "sales"}
+	after.UpdatedBy = "bob"
+	after.UpdatedAt = time.Now().Add(time.Minute)
+
+	changes, err := DiffVehicles(before, &after)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 changes, got %+v", changes)
+	}
+	if c := changes[0]; c.Field != "color" || string(c.Old) != `"red"` || string(c.New) != `"blue"` {
+		t.Errorf("Unexpected color change %+v", c)
+	}
+	if c := changes[1]; c.Field != "metadata" || string(c.Old) != "null" || string(c.New) != `{"department":"sales"}` {
+		t.Errorf("Unexpected metadata change %+v", c)
+	}
+}
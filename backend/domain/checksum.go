@@ -0,0 +1,13 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Checksum is the hex SHA-256 of a stored file's bytes, recorded at upload so
+// exports can tell a file that changed or got corrupted in storage
+func Checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+)
+
+// statusRequiredDocuments lists the paperwork a vehicle must have on file in
+// a status. It is independent of RequiredDocumentTypes, which only the
+// completeness score counts. A sale needs the purchase agreement and the
+// title; statuses without an entry need nothing.
+var statusRequiredDocuments = map[VehicleStatus][]DocumentType{
+	VehicleStatusSold: {DocumentTypePurchaseAgreement, DocumentTypeTitle},
+}
+
+// defaultEnforcedStatuses are the statuses a vehicle can only be moved to with
+// every document the status requires, when the configuration lists none
+var defaultEnforcedStatuses = []VehicleStatus{VehicleStatusSold}
+
+// DefaultEnforcedStatuses returns the statuses whose required documents are
+// enforced by default
+func DefaultEnforcedStatuses() []VehicleStatus {
+	return slices.Clone(defaultEnforcedStatuses)
+}
+
+// StatusRequiredDocuments returns the document types a vehicle in status must have
+func StatusRequiredDocuments(status VehicleStatus) []DocumentType {
+	return statusRequiredDocuments[status]
+}
+
+// MissingDocumentsForStatus returns the document types status requires that
+// the vehicle has no document of
+func (v *Vehicle) MissingDocumentsForStatus(status VehicleStatus) []DocumentType {
+	missing := []DocumentType{}
+	for _, docType := range statusRequiredDocuments[status] {
+		if len(v.GetDocumentsByType(docType)) == 0 {
+			missing = append(missing, docType)
+		}
+	}
+	return missing
+}
+
+// SetStatusRequiredDocuments replaces the documents each status requires;
+// statuses left out require none. Register extra document types first. Like
+// SetRequiredDocumentTypes it must only be called at startup.
+func SetStatusRequiredDocuments(required map[string][]string) error {
+	byStatus := make(map[VehicleStatus][]DocumentType, len(required))
+	for status, types := range required {
+		if !slices.Contains(AllVehicleStatuses(), VehicleStatus(status)) {
+			return fmt.Errorf("unknown vehicle status %q", status)
+		}
+		docTypes := make([]DocumentType, 0, len(types))
+		for _, t := range types {
+			if !IsValidDocumentType(t) {
+				return fmt.Errorf("unknown document type %q required for status %s", t, status)
+			}
+			docTypes = append(docTypes, DocumentType(t))
+		}
+		byStatus[VehicleStatus(status)] = docTypes
+	}
+	statusRequiredDocuments = byStatus
+	return nil
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMissingDocumentsForStatus(t *testing.T) {
+	vehicle := &Vehicle{Documents: []Document{{ID: "DOC_1", Type: DocumentTypeTitle}}}
+
+	if missing := vehicle.MissingDocumentsForStatus(VehicleStatusSold); !reflect.DeepEqual(missing, []DocumentType{DocumentTypePurchaseAgreement}) {
+		t.Errorf("Expected the purchase agreement to be missing, got %v", missing)
+	}
+	if missing := vehicle.MissingDocumentsForStatus(VehicleStatusActive); len(missing) != 0 {
+		t.Errorf("Expected nothing missing for active, got %v", missing)
+	}
+}
+
+func TestSetStatusRequiredDocuments(t *testing.T) {
+	defer func(required map[VehicleStatus][]DocumentType) { statusRequiredDocuments = required }(statusRequiredDocuments)
+
+	if err := SetStatusRequiredDocuments(map[string][]string{"exported": {"title"}}); err == nil {
+		t.Error("Expected an unknown status to be rejected")
+	}
+	if err := SetStatusRequiredDocuments(map[string][]string{"sold": {"passport"}}); err == nil {
+		t.Error("Expected an unknown document type to be rejected")
+	}
+	if len(StatusRequiredDocuments(VehicleStatusSold)) != 2 {
+		t.Errorf("Expected the defaults to be kept, got %v", StatusRequiredDocuments(VehicleStatusSold))
+	}
+
+	if err := SetStatusRequiredDocuments(map[string][]string{"scrapped": {"title"}}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(StatusRequiredDocuments(VehicleStatusSold)) != 0 || len(StatusRequiredDocuments(VehicleStatusScrapped)) != 1 {
+		t.Error("Expected the configuration to replace the defaults")
+	}
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// fileExtensions maps every MIME type accepted for documents and pictures to
+// the canonical extension used when naming blobs and downloads
+var fileExtensions = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"image/heic":      ".heic",
+	"image/heif":      ".heif",
+}
+
+// FileExtension returns the canonical extension of an accepted MIME type;
+// ok is false when files of the type are not accepted
+func FileExtension(mimeType string) (ext string, ok bool) {
+	ext, ok = fileExtensions[strings.ToLower(mimeType)]
+	return ext, ok
+}
+
+// AllowedMimeTypes returns the accepted MIME types in alphabetical order
+func AllowedMimeTypes() []string {
+	return slices.Sorted(maps.Keys(fileExtensions))
+}
+
+// SetAllowedFileTypes replaces the accepted MIME types and their extensions.
+// Like RegisterDocumentTypes it must only be called at startup.
+func SetAllowedFileTypes(types map[string]string) error {
+	allowed := make(map[string]string, len(types))
+	for mimeType, ext := range types {
+		mimeType, ext = strings.ToLower(mimeType), strings.ToLower(ext)
+		if major, minor, ok := strings.Cut(mimeType, "/"); !ok || major == "" || minor == "" {
+			return fmt.Errorf("invalid MIME type %q", mimeType)
+		}
+		if len(ext) < 2 || ext[0] != '.' || strings.ContainsAny(ext[1:], "./\\") {
+			return fmt.Errorf("invalid extension %q for %s, expected a dot and a name such as .pdf", ext, mimeType)
+		}
+		allowed[mimeType] = ext
+	}
+	if len(allowed) == 0 {
+		return fmt.Errorf("at least one file type must be allowed")
+	}
+	fileExtensions = allowed
+	return nil
+}
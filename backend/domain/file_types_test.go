@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestFileExtension(t *testing.T) {
+	tests := []struct {
+		mimeType string
+		ext      string
+		ok       bool
+	}{
+		{"application/pdf", ".pdf", true},
+		{"image/jpeg", ".jpg", true},
+		{"image/png", ".png", true},
+		{"image/gif", ".gif", true},
+		{"image/webp", ".webp", true},
+		{"image/heic", ".heic", true},
+		{"image/heif", ".heif", true},
+		{"IMAGE/PNG", ".png", true},
+		{"text/html", "", false},
+		{"application/zip", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mimeType, func(t *testing.T) {
+			ext, ok := FileExtension(tt.mimeType)
+			if ext != tt.ext || ok != tt.ok {
+				t.Errorf("Expected %q, %v, got %q, %v", tt.ext, tt.ok, ext, ok)
+			}
+		})
+	}
+}
+
+func TestSetAllowedFileTypes(t *testing.T) {
+	defer func(types map[string]string) { fileExtensions = types }(maps.Clone(fileExtensions))
+
+	if err := SetAllowedFileTypes(map[string]string{"application/pdf": ".pdf", "Image/HEIC": ".HEIC"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if ext, ok := FileExtension("image/heic"); !ok || ext != ".heic" {
+		t.Errorf("Expected .heic, got %q, %v", ext, ok)
+	}
+	if _, ok := FileExtension("image/png"); ok {
+		t.Error("Expected image/png to be replaced")
+	}
+
+	for _, invalid := range []map[string]string{
+		{},
+		{"pdf": ".pdf"},
+		{"application/pdf": "pdf"},
+		{"application/pdf": ".tar.gz"},
+		{"application/pdf": "./pdf"},
+	} {
+		if err := SetAllowedFileTypes(invalid); err == nil {
+			t.Errorf("Expected %v to be rejected", invalid)
+		}
+	}
+}
@@ -1,6 +1,17 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// maxGPSClockSkew is how far in the future a device timestamp may lie
+const maxGPSClockSkew = 24 * time.Hour
+
+// earthRadiusKm is the mean Earth radius used for distance calculations
+const earthRadiusKm = 6371.0
 
 // GPSData represents GPS location data from IoT devices
 type GPSData struct {
@@ -16,6 +27,27 @@ func (g *GPSData) GetTimestamp() time.Time {
 	return time.Unix(int64(g.Timestamp), 0)
 }
 
+// Validate rejects points with out-of-range coordinates, the 0,0 "null island"
+// devices report without a fix, and missing or far-future timestamps
+func (g *GPSData) Validate() error {
+	if math.IsNaN(g.Latitude) || g.Latitude < -90 || g.Latitude > 90 {
+		return fmt.Errorf("latitude %v is out of range [-90, 90]", g.Latitude)
+	}
+	if math.IsNaN(g.Longitude) || g.Longitude < -180 || g.Longitude > 180 {
+		return fmt.Errorf("longitude %v is out of range [-180, 180]", g.Longitude)
+	}
+	if g.Latitude == 0 && g.Longitude == 0 {
+		return fmt.Errorf("coordinates 0,0 are not a valid fix")
+	}
+	if math.IsNaN(g.Timestamp) || g.Timestamp <= 0 {
+		return fmt.Errorf("timestamp %v must be a positive unix time", g.Timestamp)
+	}
+	if g.GetTimestamp().After(time.Now().Add(maxGPSClockSkew)) {
+		return fmt.Errorf("timestamp %v is too far in the future", g.Timestamp)
+	}
+	return nil
+}
+
 // GPSDataResponse represents GPS data in API responses with formatted timestamp
 type GPSDataResponse struct {
 	ID        string    `json:"id"`
@@ -23,6 +55,9 @@ type GPSDataResponse struct {
 	Latitude  float64   `json:"latitude"`
 	Longitude float64   `json:"longitude"`
 	Timestamp time.Time `json:"timestamp"`
+	// Derived from the previous point, nil on the first point or when it cannot be computed
+	SpeedKmh       *float64 `json:"speed_kmh,omitempty"`
+	HeadingDegrees *float64 `json:"heading_degrees,omitempty"`
 }
 
 // ToResponse converts GPSData to GPSDataResponse with proper time formatting
@@ -35,3 +70,118 @@ func (g *GPSData) ToResponse() GPSDataResponse {
 		Timestamp: g.GetTimestamp(),
 	}
 }
+
+// ToResponsesWithDerived converts points ordered by timestamp and adds speed and
+// heading computed from each point and its predecessor. Unordered input is
+// sorted first. Pairs with a zero or negative time delta get no speed or heading,
+// and heading is left out when the device did not move.
+func ToResponsesWithDerived(points []GPSData) []GPSDataResponse {
+	ordered := make([]GPSData, len(points))
+	copy(ordered, points)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Timestamp < ordered[j].Timestamp
+	})
+
+	responses := make([]GPSDataResponse, len(ordered))
+	for i := range ordered {
+		responses[i] = ordered[i].ToResponse()
+		if i == 0 {
+			continue
+		}
+
+		prev, cur := ordered[i-1], ordered[i]
+		seconds := cur.Timestamp - prev.Timestamp
+		if seconds <= 0 {
+			continue
+		}
+
+		distanceKm := haversineKm(prev.Latitude, prev.Longitude, cur.Latitude, cur.Longitude)
+		speed := distanceKm / (seconds / 3600)
+		responses[i].SpeedKmh = &speed
+
+		if distanceKm > 0 {
+			heading := bearingDegrees(prev.Latitude, prev.Longitude, cur.Latitude, cur.Longitude)
+			responses[i].HeadingDegrees = &heading
+		}
+	}
+
+	return responses
+}
+
+// FilterOutliers splits points into those that form a plausible track and
+// those that imply moving faster than maxSpeedKmh, such as GPS glitches that
+// teleport a device. Both are returned ordered by timestamp. Each point is
+// compared with the last kept one, so one bad fix does not also reject the
+// good point after it. The first point has no predecessor: it is rejected only
+// when the two points after it agree with each other but not with it. Points
+// sharing a timestamp are kept at the same position and rejected elsewhere.
+func FilterOutliers(points []GPSData, maxSpeedKmh float64) (kept []GPSData, rejected []GPSData) {
+	ordered := make([]GPSData, len(points))
+	copy(ordered, points)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Timestamp < ordered[j].Timestamp
+	})
+
+	plausible := func(from, to GPSData) bool {
+		return impliedSpeedKmh(from, to) <= maxSpeedKmh
+	}
+
+	for i, point := range ordered {
+		if i == 0 {
+			if len(ordered) >= 3 && !plausible(point, ordered[1]) && !plausible(point, ordered[2]) && plausible(ordered[1], ordered[2]) {
+				rejected = append(rejected, point)
+			} else {
+				kept = append(kept, point)
+			}
+			continue
+		}
+
+		if len(kept) == 0 || plausible(kept[len(kept)-1], point) {
+			kept = append(kept, point)
+		} else {
+			rejected = append(rejected, point)
+		}
+	}
+
+	return kept, rejected
+}
+
+// impliedSpeedKmh is the speed needed to travel between two points, ordered by
+// timestamp. Staying put takes no speed, even without elapsed time; moving
+// without elapsed time takes infinite speed.
+func impliedSpeedKmh(from, to GPSData) float64 {
+	distanceKm := haversineKm(from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+	if distanceKm == 0 {
+		return 0
+	}
+	seconds := to.Timestamp - from.Timestamp
+	if seconds <= 0 {
+		return math.Inf(1)
+	}
+	return distanceKm / (seconds / 3600)
+}
+
+// haversineKm returns the great-circle distance between two coordinates
+func haversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+// bearingDegrees returns the initial compass bearing from the first coordinate
+// to the second, in [0, 360) with 0 being north
+func bearingDegrees(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1, phi2 := toRadians(lat1), toRadians(lat2)
+	dLon := toRadians(lon2 - lon1)
+
+	y := math.Sin(dLon) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(dLon)
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
+
+func toRadians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
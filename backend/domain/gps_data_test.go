@@ -0,0 +1,192 @@
+package domain
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestGPSData_Validate(t *testing.T) {
+	now := float64(time.Now().Unix())
+
+	tests := []struct {
+		name      string
+		latitude  float64
+		longitude float64
+		timestamp float64
+		wantErr   bool
+	}{
+		{"valid point", 41.0082, 28.9784, now, false},
+		{"north pole", 90, 0.5, now, false},
+		{"south pole", -90, 0.5, now, false},
+		{"antimeridian east", 10, 180, now, false},
+		{"antimeridian west", 10, -180, now, false},
+		{"equator", 0, 28.9784, now, false},
+		{"prime meridian", 41.0082, 0, now, false},
+		{"latitude above range", 90.0001, 0.5, now, true},
+		{"latitude below range", -90.0001, 0.5, now, true},
+		{"longitude above range", 10, 180.0001, now, true},
+		{"longitude below range", 10, -180.0001, now, true},
+		{"latitude NaN", math.NaN(), 0.5, now, true},
+		{"null island", 0, 0, now, true},
+		{"zero timestamp", 41.0082, 28.9784, 0, true},
+		{"negative timestamp", 41.0082, 28.9784, -1, true},
+		{"far future timestamp", 41.0082, 28.9784, now + (48 * time.Hour).Seconds(), true},
+		{"slight clock skew", 41.0082, 28.9784, now + time.Hour.Seconds(), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &GPSData{Latitude: tt.latitude, Longitude: tt.longitude, Timestamp: tt.timestamp}
+
+			if err := g.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestToResponsesWithDerived(t *testing.T) {
+	// 0.01 degrees of latitude is about 1.112 km
+	points := []GPSData{
+		{ID: "3", Latitude: 41.02, Longitude: 29, Timestamp: 1200},
+		{ID: "1", Latitude: 41.00, Longitude: 29, Timestamp: 1000},
+		{ID: "2", Latitude: 41.01, Longitude: 29, Timestamp: 1100},
+		{ID: "4", Latitude: 41.02, Longitude: 29, Timestamp: 1200},
+		{ID: "5", Latitude: 41.02, Longitude: 29, Timestamp: 1300},
+	}
+
+	responses := ToResponsesWithDerived(points)
+
+	if len(responses) != len(points) {
+		t.Fatalf("Expected %d responses, got %d", len(points), len(responses))
+	}
+	for i, id := range []string{"1", "2", "3", "4", "5"} {
+		if responses[i].ID != id {
+			t.Fatalf("Expected points ordered by timestamp, got %s at %d", responses[i].ID, i)
+		}
+	}
+
+	if responses[0].SpeedKmh != nil || responses[0].HeadingDegrees != nil {
+		t.Error("Expected no derived fields on the first point")
+	}
+
+	// 1.112 km in 100 seconds is about 40 km/h, heading due north
+	if speed := responses[1].SpeedKmh; speed == nil || math.Abs(*speed-40.03) > 0.1 {
+		t.Errorf("Expected speed of about 40.03 km/h, got %v", speed)
+	}
+	if heading := responses[1].HeadingDegrees; heading == nil || math.Abs(*heading) > 0.01 {
+		t.Errorf("Expected heading of about 0 degrees, got %v", heading)
+	}
+
+	// Same timestamp as the previous point
+	if responses[3].SpeedKmh != nil || responses[3].HeadingDegrees != nil {
+		t.Error("Expected no derived fields for a zero time delta")
+	}
+
+	// Stationary
+	if speed := responses[4].SpeedKmh; speed == nil || *speed != 0 {
+		t.Errorf("Expected speed 0 for a stationary device, got %v", speed)
+	}
+	if responses[4].HeadingDegrees != nil {
+		t.Error("Expected no heading for a stationary device")
+	}
+
+	if points[0].ID != "3" {
+		t.Error("Expected input slice to be left unsorted")
+	}
+}
+
+func TestBearingDegrees(t *testing.T) {
+	tests := []struct {
+		name     string
+		lat, lon float64
+		expected float64
+	}{
+		{"east", 0, 1, 90},
+		{"south", -1, 0, 180},
+		{"west", 0, -1, 270},
+	}
+
+	for _, tt := range tests {
+		if got := bearingDegrees(0, 0, tt.lat, tt.lon); math.Abs(got-tt.expected) > 0.01 {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestFilterOutliers(t *testing.T) {
+	ids := func(points []GPSData) []string {
+		var out []string
+		for _, p := range points {
+			out = append(out, p.ID)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name     string
+		points   []GPSData
+		kept     []string
+		rejected []string
+	}{
+		{
+			name: "teleport in the middle",
+			points: []GPSData{
+				{ID: "1", Latitude: 41.00, Longitude: 28.97, Timestamp: 1000},
+				{ID: "2", Latitude: 41.01, Longitude: 28.97, Timestamp: 1060},
+				{ID: "3", Latitude: 39.93, Longitude: 32.85, Timestamp: 1120}, // Ankara
+				{ID: "4", Latitude: 41.02, Longitude: 28.97, Timestamp: 1180},
+			},
+			kept:     []string{"1", "2", "4"},
+			rejected: []string{"3"},
+		},
+		{
+			name: "glitched first point",
+			points: []GPSData{
+				{ID: "1", Latitude: 39.93, Longitude: 32.85, Timestamp: 1000},
+				{ID: "2", Latitude: 41.00, Longitude: 28.97, Timestamp: 1060},
+				{ID: "3", Latitude: 41.01, Longitude: 28.97, Timestamp: 1120},
+			},
+			kept:     []string{"2", "3"},
+			rejected: []string{"1"},
+		},
+		{
+			name: "equal timestamps",
+			points: []GPSData{
+				{ID: "1", Latitude: 41.00, Longitude: 28.97, Timestamp: 1000},
+				{ID: "2", Latitude: 41.00, Longitude: 28.97, Timestamp: 1000},
+				{ID: "3", Latitude: 41.01, Longitude: 28.97, Timestamp: 1000},
+			},
+			kept:     []string{"1", "2"},
+			rejected: []string{"3"},
+		},
+		{
+			name: "unordered input",
+			points: []GPSData{
+				{ID: "2", Latitude: 41.01, Longitude: 28.97, Timestamp: 1060},
+				{ID: "1", Latitude: 41.00, Longitude: 28.97, Timestamp: 1000},
+			},
+			kept: []string{"1", "2"},
+		},
+		{
+			name:   "single point",
+			points: []GPSData{{ID: "1", Latitude: 39.93, Longitude: 32.85, Timestamp: 1000}},
+			kept:   []string{"1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, rejected := FilterOutliers(tt.points, 200)
+
+			if !slices.Equal(ids(kept), tt.kept) {
+				t.Errorf("Expected %v kept, got %v", tt.kept, ids(kept))
+			}
+			if !slices.Equal(ids(rejected), tt.rejected) {
+				t.Errorf("Expected %v rejected, got %v", tt.rejected, ids(rejected))
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Limits on the free-form metadata a fleet can attach to a vehicle
+const (
+	MaxMetadataEntries     = 20
+	MaxMetadataKeyLength   = 64
+	MaxMetadataValueLength = 256
+	MaxMetadataSize        = 4096 // Bytes over all keys and values
+)
+
+// metadataKeyPattern keeps keys safe to use as a field name in queries
+var metadataKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ValidateMetadata checks the metadata against the entry, key, value and total size limits
+func ValidateMetadata(metadata map[string]string) error {
+	if len(metadata) > MaxMetadataEntries {
+		return fmt.Errorf("at most %d entries are allowed", MaxMetadataEntries)
+	}
+
+	size := 0
+	for key, value := range metadata {
+		if len(key) > MaxMetadataKeyLength || !metadataKeyPattern.MatchString(key) {
+			return fmt.Errorf("key %q must be 1-%d letters, digits, '_' or '-'", key, MaxMetadataKeyLength)
+		}
+		if len(value) > MaxMetadataValueLength {
+			return fmt.Errorf("value of %q must be at most %d bytes", key, MaxMetadataValueLength)
+		}
+		size += len(key) + len(value)
+	}
+
+	if size > MaxMetadataSize {
+		return fmt.Errorf("total size must be at most %d bytes", MaxMetadataSize)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestValidateMetadata(t *testing.T) {
+	tooMany := make(map[string]string)
+	for i := 0; i <= MaxMetadataEntries; i++ {
+		tooMany[fmt.Sprintf("key_%d", i)] = "x"
+	}
+	tooLarge := make(map[string]string)
+	for i := 0; i < MaxMetadataEntries; i++ {
+		tooLarge[fmt.Sprintf("key_%d", i)] = strings.Repeat("x", MaxMetadataValueLength)
+	}
+
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		wantErr  bool
+	}{
+		{"empty", nil, false},
+		{"valid", map[string]string{"department": "sales", "cost-center": "CC_42"}, false},
+		{"too many entries", tooMany, true},
+		{"empty key", map[string]string{"": "x"}, true},
+		{"key with a dot", map[string]string{"parking.spot": "B2"}, true},
+		{"key too long", map[string]string{strings.Repeat("k", MaxMetadataKeyLength+1): "x"}, true},
+		{"value too long", map[string]string{"note": strings.Repeat("x", MaxMetadataValueLength+1)}, true},
+		{"total too large", tooLarge, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMetadata(tt.metadata)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
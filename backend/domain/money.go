@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount in the minor unit of its currency, cents for EUR, so
+// sums and comparisons are exact
+type Money struct {
+	AmountMinor int64  `json:"amount_minor" validate:"gte=0"`
+	Currency    string `json:"currency" validate:"required,iso4217"` // ISO 4217, e.g. EUR
+}
+
+// currencyExponents lists the ISO 4217 currencies whose minor unit is not a
+// hundredth of the major one
+var currencyExponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// DefaultCurrency is assumed for amounts stored before they carried a currency
+var DefaultCurrency = "TRY"
+
+// SetDefaultCurrency replaces DefaultCurrency. Like SetAllowedFileTypes it
+// must only be called at startup.
+func SetDefaultCurrency(currency string) error {
+	if len(currency) != 3 || strings.ContainsFunc(currency, func(r rune) bool { return r < 'A' || r > 'Z' }) {
+		return fmt.Errorf("currency %q is not a three-letter ISO 4217 code", currency)
+	}
+	DefaultCurrency = currency
+	return nil
+}
+
+// CurrencyExponent returns the number of decimals of a currency's minor unit
+func CurrencyExponent(currency string) int {
+	if exp, ok := currencyExponents[currency]; ok {
+		return exp
+	}
+	return 2
+}
+
+// MoneyFromFloat converts a major-unit amount, rounding to the nearest minor unit
+func MoneyFromFloat(amount float64, currency string) Money {
+	scale := math.Pow10(CurrencyExponent(currency))
+	return Money{AmountMinor: int64(math.Round(amount * scale)), Currency: currency}
+}
+
+// IsZero reports whether no amount is set
+func (m Money) IsZero() bool {
+	return m.AmountMinor == 0
+}
+
+// String formats the amount in major units with its currency, e.g. "12.50 EUR"
+func (m Money) String() string {
+	exp := CurrencyExponent(m.Currency)
+	if exp == 0 {
+		return fmt.Sprintf("%d %s", m.AmountMinor, m.Currency)
+	}
+	scale := int64(math.Pow10(exp))
+	sign, amount := "", m.AmountMinor
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	return fmt.Sprintf("%s%d.%0*d %s", sign, amount/scale, exp, amount%scale, m.Currency)
+}
+
+// UnmarshalJSON also reads the bare float amounts stored before Money existed,
+// taking them as DefaultCurrency
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if amount, err := strconv.ParseFloat(string(data), 64); err == nil {
+		*m = MoneyFromFloat(amount, DefaultCurrency)
+		return nil
+	}
+
+	type money Money
+	var decoded money
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*m = Money(decoded)
+	return nil
+}
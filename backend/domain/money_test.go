@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMoneyFromFloat(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		currency string
+		expected int64
+		text     string
+	}{
+		{0.1 + 0.2, "EUR", 30, "0.30 EUR"},
+		{1999.99, "TRY", 199999, "1999.99 TRY"},
+		{1500, "JPY", 1500, "1500 JPY"},
+		{12.345, "KWD", 12345, "12.345 KWD"},
+	}
+
+	for _, tt := range tests {
+		m := MoneyFromFloat(tt.amount, tt.currency)
+		if m.AmountMinor != tt.expected || m.Currency != tt.currency {
+			t.Errorf("%v %s: expected %d minor units, got %+v", tt.amount, tt.currency, tt.expected, m)
+		}
+		if m.String() != tt.text {
+			t.Errorf("%v %s: expected %q, got %q", tt.amount, tt.currency, tt.text, m.String())
+		}
+	}
+}
+
+func TestMoney_UnmarshalLegacyFloat(t *testing.T) {
+	var insurance InsuranceInfo
+	data := `{"coverage_amount": 250000.5, "deductible": {"amount_minor": 50000, "currency": "EUR"}, "premium_amount": null}`
+	if err := json.Unmarshal([]byte(data), &insurance); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if insurance.CoverageAmount != (Money{AmountMinor: 25000050, Currency: DefaultCurrency}) {
+		t.Errorf("Expected the legacy float in %s minor units, got %+v", DefaultCurrency, insurance.CoverageAmount)
+	}
+	if insurance.Deductible != (Money{AmountMinor: 50000, Currency: "EUR"}) {
+		t.Errorf("Expected 500.00 EUR, got %+v", insurance.Deductible)
+	}
+	if !insurance.PremiumAmount.IsZero() {
+		t.Errorf("Expected no premium, got %+v", insurance.PremiumAmount)
+	}
+}
+
+func TestSetDefaultCurrency(t *testing.T) {
+	defer func(currency string) { DefaultCurrency = currency }(DefaultCurrency)
+
+	for _, currency := range []string{"", "eur", "EURO", "E1R"} {
+		if err := SetDefaultCurrency(currency); err == nil {
+			t.Errorf("Expected %q to be rejected", currency)
+		}
+	}
+	if err := SetDefaultCurrency("EUR"); err != nil || DefaultCurrency != "EUR" {
+		t.Errorf("Expected EUR to be set, got %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package domain
+
+import "fmt"
+
+// Resolution is a picture size in pixels
+type Resolution struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// minPictureResolutions holds the smallest picture each type accepts. Claims
+// need legible damage and accident photos; types without an entry take any size.
+var minPictureResolutions = map[PictureType]Resolution{
+	PictureTypeDamage:   {Width: 1024, Height: 768},
+	PictureTypeAccident: {Width: 1024, Height: 768},
+}
+
+// MinPictureResolution returns the minimum size of a picture type; ok is false
+// when the type has none
+func MinPictureResolution(picType PictureType) (minimum Resolution, ok bool) {
+	minimum, ok = minPictureResolutions[picType]
+	return minimum, ok
+}
+
+// MeetsMinResolution reports whether a width x height picture is large enough
+// for its type
+func (t PictureType) MeetsMinResolution(width, height int) bool {
+	minimum, ok := minPictureResolutions[t]
+	return !ok || (width >= minimum.Width && height >= minimum.Height)
+}
+
+// SetMinPictureResolutions replaces the minimum sizes; types left out accept
+// any size. Like SetRequiredPictureTypes it must only be called at startup.
+func SetMinPictureResolutions(resolutions map[string]Resolution) error {
+	mins := make(map[PictureType]Resolution, len(resolutions))
+	for t, res := range resolutions {
+		if !IsValidPictureType(t) {
+			return fmt.Errorf("unknown picture type %q", t)
+		}
+		if res.Width < 0 || res.Height < 0 {
+			return fmt.Errorf("minimum resolution of %s must not be negative, got %dx%d", t, res.Width, res.Height)
+		}
+		mins[PictureType(t)] = res
+	}
+	minPictureResolutions = mins
+	return nil
+}
@@ -0,0 +1,44 @@
+package domain
+
+import "testing"
+
+func TestMeetsMinResolution(t *testing.T) {
+	tests := []struct {
+		picType       PictureType
+		width, height int
+		expected      bool
+	}{
+		{PictureTypeDamage, 1024, 768, true},
+		{PictureTypeDamage, 4032, 3024, true},
+		{PictureTypeDamage, 800, 600, false},
+		{PictureTypeAccident, 2000, 700, false},
+		{PictureTypeOther, 10, 10, true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.picType.MeetsMinResolution(tt.width, tt.height); got != tt.expected {
+			t.Errorf("%s %dx%d: expected %v, got %v", tt.picType, tt.width, tt.height, tt.expected, got)
+		}
+	}
+}
+
+func TestSetMinPictureResolutions(t *testing.T) {
+	defer func(mins map[PictureType]Resolution) { minPictureResolutions = mins }(minPictureResolutions)
+
+	if err := SetMinPictureResolutions(map[string]Resolution{"selfie": {Width: 10, Height: 10}}); err == nil {
+		t.Error("Expected an unknown picture type to be rejected")
+	}
+	if _, ok := MinPictureResolution(PictureTypeDamage); !ok {
+		t.Error("Expected the defaults to be kept after a rejected configuration")
+	}
+
+	if err := SetMinPictureResolutions(map[string]Resolution{"engine": {Width: 640, Height: 480}}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if PictureTypeEngine.MeetsMinResolution(320, 240) {
+		t.Error("Expected the configured minimum to apply")
+	}
+	if !PictureTypeDamage.MeetsMinResolution(320, 240) {
+		t.Error("Expected types left out to accept any size")
+	}
+}
@@ -0,0 +1,40 @@
+package domain
+
+import "errors"
+
+// BatteryInfo describes the traction battery of electric and hybrid vehicles
+type BatteryInfo struct {
+	CapacityKwh float64 `json:"capacity_kwh" couchbase:"capacity_kwh"` // Usable capacity
+	RangeKm     int     `json:"range_km" couchbase:"range_km"`         // Rated range on a full charge
+}
+
+// HasBattery reports whether vehicles of the fuel type carry a traction battery
+func (f FuelType) HasBattery() bool {
+	return f == FuelTypeElectric || f == FuelTypeHybrid
+}
+
+// ValidatePowertrain checks that the engine and battery fit the fuel type:
+// electric vehicles have no displacement or cylinders, and only electric and
+// hybrid vehicles have a battery
+func (v *Vehicle) ValidatePowertrain() error {
+	if v.Engine.Displacement < 0 || v.Engine.Cylinders < 0 || v.Engine.Horsepower < 0 || v.Engine.Torque < 0 {
+		return errors.New("engine values must not be negative")
+	}
+	if v.FuelType == FuelTypeElectric && (v.Engine.Displacement != 0 || v.Engine.Cylinders != 0) {
+		return errors.New("electric vehicles have no engine displacement or cylinders, describe the battery instead")
+	}
+
+	if v.Battery == nil {
+		return nil
+	}
+	if !v.FuelType.HasBattery() {
+		return errors.New("only electric and hybrid vehicles have a battery")
+	}
+	if v.Battery.CapacityKwh <= 0 {
+		return errors.New("battery capacity_kwh must be positive")
+	}
+	if v.Battery.RangeKm < 0 {
+		return errors.New("battery range_km must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "testing"
+
+func TestValidatePowertrain(t *testing.T) {
+	battery := &BatteryInfo{CapacityKwh: 75, RangeKm: 480}
+
+	tests := []struct {
+		name    string
+		vehicle Vehicle
+		wantErr bool
+	}{
+		{"electric with battery", Vehicle{FuelType: FuelTypeElectric, Engine: EngineInfo{Horsepower: 300, Torque: 420}, Battery: battery}, false},
+		{"electric without battery", Vehicle{FuelType: FuelTypeElectric}, false},
+		{"electric with displacement", Vehicle{FuelType: FuelTypeElectric, Engine: EngineInfo{Displacement: 2.0}}, true},
+		{"electric with cylinders", Vehicle{FuelType: FuelTypeElectric, Engine: EngineInfo{Cylinders: 4}}, true},
+		{"electric with empty battery", Vehicle{FuelType: FuelTypeElectric, Battery: &BatteryInfo{}}, true},
+		{"hybrid with engine and battery", Vehicle{FuelType: FuelTypeHybrid, Engine: EngineInfo{Displacement: 1.8, Cylinders: 4}, Battery: battery}, false},
+		{"gasoline with engine", Vehicle{FuelType: FuelTypeGasoline, Engine: EngineInfo{Displacement: 2.5, Cylinders: 4}}, false},
+		{"gasoline with battery", Vehicle{FuelType: FuelTypeGasoline, Battery: battery}, true},
+		{"negative displacement", Vehicle{FuelType: FuelTypeDiesel, Engine: EngineInfo{Displacement: -1}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.vehicle.ValidatePowertrain()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ServiceRecord is a maintenance entry in the vehicle's service history.
+// Receipts and invoices are regular documents linked by ID.
+type ServiceRecord struct {
+	ID              string    `json:"id" couchbase:"id"`
+	Date            time.Time `json:"date" couchbase:"date"`
+	OdometerReading int       `json:"odometer_reading" couchbase:"odometer_reading"`
+	Cost            Money     `json:"cost" couchbase:"cost"` // Zero when no cost was recorded
+	Description     string    `json:"description" couchbase:"description"`
+	ShopName        string    `json:"shop_name" couchbase:"shop_name"`
+	DocumentIDs     []string  `json:"document_ids" couchbase:"document_ids"`
+	CreatedAt       time.Time `json:"created_at" couchbase:"created_at"`
+}
+
+// UnmarshalJSON also reads the records stored before Cost was Money, with a
+// float cost and its currency in a separate field. A legacy cost without a
+// currency is taken as DefaultCurrency.
+func (r *ServiceRecord) UnmarshalJSON(data []byte) error {
+	type serviceRecord ServiceRecord
+	var decoded struct {
+		serviceRecord
+		Cost     json.RawMessage `json:"cost"`
+		Currency string          `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*r = ServiceRecord(decoded.serviceRecord)
+
+	if len(decoded.Cost) == 0 || string(decoded.Cost) == "null" {
+		return nil
+	}
+	var amount float64
+	if err := json.Unmarshal(decoded.Cost, &amount); err == nil {
+		currency := decoded.Currency
+		if currency == "" {
+			currency = DefaultCurrency
+		}
+		r.Cost = MoneyFromFloat(amount, currency)
+		return nil
+	}
+	return json.Unmarshal(decoded.Cost, &r.Cost)
+}
+
+// AddServiceRecord adds a record to the service history, kept ordered by date.
+// Odometer readings may never go down over time: a record reading less than an
+// earlier one, or more than a later one, points to a rolled back odometer.
+// Linked documents must exist on the vehicle. The vehicle mileage follows the
+// highest reading.
+func (v *Vehicle) AddServiceRecord(record ServiceRecord) error {
+	for _, existing := range v.ServiceRecords {
+		if existing.ID == record.ID {
+			return fmt.Errorf("service record with ID %s already exists", record.ID)
+		}
+
+		if !existing.Date.After(record.Date) && record.OdometerReading < existing.OdometerReading {
+			return fmt.Errorf("odometer reading %d is lower than %d recorded on %s",
+				record.OdometerReading, existing.OdometerReading, existing.Date.Format("2006-01-02"))
+		}
+		if existing.Date.After(record.Date) && record.OdometerReading > existing.OdometerReading {
+			return fmt.Errorf("odometer reading %d is higher than %d recorded later on %s",
+				record.OdometerReading, existing.OdometerReading, existing.Date.Format("2006-01-02"))
+		}
+	}
+
+	for _, documentID := range record.DocumentIDs {
+		if !slices.ContainsFunc(v.Documents, func(doc Document) bool { return doc.ID == documentID }) {
+			return fmt.Errorf("document with ID %s not found", documentID)
+		}
+	}
+
+	v.ServiceRecords = append(v.ServiceRecords, record)
+	slices.SortStableFunc(v.ServiceRecords, func(a, b ServiceRecord) int {
+		return a.Date.Compare(b.Date)
+	})
+
+	if record.OdometerReading > v.Mileage {
+		v.Mileage = record.OdometerReading
+	}
+
+	return nil
+}
+
+func GenerateServiceRecordID() string {
+	return "SVC_" + uuid.NewString()
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestAddServiceRecord_KeepsHistoryOrdered(t *testing.T) {
+	jan := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	vehicle := &Vehicle{
+		Mileage:   30000,
+		Documents: []Document{{ID: "DOC_1"}},
+	}
+
+	if err := vehicle.AddServiceRecord(ServiceRecord{ID: "SVC_2", Date: jan.AddDate(0, 6, 0), OdometerReading: 40000}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := vehicle.AddServiceRecord(ServiceRecord{ID: "SVC_1", Date: jan, OdometerReading: 32000, DocumentIDs: []string{"DOC_1"}}); err != nil {
+		t.Fatalf("Expected no error for an earlier record, got %v", err)
+	}
+
+	if vehicle.ServiceRecords[0].ID != "SVC_1" || vehicle.ServiceRecords[1].ID != "SVC_2" {
+		t.Errorf("Expected records ordered by date, got %v", vehicle.ServiceRecords)
+	}
+	if vehicle.Mileage != 40000 {
+		t.Errorf("Expected mileage to follow the highest reading, got %d", vehicle.Mileage)
+	}
+}
+
+func TestAddServiceRecord_RejectsOdometerRollback(t *testing.T) {
+	jan := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	vehicle := &Vehicle{
+		ServiceRecords: []ServiceRecord{
+			{ID: "SVC_1", Date: jan, OdometerReading: 30000},
+			{ID: "SVC_2", Date: jan.AddDate(0, 6, 0), OdometerReading: 40000},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		record ServiceRecord
+	}{
+		{"lower than an earlier record", ServiceRecord{ID: "SVC_3", Date: jan.AddDate(1, 0, 0), OdometerReading: 35000}},
+		{"higher than a later record", ServiceRecord{ID: "SVC_3", Date: jan.AddDate(0, 3, 0), OdometerReading: 45000}},
+		{"duplicate ID", ServiceRecord{ID: "SVC_1", Date: jan.AddDate(1, 0, 0), OdometerReading: 50000}},
+		{"unknown document", ServiceRecord{ID: "SVC_3", Date: jan.AddDate(1, 0, 0), OdometerReading: 50000, DocumentIDs: []string{"DOC_404"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := vehicle.AddServiceRecord(tt.record); err == nil {
+				t.Error("Expected an error")
+			}
+			if len(vehicle.ServiceRecords) != 2 {
+				t.Errorf("Expected history to be unchanged, got %v", vehicle.ServiceRecords)
+			}
+		})
+	}
+}
+
+func TestServiceRecord_UnmarshalLegacyCost(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want Money
+	}{
+		{"money", `{"cost": {"amount_minor": 18990, "currency": "EUR"}}`, Money{AmountMinor: 18990, Currency: "EUR"}},
+		{"legacy float", `{"cost": 189.9, "currency": "EUR"}`, Money{AmountMinor: 18990, Currency: "EUR"}},
+		{"legacy float without currency", `{"cost": 75}`, Money{AmountMinor: 7500, Currency: DefaultCurrency}},
+		{"no cost", `{"id": "SVC_1"}`, Money{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var record ServiceRecord
+			if err := json.Unmarshal([]byte(tt.data), &record); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if record.Cost != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, record.Cost)
+			}
+		})
+	}
+}
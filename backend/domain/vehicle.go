@@ -2,7 +2,11 @@ package domain
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // Vehicle represents a vehicle in the system
@@ -26,6 +30,7 @@ type Vehicle struct {
 	Transmission string         `json:"transmission" couchbase:"transmission"` // Manual, Automatic, CVT
 	FuelType    FuelType       `json:"fuel_type" couchbase:"fuel_type"`
 	Mileage     int            `json:"mileage" couchbase:"mileage"`           // Current mileage
+	Battery     *BatteryInfo   `json:"battery,omitempty" couchbase:"battery"` // Electric and hybrid vehicles only
 	
 	// Insurance information
 	Insurance   InsuranceInfo  `json:"insurance" couchbase:"insurance"`
@@ -33,6 +38,12 @@ type Vehicle struct {
 	// Documents and media
 	Documents   []Document     `json:"documents" couchbase:"documents"`
 	Pictures    []Picture      `json:"pictures" couchbase:"pictures"`
+
+	// Maintenance history, ordered by date
+	ServiceRecords []ServiceRecord `json:"service_records" couchbase:"service_records"`
+
+	// Fleet-defined key-value pairs such as department or cost center, see ValidateMetadata
+	Metadata map[string]string `json:"metadata,omitempty" couchbase:"metadata"`
 	
 	// Status and metadata
 	Status      VehicleStatus  `json:"status" couchbase:"status"`
@@ -40,6 +51,28 @@ type Vehicle struct {
 	UpdatedAt   time.Time      `json:"updated_at" couchbase:"updated_at"`
 	CreatedBy   string         `json:"created_by" couchbase:"created_by"`
 	UpdatedBy   string         `json:"updated_by" couchbase:"updated_by"`
+
+	// Snapshots of GetInsuranceStatus and GetDocumentStatus so queries can filter
+	// on them. Refreshed on every write and by a periodic sweep, as both change
+	// with time; the methods remain the source of truth.
+	InsuranceStatus InsuranceStatus `json:"insurance_status" couchbase:"insurance_status"`
+	DocumentStatus  DocumentStatus  `json:"document_status" couchbase:"document_status"`
+
+	// Set while the vehicle is soft deleted; status keeps its business meaning
+	DeletedAt *time.Time `json:"deleted_at,omitempty" couchbase:"deleted_at"`
+
+	// Set once the vehicle is reported stolen
+	Theft *TheftReport `json:"theft,omitempty" couchbase:"theft"`
+}
+
+// TheftReport records the incident behind a stolen status
+type TheftReport struct {
+	IncidentDate       time.Time `json:"incident_date" couchbase:"incident_date"`
+	PoliceReportNumber string    `json:"police_report_number" couchbase:"police_report_number"`
+	Description        string    `json:"description" couchbase:"description"`
+	DocumentID         string    `json:"document_id" couchbase:"document_id"` // accident_report document filed with the report
+	ReportedAt         time.Time `json:"reported_at" couchbase:"reported_at"`
+	ReportedBy         string    `json:"reported_by" couchbase:"reported_by"`
 }
 
 // EngineInfo contains engine specifications
@@ -55,9 +88,9 @@ type InsuranceInfo struct {
 	PolicyNumber    string            `json:"policy_number" couchbase:"policy_number"`
 	Provider        string            `json:"provider" couchbase:"provider"`         // Insurance company name
 	PolicyType      InsurancePolicyType `json:"policy_type" couchbase:"policy_type"`
-	CoverageAmount  float64           `json:"coverage_amount" couchbase:"coverage_amount"`
-	Deductible      float64           `json:"deductible" couchbase:"deductible"`
-	PremiumAmount   float64           `json:"premium_amount" couchbase:"premium_amount"`
+	CoverageAmount  Money             `json:"coverage_amount" couchbase:"coverage_amount"`
+	Deductible      Money             `json:"deductible" couchbase:"deductible"`
+	PremiumAmount   Money             `json:"premium_amount" couchbase:"premium_amount"`
 	StartDate       time.Time         `json:"start_date" couchbase:"start_date"`
 	EndDate         time.Time         `json:"end_date" couchbase:"end_date"`
 	IsActive        bool              `json:"is_active" couchbase:"is_active"`
@@ -83,6 +116,7 @@ type Document struct {
 	FileName     string       `json:"file_name" couchbase:"file_name"`
 	FileSize     int64        `json:"file_size" couchbase:"file_size"`     // Size in bytes
 	MimeType     string       `json:"mime_type" couchbase:"mime_type"`     // application/pdf, image/jpeg, etc.
+	Checksum     string       `json:"checksum,omitempty" couchbase:"checksum"` // See Checksum, empty for files stored before checksums
 	ExpiryDate   *time.Time   `json:"expiry_date" couchbase:"expiry_date"` // For documents that expire
 	IssuedDate   *time.Time   `json:"issued_date" couchbase:"issued_date"`
 	IssuedBy     string       `json:"issued_by" couchbase:"issued_by"`     // Issuing authority
@@ -92,6 +126,55 @@ type Document struct {
 	IsVerified   bool         `json:"is_verified" couchbase:"is_verified"`
 	VerifiedAt   *time.Time   `json:"verified_at" couchbase:"verified_at"`
 	VerifiedBy   string       `json:"verified_by" couchbase:"verified_by"`
+	// Set when a verification lapsed because the document expired
+	VerificationExpiredAt *time.Time `json:"verification_expired_at,omitempty" couchbase:"verification_expired_at"`
+	VerificationNote      string     `json:"verification_note,omitempty" couchbase:"verification_note"`
+	// Every page of a multi-file document, page 1 first. Empty for single-file
+	// documents; the file fields above always describe page 1, except FileSize
+	// which is the total over all pages.
+	Files []DocumentFile `json:"files,omitempty" couchbase:"files"`
+}
+
+// MaxDocumentFiles caps the pages of a multi-file document
+const MaxDocumentFiles = 50
+
+// DocumentFile is one page of a multi-file document
+type DocumentFile struct {
+	URL      string `json:"url" couchbase:"url"`
+	FileName string `json:"file_name" couchbase:"file_name"`
+	FileSize int64  `json:"file_size" couchbase:"file_size"`
+	MimeType string `json:"mime_type" couchbase:"mime_type"`
+	Checksum string `json:"checksum,omitempty" couchbase:"checksum"` // See Checksum
+	Page     int    `json:"page" couchbase:"page"` // 1-based
+}
+
+// AllFiles returns the document's pages, including the single file of
+// documents stored before multi-file support
+func (d *Document) AllFiles() []DocumentFile {
+	if len(d.Files) > 0 {
+		return d.Files
+	}
+	if d.FileURL == "" {
+		return nil
+	}
+	return []DocumentFile{{URL: d.FileURL, FileName: d.FileName, FileSize: d.FileSize, MimeType: d.MimeType, Checksum: d.Checksum, Page: 1}}
+}
+
+// AppendFile adds file as the next page and returns it with its page number
+func (d *Document) AppendFile(file DocumentFile) (DocumentFile, error) {
+	files := d.AllFiles()
+	if len(files) >= MaxDocumentFiles {
+		return DocumentFile{}, fmt.Errorf("document already has the maximum of %d files", MaxDocumentFiles)
+	}
+
+	file.Page = len(files) + 1
+	d.Files = append(files, file)
+	if len(d.Files) == 1 {
+		// A document that had no file yet gets it as its single file
+		d.FileURL, d.FileName, d.MimeType, d.Checksum = file.URL, file.FileName, file.MimeType, file.Checksum
+	}
+	d.FileSize += file.FileSize
+	return file, nil
 }
 
 // Picture represents vehicle images
@@ -104,9 +187,11 @@ type Picture struct {
 	ThumbnailURL string     `json:"thumbnail_url" couchbase:"thumbnail_url"`
 	FileName    string      `json:"file_name" couchbase:"file_name"`
 	FileSize    int64       `json:"file_size" couchbase:"file_size"`
+	ThumbnailSize int64     `json:"thumbnail_size,omitempty" couchbase:"thumbnail_size"`
 	Width       int         `json:"width" couchbase:"width"`
 	Height      int         `json:"height" couchbase:"height"`
 	MimeType    string      `json:"mime_type" couchbase:"mime_type"`
+	Checksum    string      `json:"checksum,omitempty" couchbase:"checksum"` // See Checksum, of the picture, not the thumbnail
 	TakenAt     *time.Time  `json:"taken_at" couchbase:"taken_at"`
 	UploadedAt  time.Time   `json:"uploaded_at" couchbase:"uploaded_at"`
 	UploadedBy  string      `json:"uploaded_by" couchbase:"uploaded_by"`
@@ -127,6 +212,36 @@ const (
 	VehicleStatusAccident  VehicleStatus = "accident"
 )
 
+// AllVehicleStatuses returns every vehicle status constant
+func AllVehicleStatuses() []VehicleStatus {
+	return []VehicleStatus{
+		VehicleStatusActive,
+		VehicleStatusInactive,
+		VehicleStatusSold,
+		VehicleStatusScrapped,
+		VehicleStatusStolen,
+		VehicleStatusAccident,
+	}
+}
+
+// statusTransitions lists the statuses each status may move to. Sold and
+// scrapped vehicles are final; a recovered stolen vehicle goes back to active.
+var statusTransitions = map[VehicleStatus][]VehicleStatus{
+	VehicleStatusActive:   {VehicleStatusInactive, VehicleStatusSold, VehicleStatusScrapped, VehicleStatusStolen, VehicleStatusAccident},
+	VehicleStatusInactive: {VehicleStatusActive, VehicleStatusSold, VehicleStatusScrapped, VehicleStatusStolen},
+	VehicleStatusAccident: {VehicleStatusActive, VehicleStatusInactive, VehicleStatusSold, VehicleStatusScrapped, VehicleStatusStolen},
+	VehicleStatusStolen:   {VehicleStatusActive, VehicleStatusScrapped},
+}
+
+// TransitionStatus moves the vehicle to status if statusTransitions allows it
+func (v *Vehicle) TransitionStatus(status VehicleStatus) error {
+	if !slices.Contains(statusTransitions[v.Status], status) {
+		return fmt.Errorf("vehicle status cannot change from %s to %s", v.Status, status)
+	}
+	v.Status = status
+	return nil
+}
+
 type FuelType string
 
 const (
@@ -138,6 +253,78 @@ const (
 	FuelTypeCNG      FuelType = "cng"
 )
 
+// AllFuelTypes returns every fuel type constant
+func AllFuelTypes() []FuelType {
+	return []FuelType{
+		FuelTypeGasoline,
+		FuelTypeDiesel,
+		FuelTypeElectric,
+		FuelTypeHybrid,
+		FuelTypeLPG,
+		FuelTypeCNG,
+	}
+}
+
+// Transmission values accepted for Vehicle.Transmission
+type Transmission string
+
+const (
+	TransmissionManual    Transmission = "manual"
+	TransmissionAutomatic Transmission = "automatic"
+	TransmissionCVT       Transmission = "cvt"
+)
+
+// AllTransmissions returns every transmission constant
+func AllTransmissions() []Transmission {
+	return []Transmission{
+		TransmissionManual,
+		TransmissionAutomatic,
+		TransmissionCVT,
+	}
+}
+
+// InsuranceStatus summarizes a vehicle's insurance, see Vehicle.GetInsuranceStatus
+type InsuranceStatus string
+
+const (
+	InsuranceStatusInactive     InsuranceStatus = "inactive"
+	InsuranceStatusExpired      InsuranceStatus = "expired"
+	InsuranceStatusExpiringSoon InsuranceStatus = "expiring_soon"
+	InsuranceStatusActive       InsuranceStatus = "active"
+)
+
+// AllInsuranceStatuses returns every insurance status constant
+func AllInsuranceStatuses() []InsuranceStatus {
+	return []InsuranceStatus{
+		InsuranceStatusInactive,
+		InsuranceStatusExpired,
+		InsuranceStatusExpiringSoon,
+		InsuranceStatusActive,
+	}
+}
+
+// DocumentStatus summarizes a vehicle's documents, see Vehicle.GetDocumentStatus
+type DocumentStatus string
+
+const (
+	DocumentStatusNoDocuments         DocumentStatus = "no_documents"
+	DocumentStatusHasExpired          DocumentStatus = "has_expired"
+	DocumentStatusVerificationExpired DocumentStatus = "verification_expired"
+	DocumentStatusHasExpiring         DocumentStatus = "has_expiring"
+	DocumentStatusUpToDate            DocumentStatus = "up_to_date"
+)
+
+// AllDocumentStatuses returns every document status constant
+func AllDocumentStatuses() []DocumentStatus {
+	return []DocumentStatus{
+		DocumentStatusNoDocuments,
+		DocumentStatusHasExpired,
+		DocumentStatusVerificationExpired,
+		DocumentStatusHasExpiring,
+		DocumentStatusUpToDate,
+	}
+}
+
 type InsurancePolicyType string
 
 const (
@@ -164,6 +351,70 @@ const (
 	DocumentTypeOther              DocumentType = "other"
 )
 
+// documentTypes is the set of accepted document types. It holds the constants
+// above plus any extra types registered from config at startup.
+var documentTypes = map[DocumentType]struct{}{
+	DocumentTypeInsurancePolicy:   {},
+	DocumentTypeInsuranceCard:     {},
+	DocumentTypeRegistration:      {},
+	DocumentTypeTitle:             {},
+	DocumentTypeInspection:        {},
+	DocumentTypeEmissionTest:      {},
+	DocumentTypePurchaseAgreement: {},
+	DocumentTypeServiceRecord:     {},
+	DocumentTypeWarranty:          {},
+	DocumentTypeReceipt:           {},
+	DocumentTypeAccidentReport:    {},
+	DocumentTypeOther:             {},
+}
+
+// RegisterDocumentTypes extends the accepted document types. It is not safe for
+// concurrent use and must only be called at startup, before serving requests.
+func RegisterDocumentTypes(types ...string) {
+	for _, t := range types {
+		documentTypes[DocumentType(t)] = struct{}{}
+	}
+}
+
+// RequiredDocumentTypes are the documents every vehicle is expected to have on file
+var RequiredDocumentTypes = []DocumentType{
+	DocumentTypeRegistration,
+	DocumentTypeInsurancePolicy,
+	DocumentTypeInspection,
+}
+
+// SetRequiredDocumentTypes replaces RequiredDocumentTypes. Every type must be
+// accepted, so register extra types first. Like RegisterDocumentTypes it must
+// only be called at startup.
+func SetRequiredDocumentTypes(types ...string) error {
+	required := make([]DocumentType, 0, len(types))
+	for _, t := range types {
+		if !IsValidDocumentType(t) {
+			return fmt.Errorf("unknown required document type %q", t)
+		}
+		required = append(required, DocumentType(t))
+	}
+	RequiredDocumentTypes = required
+	return nil
+}
+
+// IsValidDocumentType reports whether t is an accepted document type
+func IsValidDocumentType(t string) bool {
+	_, ok := documentTypes[DocumentType(t)]
+	return ok
+}
+
+// AllDocumentTypes returns the accepted document types, including registered
+// extras, sorted so the list is stable in error messages
+func AllDocumentTypes() []DocumentType {
+	types := make([]DocumentType, 0, len(documentTypes))
+	for t := range documentTypes {
+		types = append(types, t)
+	}
+	slices.Sort(types)
+	return types
+}
+
 type PictureType string
 
 const (
@@ -182,6 +433,54 @@ const (
 	PictureTypeOther          PictureType = "other"
 )
 
+// AllPictureTypes returns every picture type constant
+func AllPictureTypes() []PictureType {
+	return []PictureType{
+		PictureTypeExteriorFront,
+		PictureTypeExteriorBack,
+		PictureTypeExteriorLeft,
+		PictureTypeExteriorRight,
+		PictureTypeInteriorFront,
+		PictureTypeInteriorBack,
+		PictureTypeDashboard,
+		PictureTypeEngine,
+		PictureTypeTrunk,
+		PictureTypeWheels,
+		PictureTypeDamage,
+		PictureTypeAccident,
+		PictureTypeOther,
+	}
+}
+
+// IsValidPictureType reports whether t is one of the picture types above
+func IsValidPictureType(t string) bool {
+	return slices.Contains(AllPictureTypes(), PictureType(t))
+}
+
+// RequiredPictureTypes are the angles an appraisal expects: the four exterior
+// sides and the dashboard
+var RequiredPictureTypes = []PictureType{
+	PictureTypeExteriorFront,
+	PictureTypeExteriorBack,
+	PictureTypeExteriorLeft,
+	PictureTypeExteriorRight,
+	PictureTypeDashboard,
+}
+
+// SetRequiredPictureTypes replaces RequiredPictureTypes. It must only be called
+// at startup, before serving requests.
+func SetRequiredPictureTypes(types ...string) error {
+	required := make([]PictureType, 0, len(types))
+	for _, t := range types {
+		if !IsValidPictureType(t) {
+			return fmt.Errorf("unknown required picture type %q", t)
+		}
+		required = append(required, PictureType(t))
+	}
+	RequiredPictureTypes = required
+	return nil
+}
+
 // Helper methods
 
 // IsInsuranceExpired checks if the vehicle's insurance has expired
@@ -189,23 +488,26 @@ func (v *Vehicle) IsInsuranceExpired() bool {
 	return time.Now().After(v.Insurance.EndDate)
 }
 
-// IsInsuranceExpiringSoon checks if insurance expires within the given days
+// IsInsuranceExpiringSoon checks if insurance is still valid but expires within the given days
 func (v *Vehicle) IsInsuranceExpiringSoon(days int) bool {
-	expiryThreshold := time.Now().AddDate(0, 0, days)
-	return v.Insurance.EndDate.Before(expiryThreshold)
+	now := time.Now()
+	expiryThreshold := now.AddDate(0, 0, days)
+	return v.Insurance.EndDate.After(now) && v.Insurance.EndDate.Before(expiryThreshold)
 }
 
-// GetMainPicture returns the main picture of the vehicle
+// GetMainPicture returns a pointer to the main picture in the vehicle's slice,
+// so changes made through it are reflected on the vehicle
 func (v *Vehicle) GetMainPicture() *Picture {
-	for _, picture := range v.Pictures {
-		if picture.IsMain {
-			return &picture
+	for i := range v.Pictures {
+		if v.Pictures[i].IsMain {
+			return &v.Pictures[i]
 		}
 	}
 	return nil
 }
 
-// GetDocumentsByType returns documents of a specific type
+// GetDocumentsByType returns copies of the documents of a specific type;
+// modifying them does not change the vehicle
 func (v *Vehicle) GetDocumentsByType(docType DocumentType) []Document {
 	var documents []Document
 	for _, doc := range v.Documents {
@@ -269,43 +571,164 @@ func (v *Vehicle) CalculateAge() int {
 	return time.Now().Year() - v.Year
 }
 
+// VintageAge is the age in years from which a vehicle counts as vintage
+const VintageAge = 25
+
 // IsVintage checks if the vehicle is considered vintage (25+ years old)
 func (v *Vehicle) IsVintage() bool {
-	return v.CalculateAge() >= 25
+	return v.CalculateAge() >= VintageAge
 }
 
 // GetInsuranceStatus returns the current insurance status
-func (v *Vehicle) GetInsuranceStatus() string {
+func (v *Vehicle) GetInsuranceStatus() InsuranceStatus {
 	if !v.Insurance.IsActive {
-		return "inactive"
+		return InsuranceStatusInactive
 	}
 	
 	if v.IsInsuranceExpired() {
-		return "expired"
+		return InsuranceStatusExpired
 	}
 	
 	if v.IsInsuranceExpiringSoon(30) {
-		return "expiring_soon"
+		return InsuranceStatusExpiringSoon
 	}
 	
-	return "active"
+	return InsuranceStatusActive
 }
 
-// GetDocumentStatus returns overall document status
-func (v *Vehicle) GetDocumentStatus() string {
+// GetDocumentStatus returns overall document status. Expired documents come
+// before expired verifications, as ExpireVerifications only marks documents
+// that have expired; verification_expired remains for documents whose expiry
+// was extended without being verified again.
+func (v *Vehicle) GetDocumentStatus() DocumentStatus {
 	if len(v.Documents) == 0 {
-		return "no_documents"
+		return DocumentStatusNoDocuments
 	}
-	
+
 	if v.HasExpiredDocuments() {
-		return "has_expired"
+		return DocumentStatusHasExpired
+	}
+
+	for _, doc := range v.Documents {
+		if !doc.IsVerified && doc.VerificationExpiredAt != nil {
+			return DocumentStatusVerificationExpired
+		}
 	}
 	
 	if len(v.GetExpiringDocuments(30)) > 0 {
-		return "has_expiring"
+		return DocumentStatusHasExpiring
 	}
 	
-	return "up_to_date"
+	return DocumentStatusUpToDate
+}
+
+// RefreshStatusFlags stores the current insurance and document status in
+// InsuranceStatus and DocumentStatus and reports whether either changed
+func (v *Vehicle) RefreshStatusFlags() bool {
+	insuranceStatus, documentStatus := v.GetInsuranceStatus(), v.GetDocumentStatus()
+	changed := insuranceStatus != v.InsuranceStatus || documentStatus != v.DocumentStatus
+	v.InsuranceStatus, v.DocumentStatus = insuranceStatus, documentStatus
+	return changed
+}
+
+// MissingRequiredDocuments returns the required document types the vehicle has no document of
+func (v *Vehicle) MissingRequiredDocuments() []DocumentType {
+	missing := []DocumentType{}
+	for _, docType := range RequiredDocumentTypes {
+		if len(v.GetDocumentsByType(docType)) == 0 {
+			missing = append(missing, docType)
+		}
+	}
+	return missing
+}
+
+// DocumentCompleteness scores how many of the required document types the
+// vehicle has on file, from 0 to 1, and lists the ones still missing
+func (v *Vehicle) DocumentCompleteness() (score float64, missing []DocumentType) {
+	missing = v.MissingRequiredDocuments()
+	if len(RequiredDocumentTypes) == 0 {
+		return 1, missing
+	}
+	present := len(RequiredDocumentTypes) - len(missing)
+	return float64(present) / float64(len(RequiredDocumentTypes)), missing
+}
+
+// PictureCoverage scores how many of the required picture types the vehicle
+// has, from 0 to 1, and splits the required types into present and missing
+func (v *Vehicle) PictureCoverage() (score float64, present, missing []PictureType) {
+	present, missing = []PictureType{}, []PictureType{}
+	for _, picType := range RequiredPictureTypes {
+		if len(v.GetPicturesByType(picType)) > 0 {
+			present = append(present, picType)
+		} else {
+			missing = append(missing, picType)
+		}
+	}
+	if len(RequiredPictureTypes) == 0 {
+		return 1, present, missing
+	}
+	return float64(len(present)) / float64(len(RequiredPictureTypes)), present, missing
+}
+
+// RenewInsurance extends the insurance to endDate, which must be later than the
+// current end date. An empty policyNumber keeps the current policy number and a
+// nil premium the current premium.
+func (v *Vehicle) RenewInsurance(policyNumber string, endDate time.Time, premium *Money) error {
+	if !endDate.After(v.Insurance.EndDate) {
+		return fmt.Errorf("new end date %s is not after the current end date %s",
+			endDate.Format(time.RFC3339), v.Insurance.EndDate.Format(time.RFC3339))
+	}
+	if policyNumber != "" {
+		v.Insurance.PolicyNumber = policyNumber
+	}
+	if premium != nil {
+		v.Insurance.PremiumAmount = *premium
+	}
+	v.Insurance.EndDate = endDate
+	v.Insurance.IsActive = true
+	return nil
+}
+
+// ReportStolen moves the vehicle to stolen and files the report together with
+// its accident_report document
+func (v *Vehicle) ReportStolen(report TheftReport, document Document) error {
+	if err := v.TransitionStatus(VehicleStatusStolen); err != nil {
+		return err
+	}
+	if err := v.AddDocument(document); err != nil {
+		return err
+	}
+	report.DocumentID = document.ID
+	v.Theft = &report
+	return nil
+}
+
+// IsDeleted reports whether the vehicle is soft deleted
+func (v *Vehicle) IsDeleted() bool {
+	return v.DeletedAt != nil
+}
+
+// SoftDelete marks the vehicle as deleted without touching its status
+func (v *Vehicle) SoftDelete(now time.Time) error {
+	if v.IsDeleted() {
+		return fmt.Errorf("vehicle %s is already deleted", v.ID)
+	}
+	v.DeletedAt = &now
+	return nil
+}
+
+// Restore undoes SoftDelete
+func (v *Vehicle) Restore() error {
+	if !v.IsDeleted() {
+		return fmt.Errorf("vehicle %s is not deleted", v.ID)
+	}
+	v.DeletedAt = nil
+	return nil
+}
+
+// NormalizeLicensePlate returns the canonical stored form of a plate: trimmed and uppercase
+func NormalizeLicensePlate(plate string) string {
+	return strings.ToUpper(strings.TrimSpace(plate))
 }
 
 // UpdateTimestamp updates the UpdatedAt field and UpdatedBy
@@ -314,6 +737,27 @@ func (v *Vehicle) UpdateTimestamp(updatedBy string) {
 	v.UpdatedBy = updatedBy
 }
 
+// ModifiedSince reports whether the vehicle changed after t. UpdatedAt is
+// truncated to whole seconds, the precision of HTTP dates.
+func (v *Vehicle) ModifiedSince(t time.Time) bool {
+	return v.UpdatedAt.Truncate(time.Second).After(t)
+}
+
+// ApplyMutableFields copies the fields that may change after registration
+// from src onto the vehicle
+func (v *Vehicle) ApplyMutableFields(src *Vehicle) {
+	v.Color = src.Color
+	v.LicensePlate = src.LicensePlate
+	v.OwnerName = src.OwnerName
+	v.OwnerEmail = src.OwnerEmail
+	v.OwnerPhone = src.OwnerPhone
+	v.Transmission = src.Transmission
+	v.Mileage = src.Mileage
+	v.Metadata = src.Metadata
+	v.Engine = src.Engine
+	v.Battery = src.Battery
+}
+
 // SetMainPicture sets a picture as the main picture and unsets others
 func (v *Vehicle) SetMainPicture(pictureID string) error {
 	found := false
@@ -348,6 +792,16 @@ func (v *Vehicle) AddDocument(doc Document) error {
 	return nil
 }
 
+// AppendDocumentFile adds a page to one of the vehicle's documents
+func (v *Vehicle) AppendDocumentFile(documentID string, file DocumentFile) (DocumentFile, error) {
+	for i := range v.Documents {
+		if v.Documents[i].ID == documentID {
+			return v.Documents[i].AppendFile(file)
+		}
+	}
+	return DocumentFile{}, fmt.Errorf("document with ID %s not found", documentID)
+}
+
 // AddPicture adds a new picture to the vehicle
 func (v *Vehicle) AddPicture(pic Picture) error {
 	
@@ -367,6 +821,56 @@ func (v *Vehicle) AddPicture(pic Picture) error {
 	return nil
 }
 
+// AddPictures adds several pictures at once and then picks the main picture
+// once, instead of the first picture of the batch becoming main
+func (v *Vehicle) AddPictures(pics []Picture) error {
+	ids := make(map[string]bool, len(v.Pictures)+len(pics))
+	for _, existingPic := range v.Pictures {
+		ids[existingPic.ID] = true
+	}
+	for _, pic := range pics {
+		if ids[pic.ID] {
+			return fmt.Errorf("picture with ID %s already exists", pic.ID)
+		}
+		ids[pic.ID] = true
+	}
+
+	v.Pictures = append(v.Pictures, pics...)
+	v.ensureMainPicture()
+	return nil
+}
+
+// StorageUsed returns the bytes stored for the vehicle's documents and pictures,
+// thumbnails included
+func (v *Vehicle) StorageUsed() int64 {
+	var used int64
+	for _, doc := range v.Documents {
+		used += doc.FileSize
+	}
+	for _, pic := range v.Pictures {
+		used += pic.FileSize + pic.ThumbnailSize
+	}
+	return used
+}
+
+// ExpireVerifications marks verified documents whose expiry date is before now
+// as unverified, noting why, and returns the affected documents
+func (v *Vehicle) ExpireVerifications(now time.Time) []Document {
+	var expired []Document
+	for i := range v.Documents {
+		doc := &v.Documents[i]
+		if !doc.IsVerified || doc.ExpiryDate == nil || !doc.ExpiryDate.Before(now) {
+			continue
+		}
+
+		doc.IsVerified = false
+		doc.VerificationExpiredAt = &now
+		doc.VerificationNote = fmt.Sprintf("verification expired with the document on %s", doc.ExpiryDate.Format("2006-01-02"))
+		expired = append(expired, *doc)
+	}
+	return expired
+}
+
 // RemoveDocument removes a document by ID
 func (v *Vehicle) RemoveDocument(documentID string) error {
 	for i, doc := range v.Documents {
@@ -396,6 +900,50 @@ func (v *Vehicle) RemovePicture(pictureID string) error {
 	return fmt.Errorf("picture with ID %s not found", pictureID)
 }
 
+// RemovePicturesByType removes every picture of the given type and returns them.
+// Exactly one main picture remains among the survivors, see ensureMainPicture.
+func (v *Vehicle) RemovePicturesByType(picType PictureType) []Picture {
+	removed := v.GetPicturesByType(picType)
+	if len(removed) == 0 {
+		return nil
+	}
+
+	kept := make([]Picture, 0, len(v.Pictures)-len(removed))
+	for _, pic := range v.Pictures {
+		if pic.Type != picType {
+			kept = append(kept, pic)
+		}
+	}
+	v.Pictures = kept
+
+	v.ensureMainPicture()
+	return removed
+}
+
+// ensureMainPicture keeps a single existing main picture, otherwise makes the
+// picture with the lowest SortOrder main (the first one on ties)
+func (v *Vehicle) ensureMainPicture() {
+	mains := 0
+	for _, pic := range v.Pictures {
+		if pic.IsMain {
+			mains++
+		}
+	}
+	if mains == 1 || len(v.Pictures) == 0 {
+		return
+	}
+
+	main := 0
+	for i := range v.Pictures {
+		if v.Pictures[i].SortOrder < v.Pictures[main].SortOrder {
+			main = i
+		}
+	}
+	for i := range v.Pictures {
+		v.Pictures[i].IsMain = i == main
+	}
+}
+
 // Factory methods
 
 // NewVehicle creates a new vehicle with default values
@@ -403,17 +951,18 @@ func NewVehicle(vin, vehicleMake, vehicleModel string, year int, ownerID string)
 	now := time.Now()
 	
 	return &Vehicle{
-		ID:        GenerateVehicleID(),
-		VIN:       vin,
-		Make:      vehicleMake,
-		Model:     vehicleModel,
-		Year:      year,
-		OwnerID:   ownerID,
-		Status:    VehicleStatusActive,
-		Documents: make([]Document, 0),
-		Pictures:  make([]Picture, 0),
-		CreatedAt: now,
-		UpdatedAt: now,
+		ID:             GenerateVehicleID(),
+		VIN:            vin,
+		Make:           vehicleMake,
+		Model:          vehicleModel,
+		Year:           year,
+		OwnerID:        ownerID,
+		Status:         VehicleStatusActive,
+		Documents:      make([]Document, 0),
+		Pictures:       make([]Picture, 0),
+		ServiceRecords: make([]ServiceRecord, 0),
+		CreatedAt:      now,
+		UpdatedAt:      now,
 	}
 }
 
@@ -450,15 +999,16 @@ func NewPicture(picType PictureType, title, url, fileName string, fileSize int64
 	}
 }
 
-// Helper functions for ID generation (you can implement these based on your needs)
+// ID generators prefix a random UUID, so IDs made in the same instant (imports,
+// bulk uploads) never collide
 func GenerateVehicleID() string {
-	return "VEH_" + time.Now().Format("20060102150405")
+	return "VEH_" + uuid.NewString()
 }
 
 func GenerateDocumentID() string {
-	return "DOC_" + time.Now().Format("20060102150405")
+	return "DOC_" + uuid.NewString()
 }
 
 func GeneratePictureID() string {
-	return "PIC_" + time.Now().Format("20060102150405")
+	return "PIC_" + uuid.NewString()
 }
\ No newline at end of file
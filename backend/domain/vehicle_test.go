@@ -0,0 +1,452 @@
+package domain
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestIsInsuranceExpiringSoon(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		endDate  time.Time
+		expected bool
+	}{
+		{"expires within window", now.AddDate(0, 0, 10), true},
+		{"expires after window", now.AddDate(0, 0, 60), false},
+		{"already expired", now.AddDate(0, 0, -1), false},
+	}
+
+	for _, tt := range tests {
+		vehicle := &Vehicle{Insurance: InsuranceInfo{EndDate: tt.endDate}}
+
+		if got := vehicle.IsInsuranceExpiringSoon(30); got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestGetInsuranceStatus(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		endDate  time.Time
+		expected InsuranceStatus
+	}{
+		{"expired", now.AddDate(0, 0, -1), InsuranceStatusExpired},
+		{"expiring soon", now.AddDate(0, 0, 10), InsuranceStatusExpiringSoon},
+		{"active", now.AddDate(1, 0, 0), InsuranceStatusActive},
+	}
+
+	for _, tt := range tests {
+		vehicle := &Vehicle{Insurance: InsuranceInfo{EndDate: tt.endDate, IsActive: true}}
+
+		if got := vehicle.GetInsuranceStatus(); got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestGetMainPicture_ReturnsSliceElement(t *testing.T) {
+	vehicle := &Vehicle{
+		Pictures: []Picture{
+			{ID: "PIC_1"},
+			{ID: "PIC_2", IsMain: true},
+		},
+	}
+
+	main := vehicle.GetMainPicture()
+	if main == nil {
+		t.Fatal("Expected main picture, got nil")
+	}
+
+	main.Title = "Front view"
+
+	if vehicle.Pictures[1].Title != "Front view" {
+		t.Errorf("Expected mutation to be reflected in the slice, got %q", vehicle.Pictures[1].Title)
+	}
+}
+
+func TestGetMainPicture_NoMain(t *testing.T) {
+	vehicle := &Vehicle{Pictures: []Picture{{ID: "PIC_1"}}}
+
+	if main := vehicle.GetMainPicture(); main != nil {
+		t.Errorf("Expected nil, got %v", main)
+	}
+}
+
+func TestGetDocumentsByType_ReturnsCopies(t *testing.T) {
+	vehicle := &Vehicle{
+		Documents: []Document{
+			{ID: "DOC_1", Type: DocumentTypeRegistration},
+			{ID: "DOC_2", Type: DocumentTypeInspection},
+		},
+	}
+
+	docs := vehicle.GetDocumentsByType(DocumentTypeRegistration)
+	if len(docs) != 1 {
+		t.Fatalf("Expected 1 document, got %d", len(docs))
+	}
+
+	docs[0].Name = "changed"
+
+	if vehicle.Documents[0].Name != "" {
+		t.Errorf("Expected vehicle documents to be unchanged, got %q", vehicle.Documents[0].Name)
+	}
+}
+
+func TestIsValidDocumentType(t *testing.T) {
+	for _, docType := range []string{"insurance_policy", "title", "other"} {
+		if !IsValidDocumentType(docType) {
+			t.Errorf("Expected %q to be valid", docType)
+		}
+	}
+
+	for _, docType := range []string{"", "Title", "junk"} {
+		if IsValidDocumentType(docType) {
+			t.Errorf("Expected %q to be invalid", docType)
+		}
+	}
+}
+
+func TestRegisterDocumentTypes(t *testing.T) {
+	defer delete(documentTypes, "fleet_card")
+
+	RegisterDocumentTypes("fleet_card")
+
+	if !IsValidDocumentType("fleet_card") {
+		t.Error("Expected registered type fleet_card to be valid")
+	}
+}
+
+func TestDocumentCompleteness(t *testing.T) {
+	defer func(required []DocumentType) { RequiredDocumentTypes = required }(RequiredDocumentTypes)
+
+	if err := SetRequiredDocumentTypes("registration", "insurance_policy", "title", "inspection", "emission_test"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	vehicle := &Vehicle{Documents: []Document{
+		{ID: "DOC_1", Type: DocumentTypeRegistration},
+		{ID: "DOC_2", Type: DocumentTypeTitle},
+		{ID: "DOC_3", Type: DocumentTypeTitle},
+		{ID: "DOC_4", Type: DocumentTypeInspection},
+		{ID: "DOC_5", Type: DocumentTypeEmissionTest},
+	}}
+
+	score, missing := vehicle.DocumentCompleteness()
+	if score != 0.8 {
+		t.Errorf("Expected score 0.8, got %v", score)
+	}
+	if len(missing) != 1 || missing[0] != DocumentTypeInsurancePolicy {
+		t.Errorf("Expected insurance_policy missing, got %v", missing)
+	}
+}
+
+func TestDocumentCompleteness_NothingRequired(t *testing.T) {
+	defer func(required []DocumentType) { RequiredDocumentTypes = required }(RequiredDocumentTypes)
+	SetRequiredDocumentTypes()
+
+	score, missing := (&Vehicle{}).DocumentCompleteness()
+	if score != 1 || len(missing) != 0 {
+		t.Errorf("Expected a full score with nothing missing, got %v and %v", score, missing)
+	}
+}
+
+func TestSetRequiredDocumentTypes_Unknown(t *testing.T) {
+	defer func(required []DocumentType) { RequiredDocumentTypes = required }(RequiredDocumentTypes)
+
+	if err := SetRequiredDocumentTypes("registration", "passport"); err == nil {
+		t.Error("Expected an unknown document type to be rejected")
+	}
+	if len(RequiredDocumentTypes) != 3 {
+		t.Errorf("Expected the defaults to be kept, got %v", RequiredDocumentTypes)
+	}
+}
+
+func TestGetDocumentStatus_ExpiredBeforeVerificationExpired(t *testing.T) {
+	now := time.Now()
+	past := now.AddDate(0, 0, -1)
+	future := now.AddDate(1, 0, 0)
+
+	vehicle := &Vehicle{
+		Documents: []Document{
+			{ID: "DOC_1", IsVerified: true, ExpiryDate: &past},
+			{ID: "DOC_2", IsVerified: true, ExpiryDate: &future},
+		},
+	}
+	vehicle.ExpireVerifications(now)
+
+	if status := vehicle.GetDocumentStatus(); status != "has_expired" {
+		t.Errorf("Expected an expired document with an expired verification to report has_expired, got %s", status)
+	}
+
+	// Renewed but not verified again
+	vehicle.Documents[0].ExpiryDate = &future
+	if status := vehicle.GetDocumentStatus(); status != "verification_expired" {
+		t.Errorf("Expected status verification_expired once renewed, got %s", status)
+	}
+}
+
+func TestExpireVerifications(t *testing.T) {
+	now := time.Now()
+	past := now.AddDate(0, 0, -1)
+	future := now.AddDate(0, 0, 10)
+
+	vehicle := &Vehicle{
+		Documents: []Document{
+			{ID: "DOC_1", IsVerified: true, ExpiryDate: &past},
+			{ID: "DOC_2", IsVerified: true, ExpiryDate: &future},
+			{ID: "DOC_3", IsVerified: false, ExpiryDate: &past},
+			{ID: "DOC_4", IsVerified: true},
+		},
+	}
+
+	expired := vehicle.ExpireVerifications(now)
+
+	if len(expired) != 1 || expired[0].ID != "DOC_1" {
+		t.Fatalf("Expected only DOC_1 to expire, got %+v", expired)
+	}
+	doc := vehicle.Documents[0]
+	if doc.IsVerified || doc.VerificationExpiredAt == nil || doc.VerificationNote == "" {
+		t.Errorf("Expected DOC_1 to be unverified with a note, got %+v", doc)
+	}
+	if !vehicle.Documents[1].IsVerified || !vehicle.Documents[3].IsVerified {
+		t.Error("Expected unexpired documents to stay verified")
+	}
+
+	if again := vehicle.ExpireVerifications(now); len(again) != 0 {
+		t.Errorf("Expected a second run to change nothing, got %+v", again)
+	}
+}
+
+func TestRemovePicturesByType_ReassignsMain(t *testing.T) {
+	vehicle := &Vehicle{
+		Pictures: []Picture{
+			{ID: "PIC_1", Type: PictureTypeAccident, IsMain: true, SortOrder: 0},
+			{ID: "PIC_2", Type: PictureTypeExteriorFront, SortOrder: 3},
+			{ID: "PIC_3", Type: PictureTypeAccident, SortOrder: 1},
+			{ID: "PIC_4", Type: PictureTypeEngine, SortOrder: 2},
+		},
+	}
+
+	removed := vehicle.RemovePicturesByType(PictureTypeAccident)
+
+	if len(removed) != 2 {
+		t.Fatalf("Expected 2 removed pictures, got %d", len(removed))
+	}
+	if len(vehicle.Pictures) != 2 {
+		t.Fatalf("Expected 2 remaining pictures, got %d", len(vehicle.Pictures))
+	}
+	if main := vehicle.GetMainPicture(); main == nil || main.ID != "PIC_4" {
+		t.Errorf("Expected PIC_4 (lowest sort order) to become main, got %v", main)
+	}
+	if vehicle.Pictures[0].IsMain {
+		t.Error("Expected exactly one main picture")
+	}
+}
+
+func TestRemovePicturesByType_KeepsMain(t *testing.T) {
+	vehicle := &Vehicle{
+		Pictures: []Picture{
+			{ID: "PIC_1", Type: PictureTypeAccident, SortOrder: 0},
+			{ID: "PIC_2", Type: PictureTypeExteriorFront, IsMain: true, SortOrder: 3},
+			{ID: "PIC_3", Type: PictureTypeEngine, SortOrder: 2},
+		},
+	}
+
+	vehicle.RemovePicturesByType(PictureTypeAccident)
+
+	if main := vehicle.GetMainPicture(); main == nil || main.ID != "PIC_2" {
+		t.Errorf("Expected PIC_2 to stay main, got %v", main)
+	}
+}
+
+func TestRemovePicturesByType_NoMatch(t *testing.T) {
+	vehicle := &Vehicle{Pictures: []Picture{{ID: "PIC_1", Type: PictureTypeEngine, IsMain: true}}}
+
+	if removed := vehicle.RemovePicturesByType(PictureTypeAccident); removed != nil {
+		t.Errorf("Expected nothing removed, got %v", removed)
+	}
+	if len(vehicle.Pictures) != 1 {
+		t.Errorf("Expected pictures to be untouched, got %v", vehicle.Pictures)
+	}
+}
+
+func TestAddPictures_PicksMainOnce(t *testing.T) {
+	vehicle := &Vehicle{}
+
+	err := vehicle.AddPictures([]Picture{
+		{ID: "PIC_1", Type: PictureTypeEngine, SortOrder: 1},
+		{ID: "PIC_2", Type: PictureTypeExteriorFront, SortOrder: 0},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if main := vehicle.GetMainPicture(); main == nil || main.ID != "PIC_2" {
+		t.Errorf("Expected PIC_2 (lowest sort order) to become main, got %v", main)
+	}
+	if vehicle.Pictures[0].IsMain {
+		t.Error("Expected exactly one main picture")
+	}
+
+	if err := vehicle.AddPictures([]Picture{{ID: "PIC_3"}, {ID: "PIC_1"}}); err == nil {
+		t.Error("Expected a duplicate picture ID to be rejected")
+	}
+	if len(vehicle.Pictures) != 2 {
+		t.Errorf("Expected a rejected batch to add nothing, got %d pictures", len(vehicle.Pictures))
+	}
+}
+
+func TestStorageUsed(t *testing.T) {
+	vehicle := &Vehicle{
+		Documents: []Document{{FileSize: 1000}, {FileSize: 500}},
+		Pictures:  []Picture{{FileSize: 250, ThumbnailSize: 50}},
+	}
+
+	if used := vehicle.StorageUsed(); used != 1800 {
+		t.Errorf("Expected 1800 bytes used, thumbnails included, got %d", used)
+	}
+}
+
+func TestSoftDeleteAndRestore(t *testing.T) {
+	vehicle := &Vehicle{ID: "VEH_1", Status: VehicleStatusInactive}
+	now := time.Now()
+
+	if err := vehicle.SoftDelete(now); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !vehicle.IsDeleted() || !vehicle.DeletedAt.Equal(now) {
+		t.Errorf("Expected vehicle to be deleted at %v, got %v", now, vehicle.DeletedAt)
+	}
+	if vehicle.Status != VehicleStatusInactive {
+		t.Errorf("Expected status to be kept, got %s", vehicle.Status)
+	}
+	if err := vehicle.SoftDelete(now); err == nil {
+		t.Error("Expected deleting twice to fail")
+	}
+
+	if err := vehicle.Restore(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if vehicle.IsDeleted() {
+		t.Error("Expected vehicle to be restored")
+	}
+	if err := vehicle.Restore(); err == nil {
+		t.Error("Expected restoring a vehicle that is not deleted to fail")
+	}
+}
+
+func TestPictureCoverage(t *testing.T) {
+	vehicle := &Vehicle{Pictures: []Picture{
+		{ID: "PIC_1", Type: PictureTypeExteriorFront},
+		{ID: "PIC_2", Type: PictureTypeExteriorFront},
+		{ID: "PIC_3", Type: PictureTypeExteriorLeft},
+		{ID: "PIC_4", Type: PictureTypeDashboard},
+		{ID: "PIC_5", Type: PictureTypeEngine},
+	}}
+
+	score, present, missing := vehicle.PictureCoverage()
+	if score != 0.6 {
+		t.Errorf("Expected score 0.6, got %v", score)
+	}
+	if len(present) != 3 {
+		t.Errorf("Expected 3 required types present, got %v", present)
+	}
+	expected := []PictureType{PictureTypeExteriorBack, PictureTypeExteriorRight}
+	if !slices.Equal(missing, expected) {
+		t.Errorf("Expected %v missing, got %v", expected, missing)
+	}
+}
+
+func TestSetRequiredPictureTypes_Unknown(t *testing.T) {
+	defer func(required []PictureType) { RequiredPictureTypes = required }(RequiredPictureTypes)
+
+	if err := SetRequiredPictureTypes("dashboard", "selfie"); err == nil {
+		t.Error("Expected an unknown picture type to be rejected")
+	}
+	if len(RequiredPictureTypes) != 5 {
+		t.Errorf("Expected the defaults to be kept, got %v", RequiredPictureTypes)
+	}
+}
+
+func TestDocumentAppendFile(t *testing.T) {
+	doc := Document{ID: "DOC_1", FileURL: "https://blob/page-1", FileName: "scan-1.pdf", FileSize: 100, MimeType: "application/pdf"}
+
+	file, err := doc.AppendFile(DocumentFile{URL: "https://blob/page-2", FileName: "scan-2.pdf", FileSize: 50})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if file.Page != 2 {
+		t.Errorf("Expected page 2, got %d", file.Page)
+	}
+
+	files := doc.AllFiles()
+	if len(files) != 2 || files[0].Page != 1 || files[0].URL != "https://blob/page-1" {
+		t.Errorf("Expected the single file to become page 1, got %+v", files)
+	}
+	if doc.FileURL != "https://blob/page-1" || doc.FileSize != 150 {
+		t.Errorf("Expected page 1 fields and a total size of 150, got %s and %d", doc.FileURL, doc.FileSize)
+	}
+}
+
+func TestDocumentAppendFile_Limit(t *testing.T) {
+	doc := Document{ID: "DOC_1"}
+	for i := 0; i < MaxDocumentFiles; i++ {
+		if _, err := doc.AppendFile(DocumentFile{URL: "https://blob/page"}); err != nil {
+			t.Fatalf("Expected no error for page %d, got %v", i+1, err)
+		}
+	}
+	if doc.FileURL != "https://blob/page" {
+		t.Errorf("Expected the first file to fill the single-file fields, got %q", doc.FileURL)
+	}
+
+	if _, err := doc.AppendFile(DocumentFile{URL: "https://blob/page"}); err == nil {
+		t.Error("Expected the file limit to be enforced")
+	}
+}
+
+func TestReportStolen(t *testing.T) {
+	incident := time.Now().AddDate(0, 0, -1)
+
+	vehicle := &Vehicle{ID: "VEH_1", Status: VehicleStatusActive}
+	err := vehicle.ReportStolen(TheftReport{IncidentDate: incident, PoliceReportNumber: "PR-1"}, Document{ID: "DOC_1", Type: DocumentTypeAccidentReport})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if vehicle.Status != VehicleStatusStolen || vehicle.Theft == nil || vehicle.Theft.DocumentID != "DOC_1" || len(vehicle.Documents) != 1 {
+		t.Errorf("Expected a stolen vehicle with the report filed, got %+v", vehicle)
+	}
+
+	for _, status := range []VehicleStatus{VehicleStatusStolen, VehicleStatusSold, VehicleStatusScrapped} {
+		vehicle := &Vehicle{ID: "VEH_2", Status: status}
+		if err := vehicle.ReportStolen(TheftReport{IncidentDate: incident}, Document{ID: "DOC_2"}); err == nil {
+			t.Errorf("Expected a %s vehicle not to be reported stolen", status)
+		}
+		if vehicle.Theft != nil || len(vehicle.Documents) != 0 {
+			t.Errorf("Expected a %s vehicle to be unchanged, got %+v", status, vehicle)
+		}
+	}
+}
+
+func TestRefreshStatusFlags(t *testing.T) {
+	vehicle := &Vehicle{Insurance: InsuranceInfo{IsActive: true, EndDate: time.Now().AddDate(0, 0, 10)}}
+
+	if !vehicle.RefreshStatusFlags() {
+		t.Error("Expected unset flags to change")
+	}
+	if vehicle.InsuranceStatus != "expiring_soon" || vehicle.DocumentStatus != "no_documents" {
+		t.Errorf("Expected expiring_soon and no_documents, got %s and %s", vehicle.InsuranceStatus, vehicle.DocumentStatus)
+	}
+	if vehicle.RefreshStatusFlags() {
+		t.Error("Expected no change on a second refresh")
+	}
+
+	vehicle.Insurance.EndDate = time.Now().AddDate(0, 0, -1)
+	if !vehicle.RefreshStatusFlags() || vehicle.InsuranceStatus != "expired" {
+		t.Errorf("Expected the flag to follow the lapsed insurance, got %s", vehicle.InsuranceStatus)
+	}
+}
@@ -5,13 +5,18 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"microservicetest/app"
 	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/log"
+	"microservicetest/pkg/metrics"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/sas"
+	"go.uber.org/zap"
 )
 
 type Storage struct {
@@ -51,7 +56,10 @@ func NewStorage(connString string, containerName string) (*Storage, error) {
 }
 
 // Upload file to Azure Blob Storage with SAS token
-func (s *Storage) Upload(ctx context.Context, file io.Reader, filename string, contentType string) (string, error) {
+func (s *Storage) Upload(ctx context.Context, file io.Reader, filename string, contentType string) (_ string, err error) {
+	var size int
+	defer s.observe(ctx, "upload", filename, time.Now(), &size, &err)
+
 	// Read file into buffer
 	data, err := io.ReadAll(file)
 	if err != nil {
@@ -59,7 +67,7 @@ func (s *Storage) Upload(ctx context.Context, file io.Reader, filename string, c
 	}
 
 	// Generate SAS token for upload
-	sasURL, err := s.generateUploadSAS(filename)
+	sasURL, _, err := s.generateSAS(filename, sas.BlobPermissions{Write: true, Create: true})
 	if err != nil {
 		return "", fmt.Errorf("failed to generate SAS token: %w", err)
 	}
@@ -72,6 +80,7 @@ func (s *Storage) Upload(ctx context.Context, file io.Reader, filename string, c
 
 	// Create a ReadSeekCloser from bytes
 	reader := bytes.NewReader(data)
+	size = len(data)
 
 	// Upload with options
 	options := &blockblob.UploadOptions{
@@ -89,7 +98,10 @@ func (s *Storage) Upload(ctx context.Context, file io.Reader, filename string, c
 }
 
 // Download file from Azure Blob Storage
-func (s *Storage) Download(ctx context.Context, filename string) ([]byte, string, error) {
+func (s *Storage) Download(ctx context.Context, filename string) (_ []byte, _ string, err error) {
+	var size int
+	defer s.observe(ctx, "download", filename, time.Now(), &size, &err)
+
 	// Get blob client
 	blobClient := s.client.ServiceClient().NewContainerClient(s.containerName).NewBlobClient(filename)
 
@@ -105,6 +117,7 @@ func (s *Storage) Download(ctx context.Context, filename string) ([]byte, string
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to read blob content: %w", err)
 	}
+	size = len(data)
 
 	// Get content type
 	contentType := ""
@@ -116,12 +129,14 @@ func (s *Storage) Download(ctx context.Context, filename string) ([]byte, string
 }
 
 // Remove deletes a file from Azure Blob Storage
-func (s *Storage) Remove(ctx context.Context, filename string) error {
+func (s *Storage) Remove(ctx context.Context, filename string) (err error) {
+	defer s.observe(ctx, "remove", filename, time.Now(), nil, &err)
+
 	// Get blob client
 	blobClient := s.client.ServiceClient().NewContainerClient(s.containerName).NewBlobClient(filename)
 
 	// Delete blob
-	_, err := blobClient.Delete(ctx, nil)
+	_, err = blobClient.Delete(ctx, nil)
 	if err != nil {
 		return apperrors.ErrInternalServer.WithCause(err).WithDetails(map[string]string{
 			"operation": "remove_doc",
@@ -131,12 +146,136 @@ func (s *Storage) Remove(ctx context.Context, filename string) error {
 	return nil
 }
 
-// generateUploadSAS creates a SAS token for uploading a blob
-func (s *Storage) generateUploadSAS(filename string) (string, error) {
+// Ping reads the container properties, which fails on bad credentials or a
+// missing container as well as when the account is unreachable
+func (s *Storage) Ping(ctx context.Context) error {
+	_, err := s.client.ServiceClient().NewContainerClient(s.containerName).GetProperties(ctx, nil)
+	return err
+}
+
+// PresignUpload returns a write-only SAS URL clients upload the blob to directly
+func (s *Storage) PresignUpload(ctx context.Context, filename string) (*app.PresignedUpload, error) {
+	sasURL, expiresAt, err := s.generateSAS(filename, sas.BlobPermissions{Write: true, Create: true})
+	if err != nil {
+		return nil, apperrors.ErrInternalServer.WithCause(err).WithDetails(map[string]string{
+			"operation": "presign_upload",
+		})
+	}
+
+	return &app.PresignedUpload{
+		UploadURL: sasURL,
+		FileURL:   s.URL(filename),
+		Headers:   map[string]string{"x-ms-blob-type": "BlockBlob"},
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// PresignRead returns a read-only SAS URL for a stored blob
+func (s *Storage) PresignRead(ctx context.Context, filename string) (string, time.Time, error) {
+	sasURL, expiresAt, err := s.generateSAS(filename, sas.BlobPermissions{Read: true})
+	if err != nil {
+		return "", time.Time{}, apperrors.ErrInternalServer.WithCause(err).WithDetails(map[string]string{
+			"operation": "presign_read",
+		})
+	}
+
+	return sasURL, expiresAt, nil
+}
+
+// StatBlob reads the blob properties (a HEAD request) without downloading it
+func (s *Storage) StatBlob(ctx context.Context, filename string) (_ int64, _ string, _ bool, err error) {
+	defer s.observe(ctx, "stat", filename, time.Now(), nil, &err)
+
+	blobClient := s.client.ServiceClient().NewContainerClient(s.containerName).NewBlobClient(filename)
+
+	props, err := blobClient.GetProperties(ctx, nil)
+	if bloberror.HasCode(err, bloberror.BlobNotFound) {
+		return 0, "", false, nil
+	}
+	if err != nil {
+		return 0, "", false, apperrors.ErrInternalServer.WithCause(err).WithDetails(map[string]string{
+			"operation": "stat_blob",
+		})
+	}
+
+	var size int64
+	if props.ContentLength != nil {
+		size = *props.ContentLength
+	}
+	contentType := ""
+	if props.ContentType != nil {
+		contentType = *props.ContentType
+	}
+	return size, contentType, true, nil
+}
+
+// ListBlobs pages through the whole container, 5000 blobs per request
+func (s *Storage) ListBlobs(ctx context.Context) (_ []app.BlobInfo, err error) {
+	defer s.observe(ctx, "list", "", time.Now(), nil, &err)
+
+	var blobs []app.BlobInfo
+	pager := s.client.NewListBlobsFlatPager(s.containerName, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, apperrors.ErrInternalServer.WithCause(err).WithDetails(map[string]string{
+				"operation": "list_blobs",
+			})
+		}
+		for _, item := range page.Segment.BlobItems {
+			if item.Name == nil {
+				continue
+			}
+			blob := app.BlobInfo{Name: *item.Name}
+			if item.Properties != nil {
+				if item.Properties.ContentLength != nil {
+					blob.Size = *item.Properties.ContentLength
+				}
+				if item.Properties.LastModified != nil {
+					blob.LastModified = *item.Properties.LastModified
+				}
+			}
+			blobs = append(blobs, blob)
+		}
+	}
+
+	return blobs, nil
+}
+
+// observe logs a finished blob operation with the request-scoped logger and
+// records its outcome, duration and, for transfers, size in the blob metrics.
+// It is deferred, so size and err point at the operation's results.
+func (s *Storage) observe(ctx context.Context, operation string, filename string, start time.Time, size *int, err *error) {
+	duration := time.Since(start)
+	metrics.BlobDurationSeconds.Observe(duration.Seconds())
+
+	fields := []zap.Field{
+		zap.String("operation", operation),
+		zap.String("container", s.containerName),
+		zap.String("filename", filename),
+		zap.Duration("duration", duration),
+	}
+	if size != nil {
+		metrics.BlobTransferBytes.Observe(float64(*size))
+		fields = append(fields, zap.Int("bytes", *size))
+	}
+
+	if *err != nil {
+		metrics.BlobOperations.Add(operation+".failed", 1)
+		log.FromContext(ctx).Error("Blob operation failed", append(fields, zap.Error(*err))...)
+		return
+	}
+	metrics.BlobOperations.Add(operation+".ok", 1)
+	log.FromContext(ctx).Info("Blob operation completed", fields...)
+}
+
+// generateSAS creates a SAS token granting permissions on a single blob and
+// returns the signed URL with its expiry
+func (s *Storage) generateSAS(filename string, permissions sas.BlobPermissions) (string, time.Time, error) {
 	// Create shared key credential
 	credential, err := azblob.NewSharedKeyCredential(s.account, s.accountKey)
 	if err != nil {
-		return "", fmt.Errorf("failed to create credential: %w", err)
+		return "", time.Time{}, fmt.Errorf("failed to create credential: %w", err)
 	}
 
 	// Set SAS token permissions and expiry
@@ -144,7 +283,6 @@ func (s *Storage) generateUploadSAS(filename string) (string, error) {
 	expiry := now.Add(15 * time.Minute) // Token valid for 15 minutes
 
 	// Create SAS query parameters
-	permissions := sas.BlobPermissions{Write: true, Create: true}
 	sasQueryParams, err := sas.BlobSignatureValues{
 		Protocol:      sas.ProtocolHTTPS,
 		StartTime:     now.Add(-5 * time.Minute), // Start 5 minutes ago to handle clock skew
@@ -155,7 +293,7 @@ func (s *Storage) generateUploadSAS(filename string) (string, error) {
 	}.SignWithSharedKey(credential)
 
 	if err != nil {
-		return "", fmt.Errorf("failed to sign SAS: %w", err)
+		return "", time.Time{}, fmt.Errorf("failed to sign SAS: %w", err)
 	}
 
 	// Build full URL with SAS token
@@ -166,7 +304,7 @@ func (s *Storage) generateUploadSAS(filename string) (string, error) {
 		sasQueryParams.Encode(),
 	)
 
-	return sasURL, nil
+	return sasURL, expiry, nil
 }
 
 // // Delete file
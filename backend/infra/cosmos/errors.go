@@ -0,0 +1,58 @@
+package cosmosdb
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+
+	apperrors "microservicetest/pkg/errors"
+)
+
+// retryAfterHeader carries Cosmos DB's backoff hint on throttled (429) responses
+const retryAfterHeader = "x-ms-retry-after-ms"
+
+// convertCosmosError converts Cosmos DB errors to application errors. The SDK
+// has already retried throttled requests by the time a 429 surfaces here.
+func convertCosmosError(operation string, err error) error {
+	var respErr *azcore.ResponseError
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return apperrors.ErrRequestTimeout.WithCause(err)
+
+	case errors.As(err, &respErr) && respErr.StatusCode == http.StatusTooManyRequests:
+		appErr := apperrors.ErrRateLimitExceeded.WithCause(err)
+		if retryAfter, ok := parseRetryAfter(respErr.RawResponse); ok {
+			appErr = appErr.WithRetryAfter(retryAfter)
+		}
+		return appErr
+
+	case errors.As(err, &respErr) && respErr.StatusCode == http.StatusServiceUnavailable:
+		return apperrors.ErrServiceUnavailable.WithCause(err)
+
+	case errors.As(err, &netErr):
+		// Connection refused, DNS failures, resets
+		return apperrors.ErrServiceUnavailable.WithCause(err)
+
+	default:
+		return apperrors.NewDatabaseError(operation, err)
+	}
+}
+
+func parseRetryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+
+	ms, err := strconv.ParseFloat(resp.Header.Get(retryAfterHeader), 64)
+	if err != nil || ms <= 0 {
+		return 0, false
+	}
+	return time.Duration(ms * float64(time.Millisecond)), true
+}
@@ -0,0 +1,75 @@
+package cosmosdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+
+	apperrors "microservicetest/pkg/errors"
+)
+
+func TestConvertCosmosError_Throttled(t *testing.T) {
+	err := &azcore.ResponseError{
+		StatusCode: http.StatusTooManyRequests,
+		RawResponse: &http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Header:     http.Header{"X-Ms-Retry-After-Ms": []string{"1500"}},
+		},
+	}
+
+	converted := convertCosmosError("query", fmt.Errorf("paging: %w", err))
+
+	if !errors.Is(converted, apperrors.ErrRateLimitExceeded) {
+		t.Fatalf("Expected ErrRateLimitExceeded, got %v", converted)
+	}
+
+	var appErr *apperrors.AppError
+	errors.As(converted, &appErr)
+	if appErr.RetryAfter != 1500*time.Millisecond {
+		t.Errorf("Expected RetryAfter 1.5s, got %v", appErr.RetryAfter)
+	}
+}
+
+func TestConvertCosmosError_ThrottledWithoutHint(t *testing.T) {
+	err := &azcore.ResponseError{StatusCode: http.StatusTooManyRequests}
+
+	var appErr *apperrors.AppError
+	if !errors.As(convertCosmosError("query", err), &appErr) || appErr.Code != "RATE_LIMIT_EXCEEDED" {
+		t.Fatalf("Expected RATE_LIMIT_EXCEEDED, got %v", appErr)
+	}
+	if appErr.RetryAfter != 0 {
+		t.Errorf("Expected no RetryAfter, got %v", appErr.RetryAfter)
+	}
+}
+
+func TestConvertCosmosError_Unavailable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"service unavailable", &azcore.ResponseError{StatusCode: http.StatusServiceUnavailable}},
+		{"connection refused", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}},
+	}
+
+	for _, tt := range tests {
+		if converted := convertCosmosError("query", tt.err); !errors.Is(converted, apperrors.ErrServiceUnavailable) {
+			t.Errorf("%s: expected ErrServiceUnavailable, got %v", tt.name, converted)
+		}
+	}
+}
+
+func TestConvertCosmosError_Other(t *testing.T) {
+	if converted := convertCosmosError("query", context.DeadlineExceeded); !errors.Is(converted, apperrors.ErrRequestTimeout) {
+		t.Errorf("Expected ErrRequestTimeout, got %v", converted)
+	}
+
+	if converted := convertCosmosError("query", &azcore.ResponseError{StatusCode: http.StatusBadRequest}); !errors.Is(converted, apperrors.ErrDatabaseQuery) {
+		t.Errorf("Expected ErrDatabaseQuery, got %v", converted)
+	}
+}
@@ -3,11 +3,17 @@ package cosmosdb
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"microservicetest/domain"
+	"microservicetest/pkg/log"
+	"net/http"
+	"sync"
 	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	"go.uber.org/zap"
 )
 
 type GPSRepository struct {
@@ -48,42 +54,86 @@ func NewGPSRepository(endpoint, key, databaseName, containerName string) (*GPSRe
 	}, nil
 }
 
-// GetGPSDataByDateRange retrieves GPS data within a date range
-func (r *GPSRepository) GetGPSDataByDateRange(ctx context.Context, deviceID string, startDate, endDate time.Time) ([]domain.GPSData, error) {
-	query := `SELECT * FROM c`
-
-	// queryOptions := azcosmos.QueryOptions{
-	// 	QueryParameters: []azcosmos.QueryParameter{
-	// 		{Name: "@deviceID", Value: deviceID},
-	// 		{Name: "@startDate", Value: startDate.Unix()},
-	// 		{Name: "@endDate", Value: endDate.Unix()},
-	// 	},
-	// }
+// GetGPSDataByDateRange retrieves at most limit GPS points with a timestamp
+// within [startDate, endDate], oldest first
+func (r *GPSRepository) GetGPSDataByDateRange(ctx context.Context, deviceID string, startDate, endDate time.Time, limit int) ([]domain.GPSData, error) {
+	query, queryOptions := dateRangeQuery(limit, startDate, endDate)
 
 	// Create partition key with the device_id value
 	pk := azcosmos.NewPartitionKeyString(deviceID)
-	queryPager := r.container.NewQueryItemsPager(query, pk, nil)
+	queryPager := r.container.NewQueryItemsPager(query, pk, queryOptions)
 
 	var gpsDataList []domain.GPSData
 
 	for queryPager.More() {
 		response, err := queryPager.NextPage(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("failed to query items: %w", err)
+			return nil, convertCosmosError("get_gps_data_by_date_range", err)
 		}
 
-		for _, item := range response.Items {
-			var gpsData domain.GPSData
-			if err := json.Unmarshal(item, &gpsData); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal item: %w", err)
+		points, err := decodeGPSPoints(ctx, response.Items)
+		if err != nil {
+			return nil, err
+		}
+		for _, point := range points {
+			if inDateRange(point, startDate, endDate) {
+				gpsDataList = append(gpsDataList, point)
 			}
-			gpsDataList = append(gpsDataList, gpsData)
 		}
 	}
 
 	return gpsDataList, nil
 }
 
+// dateRangeQuery selects up to limit points with a timestamp within
+// [startDate, endDate], oldest first. Timestamps are stored as unix seconds, so
+// the bounds are widened to whole seconds and inDateRange applies them exactly.
+func dateRangeQuery(limit int, startDate, endDate time.Time) (string, *azcosmos.QueryOptions) {
+	query := fmt.Sprintf(`SELECT TOP %d * FROM c WHERE c.timestamp >= @startDate AND c.timestamp <= @endDate ORDER BY c.timestamp ASC`, limit)
+
+	return query, &azcosmos.QueryOptions{
+		QueryParameters: []azcosmos.QueryParameter{
+			{Name: "@startDate", Value: startDate.Unix()},
+			{Name: "@endDate", Value: endDate.Add(time.Second - 1).Unix()},
+		},
+	}
+}
+
+func inDateRange(point domain.GPSData, startDate, endDate time.Time) bool {
+	return point.Timestamp >= unixSeconds(startDate) && point.Timestamp <= unixSeconds(endDate)
+}
+
+func unixSeconds(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(time.Second)
+}
+
+// decodeGPSPoints unmarshals query items, skipping and logging invalid points
+func decodeGPSPoints(ctx context.Context, items [][]byte) ([]domain.GPSData, error) {
+	var points []domain.GPSData
+	for _, item := range items {
+		var gpsData domain.GPSData
+		if err := json.Unmarshal(item, &gpsData); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal item: %w", err)
+		}
+		if err := gpsData.Validate(); err != nil {
+			log.FromContext(ctx).Warn("Skipping invalid GPS point",
+				zap.String("id", gpsData.ID),
+				zap.String("device_id", gpsData.DeviceID),
+				zap.Error(err),
+			)
+			continue
+		}
+		points = append(points, gpsData)
+	}
+	return points, nil
+}
+
+// Ping reads the container's properties
+func (r *GPSRepository) Ping(ctx context.Context) error {
+	_, err := r.container.Read(ctx, nil)
+	return err
+}
+
 // GetGPSDataByDevice retrieves all GPS data for a specific device
 func (r *GPSRepository) GetGPSDataByDevice(ctx context.Context, deviceID string, limit int) ([]domain.GPSData, error) {
 	query := fmt.Sprintf(`SELECT TOP %d * FROM c WHERE c.device_id = @deviceID ORDER BY c.timestamp DESC`, limit)
@@ -103,17 +153,100 @@ func (r *GPSRepository) GetGPSDataByDevice(ctx context.Context, deviceID string,
 	for queryPager.More() {
 		response, err := queryPager.NextPage(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("failed to query items: %w", err)
+			return nil, convertCosmosError("get_gps_data_by_device", err)
+		}
+
+		points, err := decodeGPSPoints(ctx, response.Items)
+		if err != nil {
+			return nil, err
+		}
+		gpsDataList = append(gpsDataList, points...)
+	}
+
+	return gpsDataList, nil
+}
+
+// gpsDeleteWorkers bounds the point deletes sent to Cosmos DB at once
+const gpsDeleteWorkers = 8
+
+// DeleteGPSDataByDateRange deletes a device's GPS points with a timestamp
+// within [startDate, endDate] and returns how many were deleted. The points are
+// looked up and deleted within the device partition. Points that are already
+// gone are not counted; on failure the points deleted so far are reported with
+// the first error.
+func (r *GPSRepository) DeleteGPSDataByDateRange(ctx context.Context, deviceID string, startDate, endDate time.Time) (int, error) {
+	query := `SELECT c.id FROM c WHERE c.timestamp >= @startDate AND c.timestamp <= @endDate`
+
+	queryOptions := azcosmos.QueryOptions{
+		QueryParameters: []azcosmos.QueryParameter{
+			{Name: "@startDate", Value: startDate.Unix()},
+			{Name: "@endDate", Value: endDate.Unix()},
+		},
+	}
+
+	pk := azcosmos.NewPartitionKeyString(deviceID)
+	queryPager := r.container.NewQueryItemsPager(query, pk, &queryOptions)
+
+	var ids []string
+	for queryPager.More() {
+		response, err := queryPager.NextPage(ctx)
+		if err != nil {
+			return 0, convertCosmosError("find_gps_data_to_delete", err)
 		}
 
 		for _, item := range response.Items {
-			var gpsData domain.GPSData
-			if err := json.Unmarshal(item, &gpsData); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal item: %w", err)
+			var row struct {
+				ID string `json:"id"`
+			}
+			if err := json.Unmarshal(item, &row); err != nil {
+				return 0, fmt.Errorf("failed to unmarshal item: %w", err)
 			}
-			gpsDataList = append(gpsDataList, gpsData)
+			ids = append(ids, row.ID)
 		}
 	}
 
-	return gpsDataList, nil
+	var (
+		mu       sync.Mutex
+		deleted  int
+		firstErr error
+	)
+	queue := make(chan string)
+	var wg sync.WaitGroup
+	for range min(gpsDeleteWorkers, len(ids)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range queue {
+				_, err := r.container.DeleteItem(ctx, pk, id, nil)
+				var respErr *azcore.ResponseError
+				if errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound {
+					continue
+				}
+
+				mu.Lock()
+				if err == nil {
+					deleted++
+				} else if firstErr == nil {
+					firstErr = convertCosmosError("delete_gps_data", err)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, id := range ids {
+		queue <- id
+	}
+	close(queue)
+	wg.Wait()
+
+	log.FromContext(ctx).Info("Deleted GPS data",
+		zap.String("device_id", deviceID),
+		zap.Time("start_date", startDate),
+		zap.Time("end_date", endDate),
+		zap.Int("found", len(ids)),
+		zap.Int("deleted", deleted),
+		zap.Error(firstErr),
+	)
+
+	return deleted, firstErr
 }
@@ -0,0 +1,56 @@
+package cosmosdb
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDateRangeQuery(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	end := time.Unix(1700003600, 500*int64(time.Millisecond))
+
+	query, options := dateRangeQuery(50, start, end)
+	if !strings.Contains(query, "WHERE c.timestamp >= @startDate AND c.timestamp <= @endDate") {
+		t.Errorf("Expected the query to filter on the date range, got %s", query)
+	}
+	if !strings.HasPrefix(query, "SELECT TOP 50 ") {
+		t.Errorf("Expected the limit in the query, got %s", query)
+	}
+
+	params := make(map[string]any)
+	for _, p := range options.QueryParameters {
+		params[p.Name] = p.Value
+	}
+	if params["@startDate"] != int64(1700000000) || params["@endDate"] != int64(1700003601) {
+		t.Errorf("Expected the bounds as whole unix seconds covering the range, got %v", params)
+	}
+}
+
+func TestDecodeGPSPoints_DateRange(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	end := time.Unix(1700003600, 0)
+	items := [][]byte{
+		[]byte(`{"id":"before","device_id":"DEV_1","latitude":41,"longitude":29,"timestamp":1699999999.5}`),
+		[]byte(`{"id":"first","device_id":"DEV_1","latitude":41,"longitude":29,"timestamp":1700000000}`),
+		[]byte(`{"id":"inside","device_id":"DEV_1","latitude":41,"longitude":29,"timestamp":1700001800.25}`),
+		[]byte(`{"id":"last","device_id":"DEV_1","latitude":41,"longitude":29,"timestamp":1700003600}`),
+		[]byte(`{"id":"after","device_id":"DEV_1","latitude":41,"longitude":29,"timestamp":1700003600.5}`),
+	}
+
+	points, err := decodeGPSPoints(context.Background(), items)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var kept []string
+	for _, point := range points {
+		if inDateRange(point, start, end) {
+			kept = append(kept, point.ID)
+		}
+	}
+	if strings.Join(kept, ",") != "first,inside,last" {
+		t.Errorf("Expected only the points within the range, got %v", kept)
+	}
+}
@@ -0,0 +1,146 @@
+package couchbase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/couchbase/gocb/v2"
+	"go.uber.org/zap"
+
+	"microservicetest/app"
+	apperrors "microservicetest/pkg/errors"
+)
+
+// auditEntryType tells audit entries apart from the vehicles sharing the bucket
+const auditEntryType = "audit_entry"
+
+// AuditStore keeps audit entries as "audit::<id>" documents next to the vehicles.
+// Listing is served by idx_audit_vehicle, see the README.
+type AuditStore struct {
+	cluster    *gocb.Cluster
+	collection *gocb.Collection
+	durability gocb.DurabilityLevel
+	timeouts   Timeouts
+}
+
+var _ app.AuditLog = (*AuditStore)(nil)
+
+// auditDocument is the stored form of an entry
+type auditDocument struct {
+	app.AuditEntry
+	Type string `json:"type"`
+}
+
+// NewAuditStore stores entries in the default collection of bucket and lists them through cluster
+func NewAuditStore(cluster *gocb.Cluster, bucket *gocb.Bucket, durability string, timeouts Timeouts) *AuditStore {
+	durabilityLevel, ok := durabilityLevels[durability]
+	if !ok {
+		zap.L().Fatal("Unknown couchbase durability level", zap.String("durability", durability))
+	}
+
+	return &AuditStore{
+		cluster:    cluster,
+		collection: bucket.DefaultCollection(),
+		durability: durabilityLevel,
+		timeouts:   timeouts,
+	}
+}
+
+func auditKey(id string) string {
+	return "audit::" + id
+}
+
+// Record upserts the entry, stored with its time in UTC. A fail-closed audit
+// log writes an entry twice, pending before its change and complete after.
+func (s *AuditStore) Record(ctx context.Context, entry app.AuditEntry) error {
+	if entry.ID == "" || entry.VehicleID == "" {
+		return apperrors.NewValidationError("audit_entry", "id and vehicle_id are required")
+	}
+	entry.OccurredAt = entry.OccurredAt.UTC()
+
+	_, err := s.collection.Upsert(auditKey(entry.ID), auditDocument{AuditEntry: entry, Type: auditEntryType}, &gocb.UpsertOptions{
+		DurabilityLevel: s.durability,
+		Timeout:         s.timeouts.KV,
+		Context:         ctx,
+	})
+	if err != nil {
+		return apperrors.NewDatabaseError("record_audit_entry", err)
+	}
+	return nil
+}
+
+// List queries a vehicle's entries newest first
+func (s *AuditStore) List(ctx context.Context, vehicleID string, filter app.AuditFilter) ([]app.AuditEntry, int, error) {
+	if vehicleID == "" {
+		return nil, 0, apperrors.ErrInvalidID
+	}
+
+	where := `a.type = $type AND a.vehicle_id = $vehicle_id`
+	params := map[string]interface{}{"type": auditEntryType, "vehicle_id": vehicleID}
+
+	if filter.Action != "" {
+		where += ` AND a.action = $action`
+		params["action"] = filter.Action
+	}
+	if filter.Actor != "" {
+		where += ` AND a.actor = $actor`
+		params["actor"] = filter.Actor
+	}
+	if !filter.From.IsZero() {
+		where += ` AND STR_TO_MILLIS(a.occurred_at) >= $from`
+		params["from"] = filter.From.UnixMilli()
+	}
+	if !filter.To.IsZero() {
+		where += ` AND STR_TO_MILLIS(a.occurred_at) < $to`
+		params["to"] = filter.To.UnixMilli()
+	}
+
+	countResult, err := s.cluster.Query(`SELECT RAW COUNT(*) FROM vehicles a WHERE `+where, &gocb.QueryOptions{
+		NamedParameters: params,
+		Timeout:         s.timeouts.Query,
+		Context:         ctx,
+	})
+	if err != nil {
+		return nil, 0, apperrors.NewDatabaseError("count_audit_entries", err)
+	}
+
+	var total int
+	if err := countResult.One(&total); err != nil {
+		return nil, 0, apperrors.NewDatabaseError("count_audit_entries", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT a.id, a.vehicle_id, a.action, a.actor, a.occurred_at, a.changes
+		FROM vehicles a
+		WHERE %s
+		ORDER BY STR_TO_MILLIS(a.occurred_at) DESC, a.id
+		LIMIT $limit OFFSET $offset
+	`, where)
+	params["limit"] = filter.Limit
+	params["offset"] = filter.Offset
+
+	result, err := s.cluster.Query(query, &gocb.QueryOptions{
+		NamedParameters: params,
+		Timeout:         s.timeouts.Query,
+		Context:         ctx,
+	})
+	if err != nil {
+		return nil, 0, apperrors.NewDatabaseError("list_audit_entries", err)
+	}
+	defer result.Close()
+
+	entries := []app.AuditEntry{}
+	for result.Next() {
+		var entry app.AuditEntry
+		if err := decodeRow(ctx, result, "audit_entry", &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := result.Err(); err != nil {
+		return nil, 0, apperrors.NewDatabaseError("list_audit_entries_iteration", err)
+	}
+
+	return entries, total, nil
+}
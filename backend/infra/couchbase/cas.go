@@ -0,0 +1,51 @@
+package couchbase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/couchbase/gocb/v2"
+
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+)
+
+// maxCASRetries bounds how often an optimistic read-modify-write is retried
+// after losing a CAS race
+const maxCASRetries = 5
+
+// vehicleStore is the CAS-aware persistence needed by mutateVehicle
+type vehicleStore interface {
+	getVehicleWithCAS(ctx context.Context, id string) (*domain.Vehicle, gocb.Cas, error)
+	replaceVehicleWithCAS(ctx context.Context, vehicle *domain.Vehicle, cas gocb.Cas) error
+}
+
+// mutateVehicle applies mutate to the stored vehicle and writes it back only if
+// nobody replaced it in between. On a CAS mismatch the vehicle is re-read and
+// mutate runs again, so checks inside mutate (e.g. duplicate IDs) always see
+// the latest state. Errors returned by mutate abort the loop unchanged.
+func mutateVehicle(ctx context.Context, store vehicleStore, id string, mutate func(vehicle *domain.Vehicle) error) (*domain.Vehicle, error) {
+	for attempt := 0; attempt < maxCASRetries; attempt++ {
+		vehicle, cas, err := store.getVehicleWithCAS(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := mutate(vehicle); err != nil {
+			return nil, err
+		}
+
+		err = store.replaceVehicleWithCAS(ctx, vehicle, cas)
+		if err == nil {
+			return vehicle, nil
+		}
+		if !errors.Is(err, gocb.ErrCasMismatch) {
+			return nil, err
+		}
+	}
+
+	return nil, apperrors.ErrConcurrentModification.WithDetails(map[string]string{
+		"resource": "vehicle",
+		"id":       id,
+	})
+}
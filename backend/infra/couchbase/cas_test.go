@@ -0,0 +1,275 @@
+package couchbase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/couchbase/gocb/v2"
+)
+
+// memoryVehicleStore is an in-memory vehicleStore with Couchbase-like CAS semantics
+type memoryVehicleStore struct {
+	mu      sync.Mutex
+	vehicle domain.Vehicle
+	cas     gocb.Cas
+}
+
+func (s *memoryVehicleStore) getVehicleWithCAS(ctx context.Context, id string) (*domain.Vehicle, gocb.Cas, error) {
+	s.mu.Lock()
+	vehicle := s.vehicle
+	vehicle.Documents = append([]domain.Document(nil), s.vehicle.Documents...)
+	vehicle.Pictures = append([]domain.Picture(nil), s.vehicle.Pictures...)
+	cas := s.cas
+	s.mu.Unlock()
+
+	// Let other goroutines read the same version before we write it back
+	runtime.Gosched()
+
+	return &vehicle, cas, nil
+}
+
+func (s *memoryVehicleStore) replaceVehicleWithCAS(ctx context.Context, vehicle *domain.Vehicle, cas gocb.Cas) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cas != s.cas {
+		return gocb.ErrCasMismatch
+	}
+	s.vehicle = *vehicle
+	s.cas++
+	return nil
+}
+
+func addDocument(store vehicleStore, document domain.Document) error {
+	_, err := mutateVehicle(context.Background(), store, "vehicle-1", func(vehicle *domain.Vehicle) error {
+		if err := vehicle.AddDocument(document); err != nil {
+			return apperrors.NewConflictError("document", err.Error())
+		}
+		return nil
+	})
+	return err
+}
+
+func TestMutateVehicle_ConcurrentAddsAreNotLost(t *testing.T) {
+	store := &memoryVehicleStore{vehicle: domain.Vehicle{ID: "vehicle-1"}, cas: 1}
+	const writers = maxCASRetries
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- addDocument(store, domain.Document{ID: fmt.Sprintf("doc-%d", i), Type: domain.DocumentTypeInsurancePolicy})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	}
+
+	if len(store.vehicle.Documents) != writers {
+		t.Errorf("Expected %d documents, got %d", writers, len(store.vehicle.Documents))
+	}
+}
+
+func TestMutateVehicle_ConcurrentDuplicateIDs(t *testing.T) {
+	store := &memoryVehicleStore{vehicle: domain.Vehicle{ID: "vehicle-1"}, cas: 1}
+	const writers = maxCASRetries
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- addDocument(store, domain.Document{ID: "doc-1", Type: domain.DocumentTypeInsurancePolicy})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded, conflicts := 0, 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, apperrors.ErrResourceExists):
+			conflicts++
+		default:
+			t.Errorf("Expected ErrResourceExists, got %v", err)
+		}
+	}
+
+	if succeeded != 1 || conflicts != writers-1 {
+		t.Errorf("Expected 1 success and %d conflicts, got %d and %d", writers-1, succeeded, conflicts)
+	}
+	if len(store.vehicle.Documents) != 1 {
+		t.Errorf("Expected 1 document, got %d", len(store.vehicle.Documents))
+	}
+}
+
+func TestMutateVehicle_RetriesExhausted(t *testing.T) {
+	store := &memoryVehicleStore{vehicle: domain.Vehicle{ID: "vehicle-1"}, cas: 1}
+
+	_, err := mutateVehicle(context.Background(), store, "vehicle-1", func(vehicle *domain.Vehicle) error {
+		// Simulate another writer winning every race
+		store.mu.Lock()
+		store.cas++
+		store.mu.Unlock()
+		return nil
+	})
+
+	if !errors.Is(err, apperrors.ErrConcurrentModification) {
+		t.Fatalf("Expected ErrConcurrentModification, got %v", err)
+	}
+}
+
+func TestUpdateVehicle_ConcurrentWithAddDocument(t *testing.T) {
+	store := &memoryVehicleStore{vehicle: domain.Vehicle{ID: "vehicle-1", Color: "red"}, cas: 1}
+
+	// Even writers add documents, odd writers update the vehicle as PUT does
+	const writers = maxCASRetries
+	const adds = (writers + 1) / 2
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 0 {
+				errs <- addDocument(store, domain.Document{ID: fmt.Sprintf("doc-%d", i), Type: domain.DocumentTypeInsurancePolicy})
+				return
+			}
+			_, err := mutateVehicle(context.Background(), store, "vehicle-1", func(vehicle *domain.Vehicle) error {
+				vehicle.Color = "blue"
+				vehicle.Mileage += 100
+				return nil
+			})
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	}
+
+	if len(store.vehicle.Documents) != adds {
+		t.Errorf("Expected %d documents, got %d", adds, len(store.vehicle.Documents))
+	}
+	if store.vehicle.Color != "blue" || store.vehicle.Mileage != (writers-adds)*100 {
+		t.Errorf("Expected every update to be applied, got color %q and mileage %d", store.vehicle.Color, store.vehicle.Mileage)
+	}
+}
+
+func TestSetMainPicture_ConcurrentWithAdds(t *testing.T) {
+	store := &memoryVehicleStore{vehicle: domain.Vehicle{
+		ID: "vehicle-1",
+		Pictures: []domain.Picture{
+			{ID: "pic-0", IsMain: true},
+			{ID: "pic-1"},
+		},
+	}, cas: 1}
+
+	// Odd writers add pictures, even writers move the main picture
+	const writers = maxCASRetries
+	const adds = writers / 2
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var err error
+			if i%2 == 0 {
+				_, err = setMainPicture(context.Background(), store, "vehicle-1", "pic-1")
+			} else {
+				_, err = addPicture(context.Background(), store, "vehicle-1", domain.Picture{ID: fmt.Sprintf("new-%d", i)})
+			}
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	}
+
+	if len(store.vehicle.Pictures) != 2+adds {
+		t.Errorf("Expected %d pictures, got %d", 2+adds, len(store.vehicle.Pictures))
+	}
+	if mains := mainPictureCount(&store.vehicle); mains != 1 {
+		t.Errorf("Expected exactly one main picture, got %d", mains)
+	}
+	if main := store.vehicle.GetMainPicture(); main == nil || main.ID != "pic-1" {
+		t.Errorf("Expected pic-1 to be main, got %v", main)
+	}
+}
+
+func TestSetMainPicture_UnknownPicture(t *testing.T) {
+	store := &memoryVehicleStore{vehicle: domain.Vehicle{
+		ID:       "vehicle-1",
+		Pictures: []domain.Picture{{ID: "pic-0", IsMain: true}},
+	}, cas: 1}
+
+	_, err := setMainPicture(context.Background(), store, "vehicle-1", "pic-404")
+
+	if !errors.Is(err, apperrors.ErrResourceNotFound) {
+		t.Errorf("Expected ErrResourceNotFound, got %v", err)
+	}
+	if !store.vehicle.Pictures[0].IsMain {
+		t.Error("Expected the main picture to be unchanged")
+	}
+}
+
+func TestDeleteDocument_UnmodifiedSince(t *testing.T) {
+	updatedAt := time.Date(2026, 3, 1, 12, 0, 0, 500_000_000, time.UTC)
+	newStore := func() *memoryVehicleStore {
+		return &memoryVehicleStore{vehicle: domain.Vehicle{
+			ID:        "vehicle-1",
+			Documents: []domain.Document{{ID: "doc-1"}},
+			UpdatedAt: updatedAt,
+		}, cas: 1}
+	}
+
+	// The client read the vehicle before its last change
+	store := newStore()
+	_, err := deleteDocument(context.Background(), store, "vehicle-1", "doc-1", updatedAt.Add(-time.Minute))
+	if !errors.Is(err, apperrors.ErrPreconditionFailed) {
+		t.Errorf("Expected ErrPreconditionFailed, got %v", err)
+	}
+	if len(store.vehicle.Documents) != 1 {
+		t.Error("Expected the document to be kept")
+	}
+
+	// HTTP dates drop the sub-second part of updated_at
+	for _, since := range []time.Time{updatedAt.Truncate(time.Second), {}} {
+		store = newStore()
+		if _, err := deleteDocument(context.Background(), store, "vehicle-1", "doc-1", since); err != nil {
+			t.Errorf("Expected no error for %v, got %v", since, err)
+		}
+		if len(store.vehicle.Documents) != 0 {
+			t.Errorf("Expected the document to be deleted for %v", since)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package couchbase
+
+import (
+	"context"
+	"encoding/json"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/log"
+
+	"go.uber.org/zap"
+)
+
+// contentResult is a key-value lookup result, such as *gocb.GetResult
+type contentResult interface {
+	Content(valuePtr interface{}) error
+}
+
+// rowResult is a query result positioned on a row, such as *gocb.QueryResult
+type rowResult interface {
+	Row(valuePtr interface{}) error
+}
+
+// decodeContent decodes a looked-up document into valuePtr. A document that
+// does not fit is reported as ErrDataCorruption with its ID, and its raw JSON
+// is logged at debug level so it can be found and fixed.
+func decodeContent(ctx context.Context, result contentResult, resource, id string, valuePtr any) error {
+	var raw json.RawMessage
+	if err := result.Content(&raw); err != nil {
+		logDataCorruption(ctx, resource, id, nil, err)
+		return apperrors.NewDataCorruptionError(resource, id, err)
+	}
+	if err := json.Unmarshal(raw, valuePtr); err != nil {
+		logDataCorruption(ctx, resource, id, raw, err)
+		return apperrors.NewDataCorruptionError(resource, id, err)
+	}
+	return nil
+}
+
+// decodeRow decodes the current query row into valuePtr like decodeContent.
+// The ID is read from the row's "id" field where it has one.
+func decodeRow(ctx context.Context, result rowResult, resource string, valuePtr any) error {
+	var raw json.RawMessage
+	if err := result.Row(&raw); err != nil {
+		logDataCorruption(ctx, resource, "", nil, err)
+		return apperrors.NewDataCorruptionError(resource, "", err)
+	}
+	if err := json.Unmarshal(raw, valuePtr); err != nil {
+		var row struct {
+			ID string `json:"id"`
+		}
+		_ = json.Unmarshal(raw, &row)
+		logDataCorruption(ctx, resource, row.ID, raw, err)
+		return apperrors.NewDataCorruptionError(resource, row.ID, err)
+	}
+	return nil
+}
+
+func logDataCorruption(ctx context.Context, resource, id string, raw json.RawMessage, err error) {
+	logger := log.FromContext(ctx).With(zap.String("resource", resource), zap.String("id", id))
+	logger.Error("Failed to decode stored document", zap.Error(err))
+	if raw != nil {
+		logger.Debug("Undecodable document", zap.ByteString("raw", raw))
+	}
+}
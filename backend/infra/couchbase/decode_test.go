@@ -0,0 +1,65 @@
+package couchbase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/log"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// rawResult serves a stored JSON document as a lookup result or query row
+type rawResult []byte
+
+func (r rawResult) Content(valuePtr interface{}) error { return json.Unmarshal(r, valuePtr) }
+func (r rawResult) Row(valuePtr interface{}) error     { return json.Unmarshal(r, valuePtr) }
+
+func TestDecodeContent(t *testing.T) {
+	var vehicle domain.Vehicle
+	if err := decodeContent(context.Background(), rawResult(`{"id":"VEH_1","year":2020}`), "vehicle", "VEH_1", &vehicle); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if vehicle.ID != "VEH_1" || vehicle.Year != 2020 {
+		t.Errorf("Expected the vehicle to be decoded, got %+v", vehicle)
+	}
+}
+
+func TestDecodeContent_DataCorruption(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	ctx := log.WithLogger(context.Background(), zap.New(core))
+
+	raw := `{"id":"VEH_1","year":"twenty twenty"}`
+	var vehicle domain.Vehicle
+	err := decodeContent(ctx, rawResult(raw), "vehicle", "VEH_1", &vehicle)
+
+	var appErr *apperrors.AppError
+	if !errors.Is(err, apperrors.ErrDataCorruption) || !errors.As(err, &appErr) {
+		t.Fatalf("Expected ErrDataCorruption, got %v", err)
+	}
+	if details, _ := appErr.Details.(map[string]string); details["resource"] != "vehicle" || details["id"] != "VEH_1" {
+		t.Errorf("Expected the vehicle ID in the details, got %v", appErr.Details)
+	}
+
+	debug := logs.FilterLevelExact(zap.DebugLevel).All()
+	if len(debug) != 1 || debug[0].ContextMap()["raw"] != raw {
+		t.Errorf("Expected the raw document logged at debug level, got %v", debug)
+	}
+}
+
+func TestDecodeRow_DataCorruption(t *testing.T) {
+	var vehicle domain.Vehicle
+	err := decodeRow(context.Background(), rawResult(`{"id":"VEH_2","mileage":"lots"}`), "vehicle", &vehicle)
+
+	var appErr *apperrors.AppError
+	if !errors.As(err, &appErr) || !errors.Is(err, apperrors.ErrDataCorruption) {
+		t.Fatalf("Expected ErrDataCorruption, got %v", err)
+	}
+	if details, _ := appErr.Details.(map[string]string); details["id"] != "VEH_2" {
+		t.Errorf("Expected the row ID in the details, got %v", appErr.Details)
+	}
+}
@@ -0,0 +1,109 @@
+package couchbase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/couchbase/gocb/v2"
+	"go.uber.org/zap"
+
+	"microservicetest/app"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/metrics"
+)
+
+// IdempotencyStore keeps idempotency records as Couchbase documents that expire on their own
+type IdempotencyStore struct {
+	collection *gocb.Collection
+	durability gocb.DurabilityLevel
+	kvTimeout  time.Duration
+}
+
+var _ app.IdempotencyStore = (*IdempotencyStore)(nil)
+
+// NewIdempotencyStore stores records in the default collection of bucket,
+// under keys prefixed with "idempotency::"
+func NewIdempotencyStore(bucket *gocb.Bucket, durability string, timeouts Timeouts) *IdempotencyStore {
+	durabilityLevel, ok := durabilityLevels[durability]
+	if !ok {
+		zap.L().Fatal("Unknown couchbase durability level", zap.String("durability", durability))
+	}
+
+	return &IdempotencyStore{
+		collection: bucket.DefaultCollection(),
+		durability: durabilityLevel,
+		kvTimeout:  timeouts.KV,
+	}
+}
+
+func idempotencyKey(key string) string {
+	return "idempotency::" + key
+}
+
+// idempotencyDocuments looks up stored idempotency documents, so Get can be
+// tested without Couchbase
+type idempotencyDocuments interface {
+	getIdempotencyDocument(ctx context.Context, docKey string) (contentResult, error)
+}
+
+// Get returns the record stored for key, counting the lookup as a hit or miss
+func (s *IdempotencyStore) Get(ctx context.Context, key string) (*app.IdempotencyRecord, bool, error) {
+	return getIdempotencyRecord(ctx, s, key)
+}
+
+func (s *IdempotencyStore) getIdempotencyDocument(ctx context.Context, docKey string) (contentResult, error) {
+	result, err := s.collection.Get(docKey, &gocb.GetOptions{
+		Timeout: s.kvTimeout,
+		Context: ctx,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func getIdempotencyRecord(ctx context.Context, docs idempotencyDocuments, key string) (*app.IdempotencyRecord, bool, error) {
+	if key == "" {
+		return nil, false, apperrors.NewValidationError("idempotency_key", "must not be empty")
+	}
+
+	result, err := docs.getIdempotencyDocument(ctx, idempotencyKey(key))
+	if err != nil {
+		if errors.Is(err, gocb.ErrDocumentNotFound) {
+			metrics.IdempotencyMisses.Add(1)
+			return nil, false, nil
+		}
+		return nil, false, apperrors.NewDatabaseError("get_idempotency_record", err)
+	}
+
+	var record app.IdempotencyRecord
+	if err := decodeContent(ctx, result, "idempotency_record", idempotencyKey(key), &record); err != nil {
+		return nil, false, err
+	}
+
+	metrics.IdempotencyHits.Add(1)
+	return &record, true, nil
+}
+
+// Save inserts the record with a document expiry of ttl
+func (s *IdempotencyStore) Save(ctx context.Context, record app.IdempotencyRecord, ttl time.Duration) error {
+	if record.Key == "" {
+		return apperrors.NewValidationError("idempotency_key", "must not be empty")
+	}
+
+	_, err := s.collection.Insert(idempotencyKey(record.Key), record, &gocb.InsertOptions{
+		Expiry:          ttl,
+		DurabilityLevel: s.durability,
+		Timeout:         s.kvTimeout,
+		Context:         ctx,
+	})
+	if err != nil {
+		if errors.Is(err, gocb.ErrDocumentExists) {
+			return apperrors.NewConflictError("idempotency_key", "a request with this key was already processed")
+		}
+		return apperrors.NewDatabaseError("save_idempotency_record", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package couchbase
+
+import (
+	"context"
+	"errors"
+	"microservicetest/app"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/metrics"
+	"testing"
+	"time"
+
+	"github.com/couchbase/gocb/v2"
+)
+
+func TestIdempotencyStore_EmptyKey(t *testing.T) {
+	store := &IdempotencyStore{}
+
+	if _, _, err := store.Get(context.Background(), ""); !errors.Is(err, apperrors.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput from Get, got %v", err)
+	}
+
+	if err := store.Save(context.Background(), app.IdempotencyRecord{}, time.Hour); !errors.Is(err, apperrors.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput from Save, got %v", err)
+	}
+}
+
+// memoryIdempotencyDocuments serves stored idempotency documents from a map
+type memoryIdempotencyDocuments map[string]rawResult
+
+func (m memoryIdempotencyDocuments) getIdempotencyDocument(ctx context.Context, docKey string) (contentResult, error) {
+	doc, ok := m[docKey]
+	if !ok {
+		return nil, gocb.ErrDocumentNotFound
+	}
+	return doc, nil
+}
+
+func TestGetIdempotencyRecord_CountsHitsAndMisses(t *testing.T) {
+	docs := memoryIdempotencyDocuments{
+		idempotencyKey("known"): rawResult(`{"key":"known","status_code":201}`),
+	}
+	hits, misses := metrics.IdempotencyHits.Value(), metrics.IdempotencyMisses.Value()
+
+	record, found, err := getIdempotencyRecord(context.Background(), docs, "known")
+	if err != nil || !found || record.Key != "known" {
+		t.Fatalf("Expected the stored record, got %+v, %v, %v", record, found, err)
+	}
+	if _, found, err := getIdempotencyRecord(context.Background(), docs, "unknown"); err != nil || found {
+		t.Fatalf("Expected no record, got %v, %v", found, err)
+	}
+
+	if got := metrics.IdempotencyHits.Value() - hits; got != 1 {
+		t.Errorf("Expected 1 hit, got %d", got)
+	}
+	if got := metrics.IdempotencyMisses.Value() - misses; got != 1 {
+		t.Errorf("Expected 1 miss, got %d", got)
+	}
+}
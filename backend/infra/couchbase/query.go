@@ -0,0 +1,47 @@
+package couchbase
+
+import (
+	"fmt"
+	"strings"
+
+	"microservicetest/pkg/pagination"
+)
+
+// likeEscaper escapes the backslash first so the escapes it adds for the
+// wildcards are not escaped again
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike escapes the N1QL LIKE wildcards in a user-supplied term so it
+// matches literally. Always pass the result as a query parameter, never
+// concatenate it into the statement, and match it with likeCondition.
+func escapeLike(term string) string {
+	return likeEscaper.Replace(term)
+}
+
+// containsPattern builds a LIKE pattern matching values that contain term
+func containsPattern(term string) string {
+	return "%" + escapeLike(term) + "%"
+}
+
+// likeCondition builds the condition matching expr against the pattern bound
+// to param, naming the backslash escapeLike uses as the escape character
+func likeCondition(expr string, param string) string {
+	return fmt.Sprintf(`%s LIKE %s ESCAPE "\\"`, expr, param)
+}
+
+// keysetAfter builds the condition selecting the rows after after for
+// ORDER BY sortExpr ASC|DESC, idExpr ASC where rows without a sort value come
+// last. valueParam and idParam are the placeholders bound to after.Value and
+// after.ID; with a nil after.Value only the rows without a value remain.
+func keysetAfter(sortExpr string, idExpr string, desc bool, after pagination.Cursor, valueParam string, idParam string) string {
+	if after.Value == nil {
+		return fmt.Sprintf("(%s IS NOT VALUED AND %s > %s)", sortExpr, idExpr, idParam)
+	}
+
+	op := ">"
+	if desc {
+		op = "<"
+	}
+	return fmt.Sprintf("(%[1]s IS NOT VALUED OR %[1]s %[2]s %[3]s OR (%[1]s = %[3]s AND %[4]s > %[5]s))",
+		sortExpr, op, valueParam, idExpr, idParam)
+}
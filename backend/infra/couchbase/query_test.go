@@ -0,0 +1,61 @@
+package couchbase
+
+import (
+	"testing"
+
+	"microservicetest/pkg/pagination"
+)
+
+func TestEscapeLike(t *testing.T) {
+	tests := map[string]string{
+		"ABC123":    "ABC123",
+		"100%":      `100\%`,
+		"AB_12":     `AB\_12`,
+		`C:\plates`: `C:\\plates`,
+		`%_\`:       `\%\_\\`,
+		"":          "",
+	}
+
+	for term, expected := range tests {
+		if got := escapeLike(term); got != expected {
+			t.Errorf("Expected escapeLike(%q) to be %q, got %q", term, expected, got)
+		}
+	}
+}
+
+func TestContainsPattern(t *testing.T) {
+	if got := containsPattern("34_AB%"); got != `%34\_AB\%%` {
+		t.Errorf("Expected wildcards in the term to be escaped, got %q", got)
+	}
+}
+
+func TestLikeCondition(t *testing.T) {
+	expected := `v.license_plate LIKE $plate ESCAPE "\\"`
+	if got := likeCondition("v.license_plate", "$plate"); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestKeysetAfter(t *testing.T) {
+	tests := []struct {
+		name     string
+		desc     bool
+		after    pagination.Cursor
+		expected string
+	}{
+		{"descending", true, pagination.Cursor{Value: "2026-01-02T00:00:00Z", ID: "VEH_1"},
+			"(v.created_at IS NOT VALUED OR v.created_at < $2 OR (v.created_at = $2 AND v.id > $3))"},
+		{"ascending", false, pagination.Cursor{Value: float64(1), ID: "VEH_1"},
+			"(v.created_at IS NOT VALUED OR v.created_at > $2 OR (v.created_at = $2 AND v.id > $3))"},
+		{"after the valued rows", false, pagination.Cursor{ID: "VEH_1"},
+			"(v.created_at IS NOT VALUED AND v.id > $3)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keysetAfter("v.created_at", "v.id", tt.desc, tt.after, "$2", "$3"); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
@@ -4,29 +4,72 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/couchbase/gocb/v2"
 	"go.uber.org/zap"
 
+	"microservicetest/app"
 	"microservicetest/app/vehicle"
 	"microservicetest/domain"
 	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/log"
 )
 
+// durabilityLevels maps the couchbase_durability config values to gocb levels
+var durabilityLevels = map[string]gocb.DurabilityLevel{
+	"none":              gocb.DurabilityLevelNone,
+	"majority":          gocb.DurabilityLevelMajority,
+	"persistToMajority": gocb.DurabilityLevelPersistToMajority,
+}
+
+// scanConsistencies maps the couchbase_scan_consistency values to the N1QL
+// scan consistency of user-facing queries
+var scanConsistencies = map[string]gocb.QueryScanConsistency{
+	"not_bounded":  gocb.QueryScanConsistencyNotBounded,
+	"request_plus": gocb.QueryScanConsistencyRequestPlus,
+}
+
+// Timeouts bound connecting and every key-value and query operation. The same
+// values are set on the cluster and passed with each operation.
+type Timeouts struct {
+	Connect time.Duration
+	KV      time.Duration
+	Query   time.Duration // Also bounds transactions, which may run several operations
+}
+
 type VehicleRepository struct {
 	cluster    *gocb.Cluster
 	bucket     *gocb.Bucket
 	collection *gocb.Collection
+	durability gocb.DurabilityLevel
+	timeouts   Timeouts
+	// scanConsistency applies to user-facing queries; request_plus waits for the
+	// index to catch up with earlier writes, not_bounded answers from it at once
+	scanConsistency gocb.QueryScanConsistency
 }
 
-func NewVehicleRepository(couchbaseUrl string, username string, password string) *VehicleRepository {
+// NewVehicleRepository connects to the vehicles bucket. durability is applied to
+// every write; stronger levels survive node failures but add latency and fail
+// outright on clusters without enough replicas.
+func NewVehicleRepository(couchbaseUrl string, username string, password string, durability string, scanConsistency string, timeouts Timeouts) *VehicleRepository {
+	durabilityLevel, ok := durabilityLevels[durability]
+	if !ok {
+		zap.L().Fatal("Unknown couchbase durability level", zap.String("durability", durability))
+	}
+	queryConsistency, ok := scanConsistencies[scanConsistency]
+	if !ok {
+		zap.L().Fatal("Unknown couchbase scan consistency", zap.String("scan_consistency", scanConsistency))
+	}
+
 	cluster, err := gocb.Connect(couchbaseUrl, gocb.ClusterOptions{
 		TimeoutsConfig: gocb.TimeoutsConfig{
-			ConnectTimeout: 10 * time.Second,
-			KVTimeout:      5 * time.Second,
-			QueryTimeout:   10 * time.Second,
+			ConnectTimeout: timeouts.Connect,
+			KVTimeout:      timeouts.KV,
+			QueryTimeout:   timeouts.Query,
 		},
 		Authenticator: gocb.PasswordAuthenticator{
 			Username: username,
@@ -39,17 +82,52 @@ func NewVehicleRepository(couchbaseUrl string, username string, password string)
 	}
 
 	bucket := cluster.Bucket("vehicles")
-	bucket.WaitUntilReady(10*time.Second, &gocb.WaitUntilReadyOptions{})
+	bucket.WaitUntilReady(timeouts.Connect, &gocb.WaitUntilReadyOptions{})
 
 	collection := bucket.DefaultCollection()
 
 	return &VehicleRepository{
-		cluster:    cluster,
-		bucket:     bucket,
-		collection: collection,
+		cluster:         cluster,
+		bucket:          bucket,
+		collection:      collection,
+		durability:      durabilityLevel,
+		timeouts:        timeouts,
+		scanConsistency: queryConsistency,
 	}
 }
 
+// Bucket returns the connected vehicles bucket so other stores can share the connection
+func (r *VehicleRepository) Bucket() *gocb.Bucket {
+	return r.bucket
+}
+
+// Cluster returns the connected cluster for stores that query the bucket
+func (r *VehicleRepository) Cluster() *gocb.Cluster {
+	return r.cluster
+}
+
+// Ping runs a trivial N1QL query, so it fails while the query service is
+// unusable even though the cluster still accepts connections
+func (r *VehicleRepository) Ping(ctx context.Context) error {
+	result, err := r.cluster.Query("SELECT RAW 1", &gocb.QueryOptions{
+		Timeout: r.timeouts.Query,
+		Context: ctx,
+	})
+	if err != nil {
+		return err
+	}
+	return result.Close()
+}
+
+// queryConsistency is the scan consistency of a user-facing query: the
+// configured one, or request_plus when the request asked for consistent reads
+func (r *VehicleRepository) queryConsistency(ctx context.Context) gocb.QueryScanConsistency {
+	if app.ConsistentReads(ctx) {
+		return gocb.QueryScanConsistencyRequestPlus
+	}
+	return r.scanConsistency
+}
+
 // GetVehicle retrieves a vehicle by ID
 func (r *VehicleRepository) GetVehicle(ctx context.Context, id string) (*domain.Vehicle, error) {
 	if id == "" {
@@ -57,7 +135,7 @@ func (r *VehicleRepository) GetVehicle(ctx context.Context, id string) (*domain.
 	}
 
 	data, err := r.collection.Get(id, &gocb.GetOptions{
-		Timeout: 5 * time.Second,
+		Timeout: r.timeouts.KV,
 		Context: ctx,
 	})
 	if err != nil {
@@ -65,19 +143,43 @@ func (r *VehicleRepository) GetVehicle(ctx context.Context, id string) (*domain.
 	}
 
 	var vehicle domain.Vehicle
-	if err := data.Content(&vehicle); err != nil {
-		return nil, apperrors.NewDatabaseError("decode_vehicle", err)
+	if err := decodeContent(ctx, data, "vehicle", id, &vehicle); err != nil {
+		return nil, err
+	}
+
+	if vehicle.IsDeleted() {
+		return nil, apperrors.NewNotFoundError("vehicle", id)
 	}
 
+	// The stored flags may predate the last sweep, a single read is always current
+	vehicle.RefreshStatusFlags()
+
 	return &vehicle, nil
 }
 
+// vinKey normalizes the VIN the same way create stores it and builds the
+// key of its reference document. Empty or malformed VINs are rejected so a
+// lookup can never resolve to a bare "vin::" key.
+func vinKey(vin string) (string, error) {
+	vin = strings.ToUpper(strings.TrimSpace(vin))
+	if len(vin) != 17 {
+		return "", apperrors.ErrInvalidID.WithDetails(map[string]string{
+			"field":   "vin",
+			"message": "must be exactly 17 characters",
+		})
+	}
+	return "vin::" + vin, nil
+}
+
 // GetVehicleByVIN retrieves a vehicle by VIN using lookup operation
 func (r *VehicleRepository) GetVehicleByVIN(ctx context.Context, vin string) (*domain.Vehicle, error) {
-	vinKey := "vin::" + vin
+	key, err := vinKey(vin)
+	if err != nil {
+		return nil, err
+	}
 
-	result, err := r.collection.Get(vinKey, &gocb.GetOptions{
-		Timeout: 5 * time.Second,
+	result, err := r.collection.Get(key, &gocb.GetOptions{
+		Timeout: r.timeouts.KV,
 		Context: ctx,
 	})
 	if err != nil {
@@ -90,25 +192,132 @@ func (r *VehicleRepository) GetVehicleByVIN(ctx context.Context, vin string) (*d
 	var vehicleRef struct {
 		VehicleID string `json:"vehicle_id"`
 	}
-	if err := result.Content(&vehicleRef); err != nil {
-		return nil, apperrors.NewDatabaseError("decode_vin_reference", err)
+	if err := decodeContent(ctx, result, "vin_reference", key, &vehicleRef); err != nil {
+		return nil, err
 	}
 
 	// Now get the actual vehicle document
 	return r.GetVehicle(ctx, vehicleRef.VehicleID)
 }
 
+// GetVehiclesByVINs resolves the VINs through their vin:: reference documents
+// in a single query, so no index is involved. VINs without a live vehicle are
+// returned as not found, in input order.
+func (r *VehicleRepository) GetVehiclesByVINs(ctx context.Context, vins []string) (map[string]*domain.Vehicle, []string, error) {
+	keys := make([]string, 0, len(vins))
+	for _, vin := range vins {
+		key, err := vinKey(vin)
+		if err != nil {
+			return nil, nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	vehicles := make(map[string]*domain.Vehicle, len(vins))
+	if len(keys) > 0 {
+		query := `
+			SELECT v.*
+			FROM vehicles ref USE KEYS $1
+			JOIN vehicles v ON KEYS ref.vehicle_id
+			WHERE v.deleted_at IS MISSING
+		`
+
+		result, err := r.cluster.Query(query, &gocb.QueryOptions{
+			PositionalParameters: []interface{}{keys},
+			Timeout:              r.timeouts.Query,
+			ScanConsistency:      r.queryConsistency(ctx),
+			Context:              ctx,
+		})
+		if err != nil {
+			return nil, nil, r.convertDBError("get_vehicles_by_vins", err)
+		}
+		defer result.Close()
+
+		for result.Next() {
+			var vehicle domain.Vehicle
+			if err := decodeRow(ctx, result, "vehicle", &vehicle); err != nil {
+				continue
+			}
+			vehicles[vehicle.VIN] = &vehicle
+		}
+
+		if err := result.Err(); err != nil {
+			return nil, nil, r.convertDBError("get_vehicles_by_vins_iteration", err)
+		}
+	}
+
+	notFound := []string{}
+	for _, key := range keys {
+		if vin := strings.TrimPrefix(key, "vin::"); vehicles[vin] == nil {
+			notFound = append(notFound, vin)
+		}
+	}
+
+	return vehicles, notFound, nil
+}
+
+// GetVehiclesByLicensePlate finds the vehicles carrying a plate, newest first.
+// Plates are reissued, so more than one vehicle can match. Served by
+// idx_vehicles_license_plate, see the README.
+func (r *VehicleRepository) GetVehiclesByLicensePlate(ctx context.Context, plate string) ([]*domain.Vehicle, error) {
+	plate = domain.NormalizeLicensePlate(plate)
+	if plate == "" {
+		return nil, apperrors.ErrInvalidID.WithDetails(map[string]string{
+			"field":   "license_plate",
+			"message": "must not be empty",
+		})
+	}
+
+	query := `
+		SELECT v.*
+		FROM vehicles v
+		WHERE v.license_plate = $1
+		AND v.deleted_at IS MISSING
+		ORDER BY v.created_at DESC, v.id
+	`
+
+	result, err := r.cluster.Query(query, &gocb.QueryOptions{
+		PositionalParameters: []interface{}{plate},
+		Timeout:              r.timeouts.Query,
+		ScanConsistency:      r.queryConsistency(ctx),
+		Context:              ctx,
+	})
+	if err != nil {
+		return nil, r.convertDBError("get_vehicles_by_license_plate", err)
+	}
+	defer result.Close()
+
+	vehicles := []*domain.Vehicle{}
+	for result.Next() {
+		var vehicle domain.Vehicle
+		if err := decodeRow(ctx, result, "vehicle", &vehicle); err != nil {
+			continue
+		}
+		vehicles = append(vehicles, &vehicle)
+	}
+
+	if err := result.Err(); err != nil {
+		return nil, r.convertDBError("get_vehicles_by_license_plate_iteration", err)
+	}
+
+	return vehicles, nil
+}
+
 // CreateVehicle creates a new vehicle using atomic operations
 func (r *VehicleRepository) CreateVehicle(ctx context.Context, vehicle *domain.Vehicle) error {
 	now := time.Now()
 	vehicle.CreatedAt = now
 	vehicle.UpdatedAt = now
+	vehicle.RefreshStatusFlags()
 
-	vinKey := "vin::" + vehicle.VIN
+	key, err := vinKey(vehicle.VIN)
+	if err != nil {
+		return err
+	}
 	vinRef := map[string]string{"vehicle_id": vehicle.ID}
 
-	_, err := r.cluster.Transactions().Run(func(attempt *gocb.TransactionAttemptContext) error {
-		_, err := attempt.Insert(r.collection, vinKey, vinRef)
+	_, err = r.cluster.Transactions().Run(func(attempt *gocb.TransactionAttemptContext) error {
+		_, err := attempt.Insert(r.collection, key, vinRef)
 		if err != nil {
 			return err
 		}
@@ -120,8 +329,8 @@ func (r *VehicleRepository) CreateVehicle(ctx context.Context, vehicle *domain.V
 
 		return nil
 	}, &gocb.TransactionOptions{
-		Timeout:         10 * time.Second,
-		DurabilityLevel: gocb.DurabilityLevelMajority,
+		Timeout:         r.timeouts.Query,
+		DurabilityLevel: r.durability,
 	})
 
 	if err != nil {
@@ -133,94 +342,503 @@ func (r *VehicleRepository) CreateVehicle(ctx context.Context, vehicle *domain.V
 	return nil
 }
 
-// UpdateVehicle updates an existing vehicle
-func (r *VehicleRepository) UpdateVehicle(ctx context.Context, vehicle *domain.Vehicle) error {
-	vehicle.UpdatedAt = time.Now()
+// UpdateVehicle applies update to the stored vehicle and writes it back under
+// CAS. A document, picture or delete written after the read makes the write
+// fail, so the vehicle is re-read and update runs again instead of overwriting it.
+func (r *VehicleRepository) UpdateVehicle(ctx context.Context, id string, update func(vehicle *domain.Vehicle) error) (*domain.Vehicle, error) {
+	return mutateVehicle(ctx, r, id, update)
+}
 
-	_, err := r.collection.Replace(vehicle.ID, vehicle, &gocb.ReplaceOptions{
-		Timeout: 5 * time.Second,
+// UpsertVehicleByVIN creates the vehicle when its VIN is unknown, otherwise it
+// applies the mutable fields onto the stored vehicle. The VIN reference document
+// guards the create path and CAS guards the update path, so concurrent sync
+// jobs for the same VIN cannot overwrite each other. The returned flag reports
+// whether the vehicle was created.
+func (r *VehicleRepository) UpsertVehicleByVIN(ctx context.Context, vehicle *domain.Vehicle) (*domain.Vehicle, bool, error) {
+	return upsertVehicleByVIN(ctx, r, vehicle)
+}
+
+// vinVehicleStore adds the VIN lookup and the create needed by upsertVehicleByVIN
+type vinVehicleStore interface {
+	vehicleStore
+	getVehicleByVINWithCAS(ctx context.Context, vin string) (*domain.Vehicle, gocb.Cas, error)
+	CreateVehicle(ctx context.Context, vehicle *domain.Vehicle) error
+}
+
+func upsertVehicleByVIN(ctx context.Context, store vinVehicleStore, vehicle *domain.Vehicle) (*domain.Vehicle, bool, error) {
+	for attempt := 0; attempt < maxCASRetries; attempt++ {
+		existing, cas, err := store.getVehicleByVINWithCAS(ctx, vehicle.VIN)
+		if err != nil {
+			if !errors.Is(err, apperrors.ErrResourceNotFound) {
+				return nil, false, err
+			}
+
+			err = store.CreateVehicle(ctx, vehicle)
+			if err == nil {
+				return vehicle, true, nil
+			}
+			if errors.Is(err, apperrors.ErrResourceExists) {
+				// Another writer registered the VIN first, retry as an update
+				continue
+			}
+			return nil, false, err
+		}
+
+		if existing.IsDeleted() {
+			return nil, false, apperrors.NewConflictError("vehicle",
+				fmt.Sprintf("Vehicle with VIN %s is deleted, restore it first", vehicle.VIN))
+		}
+
+		existing.ApplyMutableFields(vehicle)
+		existing.UpdateTimestamp(vehicle.UpdatedBy)
+
+		err = store.replaceVehicleWithCAS(ctx, existing, cas)
+		if err == nil {
+			return existing, false, nil
+		}
+		if !errors.Is(err, gocb.ErrCasMismatch) {
+			return nil, false, err
+		}
+	}
+
+	return nil, false, apperrors.ErrConcurrentModification.WithDetails(map[string]string{
+		"resource": "vehicle",
+		"vin":      vehicle.VIN,
+	})
+}
+
+// getVehicleByVINWithCAS resolves the VIN reference and returns the vehicle
+// together with the CAS value of the vehicle document
+func (r *VehicleRepository) getVehicleByVINWithCAS(ctx context.Context, vin string) (*domain.Vehicle, gocb.Cas, error) {
+	key, err := vinKey(vin)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result, err := r.collection.Get(key, &gocb.GetOptions{
+		Timeout: r.timeouts.KV,
 		Context: ctx,
 	})
 	if err != nil {
-		return r.convertDBError("update_vehicle", err)
+		if errors.Is(err, gocb.ErrDocumentNotFound) {
+			return nil, 0, apperrors.NewNotFoundError("vehicle", vin)
+		}
+		return nil, 0, r.convertDBError("get_vehicle_by_vin", err)
 	}
 
-	return nil
+	var vehicleRef struct {
+		VehicleID string `json:"vehicle_id"`
+	}
+	if err := decodeContent(ctx, result, "vin_reference", key, &vehicleRef); err != nil {
+		return nil, 0, err
+	}
+
+	return r.getVehicleDocWithCAS(ctx, vehicleRef.VehicleID)
 }
 
-// DeleteVehicle soft deletes a vehicle by setting status to inactive
-func (r *VehicleRepository) DeleteVehicle(ctx context.Context, id string) error {
+// getVehicleWithCAS retrieves a vehicle together with the CAS value of its
+// document. Soft deleted vehicles are reported as not found, so mutations
+// through mutateVehicle cannot change them.
+func (r *VehicleRepository) getVehicleWithCAS(ctx context.Context, id string) (*domain.Vehicle, gocb.Cas, error) {
+	vehicle, cas, err := r.getVehicleDocWithCAS(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	if vehicle.IsDeleted() {
+		return nil, 0, apperrors.NewNotFoundError("vehicle", id)
+	}
+	return vehicle, cas, nil
+}
 
-	// Get the vehicle first
-	vehicle, err := r.GetVehicle(ctx, id)
+// getVehicleDocWithCAS is getVehicleWithCAS including soft deleted vehicles
+func (r *VehicleRepository) getVehicleDocWithCAS(ctx context.Context, id string) (*domain.Vehicle, gocb.Cas, error) {
+	if id == "" {
+		return nil, 0, apperrors.ErrInvalidID
+	}
+
+	data, err := r.collection.Get(id, &gocb.GetOptions{
+		Timeout: r.timeouts.KV,
+		Context: ctx,
+	})
 	if err != nil {
-		return err
+		return nil, 0, r.convertDBError("get_vehicle", err)
+	}
+
+	var vehicle domain.Vehicle
+	if err := decodeContent(ctx, data, "vehicle", id, &vehicle); err != nil {
+		return nil, 0, err
 	}
 
-	// Set status to inactive and update timestamp
-	vehicle.Status = domain.VehicleStatusInactive
+	return &vehicle, data.Cas(), nil
+}
+
+// replaceVehicleWithCAS replaces the vehicle only if its document still has the
+// given CAS. A mismatch is returned as gocb.ErrCasMismatch so callers can retry.
+func (r *VehicleRepository) replaceVehicleWithCAS(ctx context.Context, vehicle *domain.Vehicle, cas gocb.Cas) error {
 	vehicle.UpdatedAt = time.Now()
+	vehicle.RefreshStatusFlags()
 
-	return r.UpdateVehicle(ctx, vehicle)
+	_, err := r.collection.Replace(vehicle.ID, vehicle, &gocb.ReplaceOptions{
+		Cas:             cas,
+		DurabilityLevel: r.durability,
+		Timeout:         r.timeouts.KV,
+		Context:         ctx,
+	})
+	if err != nil {
+		if errors.Is(err, gocb.ErrCasMismatch) {
+			return err
+		}
+		return r.convertDBError("replace_vehicle", err)
+	}
+
+	return nil
+}
+
+// DeleteVehicle soft deletes a vehicle by setting DeletedAt. The document and
+// its VIN reference stay, so the vehicle can be restored.
+func (r *VehicleRepository) DeleteVehicle(ctx context.Context, id string) error {
+	_, err := mutateVehicle(ctx, r, id, func(vehicle *domain.Vehicle) error {
+		return vehicle.SoftDelete(time.Now())
+	})
+	return err
+}
+
+// deletedVehicleStore lets mutateVehicle load soft deleted vehicles
+type deletedVehicleStore struct {
+	*VehicleRepository
+}
+
+func (s deletedVehicleStore) getVehicleWithCAS(ctx context.Context, id string) (*domain.Vehicle, gocb.Cas, error) {
+	return s.getVehicleDocWithCAS(ctx, id)
+}
+
+// RestoreVehicle clears DeletedAt on a soft deleted vehicle and returns it.
+// The status was never changed by the delete, so it comes back as it was.
+func (r *VehicleRepository) RestoreVehicle(ctx context.Context, id string, restoredBy string) (*domain.Vehicle, error) {
+	return mutateVehicle(ctx, deletedVehicleStore{r}, id, func(vehicle *domain.Vehicle) error {
+		if err := vehicle.Restore(); err != nil {
+			return apperrors.NewConflictError("vehicle", err.Error())
+		}
+		vehicle.UpdateTimestamp(restoredBy)
+		return nil
+	})
+}
+
+// ReportStolen moves the vehicle to stolen and files the theft report in a
+// CAS-guarded write. A status that cannot become stolen is a conflict.
+func (r *VehicleRepository) ReportStolen(ctx context.Context, vehicleID string, report domain.TheftReport, document domain.Document) (*domain.Vehicle, error) {
+	return mutateVehicle(ctx, r, vehicleID, func(vehicle *domain.Vehicle) error {
+		if err := vehicle.ReportStolen(report, document); err != nil {
+			return apperrors.NewConflictError("vehicle", err.Error())
+		}
+		vehicle.UpdateTimestamp(report.ReportedBy)
+		return nil
+	})
 }
 
-// GetVehiclesByOwner retrieves all vehicles for a specific owner
-func (r *VehicleRepository) GetVehiclesByOwner(ctx context.Context, ownerID string) ([]*domain.Vehicle, error) {
+// GetVehiclesByOwner retrieves one page of an owner's vehicles and counts all of them
+func (r *VehicleRepository) GetVehiclesByOwner(ctx context.Context, ownerID string, filter vehicle.OwnerVehicleFilter) ([]*domain.Vehicle, int, error) {
 	if ownerID == "" {
-		return nil, apperrors.ErrInvalidID
+		return nil, 0, apperrors.ErrInvalidID
 	}
 
-	query := `
+	// Metadata pairs are bound as parameters, the key through a computed field name
+	conditions := ""
+	params := []interface{}{ownerID}
+	for _, key := range slices.Sorted(maps.Keys(filter.Metadata)) {
+		params = append(params, key, filter.Metadata[key])
+		conditions += fmt.Sprintf(" AND v.metadata.[$%d] = $%d", len(params)-1, len(params))
+	}
+	// The stored status flags are kept current by RefreshStatusFlags
+	if filter.InsuranceStatus != "" {
+		params = append(params, filter.InsuranceStatus)
+		conditions += fmt.Sprintf(" AND v.insurance_status = $%d", len(params))
+	}
+	if filter.DocumentStatus != "" {
+		params = append(params, filter.DocumentStatus)
+		conditions += fmt.Sprintf(" AND v.document_status = $%d", len(params))
+	}
+
+	countQuery := `SELECT RAW COUNT(*) FROM vehicles v WHERE v.owner_id = $1 AND v.deleted_at IS MISSING` + conditions
+
+	countResult, err := r.cluster.Query(countQuery, &gocb.QueryOptions{
+		PositionalParameters: params,
+		Timeout:              r.timeouts.Query,
+		ScanConsistency:      r.queryConsistency(ctx),
+		Context:              ctx,
+	})
+	if err != nil {
+		return nil, 0, r.convertDBError("count_vehicles_by_owner", err)
+	}
+
+	var total int
+	if err := countResult.One(&total); err != nil {
+		return nil, 0, r.convertDBError("count_vehicles_by_owner", err)
+	}
+
+	// Past the first page a cursor replaces the offset, so deep pages cost no more than the first
+	if filter.After != nil {
+		params = append(params, filter.After.Value, filter.After.ID)
+		conditions += " AND " + keysetAfter("v.created_at", "v.id", true, *filter.After,
+			fmt.Sprintf("$%d", len(params)-1), fmt.Sprintf("$%d", len(params)))
+	}
+
+	query := fmt.Sprintf(`
 		SELECT v.* 
 		FROM vehicles v 
 		WHERE v.owner_id = $1 
-		AND v.status != 'inactive'
-		ORDER BY v.created_at DESC
-	`
+		AND v.deleted_at IS MISSING%s
+		ORDER BY v.created_at DESC, v.id
+		LIMIT $%d OFFSET $%d
+	`, conditions, len(params)+1, len(params)+2)
 
 	result, err := r.cluster.Query(query, &gocb.QueryOptions{
-		PositionalParameters: []interface{}{ownerID},
-		Timeout:              10 * time.Second,
+		PositionalParameters: append(params, filter.Limit, filter.Offset),
+		Timeout:              r.timeouts.Query,
+		ScanConsistency:      r.queryConsistency(ctx),
 		Context:              ctx,
 	})
 	if err != nil {
-		return nil, r.convertDBError("get_vehicles_by_owner", err)
+		return nil, 0, r.convertDBError("get_vehicles_by_owner", err)
 	}
 	defer result.Close()
 
-	var vehicles []*domain.Vehicle
+	vehicles := []*domain.Vehicle{}
 	for result.Next() {
 		var vehicle domain.Vehicle
-		if err := result.Row(&vehicle); err != nil {
-			zap.L().Error("Failed to decode vehicle row", zap.Error(err))
+		if err := decodeRow(ctx, result, "vehicle", &vehicle); err != nil {
 			continue
 		}
 		vehicles = append(vehicles, &vehicle)
 	}
 
 	if err := result.Err(); err != nil {
-		return nil, r.convertDBError("get_vehicles_by_owner_iteration", err)
+		return nil, 0, r.convertDBError("get_vehicles_by_owner_iteration", err)
 	}
 
-	return vehicles, nil
+	return vehicles, total, nil
 }
 
-// AddDocument adds a document to a vehicle
-func (r *VehicleRepository) AddDocument(ctx context.Context, vehicleID string, document domain.Document) error {
-	vehicle, err := r.GetVehicle(ctx, vehicleID)
+// SearchVehicles retrieves one page of the fleet's vehicles by their stored
+// status flags, which RefreshStatusFlags keeps current, and counts all matches
+func (r *VehicleRepository) SearchVehicles(ctx context.Context, filter vehicle.VehicleSearchFilter) ([]*domain.Vehicle, int, error) {
+	conditions := ""
+	var params []interface{}
+	if filter.InsuranceStatus != "" {
+		params = append(params, filter.InsuranceStatus)
+		conditions += fmt.Sprintf(" AND v.insurance_status = $%d", len(params))
+	}
+	if filter.DocumentStatus != "" {
+		params = append(params, filter.DocumentStatus)
+		conditions += fmt.Sprintf(" AND v.document_status = $%d", len(params))
+	}
+
+	countQuery := `SELECT RAW COUNT(*) FROM vehicles v WHERE v.vin IS NOT MISSING AND v.deleted_at IS MISSING` + conditions
+
+	countResult, err := r.cluster.Query(countQuery, &gocb.QueryOptions{
+		PositionalParameters: params,
+		Timeout:              r.timeouts.Query,
+		ScanConsistency:      r.queryConsistency(ctx),
+		Context:              ctx,
+	})
 	if err != nil {
-		return err
+		return nil, 0, r.convertDBError("count_vehicles_by_status", err)
 	}
 
-	// Add the document to the vehicle
-	if err := vehicle.AddDocument(document); err != nil {
-		return apperrors.ErrInvalidInput.WithDetails(map[string]string{
-			"error": err.Error(),
-		})
+	var total int
+	if err := countResult.One(&total); err != nil {
+		return nil, 0, r.convertDBError("count_vehicles_by_status", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT v.*
+		FROM vehicles v
+		WHERE v.vin IS NOT MISSING
+		AND v.deleted_at IS MISSING%s
+		ORDER BY v.created_at DESC, v.id
+		LIMIT $%d OFFSET $%d
+	`, conditions, len(params)+1, len(params)+2)
+
+	result, err := r.cluster.Query(query, &gocb.QueryOptions{
+		PositionalParameters: append(params, filter.Limit, filter.Offset),
+		Timeout:              r.timeouts.Query,
+		ScanConsistency:      r.queryConsistency(ctx),
+		Context:              ctx,
+	})
+	if err != nil {
+		return nil, 0, r.convertDBError("search_vehicles", err)
+	}
+	defer result.Close()
+
+	vehicles := []*domain.Vehicle{}
+	for result.Next() {
+		var vehicle domain.Vehicle
+		if err := decodeRow(ctx, result, "vehicle", &vehicle); err != nil {
+			continue
+		}
+		vehicles = append(vehicles, &vehicle)
+	}
+
+	if err := result.Err(); err != nil {
+		return nil, 0, r.convertDBError("search_vehicles_iteration", err)
+	}
+
+	return vehicles, total, nil
+}
+
+// FindActiveVehicleByOwnerPlate checks whether another of the owner's vehicles
+// still carries the plate. Sold and scrapped vehicles no longer count, their
+// plates may be reissued. The query always waits for the index, a vehicle
+// created a moment ago must not slip through the check.
+func (r *VehicleRepository) FindActiveVehicleByOwnerPlate(ctx context.Context, ownerID string, plate string, excludeID string) (string, bool, error) {
+	query := `
+		SELECT RAW v.id
+		FROM vehicles v
+		WHERE v.license_plate = $1
+		AND v.owner_id = $2
+		AND v.id != $3
+		AND v.deleted_at IS MISSING
+		AND v.status NOT IN $4
+		LIMIT 1
+	`
+
+	result, err := r.cluster.Query(query, &gocb.QueryOptions{
+		PositionalParameters: []interface{}{plate, ownerID, excludeID, []domain.VehicleStatus{domain.VehicleStatusSold, domain.VehicleStatusScrapped}},
+		Timeout:              r.timeouts.Query,
+		ScanConsistency:      gocb.QueryScanConsistencyRequestPlus,
+		Context:              ctx,
+	})
+	if err != nil {
+		return "", false, r.convertDBError("find_vehicle_by_owner_plate", err)
 	}
 
-	// Update the vehicle
-	return r.UpdateVehicle(ctx, vehicle)
+	var id string
+	if err := result.One(&id); err != nil {
+		if errors.Is(err, gocb.ErrNoResult) {
+			return "", false, nil
+		}
+		return "", false, r.convertDBError("find_vehicle_by_owner_plate", err)
+	}
+	return id, true, nil
+}
+
+// OwnerExists looks for any vehicle of the owner through idx_vehicles_owner,
+// soft-deleted ones included
+func (r *VehicleRepository) OwnerExists(ctx context.Context, ownerID string) (bool, error) {
+	query := `
+		SELECT RAW v.id
+		FROM vehicles v
+		WHERE v.owner_id = $1
+		AND v.vin IS NOT MISSING
+		LIMIT 1
+	`
+
+	result, err := r.cluster.Query(query, &gocb.QueryOptions{
+		PositionalParameters: []interface{}{ownerID},
+		Timeout:              r.timeouts.Query,
+		ScanConsistency:      r.queryConsistency(ctx),
+		Context:              ctx,
+	})
+	if err != nil {
+		return false, r.convertDBError("find_owner", err)
+	}
+
+	var id string
+	if err := result.One(&id); err != nil {
+		if errors.Is(err, gocb.ErrNoResult) {
+			return false, nil
+		}
+		return false, r.convertDBError("find_owner", err)
+	}
+	return true, nil
+}
+
+// recentOrderFields maps the accepted orders of GetRecentVehiclesByOwner to document fields
+var recentOrderFields = map[string]string{
+	vehicle.RecentByUpdated: "updated_at",
+	vehicle.RecentByCreated: "created_at",
+}
+
+// GetRecentVehiclesByOwner selects only the summary fields of an owner's latest vehicles
+func (r *VehicleRepository) GetRecentVehiclesByOwner(ctx context.Context, ownerID string, by string, limit int) ([]vehicle.VehicleSummary, error) {
+	if ownerID == "" {
+		return nil, apperrors.ErrInvalidID
+	}
+	field, ok := recentOrderFields[by]
+	if !ok {
+		return nil, apperrors.NewValidationError("by", fmt.Sprintf("must be %s or %s", vehicle.RecentByUpdated, vehicle.RecentByCreated))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT v.id, v.vin, v.make, v.model, v.year, v.license_plate, v.status,
+			v.created_at, v.updated_at, v.updated_by
+		FROM vehicles v
+		WHERE v.owner_id = $1
+		AND v.deleted_at IS MISSING
+		ORDER BY v.%s DESC, v.id
+		LIMIT $2
+	`, field)
+
+	result, err := r.cluster.Query(query, &gocb.QueryOptions{
+		PositionalParameters: []interface{}{ownerID, limit},
+		Timeout:              r.timeouts.Query,
+		ScanConsistency:      r.queryConsistency(ctx),
+		Context:              ctx,
+	})
+	if err != nil {
+		return nil, r.convertDBError("get_recent_vehicles_by_owner", err)
+	}
+	defer result.Close()
+
+	summaries := []vehicle.VehicleSummary{}
+	for result.Next() {
+		var summary vehicle.VehicleSummary
+		if err := decodeRow(ctx, result, "vehicle", &summary); err != nil {
+			continue
+		}
+		summaries = append(summaries, summary)
+	}
+
+	if err := result.Err(); err != nil {
+		return nil, r.convertDBError("get_recent_vehicles_by_owner_iteration", err)
+	}
+
+	return summaries, nil
+}
+
+// AddDocument adds a document to a vehicle. The duplicate-ID check runs
+// inside a CAS-guarded read-modify-write, so concurrent uploads can neither
+// lose each other's documents nor both add the same ID.
+func (r *VehicleRepository) AddDocument(ctx context.Context, vehicleID string, document domain.Document) error {
+	_, err := mutateVehicle(ctx, r, vehicleID, func(vehicle *domain.Vehicle) error {
+		if err := vehicle.AddDocument(document); err != nil {
+			return apperrors.NewConflictError("document", err.Error())
+		}
+		return nil
+	})
+	return err
+}
+
+// AppendFileToDocument adds a file as the next page of a document in a CAS-guarded write
+func (r *VehicleRepository) AppendFileToDocument(ctx context.Context, vehicleID string, documentID string, file domain.DocumentFile) (domain.DocumentFile, error) {
+	return appendFileToDocument(ctx, r, vehicleID, documentID, file)
+}
+
+func appendFileToDocument(ctx context.Context, store vehicleStore, vehicleID string, documentID string, file domain.DocumentFile) (domain.DocumentFile, error) {
+	var appended domain.DocumentFile
+	_, err := mutateVehicle(ctx, store, vehicleID, func(vehicle *domain.Vehicle) error {
+		if !slices.ContainsFunc(vehicle.Documents, func(doc domain.Document) bool { return doc.ID == documentID }) {
+			return apperrors.NewNotFoundError("document", documentID)
+		}
+
+		var err error
+		appended, err = vehicle.AppendDocumentFile(documentID, file)
+		if err != nil {
+			return apperrors.NewUnprocessableError("file", err.Error())
+		}
+		return nil
+	})
+	return appended, err
 }
 
 // GetDocuments retrieves documents for a vehicle with optional filters
@@ -278,38 +896,425 @@ func (r *VehicleRepository) GetDocuments(ctx context.Context, vehicleID string,
 	return filtered, nil
 }
 
-// DeleteDocument removes a document from a vehicle
-func (r *VehicleRepository) DeleteDocument(ctx context.Context, vehicleID string, documentID string) error {
-	vehicle, err := r.GetVehicle(ctx, vehicleID)
+// GetOwnerDocuments lists documents across all active vehicles of an owner by
+// unnesting the documents array. Documents without an expiry date sort last.
+func (r *VehicleRepository) GetOwnerDocuments(ctx context.Context, ownerID string, filter vehicle.OwnerDocumentFilter) ([]vehicle.OwnerDocument, int, error) {
+	if ownerID == "" {
+		return nil, 0, apperrors.ErrInvalidID
+	}
+
+	where := `v.owner_id = $owner_id AND v.deleted_at IS MISSING`
+	params := map[string]interface{}{"owner_id": ownerID}
+
+	if filter.Type != "" {
+		where += ` AND d.type = $type`
+		params["type"] = filter.Type
+	}
+	if filter.ExpiringWithinDays > 0 {
+		// Same window as Vehicle.GetExpiringDocuments: not yet expired, expiring before the threshold
+		now := time.Now()
+		where += ` AND STR_TO_MILLIS(d.expiry_date) > $now AND STR_TO_MILLIS(d.expiry_date) < $threshold`
+		params["now"] = now.UnixMilli()
+		params["threshold"] = now.AddDate(0, 0, filter.ExpiringWithinDays).UnixMilli()
+	}
+
+	order := "ASC"
+	if filter.Order == "desc" {
+		order = "DESC"
+	}
+
+	countQuery := `SELECT RAW COUNT(*) FROM vehicles v UNNEST v.documents d WHERE ` + where
+
+	countResult, err := r.cluster.Query(countQuery, &gocb.QueryOptions{
+		NamedParameters: params,
+		Timeout:         r.timeouts.Query,
+		ScanConsistency: r.queryConsistency(ctx),
+		Context:         ctx,
+	})
 	if err != nil {
-		return err
+		return nil, 0, r.convertDBError("count_owner_documents", err)
+	}
+
+	var total int
+	if err := countResult.One(&total); err != nil {
+		return nil, 0, r.convertDBError("count_owner_documents", err)
+	}
+
+	if filter.After != nil {
+		where += ` AND ` + keysetAfter("STR_TO_MILLIS(d.expiry_date)", "d.id", order == "DESC", *filter.After, "$after_value", "$after_id")
+		params["after_value"] = filter.After.Value
+		params["after_id"] = filter.After.ID
+	}
+
+	query := `
+		SELECT d.*, v.id AS vehicle_id, v.vin AS vin
+		FROM vehicles v
+		UNNEST v.documents d
+		WHERE ` + where + `
+		ORDER BY d.expiry_date IS VALUED DESC, STR_TO_MILLIS(d.expiry_date) ` + order + `, d.id
+		LIMIT $limit OFFSET $offset
+	`
+	params["limit"] = filter.Limit
+	params["offset"] = filter.Offset
+
+	result, err := r.cluster.Query(query, &gocb.QueryOptions{
+		NamedParameters: params,
+		Timeout:         r.timeouts.Query,
+		ScanConsistency: r.queryConsistency(ctx),
+		Context:         ctx,
+	})
+	if err != nil {
+		return nil, 0, r.convertDBError("get_owner_documents", err)
+	}
+	defer result.Close()
+
+	documents := []vehicle.OwnerDocument{}
+	for result.Next() {
+		var document vehicle.OwnerDocument
+		if err := decodeRow(ctx, result, "vehicle", &document); err != nil {
+			continue
+		}
+		documents = append(documents, document)
+	}
+
+	if err := result.Err(); err != nil {
+		return nil, 0, r.convertDBError("get_owner_documents_iteration", err)
+	}
+
+	return documents, total, nil
+}
+
+// errNoChange aborts a mutateVehicle call without writing when there is nothing to update
+var errNoChange = errors.New("no change")
+
+// ExpireVerifications finds vehicles holding verified documents past their expiry
+// date and revokes those verifications, one CAS-guarded write per vehicle.
+// A vehicle that fails is logged and skipped so one bad document cannot stall the sweep.
+func (r *VehicleRepository) ExpireVerifications(ctx context.Context) ([]vehicle.VerificationExpiry, error) {
+	query := `
+		SELECT RAW v.id
+		FROM vehicles v
+		WHERE v.deleted_at IS MISSING
+		AND ANY d IN v.documents SATISFIES d.is_verified = true AND STR_TO_MILLIS(d.expiry_date) < $now END
+	`
+
+	now := time.Now()
+	result, err := r.cluster.Query(query, &gocb.QueryOptions{
+		NamedParameters: map[string]interface{}{"now": now.UnixMilli()},
+		Timeout:         r.timeouts.Query,
+		Context:         ctx,
+	})
+	if err != nil {
+		return nil, r.convertDBError("find_expired_verifications", err)
+	}
+	defer result.Close()
+
+	var vehicleIDs []string
+	for result.Next() {
+		var id string
+		if err := decodeRow(ctx, result, "vehicle", &id); err != nil {
+			continue
+		}
+		vehicleIDs = append(vehicleIDs, id)
+	}
+	if err := result.Err(); err != nil {
+		return nil, r.convertDBError("find_expired_verifications_iteration", err)
 	}
 
-	if err := vehicle.RemoveDocument(documentID); err != nil {
-		return apperrors.ErrInvalidInput.WithDetails(map[string]string{
-			"error": err.Error(),
+	var expiries []vehicle.VerificationExpiry
+	for _, id := range vehicleIDs {
+		var expired []domain.Document
+		updated, err := mutateVehicle(ctx, r, id, func(v *domain.Vehicle) error {
+			expired = v.ExpireVerifications(now)
+			if len(expired) == 0 {
+				return errNoChange
+			}
+			return nil
 		})
+		if errors.Is(err, errNoChange) {
+			continue
+		}
+		if err != nil {
+			log.FromContext(ctx).Error("Failed to expire document verifications", zap.String("vehicle_id", id), zap.Error(err))
+			continue
+		}
+
+		expiries = append(expiries, vehicle.VerificationExpiry{
+			VehicleID: updated.ID,
+			OwnerID:   updated.OwnerID,
+			Documents: expired,
+		})
+	}
+
+	return expiries, nil
+}
+
+// RefreshStatusFlags re-evaluates the denormalized status flags of every
+// vehicle, which go stale as insurance and documents approach or pass their
+// end dates without any write. Only the two flags are written, so UpdatedAt
+// keeps meaning a change by a user. A vehicle written concurrently is skipped,
+// that write refreshed its flags already. Returns the number of vehicles updated.
+func (r *VehicleRepository) RefreshStatusFlags(ctx context.Context) (int, error) {
+	query := `
+		SELECT RAW v.id
+		FROM vehicles v
+		WHERE v.vin IS NOT MISSING
+		AND v.deleted_at IS MISSING
+	`
+
+	result, err := r.cluster.Query(query, &gocb.QueryOptions{
+		Timeout: r.timeouts.Query,
+		Context: ctx,
+	})
+	if err != nil {
+		return 0, r.convertDBError("find_vehicles_for_status_flags", err)
+	}
+	defer result.Close()
+
+	var vehicleIDs []string
+	for result.Next() {
+		var id string
+		if err := decodeRow(ctx, result, "vehicle", &id); err != nil {
+			continue
+		}
+		vehicleIDs = append(vehicleIDs, id)
+	}
+	if err := result.Err(); err != nil {
+		return 0, r.convertDBError("find_vehicles_for_status_flags_iteration", err)
+	}
+
+	refreshed := 0
+	for _, id := range vehicleIDs {
+		if err := ctx.Err(); err != nil {
+			return refreshed, err
+		}
+
+		vehicle, cas, err := r.getVehicleWithCAS(ctx, id)
+		if err != nil {
+			log.FromContext(ctx).Error("Failed to read vehicle for status flags", zap.String("vehicle_id", id), zap.Error(err))
+			continue
+		}
+		if !vehicle.RefreshStatusFlags() {
+			continue
+		}
+
+		_, err = r.collection.MutateIn(id, []gocb.MutateInSpec{
+			gocb.UpsertSpec("insurance_status", vehicle.InsuranceStatus, nil),
+			gocb.UpsertSpec("document_status", vehicle.DocumentStatus, nil),
+		}, &gocb.MutateInOptions{
+			Cas:             cas,
+			DurabilityLevel: r.durability,
+			Timeout:         r.timeouts.KV,
+			Context:         ctx,
+		})
+		if errors.Is(err, gocb.ErrCasMismatch) {
+			continue
+		}
+		if err != nil {
+			log.FromContext(ctx).Error("Failed to refresh vehicle status flags", zap.String("vehicle_id", id), zap.Error(err))
+			continue
+		}
+		refreshed++
+	}
+
+	return refreshed, nil
+}
+
+// ReferencedFileURLs collects the file URLs of every vehicle in one query.
+// Soft-deleted vehicles are included, a restore brings their files back.
+// The query waits for the index to hold every earlier write, otherwise a file
+// recorded moments ago would look unreferenced and be deleted.
+func (r *VehicleRepository) ReferencedFileURLs(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT RAW ARRAY_CONCAT(
+			IFMISSINGORNULL(ARRAY d.file_url FOR d IN v.documents END, []),
+			IFMISSINGORNULL(ARRAY_FLATTEN(ARRAY ARRAY f.url FOR f IN IFMISSINGORNULL(d.files, []) END FOR d IN v.documents END, 1), []),
+			IFMISSINGORNULL(ARRAY p.url FOR p IN v.pictures END, []),
+			IFMISSINGORNULL(ARRAY p.thumbnail_url FOR p IN v.pictures END, [])
+		)
+		FROM vehicles v
+		WHERE v.vin IS NOT MISSING
+	`
+
+	result, err := r.cluster.Query(query, &gocb.QueryOptions{
+		Timeout:         r.timeouts.Query,
+		ScanConsistency: gocb.QueryScanConsistencyRequestPlus,
+		Context:         ctx,
+	})
+	if err != nil {
+		return nil, r.convertDBError("find_referenced_file_urls", err)
+	}
+	defer result.Close()
+
+	var urls []string
+	for result.Next() {
+		var vehicleURLs []string
+		if err := decodeRow(ctx, result, "vehicle", &vehicleURLs); err != nil {
+			// A vehicle that cannot be read might reference anything, so the
+			// caller must not treat the list as complete
+			return nil, err
+		}
+		for _, url := range vehicleURLs {
+			if url != "" {
+				urls = append(urls, url)
+			}
+		}
+	}
+	if err := result.Err(); err != nil {
+		return nil, r.convertDBError("find_referenced_file_urls_iteration", err)
 	}
 
-	return r.UpdateVehicle(ctx, vehicle)
+	return urls, nil
+}
+
+// DeleteDocument removes a document from a vehicle. The unmodifiedSince
+// precondition is checked inside the CAS-guarded write, so a change that lands
+// between the check and the delete still fails it.
+func (r *VehicleRepository) DeleteDocument(ctx context.Context, vehicleID string, documentID string, unmodifiedSince time.Time) error {
+	_, err := deleteDocument(ctx, r, vehicleID, documentID, unmodifiedSince)
+	return err
+}
+
+func deleteDocument(ctx context.Context, store vehicleStore, vehicleID string, documentID string, unmodifiedSince time.Time) (*domain.Vehicle, error) {
+	return mutateVehicle(ctx, store, vehicleID, func(vehicle *domain.Vehicle) error {
+		if !unmodifiedSince.IsZero() && vehicle.ModifiedSince(unmodifiedSince) {
+			return apperrors.ErrPreconditionFailed.WithDetails(map[string]string{
+				"resource":   "vehicle",
+				"id":         vehicleID,
+				"updated_at": vehicle.UpdatedAt.UTC().Format(time.RFC3339),
+			})
+		}
+		if err := vehicle.RemoveDocument(documentID); err != nil {
+			return apperrors.ErrInvalidInput.WithDetails(map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return nil
+	})
 }
 
 // AddPicture adds a picture to a vehicle
 func (r *VehicleRepository) AddPicture(ctx context.Context, vehicleID string, picture domain.Picture) error {
+	_, err := addPicture(ctx, r, vehicleID, picture)
+	return err
+}
+
+func addPicture(ctx context.Context, store vehicleStore, vehicleID string, picture domain.Picture) (*domain.Vehicle, error) {
+	return mutateVehicle(ctx, store, vehicleID, func(vehicle *domain.Vehicle) error {
+		if err := vehicle.AddPicture(picture); err != nil {
+			return apperrors.ErrInvalidInput.WithDetails(map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return nil
+	})
+}
+
+// AddPictures adds several pictures to a vehicle in one CAS-guarded write
+func (r *VehicleRepository) AddPictures(ctx context.Context, vehicleID string, pictures []domain.Picture) (*domain.Vehicle, error) {
+	return mutateVehicle(ctx, r, vehicleID, func(vehicle *domain.Vehicle) error {
+		if err := vehicle.AddPictures(pictures); err != nil {
+			return apperrors.ErrInvalidInput.WithDetails(map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return nil
+	})
+}
+
+// SetMainPicture makes the picture the vehicle's only main picture. The change is
+// CAS-guarded, so a picture added concurrently cannot leave zero or two main pictures.
+func (r *VehicleRepository) SetMainPicture(ctx context.Context, vehicleID string, pictureID string) error {
+	_, err := setMainPicture(ctx, r, vehicleID, pictureID)
+	return err
+}
+
+func setMainPicture(ctx context.Context, store vehicleStore, vehicleID string, pictureID string) (*domain.Vehicle, error) {
+	return mutateVehicle(ctx, store, vehicleID, func(vehicle *domain.Vehicle) error {
+		if err := vehicle.SetMainPicture(pictureID); err != nil {
+			return apperrors.NewNotFoundError("picture", pictureID)
+		}
+
+		// Never write a vehicle that breaks the single main picture invariant
+		if mains := mainPictureCount(vehicle); mains != 1 {
+			return apperrors.ErrInternalServer.WithDetails(map[string]string{
+				"error": fmt.Sprintf("vehicle %s would have %d main pictures", vehicle.ID, mains),
+			})
+		}
+		return nil
+	})
+}
+
+func mainPictureCount(vehicle *domain.Vehicle) int {
+	mains := 0
+	for _, pic := range vehicle.Pictures {
+		if pic.IsMain {
+			mains++
+		}
+	}
+	return mains
+}
+
+// AddServiceRecord appends a record to the vehicle's service history
+func (r *VehicleRepository) AddServiceRecord(ctx context.Context, vehicleID string, record domain.ServiceRecord) error {
+	_, err := mutateVehicle(ctx, r, vehicleID, func(vehicle *domain.Vehicle) error {
+		if err := vehicle.AddServiceRecord(record); err != nil {
+			return apperrors.ErrUnprocessableEntity.WithDetails(map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return nil
+	})
+	return err
+}
+
+// RenewInsurance extends a vehicle's insurance in a CAS-guarded write
+func (r *VehicleRepository) RenewInsurance(ctx context.Context, vehicleID string, policyNumber string, endDate time.Time, premium *domain.Money, renewedBy string) error {
+	_, err := mutateVehicle(ctx, r, vehicleID, func(vehicle *domain.Vehicle) error {
+		if err := vehicle.RenewInsurance(policyNumber, endDate, premium); err != nil {
+			return apperrors.NewUnprocessableError("new_end_date", err.Error())
+		}
+		vehicle.UpdateTimestamp(renewedBy)
+		return nil
+	})
+	return err
+}
+
+// GetServiceRecords returns the vehicle's service history ordered by date
+func (r *VehicleRepository) GetServiceRecords(ctx context.Context, vehicleID string) ([]domain.ServiceRecord, error) {
 	vehicle, err := r.GetVehicle(ctx, vehicleID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Add the picture to the vehicle
-	if err := vehicle.AddPicture(picture); err != nil {
-		return apperrors.ErrInvalidInput.WithDetails(map[string]string{
-			"error": err.Error(),
-		})
+	if vehicle.ServiceRecords == nil {
+		return []domain.ServiceRecord{}, nil
+	}
+
+	return vehicle.ServiceRecords, nil
+}
+
+// DeletePicturesByType removes every picture of a type from a vehicle and returns
+// the updated vehicle with the removed pictures. Nothing is written when no picture matches.
+func (r *VehicleRepository) DeletePicturesByType(ctx context.Context, vehicleID string, picType domain.PictureType) (*domain.Vehicle, []domain.Picture, error) {
+	var current *domain.Vehicle
+	var removed []domain.Picture
+	updated, err := mutateVehicle(ctx, r, vehicleID, func(vehicle *domain.Vehicle) error {
+		current = vehicle
+		removed = vehicle.RemovePicturesByType(picType)
+		if len(removed) == 0 {
+			return errNoChange
+		}
+		return nil
+	})
+	if errors.Is(err, errNoChange) {
+		return current, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Update the vehicle
-	return r.UpdateVehicle(ctx, vehicle)
+	return updated, removed, nil
 }
 
 // convertDBError converts Couchbase errors to application errors
@@ -325,6 +1330,9 @@ func (r *VehicleRepository) convertDBError(operation string, err error) error {
 
 	case errors.As(err, &timeoutErr):
 		return apperrors.ErrRequestTimeout.WithCause(timeoutErr)
+
+	case errors.Is(err, gocb.ErrDurabilityAmbiguous):
+		return apperrors.ErrWriteAmbiguous.WithCause(err)
 	default:
 		// If we can’t categorize it, just wrap it.
 		return apperrors.NewDatabaseError(operation, err)
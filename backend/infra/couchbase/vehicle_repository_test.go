@@ -0,0 +1,126 @@
+package couchbase
+
+import (
+	"context"
+	"errors"
+	"microservicetest/app"
+	"microservicetest/domain"
+	apperrors "microservicetest/pkg/errors"
+	"testing"
+	"time"
+
+	"github.com/couchbase/gocb/v2"
+)
+
+func TestVinKey_Normalization(t *testing.T) {
+	key, err := vinKey("  1hgbh41jxmn109186  ")
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if key != "vin::1HGBH41JXMN109186" {
+		t.Errorf("Expected key to be vin::1HGBH41JXMN109186, got %s", key)
+	}
+}
+
+func TestVinKey_InvalidVIN(t *testing.T) {
+	for _, vin := range []string{"", "   ", "SHORT", "1HGBH41JXMN1091860"} {
+		_, err := vinKey(vin)
+
+		if !errors.Is(err, apperrors.ErrInvalidID) {
+			t.Errorf("Expected ErrInvalidID for VIN %q, got %v", vin, err)
+		}
+	}
+}
+
+func TestGetVehicleByVIN_EmptyVIN(t *testing.T) {
+	repo := &VehicleRepository{}
+
+	_, err := repo.GetVehicleByVIN(context.Background(), "")
+
+	if !errors.Is(err, apperrors.ErrInvalidID) {
+		t.Fatalf("Expected ErrInvalidID, got %v", err)
+	}
+}
+
+func TestQueryConsistency(t *testing.T) {
+	repo := &VehicleRepository{scanConsistency: gocb.QueryScanConsistencyNotBounded}
+
+	if got := repo.queryConsistency(context.Background()); got != gocb.QueryScanConsistencyNotBounded {
+		t.Errorf("Expected the configured consistency, got %v", got)
+	}
+	if got := repo.queryConsistency(app.WithConsistentReads(context.Background())); got != gocb.QueryScanConsistencyRequestPlus {
+		t.Errorf("Expected request_plus for a consistent read, got %v", got)
+	}
+}
+
+// memoryVINStore is a memoryVehicleStore holding at most one vehicle, found by its VIN
+type memoryVINStore struct {
+	memoryVehicleStore
+}
+
+func (s *memoryVINStore) getVehicleByVINWithCAS(ctx context.Context, vin string) (*domain.Vehicle, gocb.Cas, error) {
+	s.mu.Lock()
+	found := s.vehicle.ID != "" && s.vehicle.VIN == vin
+	s.mu.Unlock()
+	if !found {
+		return nil, 0, apperrors.NewNotFoundError("vehicle", vin)
+	}
+	return s.getVehicleWithCAS(ctx, s.vehicle.ID)
+}
+
+func (s *memoryVINStore) CreateVehicle(ctx context.Context, vehicle *domain.Vehicle) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.vehicle.ID != "" {
+		return apperrors.NewConflictError("vehicle", "VIN "+vehicle.VIN+" is already registered")
+	}
+	s.vehicle = *vehicle
+	s.cas = 1
+	return nil
+}
+
+func TestUpsertVehicleByVIN(t *testing.T) {
+	store := &memoryVINStore{}
+	vin := "1HGBH41JXMN109186"
+
+	created, wasCreated, err := upsertVehicleByVIN(context.Background(), store, &domain.Vehicle{
+		ID: "VEH_1", VIN: vin, OwnerID: "owner-1", FuelType: domain.FuelTypeElectric, Color: "red",
+	})
+	if err != nil || !wasCreated || created.ID != "VEH_1" {
+		t.Fatalf("Expected the vehicle to be created, got %+v, %v, %v", created, wasCreated, err)
+	}
+
+	updated, wasCreated, err := upsertVehicleByVIN(context.Background(), store, &domain.Vehicle{
+		ID: "VEH_2", VIN: vin, OwnerID: "owner-2", FuelType: domain.FuelTypeGasoline, Color: "blue", UpdatedBy: "sync",
+	})
+	if err != nil || wasCreated {
+		t.Fatalf("Expected the vehicle to be updated, got %v, %v", wasCreated, err)
+	}
+	if updated.ID != "VEH_1" || updated.Color != "blue" || updated.UpdatedBy != "sync" {
+		t.Errorf("Expected the mutable fields applied to VEH_1, got %+v", updated)
+	}
+	if updated.OwnerID != "owner-1" || updated.FuelType != domain.FuelTypeElectric {
+		t.Errorf("Expected the owner and fuel type to be kept, got %s and %s", updated.OwnerID, updated.FuelType)
+	}
+	if store.vehicle.Color != "blue" || store.cas != 2 {
+		t.Errorf("Expected the update to be stored, got %+v at CAS %d", store.vehicle, store.cas)
+	}
+}
+
+func TestUpsertVehicleByVIN_Deleted(t *testing.T) {
+	deletedAt := time.Now()
+	store := &memoryVINStore{memoryVehicleStore{
+		vehicle: domain.Vehicle{ID: "VEH_1", VIN: "1HGBH41JXMN109186", Color: "red", DeletedAt: &deletedAt},
+		cas:     1,
+	}}
+
+	_, _, err := upsertVehicleByVIN(context.Background(), store, &domain.Vehicle{VIN: "1HGBH41JXMN109186", Color: "blue"})
+	if !errors.Is(err, apperrors.ErrResourceExists) {
+		t.Errorf("Expected a conflict for a deleted vehicle, got %v", err)
+	}
+	if store.vehicle.Color != "red" {
+		t.Error("Expected the deleted vehicle to be left unchanged")
+	}
+}
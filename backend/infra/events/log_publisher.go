@@ -0,0 +1,31 @@
+package events
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"microservicetest/app"
+	"microservicetest/pkg/log"
+)
+
+// LogPublisher writes events to the structured log, where the log pipeline
+// picks them up until a message broker is in place
+type LogPublisher struct{}
+
+var _ app.EventPublisher = (*LogPublisher)(nil)
+
+func NewLogPublisher() *LogPublisher {
+	return &LogPublisher{}
+}
+
+func (p *LogPublisher) Publish(ctx context.Context, event app.Event) error {
+	log.FromContext(ctx).Info("Event published",
+		zap.String("event_type", event.Type),
+		zap.String("vehicle_id", event.VehicleID),
+		zap.String("owner_id", event.OwnerID),
+		zap.Time("occurred_at", event.OccurredAt),
+		zap.Any("data", event.Data),
+	)
+	return nil
+}
@@ -2,18 +2,30 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"microservicetest/app"
 	"microservicetest/app/gps"
+	"microservicetest/app/maintenance"
 	"microservicetest/app/vehicle"
+	"microservicetest/domain"
 	"microservicetest/infra/azure"
 	"microservicetest/infra/cosmos"
+	"microservicetest/infra/events"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	expvarmw "github.com/gofiber/fiber/v2/middleware/expvar"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
@@ -21,14 +33,23 @@ import (
 	"microservicetest/infra/couchbase"
 	"microservicetest/pkg/config"
 	apperrors "microservicetest/pkg/errors"
-	_ "microservicetest/pkg/log"
+	"microservicetest/pkg/features"
+	"microservicetest/pkg/log"
+	"microservicetest/pkg/metrics"
+	"microservicetest/pkg/response"
+	"microservicetest/pkg/scheduler"
 )
 
+// RequestIDMiddleware tags each request with an ID and stores a logger carrying
+// it on the user context, so handlers and repositories get it from log.FromContext
 func RequestIDMiddleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		requestID := uuid.New().String()
 		c.Locals("requestID", requestID)
 		c.Set("X-Request-ID", requestID)
+
+		logger := zap.L().With(zap.String("request_id", requestID))
+		c.SetUserContext(log.WithLogger(c.UserContext(), logger))
 		return c.Next()
 	}
 }
@@ -40,23 +61,291 @@ func RequestDurationMiddleware() fiber.Handler {
 		err := c.Next()
 
 		duration := time.Since(start).Seconds()
-		requestID := c.Locals("requestID").(string)
-		zap.L().Info("Request completed",
-			zap.String("request_id", requestID),
+		log.FromContext(c.UserContext()).Info("Request completed",
 			zap.String("method", c.Method()),
 			zap.String("path", c.Path()),
 			zap.Int("status_code", c.Response().StatusCode()),
 			zap.Float64("duration_seconds", duration),
-			zap.Int("response_size", len(c.Response().Body())),
+			zap.Int("response_size", responseSize(c)),
 		)
 
 		return err
 	}
 }
 
+// responseSize returns the size of the response body. Streamed bodies (the
+// vehicle archive) are only written after the middlewares return and reading
+// them here would buffer the whole stream, so their Content-Length is used,
+// -1 when it is not known.
+func responseSize(c *fiber.Ctx) int {
+	if c.Response().IsBodyStream() {
+		return c.Response().Header.ContentLength()
+	}
+	return len(c.Response().Body())
+}
+
+// overloadRetryAfter is the Retry-After sent when a request is shed
+const overloadRetryAfter = time.Second
+
+// errOverloaded is returned for requests beyond max_in_flight_requests
+var errOverloaded = apperrors.ErrServiceUnavailable.WithDetails(map[string]string{
+	"reason": "too many requests in flight",
+}).WithRetryAfter(overloadRetryAfter)
+
+// inFlightRequests tracks the requests being handled so shutdown can wait for
+// them and report the ones it gave up on
+type inFlightRequests struct {
+	wg     sync.WaitGroup
+	active atomic.Int64
+	limit  int64 // Requests handled at once before Limit sheds, 0 means unlimited
+}
+
+func (r *inFlightRequests) Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		r.wg.Add(1)
+		r.active.Add(1)
+		metrics.RequestsInFlight.Add(1)
+		defer func() {
+			metrics.RequestsInFlight.Add(-1)
+			r.active.Add(-1)
+			r.wg.Done()
+		}()
+		return c.Next()
+	}
+}
+
+// Limit sheds requests once more than the limit are in flight, answering 503
+// with Retry-After instead of piling more work onto Couchbase and Blob Storage.
+// It must run after Middleware, which counts the request itself. Health checks
+// are never shed.
+func (r *inFlightRequests) Limit() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if r.limit <= 0 || r.active.Load() <= r.limit {
+			return c.Next()
+		}
+
+		path := c.Path()
+		if path == "/healthcheck" || path == "/healthcheck/ready" {
+			return c.Next()
+		}
+
+		metrics.RequestsShed.Add(1)
+		return apperrors.HandleError(c, errOverloaded)
+	}
+}
+
+// Wait waits up to timeout for in-flight requests to finish and returns how
+// many are still active
+func (r *inFlightRequests) Wait(timeout time.Duration) int64 {
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+	return r.active.Load()
+}
+
+// RequestTimeoutMiddleware bounds each request with a deadline on the user context.
+// Handlers must pass ctx.UserContext() (or the ctx given to handle) to downstream
+// calls so a stuck Couchbase, Cosmos DB or Blob Storage call is cancelled with the request.
+func RequestTimeoutMiddleware(timeout time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(c.UserContext(), timeout)
+		defer cancel()
+
+		c.SetUserContext(ctx)
+		return c.Next()
+	}
+}
+
+// ConsistentReadsMiddleware lets a request ask with ?consistent=true for queries
+// that see every earlier write, whatever couchbase_scan_consistency says
+func ConsistentReadsMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.QueryBool("consistent") {
+			c.SetUserContext(app.WithConsistentReads(c.UserContext()))
+		}
+		return c.Next()
+	}
+}
+
+// JSONContentTypeMiddleware rejects write requests whose body is not JSON with
+// ErrUnsupportedMediaType. Mount it on JSON routes only, multipart uploads skip it.
+func JSONContentTypeMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		switch c.Method() {
+		case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch:
+		default:
+			return c.Next()
+		}
+
+		// Nothing to parse, let request validation report missing fields
+		if len(c.Body()) == 0 {
+			return c.Next()
+		}
+
+		if !c.Is("json") {
+			return apperrors.HandleError(c, apperrors.ErrUnsupportedMediaType.WithDetails(map[string]string{
+				"content_type": c.Get(fiber.HeaderContentType),
+				"expected":     fiber.MIMEApplicationJSON,
+			}))
+		}
+
+		return c.Next()
+	}
+}
+
+// EnvelopeMiddleware wraps JSON responses in a response.Envelope when always is
+// set or the client sends X-Envelope: true. XML and other bodies are left alone.
+func EnvelopeMiddleware(always bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !always && !strings.EqualFold(c.Get("X-Envelope"), "true") {
+			return c.Next()
+		}
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		// Streamed bodies are never JSON here, and reading one would buffer it
+		if c.Response().IsBodyStream() {
+			return nil
+		}
+
+		body := c.Response().Body()
+		if len(body) == 0 || !strings.HasPrefix(string(c.Response().Header.ContentType()), fiber.MIMEApplicationJSON) {
+			return nil
+		}
+
+		requestID, _ := c.Locals("requestID").(string)
+		wrapped, err := response.Wrap(body, c.Response().StatusCode() >= fiber.StatusBadRequest, requestID)
+		if err != nil {
+			return err
+		}
+		c.Response().SetBodyRaw(wrapped)
+		return nil
+	}
+}
+
+// APIKeyMiddleware authenticates backend services by the X-API-Key header.
+// A valid key attaches the service identity to the request context (see
+// app.ServiceFromContext). Routes under cfg.Routes require a key, elsewhere
+// it is optional, but a key that is sent must be valid.
+func APIKeyMiddleware(cfg config.ServiceAuthConfig) fiber.Handler {
+	type serviceKey struct {
+		service string
+		digest  []byte
+	}
+
+	// Digests are checked by config.Validate
+	keys := make([]serviceKey, 0, len(cfg.APIKeys))
+	for _, key := range cfg.APIKeys {
+		digest, _ := hex.DecodeString(key.SHA256)
+		keys = append(keys, serviceKey{service: key.Service, digest: digest})
+	}
+
+	return func(c *fiber.Ctx) error {
+		apiKey := c.Get("X-API-Key")
+
+		serviceRoute := false
+		for _, prefix := range cfg.Routes {
+			if strings.HasPrefix(c.Path(), prefix) {
+				serviceRoute = true
+				break
+			}
+		}
+
+		if apiKey == "" {
+			if serviceRoute {
+				return apperrors.HandleError(c, apperrors.ErrUnauthorized.WithDetails(map[string]string{
+					"header": "X-API-Key",
+				}))
+			}
+			return c.Next()
+		}
+
+		// Compare against every key so timing does not reveal which one matched
+		sum := sha256.Sum256([]byte(apiKey))
+		service := ""
+		for _, key := range keys {
+			if subtle.ConstantTimeCompare(sum[:], key.digest) == 1 {
+				service = key.service
+			}
+		}
+
+		if service == "" {
+			return apperrors.HandleError(c, apperrors.ErrUnauthorized.WithDetails(map[string]string{
+				"header": "X-API-Key",
+				"reason": "invalid API key",
+			}))
+		}
+
+		c.Locals("service", service)
+		ctx := app.WithService(c.UserContext(), service)
+		ctx = log.WithLogger(ctx, log.FromContext(ctx).With(zap.String("service", service)))
+		c.SetUserContext(ctx)
+		return c.Next()
+	}
+}
+
+// RequireServiceMiddleware only lets through requests APIKeyMiddleware
+// authenticated as a backend service
+func RequireServiceMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, ok := app.ServiceFromContext(c.UserContext()); !ok {
+			return apperrors.HandleError(c, apperrors.ErrUnauthorized.WithDetails(map[string]string{
+				"header": "X-API-Key",
+			}))
+		}
+		return c.Next()
+	}
+}
+
+// MaintenanceModeMiddleware rejects traffic with ErrMaintenanceMode while the toggle is on.
+// Health and admin routes, plus the configured IPs and paths, are always let through.
+func MaintenanceModeMiddleware(mode *maintenance.Mode, cfg config.MaintenanceConfig) fiber.Handler {
+	allowedIPs := make(map[string]struct{}, len(cfg.AllowedIPs))
+	for _, ip := range cfg.AllowedIPs {
+		allowedIPs[ip] = struct{}{}
+	}
+
+	return func(c *fiber.Ctx) error {
+		if !mode.Enabled() {
+			return c.Next()
+		}
+
+		path := c.Path()
+		if path == "/healthcheck" || path == "/healthcheck/ready" || strings.HasPrefix(path, "/admin/") {
+			return c.Next()
+		}
+		if _, ok := allowedIPs[c.IP()]; ok {
+			return c.Next()
+		}
+		for _, allowed := range cfg.AllowedPaths {
+			if strings.HasPrefix(path, allowed) {
+				return c.Next()
+			}
+		}
+
+		retryAfter := time.Duration(cfg.RetryAfterSeconds) * time.Second
+		return apperrors.HandleError(c, apperrors.ErrMaintenanceMode.WithRetryAfter(retryAfter))
+	}
+}
+
 type Request any
 type Response any
 
+// badRequest reports a request that could not be parsed into the handler's
+// request struct, in the same error body as the handlers' own errors
+func badRequest(c *fiber.Ctx, err error) error {
+	return apperrors.HandleError(c, apperrors.ErrInvalidInput.WithCause(err))
+}
+
 // Define an interface for handlers
 type HandlerInterface[R Request, Res Response] interface {
 	Handle(ctx context.Context, req *R) (*Res, error)
@@ -72,26 +361,21 @@ func handle[R Request, Res Response](handler HandlerInterface[R, Res]) fiber.Han
 		var req R
 
 		if err := c.BodyParser(&req); err != nil && !errors.Is(err, fiber.ErrUnprocessableEntity) {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+			return badRequest(c, err)
 		}
 
 		if err := c.ParamsParser(&req); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+			return badRequest(c, err)
 		}
 
 		if err := c.QueryParser(&req); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+			return badRequest(c, err)
 		}
 
 		if err := c.ReqHeaderParser(&req); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+			return badRequest(c, err)
 		}
 
-		/*
-			ctx, cancel := context.WithTimeout(c.UserContext(), 3*time.Second)
-			defer cancel()
-		*/
-
 		ctx := c.UserContext()
 
 		res, err := handler.Handle(ctx, &req)
@@ -99,7 +383,7 @@ func handle[R Request, Res Response](handler HandlerInterface[R, Res]) fiber.Han
 			return apperrors.HandleError(c, err)
 		}
 
-		return c.JSON(res)
+		return response.Send(c, res)
 	}
 }
 
@@ -108,32 +392,27 @@ func handleFiberCtx[R Request, Res Response](handler HandlerCtxInterface[R, Res]
 		var req R
 
 		if err := c.BodyParser(&req); err != nil && !errors.Is(err, fiber.ErrUnprocessableEntity) {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+			return badRequest(c, err)
 		}
 
 		if err := c.ParamsParser(&req); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+			return badRequest(c, err)
 		}
 
 		if err := c.QueryParser(&req); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+			return badRequest(c, err)
 		}
 
 		if err := c.ReqHeaderParser(&req); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+			return badRequest(c, err)
 		}
 
-		/*
-			ctx, cancel := context.WithTimeout(c.UserContext(), 3*time.Second)
-			defer cancel()
-		*/
-
 		res, err := handler.Handle(c, &req)
 		if err != nil {
 			return apperrors.HandleError(c, err)
 		}
 
-		return c.JSON(res)
+		return response.Send(c, res)
 	}
 }
 
@@ -147,79 +426,353 @@ func handleRaw[R Request](handler HandlerRawInterface[R]) fiber.Handler {
 		var req R
 
 		if err := c.ParamsParser(&req); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+			return badRequest(c, err)
 		}
 
 		if err := c.QueryParser(&req); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+			return badRequest(c, err)
+		}
+
+		if err := handler.Handle(c, &req); err != nil {
+			return apperrors.HandleError(c, err)
 		}
 
-		return handler.Handle(c, &req)
+		return nil
 	}
 }
 
+// readinessDependencies orders the configured checks as config.ReadinessDependencies
+// lists them, marking the hard ones. Dependencies without a check are skipped.
+func readinessDependencies(checks map[string]healthcheck.Check, hard []string) []healthcheck.Dependency {
+	var dependencies []healthcheck.Dependency
+	for _, name := range config.ReadinessDependencies {
+		check, ok := checks[name]
+		if !ok {
+			continue
+		}
+		dependencies = append(dependencies, healthcheck.Dependency{
+			Name:  name,
+			Check: check,
+			Hard:  slices.Contains(hard, name),
+		})
+	}
+	return dependencies
+}
+
+// enforcedDocumentStatuses returns the statuses a vehicle can only be moved to
+// with every document they require. Configured statuses replace the domain
+// defaults, like they do for the documents required.
+func enforcedDocumentStatuses(required []config.StatusDocumentsConfig) []domain.VehicleStatus {
+	if len(required) == 0 {
+		return domain.DefaultEnforcedStatuses()
+	}
+	var statuses []domain.VehicleStatus
+	for _, entry := range required {
+		if entry.Enforce {
+			statuses = append(statuses, domain.VehicleStatus(entry.Status))
+		}
+	}
+	return statuses
+}
+
 func main() {
 	appConfig := config.Read()
 	defer zap.L().Sync()
 	zap.L().Info("app starting...")
 	zap.L().Info("app config", zap.Any("appConfig", appConfig))
 
-	storageService, err := azure.NewStorage(appConfig.AzureConnectionString, "documents")
-	if err != nil {
-		zap.L().Error("Failed to initialize Azure Blob service", zap.Error(err))
+	// Handlers check for a nil storage and answer 503, so only assign it on success;
+	// a nil *azure.Storage would make a non-nil interface.
+	var storageService app.Storage
+	azureStorage, err := azure.NewStorage(appConfig.AzureConnectionString, "documents")
+	switch {
+	case err == nil && appConfig.MaxConcurrentUploads > 0:
+		storageService = app.NewLimitedStorage(azureStorage, appConfig.MaxConcurrentUploads, appConfig.MaxQueuedUploads)
+	case err == nil:
+		storageService = azureStorage
+	case appConfig.StorageRequired:
+		zap.L().Fatal("Failed to initialize Azure Blob service", zap.Error(err))
+	default:
+		zap.L().Error("Failed to initialize Azure Blob service, document and picture files are unavailable", zap.Error(err))
 	}
 
-	couchbaseRepository := couchbase.NewVehicleRepository(appConfig.CouchbaseUrl, appConfig.CouchbaseUsername, appConfig.CouchbasePassword)
+	// Readiness checks by dependency name, see config.ReadinessDependencies
+	readinessChecks := map[string]healthcheck.Check{}
+	if err == nil {
+		readinessChecks["storage"] = azureStorage.Ping
+	} else {
+		storageErr := err
+		readinessChecks["storage"] = func(context.Context) error { return storageErr }
+	}
 
-	// Initialize Cosmos DB repository for GPS data
-	cosmosRepository, err := cosmosdb.NewGPSRepository(
-		appConfig.CosmosDBEndpoint,
-		appConfig.CosmosDBKey,
-		appConfig.CosmosDBDatabase,
-		appConfig.CosmosDBContainer,
-	)
-	if err != nil {
-		zap.L().Error("Failed to initialize Cosmos DB repository", zap.Error(err))
+	couchbaseTimeouts := couchbase.Timeouts{
+		Connect: appConfig.CouchbaseTimeouts.Connect,
+		KV:      appConfig.CouchbaseTimeouts.KV,
+		Query:   appConfig.CouchbaseTimeouts.Query,
+	}
+	couchbaseRepository := couchbase.NewVehicleRepository(appConfig.CouchbaseUrl, appConfig.CouchbaseUsername, appConfig.CouchbasePassword, appConfig.CouchbaseDurability, appConfig.CouchbaseScanConsistency, couchbaseTimeouts)
+	readinessChecks["couchbase"] = couchbaseRepository.Ping
+
+	// Initialize Cosmos DB repository for GPS data. Without Cosmos config the
+	// service still runs, just without the GPS routes.
+	var getGPSDataHandler *gps.GetGPSDataHandler
+	var deleteGPSDataHandler *gps.DeleteGPSDataHandler
+	if appConfig.Cosmos.Configured() {
+		cosmosRepository, err := cosmosdb.NewGPSRepository(
+			appConfig.Cosmos.Endpoint,
+			appConfig.Cosmos.Key,
+			appConfig.Cosmos.Database,
+			appConfig.Cosmos.Container,
+		)
+		if err != nil {
+			zap.L().Fatal("Failed to initialize Cosmos DB repository", zap.Error(err))
+		}
+		getGPSDataHandler = gps.NewGetGPSDataHandler(cosmosRepository, appConfig.GPSMaxQueryLimit, appConfig.GPSMaxSpeedKmh)
+		deleteGPSDataHandler = gps.NewDeleteGPSDataHandler(cosmosRepository)
+		readinessChecks["cosmos"] = cosmosRepository.Ping
+	} else {
+		zap.L().Warn("Cosmos DB is not configured, GPS endpoints are disabled")
+	}
+
+	domain.RegisterDocumentTypes(appConfig.ExtraDocumentTypes...)
+	if len(appConfig.RequiredDocumentTypes) > 0 {
+		if err := domain.SetRequiredDocumentTypes(appConfig.RequiredDocumentTypes...); err != nil {
+			zap.L().Fatal("Invalid required_document_types", zap.Error(err))
+		}
+	}
+	if len(appConfig.RequiredPictureTypes) > 0 {
+		if err := domain.SetRequiredPictureTypes(appConfig.RequiredPictureTypes...); err != nil {
+			zap.L().Fatal("Invalid required_picture_types", zap.Error(err))
+		}
+	}
+
+	if len(appConfig.AllowedFileTypes) > 0 {
+		allowedFileTypes := make(map[string]string, len(appConfig.AllowedFileTypes))
+		for _, fileType := range appConfig.AllowedFileTypes {
+			allowedFileTypes[fileType.MimeType] = fileType.Extension
+		}
+		if err := domain.SetAllowedFileTypes(allowedFileTypes); err != nil {
+			zap.L().Fatal("Invalid allowed_file_types", zap.Error(err))
+		}
+	}
+
+	if len(appConfig.MinPictureResolutions) > 0 {
+		minResolutions := make(map[string]domain.Resolution, len(appConfig.MinPictureResolutions))
+		for _, res := range appConfig.MinPictureResolutions {
+			minResolutions[res.Type] = domain.Resolution{Width: res.Width, Height: res.Height}
+		}
+		if err := domain.SetMinPictureResolutions(minResolutions); err != nil {
+			zap.L().Fatal("Invalid min_picture_resolutions", zap.Error(err))
+		}
+	}
+
+	if len(appConfig.StatusRequiredDocuments) > 0 {
+		statusDocuments := make(map[string][]string, len(appConfig.StatusRequiredDocuments))
+		for _, required := range appConfig.StatusRequiredDocuments {
+			statusDocuments[required.Status] = required.DocumentTypes
+		}
+		if err := domain.SetStatusRequiredDocuments(statusDocuments); err != nil {
+			zap.L().Fatal("Invalid status_required_documents", zap.Error(err))
+		}
 	}
 
+	if appConfig.DefaultCurrency != "" {
+		if err := domain.SetDefaultCurrency(appConfig.DefaultCurrency); err != nil {
+			zap.L().Fatal("Invalid default_currency", zap.Error(err))
+		}
+	}
+
+	if err := vehicle.SetMaxLookaheadDays(appConfig.MaxLookaheadDays); err != nil {
+		zap.L().Fatal("Invalid max_lookahead_days", zap.Error(err))
+	}
+
+	featureFlags := features.New(appConfig.Features)
+	zap.L().Info("Feature flags", zap.Strings("enabled", featureFlags.Enabled()))
+
 	healthcheckHandler := healthcheck.NewHealthCheckHandler()
+	readinessHandler := healthcheck.NewReadinessHandler(
+		readinessDependencies(readinessChecks, appConfig.Readiness.HardDependencies),
+		time.Duration(appConfig.Readiness.CheckTimeoutMs)*time.Millisecond,
+	)
+
+	// Maintenance mode toggle
+	maintenanceMode := maintenance.NewMode(appConfig.Maintenance.Enabled)
+	getMaintenanceHandler := maintenance.NewGetMaintenanceHandler(maintenanceMode)
+	setMaintenanceHandler := maintenance.NewSetMaintenanceHandler(maintenanceMode)
+
+	eventPublisher := events.NewLogPublisher()
+	auditStore := couchbase.NewAuditStore(couchbaseRepository.Cluster(), couchbaseRepository.Bucket(), appConfig.CouchbaseDurability, couchbaseTimeouts)
+
+	// Fail-open audit writes go through a background queue and never fail a
+	// request; fail-closed ones must be stored before a change is made
+	var auditLog app.AuditLog = app.FailClosedAuditLog{AuditLog: auditStore}
+	var asyncAuditLog *app.AsyncAuditLog
+	if appConfig.Audit.Mode == "fail_open" {
+		asyncAuditLog = app.NewAsyncAuditLog(auditStore, appConfig.Audit.QueueSize)
+		auditLog = asyncAuditLog
+	}
+
+	storageQuotaBytes := int64(appConfig.VehicleStorageQuotaMB) << 20
 
 	// Vehicle handlers
-	createVehicleHandler := vehicle.NewCreateVehicleHandler(couchbaseRepository)
+	createVehicleHandler := vehicle.NewCreateVehicleHandler(couchbaseRepository, vehicle.DuplicatePlatePolicy(appConfig.DuplicatePlatePolicy), auditLog)
+	importVehiclesHandler := vehicle.NewImportVehiclesHandler(createVehicleHandler)
 	getVehicleHandler := vehicle.NewGetVehicleHandler(couchbaseRepository)
-	updateVehicleHandler := vehicle.NewUpdateVehicleHandler(couchbaseRepository)
-	addDocumentHandler := vehicle.NewAddDocumentHandler(couchbaseRepository, storageService)
+	var enforcedStatuses []domain.VehicleStatus
+	if featureFlags.IsEnabled(features.StatusDocumentEnforcement) {
+		enforcedStatuses = enforcedDocumentStatuses(appConfig.StatusRequiredDocuments)
+	}
+	updateVehicleHandler := vehicle.NewUpdateVehicleHandler(couchbaseRepository, vehicle.DuplicatePlatePolicy(appConfig.DuplicatePlatePolicy), auditLog, enforcedStatuses)
+	deleteVehicleHandler := vehicle.NewDeleteVehicleHandler(couchbaseRepository, auditLog)
+	getVehicleAuditHandler := vehicle.NewGetVehicleAuditHandler(auditLog)
+	restoreVehicleHandler := vehicle.NewRestoreVehicleHandler(couchbaseRepository, eventPublisher, auditLog)
+	reportStolenHandler := vehicle.NewReportStolenHandler(couchbaseRepository, eventPublisher, auditLog)
+	upsertVehicleHandler := vehicle.NewUpsertVehicleHandler(couchbaseRepository, vehicle.DuplicatePlatePolicy(appConfig.DuplicatePlatePolicy), auditLog)
+	addDocumentHandler := vehicle.NewAddDocumentHandler(couchbaseRepository, storageService, storageQuotaBytes, auditLog)
+	createDocumentUploadHandler := vehicle.NewCreateDocumentUploadHandler(couchbaseRepository, storageService)
+	completeDocumentUploadHandler := vehicle.NewCompleteDocumentUploadHandler(couchbaseRepository, storageService, storageQuotaBytes, auditLog)
 	getDocumentHandler := vehicle.NewGetDocumentsHandler(couchbaseRepository)
-	deleteDocumentHandler := vehicle.NewDeleteDocumentHandler(couchbaseRepository, storageService)
+	getSingleDocumentHandler := vehicle.NewGetSingleDocumentHandler(couchbaseRepository)
+	getDocumentAlertsHandler := vehicle.NewGetDocumentAlertsHandler(couchbaseRepository)
+	getComplianceHandler := vehicle.NewGetComplianceHandler(couchbaseRepository)
+	getDocumentSummaryHandler := vehicle.NewGetDocumentSummaryHandler(couchbaseRepository)
+	deleteDocumentHandler := vehicle.NewDeleteDocumentHandler(couchbaseRepository, storageService, auditLog)
+	appendDocumentFileHandler := vehicle.NewAppendDocumentFileHandler(couchbaseRepository, storageService, storageQuotaBytes, auditLog)
 	downloadDocumentHandler := vehicle.NewDownloadDocumentHandler(couchbaseRepository, storageService)
-
-	// GPS handlers
-	getGPSDataHandler := gps.NewGetGPSDataHandler(cosmosRepository)
+	getVehicleArchiveHandler := vehicle.NewGetVehicleArchiveHandler(couchbaseRepository, storageService)
+	unknownOwnerNotFound := featureFlags.IsEnabled(features.UnknownOwnerNotFound)
+	getOwnerDocumentsHandler := vehicle.NewGetOwnerDocumentsHandler(couchbaseRepository, unknownOwnerNotFound)
+	searchVehiclesHandler := vehicle.NewSearchVehiclesHandler(couchbaseRepository)
+	getOwnerVehiclesHandler := vehicle.NewGetOwnerVehiclesHandler(couchbaseRepository, appConfig.OwnerVehiclesPageSize, unknownOwnerNotFound)
+	getRecentVehiclesHandler := vehicle.NewGetRecentVehiclesHandler(couchbaseRepository, unknownOwnerNotFound)
+	deletePicturesHandler := vehicle.NewDeletePicturesHandler(couchbaseRepository, storageService, auditLog)
+	getPictureCoverageHandler := vehicle.NewGetPictureCoverageHandler(couchbaseRepository)
+	getPictureHandler := vehicle.NewGetPictureHandler(couchbaseRepository, storageService)
+	bulkUploadPicturesHandler := vehicle.NewBulkUploadPicturesHandler(couchbaseRepository, storageService, storageQuotaBytes, auditLog)
+	getVehicleValuationHandler := vehicle.NewGetVehicleValuationHandler(couchbaseRepository, vehicle.DepreciationModel{
+		BasePrice:           appConfig.Valuation.BasePrice,
+		AnnualDepreciation:  appConfig.Valuation.AnnualDepreciation,
+		MileageDepreciation: appConfig.Valuation.MileageDepreciation,
+		ResidualFloor:       appConfig.Valuation.ResidualFloor,
+		VintageAppreciation: appConfig.Valuation.VintageAppreciation,
+	})
+	addServiceRecordHandler := vehicle.NewAddServiceRecordHandler(couchbaseRepository, auditLog)
+	getServiceRecordsHandler := vehicle.NewGetServiceRecordsHandler(couchbaseRepository)
+	getVehiclesByPlateHandler := vehicle.NewGetVehiclesByPlateHandler(couchbaseRepository)
+	getVehiclesByVINsHandler := vehicle.NewGetVehiclesByVINsHandler(couchbaseRepository)
+	bulkRenewInsuranceHandler := vehicle.NewBulkRenewInsuranceHandler(couchbaseRepository, auditLog)
+
+	// Vehicle jobs
+	expireVerificationsJob := vehicle.NewExpireVerificationsJob(couchbaseRepository, eventPublisher)
+	refreshStatusFlagsJob := vehicle.NewRefreshStatusFlagsJob(couchbaseRepository)
+	reconcileBlobsJob := vehicle.NewReconcileBlobsJob(couchbaseRepository, storageService,
+		time.Duration(appConfig.Jobs.OrphanBlobsGraceHours)*time.Hour, appConfig.Jobs.DeleteOrphanBlobs)
+	reconcileBlobsHandler := vehicle.NewReconcileBlobsHandler(reconcileBlobsJob)
 
 	app := fiber.New(fiber.Config{
+		BodyLimit:    appConfig.MaxBodySizeMB << 20,
 		IdleTimeout:  5 * time.Second,
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		Concurrency:  256 * 1024,
 	})
 
+	inFlight := &inFlightRequests{limit: int64(appConfig.MaxInFlightRequests)}
+
+	app.Use(inFlight.Middleware())
 	app.Use(RequestIDMiddleware())
 	app.Use(RequestDurationMiddleware())
+	app.Use(EnvelopeMiddleware(appConfig.ResponseEnvelope))
+	app.Use(inFlight.Limit())
+	app.Use(MaintenanceModeMiddleware(maintenanceMode, appConfig.Maintenance))
+	app.Use(RequestTimeoutMiddleware(time.Duration(appConfig.RequestTimeoutSeconds) * time.Second))
+	app.Use(ConsistentReadsMiddleware())
+	app.Use(APIKeyMiddleware(appConfig.ServiceAuth))
+
+	// JSON write routes; the multipart document upload is left out
+	requireJSON := JSONContentTypeMiddleware()
 
 	// Health check endpoint
 	app.Get("/healthcheck", handle[healthcheck.HealthCheckRequest, healthcheck.HealthCheckResponse](healthcheckHandler))
+	app.Get("/healthcheck/ready", handleFiberCtx[healthcheck.ReadinessRequest, healthcheck.ReadinessResponse](readinessHandler))
+
+	// Metrics published through expvar, for backend services only
+	app.Use("/debug/vars", RequireServiceMiddleware(), expvarmw.New())
+
+	// Admin endpoints
+	app.Get("/admin/maintenance", handle[maintenance.GetMaintenanceRequest, maintenance.MaintenanceResponse](getMaintenanceHandler))
+	app.Put("/admin/maintenance", requireJSON, handle[maintenance.SetMaintenanceRequest, maintenance.MaintenanceResponse](setMaintenanceHandler))
+	app.Post("/admin/blobs/reconcile", handle[vehicle.ReconcileBlobsRequest, vehicle.BlobReconciliation](reconcileBlobsHandler))
 
 	// Vehicle endpoints
-	app.Post("/vehicles", handle[vehicle.CreateVehicleRequest, vehicle.CreateVehicleResponse](createVehicleHandler))
+	app.Post("/vehicles", requireJSON, handle[vehicle.CreateVehicleRequest, vehicle.CreateVehicleResponse](createVehicleHandler))
+	app.Get("/vehicles/search", handle[vehicle.SearchVehiclesRequest, vehicle.SearchVehiclesResponse](searchVehiclesHandler))
 	app.Get("/vehicles/:id", handle[vehicle.GetVehicleRequest, vehicle.GetVehicleResponse](getVehicleHandler))
-	app.Put("/vehicles/:id", handle[vehicle.UpdateVehicleRequest, vehicle.UpdateVehicleResponse](updateVehicleHandler))
+	app.Put("/vehicles/:id", requireJSON, handle[vehicle.UpdateVehicleRequest, vehicle.UpdateVehicleResponse](updateVehicleHandler))
+	app.Delete("/vehicles/:id", handle[vehicle.DeleteVehicleRequest, vehicle.DeleteVehicleResponse](deleteVehicleHandler))
+	app.Get("/vehicles/:id/audit", handle[vehicle.GetVehicleAuditRequest, vehicle.GetVehicleAuditResponse](getVehicleAuditHandler))
+	app.Get("/vehicles/:id/compliance", handle[vehicle.GetComplianceRequest, vehicle.GetComplianceResponse](getComplianceHandler))
+	app.Get("/vehicles/:id/valuation", handle[vehicle.GetVehicleValuationRequest, vehicle.GetVehicleValuationResponse](getVehicleValuationHandler))
+	app.Post("/vehicles/:id/restore", requireJSON, handle[vehicle.RestoreVehicleRequest, vehicle.RestoreVehicleResponse](restoreVehicleHandler))
+	app.Post("/vehicles/:id/report-stolen", requireJSON, handle[vehicle.ReportStolenRequest, vehicle.ReportStolenResponse](reportStolenHandler))
+	app.Get("/vehicles/:id/archive", handleRaw[vehicle.GetVehicleArchiveRequest](getVehicleArchiveHandler))
+	app.Get("/vehicles/plate/:plate", handle[vehicle.GetVehiclesByPlateRequest, vehicle.GetVehiclesByPlateResponse](getVehiclesByPlateHandler))
+	app.Post("/vehicles/import", handleFiberCtx[vehicle.ImportVehiclesRequest, vehicle.ImportVehiclesResponse](importVehiclesHandler))
+	app.Post("/vehicles/by-vins", requireJSON, handle[vehicle.GetVehiclesByVINsRequest, vehicle.GetVehiclesByVINsResponse](getVehiclesByVINsHandler))
+	app.Put("/vehicles/vin/:vin", requireJSON, handleFiberCtx[vehicle.UpsertVehicleRequest, vehicle.UpsertVehicleResponse](upsertVehicleHandler))
 	app.Post("/vehicles/:id/documents", handleFiberCtx[vehicle.AddDocumentRequest, vehicle.AddDocumentResponse](addDocumentHandler))
 	app.Get("/vehicles/:id/documents", handleFiberCtx[vehicle.GetDocumentsRequest, vehicle.GetDocumentsResponse](getDocumentHandler))
+	app.Get("/vehicles/:id/documents/alerts", handle[vehicle.GetDocumentAlertsRequest, vehicle.GetDocumentAlertsResponse](getDocumentAlertsHandler))
+	app.Get("/vehicles/:id/documents/summary", handle[vehicle.GetDocumentSummaryRequest, vehicle.GetDocumentSummaryResponse](getDocumentSummaryHandler))
+	app.Get("/vehicles/:id/documents/:doc_id", handleFiberCtx[vehicle.GetSingleDocumentRequest, vehicle.DocumentResponse](getSingleDocumentHandler))
 	app.Get("/vehicles/:id/documents/:doc_id/download", handleRaw[vehicle.DownloadDocumentRequest](downloadDocumentHandler))
+	app.Post("/vehicles/:id/documents/:doc_id/files", handleFiberCtx[vehicle.AppendDocumentFileRequest, vehicle.AppendDocumentFileResponse](appendDocumentFileHandler))
 	app.Delete("/vehicles/:id/documents/:doc_id", handleFiberCtx[vehicle.DeleteDocumentRequest, vehicle.DeleteDocumentResponse](deleteDocumentHandler))
+	app.Post("/vehicles/:id/service", requireJSON, handle[vehicle.AddServiceRecordRequest, vehicle.AddServiceRecordResponse](addServiceRecordHandler))
+	app.Get("/vehicles/:id/service", handle[vehicle.GetServiceRecordsRequest, vehicle.GetServiceRecordsResponse](getServiceRecordsHandler))
+	app.Get("/vehicles/:id/pictures/coverage", handle[vehicle.GetPictureCoverageRequest, vehicle.GetPictureCoverageResponse](getPictureCoverageHandler))
+	app.Get("/vehicles/:id/pictures/:pic_id", handleFiberCtx[vehicle.GetPictureRequest, vehicle.GetPictureResponse](getPictureHandler))
+	app.Post("/vehicles/:id/pictures/bulk", handleFiberCtx[vehicle.BulkUploadPicturesRequest, vehicle.BulkUploadPicturesResponse](bulkUploadPicturesHandler))
+	app.Delete("/vehicles/:id/pictures", handleFiberCtx[vehicle.DeletePicturesRequest, vehicle.DeletePicturesResponse](deletePicturesHandler))
+
+	if featureFlags.IsEnabled(features.PresignedUploads) {
+		app.Post("/vehicles/:id/documents/upload-url", handleFiberCtx[vehicle.CreateDocumentUploadRequest, vehicle.CreateDocumentUploadResponse](createDocumentUploadHandler))
+		app.Post("/vehicles/:id/documents/:placeholder/complete", requireJSON, handleFiberCtx[vehicle.CompleteDocumentUploadRequest, vehicle.AddDocumentResponse](completeDocumentUploadHandler))
+	}
+
+	// Insurance endpoints
+	app.Post("/insurance/bulk-renew", requireJSON, handle[vehicle.BulkRenewInsuranceRequest, vehicle.BulkRenewInsuranceResponse](bulkRenewInsuranceHandler))
+
+	// Owner endpoints
+	app.Get("/owners/:owner_id/vehicles", handleFiberCtx[vehicle.GetOwnerVehiclesRequest, vehicle.GetOwnerVehiclesResponse](getOwnerVehiclesHandler))
+	app.Get("/owners/:owner_id/vehicles/recent", handleFiberCtx[vehicle.GetRecentVehiclesRequest, vehicle.GetRecentVehiclesResponse](getRecentVehiclesHandler))
+	app.Get("/owners/:owner_id/documents", handleFiberCtx[vehicle.GetOwnerDocumentsRequest, vehicle.GetOwnerDocumentsResponse](getOwnerDocumentsHandler))
 
 	// GPS endpoints
-	app.Get("/gps/data", handle[gps.GetGPSDataRequest, gps.GetGPSDataResponse](getGPSDataHandler))
+	if getGPSDataHandler != nil {
+		app.Get("/gps", handle[gps.GetGPSDataRequest, gps.GetGPSDataResponse](getGPSDataHandler))
+		// Kept for existing clients
+		app.Get("/gps/data", handle[gps.GetGPSDataRequest, gps.GetGPSDataResponse](getGPSDataHandler))
+		app.Delete("/gps", handle[gps.DeleteGPSDataRequest, gps.DeleteGPSDataResponse](deleteGPSDataHandler))
+	}
+
+	// Background jobs
+	jobScheduler := scheduler.New()
+	jobScheduler.Every("expire_document_verifications",
+		time.Duration(appConfig.Jobs.VerificationExpiryIntervalMinutes)*time.Minute,
+		expireVerificationsJob.Run,
+	)
+	jobScheduler.Every("refresh_status_flags",
+		time.Duration(appConfig.Jobs.StatusFlagsIntervalMinutes)*time.Minute,
+		refreshStatusFlagsJob.Run,
+	)
+	if storageService != nil {
+		jobScheduler.Every("reconcile_orphan_blobs",
+			time.Duration(appConfig.Jobs.OrphanBlobsIntervalMinutes)*time.Minute,
+			reconcileBlobsJob.Run,
+		)
+	}
+	jobScheduler.Start(context.Background())
 
 	// Start server in a goroutine
 	go func() {
@@ -231,22 +784,36 @@ func main() {
 
 	zap.L().Info("Server started on port", zap.String("port", appConfig.Port))
 
-	gracefulShutdown(app)
+	gracefulShutdown(app, jobScheduler, inFlight, asyncAuditLog, time.Duration(appConfig.ShutdownTimeoutSeconds)*time.Second)
 }
 
-func gracefulShutdown(app *fiber.App) {
+// gracefulShutdown waits for a signal, then drains requests and, when set, the queued audit entries
+func gracefulShutdown(app *fiber.App, jobScheduler *scheduler.Scheduler, inFlight *inFlightRequests, auditLog *app.AsyncAuditLog, timeout time.Duration) {
 	// Create channel for shutdown signals
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
 	// Wait for shutdown signal
 	<-sigChan
-	zap.L().Info("Shutting down server...")
+	zap.L().Info("Shutting down server...",
+		zap.Int64("in_flight_requests", inFlight.active.Load()),
+		zap.Duration("timeout", timeout),
+	)
 
-	// Shutdown with 5 second timeout
-	if err := app.ShutdownWithTimeout(5 * time.Second); err != nil {
+	// One deadline covers closing connections and draining in-flight requests
+	deadline := time.Now().Add(timeout)
+	if err := app.ShutdownWithTimeout(timeout); err != nil {
 		zap.L().Error("Error during server shutdown", zap.Error(err))
 	}
+	if active := inFlight.Wait(max(time.Until(deadline), 0)); active > 0 {
+		zap.L().Warn("Shutdown timeout reached with requests still in flight", zap.Int64("in_flight_requests", active))
+	}
+
+	jobScheduler.Stop()
+
+	if auditLog != nil {
+		auditLog.Close(max(time.Until(deadline), 0))
+	}
 
 	zap.L().Info("Server gracefully stopped")
 }
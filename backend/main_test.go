@@ -0,0 +1,521 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	expvarmw "github.com/gofiber/fiber/v2/middleware/expvar"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"microservicetest/app"
+	"microservicetest/domain"
+	"microservicetest/pkg/config"
+	apperrors "microservicetest/pkg/errors"
+	"microservicetest/pkg/log"
+)
+
+type blockingRequest struct{}
+
+type blockingResponse struct{}
+
+// blockingHandler stands in for a stuck downstream call: it waits until its context is done
+type blockingHandler struct {
+	ctxErr chan error
+}
+
+func (h *blockingHandler) Handle(ctx context.Context, req *blockingRequest) (*blockingResponse, error) {
+	select {
+	case <-ctx.Done():
+		h.ctxErr <- ctx.Err()
+		// Mirror how handlers wrap storage failures
+		return nil, apperrors.ErrInternalServer.WithCause(fmt.Errorf("failed to upload blob: %w", ctx.Err()))
+	case <-time.After(time.Second):
+		h.ctxErr <- nil
+		return &blockingResponse{}, nil
+	}
+}
+
+func TestRequestTimeoutMiddleware_CancelsHandlerContext(t *testing.T) {
+	handler := &blockingHandler{ctxErr: make(chan error, 1)}
+
+	app := fiber.New()
+	app.Use(RequestIDMiddleware())
+	app.Use(RequestTimeoutMiddleware(20 * time.Millisecond))
+	app.Get("/slow", handle[blockingRequest, blockingResponse](handler))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/slow", nil), 2000)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if ctxErr := <-handler.ctxErr; !errors.Is(ctxErr, context.DeadlineExceeded) {
+		t.Errorf("Expected handler context to hit its deadline, got %v", ctxErr)
+	}
+
+	if resp.StatusCode != fiber.StatusRequestTimeout {
+		t.Errorf("Expected status %d, got %d", fiber.StatusRequestTimeout, resp.StatusCode)
+	}
+
+	var body apperrors.ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.Error.Code != "REQUEST_TIMEOUT" {
+		t.Errorf("Expected code REQUEST_TIMEOUT, got %s", body.Error.Code)
+	}
+}
+
+func TestRequestIDMiddleware_InjectsRequestLogger(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	defer zap.ReplaceGlobals(zap.New(core))()
+
+	app := fiber.New()
+	app.Use(RequestIDMiddleware())
+	app.Use(RequestTimeoutMiddleware(time.Second))
+	app.Get("/ping", func(c *fiber.Ctx) error {
+		log.FromContext(c.UserContext()).Info("handling ping")
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/ping", nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	entries := logs.FilterMessage("handling ping").All()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 log entry, got %d", len(entries))
+	}
+	requestID := resp.Header.Get("X-Request-ID")
+	if got := entries[0].ContextMap()["request_id"]; got != requestID {
+		t.Errorf("Expected request_id %q, got %v", requestID, got)
+	}
+}
+
+type xmlRequest struct {
+	ID string `json:"id"`
+}
+
+type xmlResponse struct {
+	VehicleID string `json:"vehicle_id"`
+}
+
+type xmlHandler struct{}
+
+func (h *xmlHandler) Handle(ctx context.Context, req *xmlRequest) (*xmlResponse, error) {
+	if req.ID == "missing" {
+		return nil, apperrors.NewNotFoundError("vehicle", req.ID)
+	}
+	return &xmlResponse{VehicleID: req.ID}, nil
+}
+
+func TestHandle_XMLResponses(t *testing.T) {
+	server := fiber.New()
+	server.Use(RequestIDMiddleware())
+	server.Get("/vehicles/:id", handle[xmlRequest, xmlResponse](&xmlHandler{}))
+
+	tests := []struct {
+		path     string
+		status   int
+		contains string
+	}{
+		{"/vehicles/VEH_1", fiber.StatusOK, "<response><vehicle_id>VEH_1</vehicle_id></response>"},
+		{"/vehicles/missing", fiber.StatusNotFound, "<code>RESOURCE_NOT_FOUND</code>"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		req.Header.Set("Accept", "application/xml")
+		resp, err := server.Test(req)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if resp.StatusCode != tt.status {
+			t.Errorf("Expected status %d, got %d", tt.status, resp.StatusCode)
+		}
+		if got := resp.Header.Get("Content-Type"); !strings.HasPrefix(got, "application/xml") {
+			t.Errorf("Expected an XML content type, got %s", got)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if !strings.Contains(string(body), tt.contains) {
+			t.Errorf("Expected body to contain %s, got %s", tt.contains, body)
+		}
+	}
+}
+
+func TestHandle_ParseErrorShape(t *testing.T) {
+	server := fiber.New()
+	server.Use(RequestIDMiddleware())
+	server.Post("/vehicles/:id", handle[xmlRequest, xmlResponse](&xmlHandler{}))
+
+	req := httptest.NewRequest("POST", "/vehicles/VEH_1", strings.NewReader(`{"id":`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := server.Test(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", resp.StatusCode)
+	}
+
+	var body apperrors.ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Expected a JSON body, got %v", err)
+	}
+	if body.Error.Code != "INVALID_INPUT" {
+		t.Errorf("Expected code INVALID_INPUT, got %s", body.Error.Code)
+	}
+	if body.Error.RequestID == "" || body.Error.RequestID != resp.Header.Get("X-Request-ID") {
+		t.Errorf("Expected the request ID in the error, got %q", body.Error.RequestID)
+	}
+}
+
+func TestJSONContentTypeMiddleware(t *testing.T) {
+	app := fiber.New()
+	app.Use(RequestIDMiddleware())
+	app.Post("/json", JSONContentTypeMiddleware(), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		expected    int
+	}{
+		{"json", "application/json", `{"a":1}`, fiber.StatusNoContent},
+		{"json with charset", "application/json; charset=utf-8", `{"a":1}`, fiber.StatusNoContent},
+		{"form encoded", "application/x-www-form-urlencoded", "a=1", fiber.StatusUnsupportedMediaType},
+		{"missing content type", "", `{"a":1}`, fiber.StatusUnsupportedMediaType},
+		{"empty body", "", "", fiber.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/json", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if resp.StatusCode != tt.expected {
+				t.Errorf("Expected status %d, got %d", tt.expected, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	digest := func(key string) string {
+		sum := sha256.Sum256([]byte(key))
+		return hex.EncodeToString(sum[:])
+	}
+
+	server := fiber.New()
+	server.Use(RequestIDMiddleware())
+	server.Use(APIKeyMiddleware(config.ServiceAuthConfig{
+		APIKeys: []config.APIKeyConfig{
+			{Service: "billing", SHA256: digest("old-key")},
+			{Service: "billing", SHA256: digest("new-key")},
+		},
+		Routes: []string{"/internal/"},
+	}))
+	whoami := func(c *fiber.Ctx) error {
+		service, _ := app.ServiceFromContext(c.UserContext())
+		return c.SendString(service)
+	}
+	server.Get("/internal/sync", whoami)
+	server.Get("/vehicles", whoami)
+
+	tests := []struct {
+		name            string
+		path            string
+		apiKey          string
+		expectedStatus  int
+		expectedService string
+	}{
+		{"service route with current key", "/internal/sync", "new-key", fiber.StatusOK, "billing"},
+		{"service route with rotated out key", "/internal/sync", "old-key", fiber.StatusOK, "billing"},
+		{"service route without key", "/internal/sync", "", fiber.StatusUnauthorized, ""},
+		{"service route with wrong key", "/internal/sync", "guess", fiber.StatusUnauthorized, ""},
+		{"public route without key", "/vehicles", "", fiber.StatusOK, ""},
+		{"public route with key", "/vehicles", "new-key", fiber.StatusOK, "billing"},
+		{"public route with wrong key", "/vehicles", "guess", fiber.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.apiKey != "" {
+				req.Header.Set("X-API-Key", tt.apiKey)
+			}
+
+			resp, err := server.Test(req)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if resp.StatusCode != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+			if resp.StatusCode != fiber.StatusOK {
+				return
+			}
+
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != tt.expectedService {
+				t.Errorf("Expected service %q, got %q", tt.expectedService, body)
+			}
+		})
+	}
+}
+
+func TestRequireServiceMiddleware_DebugVars(t *testing.T) {
+	server := fiber.New()
+	server.Use(RequestIDMiddleware())
+	server.Use(func(c *fiber.Ctx) error {
+		if service := c.Get("X-Test-Service"); service != "" {
+			c.SetUserContext(app.WithService(c.UserContext(), service))
+		}
+		return c.Next()
+	})
+	server.Use("/debug/vars", RequireServiceMiddleware(), expvarmw.New())
+
+	tests := []struct {
+		name           string
+		service        string
+		expectedStatus int
+	}{
+		{"without service", "", fiber.StatusUnauthorized},
+		{"with service", "billing", fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/debug/vars", nil)
+			if tt.service != "" {
+				req.Header.Set("X-Test-Service", tt.service)
+			}
+
+			resp, err := server.Test(req)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestInFlightRequests_Wait(t *testing.T) {
+	inFlight := &inFlightRequests{}
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	server := fiber.New()
+	server.Use(inFlight.Middleware())
+	server.Get("/upload", func(c *fiber.Ctx) error {
+		close(started)
+		<-release
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	go server.Test(httptest.NewRequest("GET", "/upload", nil), -1)
+	<-started
+
+	if active := inFlight.Wait(10 * time.Millisecond); active != 1 {
+		t.Errorf("Expected 1 request still in flight after the timeout, got %d", active)
+	}
+
+	close(release)
+	if active := inFlight.Wait(time.Second); active != 0 {
+		t.Errorf("Expected no requests in flight once drained, got %d", active)
+	}
+}
+
+func TestInFlightRequests_Limit(t *testing.T) {
+	inFlight := &inFlightRequests{limit: 1}
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	server := fiber.New()
+	server.Use(inFlight.Middleware())
+	server.Use(inFlight.Limit())
+	server.Get("/upload", func(c *fiber.Ctx) error {
+		close(started)
+		<-release
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	server.Get("/healthcheck", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	server.Get("/vehicles", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	go server.Test(httptest.NewRequest("GET", "/upload", nil), -1)
+	<-started
+
+	resp, err := server.Test(httptest.NewRequest("GET", "/upload", nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != fiber.StatusServiceUnavailable {
+		t.Errorf("Expected 503 over the limit, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get(fiber.HeaderRetryAfter); got != "1" {
+		t.Errorf("Expected Retry-After: 1, got %q", got)
+	}
+
+	resp, err = server.Test(httptest.NewRequest("GET", "/healthcheck", nil))
+	if err != nil || resp.StatusCode != fiber.StatusOK {
+		t.Errorf("Expected health checks to pass over the limit, got %v (%v)", resp.StatusCode, err)
+	}
+
+	close(release)
+	inFlight.Wait(time.Second)
+	resp, err = server.Test(httptest.NewRequest("GET", "/vehicles", nil))
+	if err != nil || resp.StatusCode != fiber.StatusOK {
+		t.Errorf("Expected requests to pass once drained, got %v (%v)", resp.StatusCode, err)
+	}
+}
+
+func TestEnvelopeMiddleware(t *testing.T) {
+	server := fiber.New()
+	server.Use(RequestIDMiddleware())
+	server.Use(EnvelopeMiddleware(false))
+	server.Get("/vehicles/:id", handle[xmlRequest, xmlResponse](&xmlHandler{}))
+
+	tests := []struct {
+		name     string
+		path     string
+		envelope bool
+		check    func(body map[string]json.RawMessage) bool
+	}{
+		{"bare by default", "/vehicles/VEH_1", false, func(body map[string]json.RawMessage) bool {
+			return string(body["vehicle_id"]) == `"VEH_1"` && body["data"] == nil
+		}},
+		{"success", "/vehicles/VEH_1", true, func(body map[string]json.RawMessage) bool {
+			return string(body["data"]) == `{"vehicle_id":"VEH_1"}` && body["meta"] != nil && body["error"] == nil
+		}},
+		{"error", "/vehicles/missing", true, func(body map[string]json.RawMessage) bool {
+			return string(body["data"]) == "null" && strings.Contains(string(body["error"]), `"RESOURCE_NOT_FOUND"`)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.envelope {
+				req.Header.Set("X-Envelope", "true")
+			}
+			resp, err := server.Test(req)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			var body map[string]json.RawMessage
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("Expected a JSON body, got %v", err)
+			}
+			if !tt.check(body) {
+				t.Errorf("Unexpected body %v", body)
+			}
+			if tt.envelope && !strings.Contains(string(body["meta"]), resp.Header.Get("X-Request-ID")) {
+				t.Errorf("Expected meta to carry the request ID, got %s", body["meta"])
+			}
+		})
+	}
+}
+
+func TestMiddlewares_KeepBodyStreams(t *testing.T) {
+	var middlewaresDone atomic.Bool
+	var streamedLate atomic.Bool
+
+	server := fiber.New()
+	server.Use(func(c *fiber.Ctx) error {
+		err := c.Next()
+		middlewaresDone.Store(true)
+		return err
+	})
+	server.Use(RequestIDMiddleware())
+	server.Use(RequestDurationMiddleware())
+	server.Use(EnvelopeMiddleware(true))
+	// Streams like the vehicle archive route
+	server.Get("/vehicles/:id/archive", func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, "application/zip")
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			// Reading the body in a middleware runs the writer before it returns
+			streamedLate.Store(middlewaresDone.Load())
+			w.WriteString("zip")
+		})
+		return nil
+	})
+
+	resp, err := server.Test(httptest.NewRequest("GET", "/vehicles/VEH_1/archive", nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "zip" {
+		t.Errorf("Expected the streamed body, got %q", body)
+	}
+	if !streamedLate.Load() {
+		t.Error("Expected the body to be streamed after the middlewares returned, it was buffered")
+	}
+}
+
+func TestConsistentReadsMiddleware(t *testing.T) {
+	server := fiber.New()
+	server.Use(ConsistentReadsMiddleware())
+	server.Get("/vehicles", func(c *fiber.Ctx) error {
+		return c.SendString(strconv.FormatBool(app.ConsistentReads(c.UserContext())))
+	})
+
+	for query, expected := range map[string]string{
+		"":                  "false",
+		"?consistent=false": "false",
+		"?consistent=true":  "true",
+	} {
+		resp, err := server.Test(httptest.NewRequest("GET", "/vehicles"+query, nil))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if string(body) != expected {
+			t.Errorf("Expected consistent reads %s for %q, got %s", expected, query, body)
+		}
+	}
+}
+
+func TestEnforcedDocumentStatuses(t *testing.T) {
+	if statuses := enforcedDocumentStatuses(nil); len(statuses) != 1 || statuses[0] != domain.VehicleStatusSold {
+		t.Errorf("Expected an empty config to enforce sold, got %v", statuses)
+	}
+
+	statuses := enforcedDocumentStatuses([]config.StatusDocumentsConfig{
+		{Status: "sold", DocumentTypes: []string{"title"}},
+		{Status: "scrapped", DocumentTypes: []string{"title"}, Enforce: true},
+	})
+	if len(statuses) != 1 || statuses[0] != domain.VehicleStatusScrapped {
+		t.Errorf("Expected the configured statuses to replace the defaults, got %v", statuses)
+	}
+}
@@ -1,21 +1,434 @@
 package config
 
 import (
+	"encoding/hex"
 	"fmt"
+	"net/url"
+	"slices"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type AppConfig struct {
-	Port                  string `mapstructure:"port" yaml:"port"`
-	CouchbaseUrl          string `mapstructure:"couchbase_url" yaml:"couchbase_url"`
-	CouchbaseUsername     string `mapstructure:"couchbase_username" yaml:"couchbase_username"`
-	CouchbasePassword     string `mapstructure:"couchbase_password" yaml:"couchbase_password"`
-	AzureConnectionString string `mapstructure:"azure_connection_string" yaml:"azure_connection_string"`
-	CosmosDBEndpoint      string `mapstructure:"cosmosdb_endpoint" yaml:"cosmosdb_endpoint"`
-	CosmosDBKey           string `mapstructure:"cosmosdb_key" yaml:"cosmosdb_key"`
-	CosmosDBDatabase      string `mapstructure:"cosmosdb_database" yaml:"cosmosdb_database"`
-	CosmosDBContainer     string `mapstructure:"cosmosdb_container" yaml:"cosmosdb_container"`
+	Port                     string                    `mapstructure:"port" yaml:"port"`
+	CouchbaseUrl             string                    `mapstructure:"couchbase_url" yaml:"couchbase_url"`
+	CouchbaseUsername        string                    `mapstructure:"couchbase_username" yaml:"couchbase_username"`
+	CouchbasePassword        string                    `mapstructure:"couchbase_password" yaml:"couchbase_password"`
+	CouchbaseDurability      string                    `mapstructure:"couchbase_durability" yaml:"couchbase_durability"`
+	CouchbaseScanConsistency string                    `mapstructure:"couchbase_scan_consistency" yaml:"couchbase_scan_consistency"` // One of ScanConsistencies
+	CouchbaseTimeouts        CouchbaseTimeouts         `mapstructure:"couchbase_timeouts" yaml:"couchbase_timeouts"`
+	DuplicatePlatePolicy     string                    `mapstructure:"duplicate_plate_policy" yaml:"duplicate_plate_policy"` // One of DuplicatePlatePolicies
+	RequestTimeoutSeconds    int                       `mapstructure:"request_timeout_seconds" yaml:"request_timeout_seconds"`
+	ShutdownTimeoutSeconds   int                       `mapstructure:"shutdown_timeout_seconds" yaml:"shutdown_timeout_seconds"` // How long shutdown waits for in-flight requests
+	MaxInFlightRequests      int                       `mapstructure:"max_in_flight_requests" yaml:"max_in_flight_requests"`     // Requests handled at once before new ones get 503, 0 means unlimited
+	ResponseEnvelope         bool                      `mapstructure:"response_envelope" yaml:"response_envelope"`               // Wrap every JSON response, not only with X-Envelope: true
+	AzureConnectionString    string                    `mapstructure:"azure_connection_string" yaml:"azure_connection_string"`
+	StorageRequired          bool                      `mapstructure:"storage_required" yaml:"storage_required"`                 // Exit at startup when Blob Storage is unusable
+	MaxConcurrentUploads     int                       `mapstructure:"max_concurrent_uploads" yaml:"max_concurrent_uploads"`     // 0 means unlimited
+	MaxQueuedUploads         int                       `mapstructure:"max_queued_uploads" yaml:"max_queued_uploads"`             // Uploads waiting for a slot before new ones get 503
+	VehicleStorageQuotaMB    int                       `mapstructure:"vehicle_storage_quota_mb" yaml:"vehicle_storage_quota_mb"` // Documents and pictures per vehicle, 0 means unlimited
+	MaxBodySizeMB            int                       `mapstructure:"max_body_size_mb" yaml:"max_body_size_mb"`                 // Largest request body, bulk uploads need more than the default
+	Cosmos                   CosmosConfig              `mapstructure:",squash" yaml:",inline"`
+	GPSMaxQueryLimit         int                       `mapstructure:"gps_max_query_limit" yaml:"gps_max_query_limit"`
+	GPSMaxSpeedKmh           float64                   `mapstructure:"gps_max_speed_kmh" yaml:"gps_max_speed_kmh"`               // Faster jumps are outliers for GET /gps?filter_outliers=true
+	OwnerVehiclesPageSize    int                       `mapstructure:"owner_vehicles_page_size" yaml:"owner_vehicles_page_size"` // Default limit of GET /owners/:owner_id/vehicles
+	Jobs                     JobsConfig                `mapstructure:"jobs" yaml:"jobs"`
+	Readiness                ReadinessConfig           `mapstructure:"readiness" yaml:"readiness"`
+	Audit                    AuditConfig               `mapstructure:"audit" yaml:"audit"`
+	Valuation                ValuationConfig           `mapstructure:"valuation" yaml:"valuation"`
+	ExtraDocumentTypes       []string                  `mapstructure:"extra_document_types" yaml:"extra_document_types"`
+	RequiredDocumentTypes    []string                  `mapstructure:"required_document_types" yaml:"required_document_types"`     // Empty keeps the domain defaults
+	RequiredPictureTypes     []string                  `mapstructure:"required_picture_types" yaml:"required_picture_types"`       // Empty keeps the domain defaults
+	AllowedFileTypes         []FileTypeConfig          `mapstructure:"allowed_file_types" yaml:"allowed_file_types"`               // Empty keeps the domain defaults
+	MinPictureResolutions    []PictureResolutionConfig `mapstructure:"min_picture_resolutions" yaml:"min_picture_resolutions"`     // Empty keeps the domain defaults
+	StatusRequiredDocuments  []StatusDocumentsConfig   `mapstructure:"status_required_documents" yaml:"status_required_documents"` // Empty keeps the domain defaults
+	DefaultCurrency          string                    `mapstructure:"default_currency" yaml:"default_currency"`                   // Currency of insurance amounts stored as bare numbers, empty keeps TRY
+	MaxLookaheadDays         int                       `mapstructure:"max_lookahead_days" yaml:"max_lookahead_days"`               // Largest expiry lookahead queries accept
+	Maintenance              MaintenanceConfig         `mapstructure:"maintenance" yaml:"maintenance"`
+	ServiceAuth              ServiceAuthConfig         `mapstructure:"service_auth" yaml:"service_auth"`
+	Features                 map[string]bool           `mapstructure:"features" yaml:"features"` // See pkg/features for the names
+}
+
+// FileTypeConfig allows uploads of a MIME type and names their blobs with the
+// extension. It is a list rather than a map because viper splits keys on dots.
+type FileTypeConfig struct {
+	MimeType  string `mapstructure:"mime_type" yaml:"mime_type"`
+	Extension string `mapstructure:"extension" yaml:"extension"`
+}
+
+// PictureResolutionConfig is the smallest picture of a type uploads accept
+type PictureResolutionConfig struct {
+	Type   string `mapstructure:"type" yaml:"type"`
+	Width  int    `mapstructure:"width" yaml:"width"`
+	Height int    `mapstructure:"height" yaml:"height"`
+}
+
+// StatusDocumentsConfig lists the document types a vehicle needs in a status
+type StatusDocumentsConfig struct {
+	Status        string   `mapstructure:"status" yaml:"status"`
+	DocumentTypes []string `mapstructure:"document_types" yaml:"document_types"`
+	Enforce       bool     `mapstructure:"enforce" yaml:"enforce"` // Refuse status changes while documents are missing, with the status_document_enforcement feature
+}
+
+// MaintenanceConfig controls the maintenance-mode middleware. Enabled is only
+// the startup value, the admin endpoint can flip it at runtime.
+type MaintenanceConfig struct {
+	Enabled           bool     `mapstructure:"enabled" yaml:"enabled"`
+	RetryAfterSeconds int      `mapstructure:"retry_after_seconds" yaml:"retry_after_seconds"`
+	AllowedIPs        []string `mapstructure:"allowed_ips" yaml:"allowed_ips"`
+	AllowedPaths      []string `mapstructure:"allowed_paths" yaml:"allowed_paths"`
+}
+
+// ServiceAuthConfig holds the API keys backend services authenticate with.
+// Keys are stored as SHA-256 hex digests, never in plain text. A service may
+// have several keys at once so a key can be rotated without downtime.
+type ServiceAuthConfig struct {
+	APIKeys []APIKeyConfig `mapstructure:"api_keys" yaml:"api_keys"`
+	Routes  []string       `mapstructure:"routes" yaml:"routes"` // Path prefixes only services may call
+}
+
+type APIKeyConfig struct {
+	Service string `mapstructure:"service" yaml:"service"`
+	SHA256  string `mapstructure:"sha256" yaml:"sha256"`
+}
+
+// Validate checks that every key names its service and is a SHA-256 hex digest
+func (c ServiceAuthConfig) Validate() error {
+	for i, key := range c.APIKeys {
+		if key.Service == "" {
+			return fmt.Errorf("service_auth.api_keys[%d] is missing its service", i)
+		}
+		if digest, err := hex.DecodeString(key.SHA256); err != nil || len(digest) != 32 {
+			return fmt.Errorf("service_auth.api_keys[%d].sha256 must be a hex SHA-256 digest", i)
+		}
+	}
+
+	if len(c.Routes) > 0 && len(c.APIKeys) == 0 {
+		return fmt.Errorf("service_auth.routes are set but no api_keys are configured")
+	}
+
+	return nil
+}
+
+// CouchbaseTimeouts are durations such as "5s" or "500ms". Zero values get the defaults.
+type CouchbaseTimeouts struct {
+	KV      time.Duration `mapstructure:"kv_timeout" yaml:"kv_timeout"`
+	Query   time.Duration `mapstructure:"query_timeout" yaml:"query_timeout"`
+	Connect time.Duration `mapstructure:"connect_timeout" yaml:"connect_timeout"`
+}
+
+// Validate applies the defaults and rejects negative timeouts
+func (c *CouchbaseTimeouts) Validate() error {
+	for _, timeout := range []struct {
+		key          string
+		value        *time.Duration
+		defaultValue time.Duration
+	}{
+		{"kv_timeout", &c.KV, 5 * time.Second},
+		{"query_timeout", &c.Query, 10 * time.Second},
+		{"connect_timeout", &c.Connect, 10 * time.Second},
+	} {
+		if *timeout.value == 0 {
+			*timeout.value = timeout.defaultValue
+		}
+		if *timeout.value < 0 {
+			return fmt.Errorf("couchbase_timeouts.%s must be positive, got %s", timeout.key, *timeout.value)
+		}
+	}
+	return nil
+}
+
+// AuditConfig decides how audit writes behave when the audit store is slow or down
+type AuditConfig struct {
+	Mode      string `mapstructure:"mode" yaml:"mode"`             // One of AuditModes
+	QueueSize int    `mapstructure:"queue_size" yaml:"queue_size"` // Entries waiting to be written in fail_open mode
+}
+
+// AuditModes lists the accepted audit.mode values. fail_open writes in the
+// background and never fails a request; fail_closed writes each entry as
+// pending before its change and refuses the change when that write fails.
+var AuditModes = []string{"fail_open", "fail_closed"}
+
+// ValuationConfig parameterises the depreciation model behind
+// GET /vehicles/:id/valuation. Rates are fractions, zero values get the defaults.
+type ValuationConfig struct {
+	BasePrice           float64 `mapstructure:"base_price" yaml:"base_price"`                     // Value of a new vehicle
+	AnnualDepreciation  float64 `mapstructure:"annual_depreciation" yaml:"annual_depreciation"`   // Value lost per year of age, compounded
+	MileageDepreciation float64 `mapstructure:"mileage_depreciation" yaml:"mileage_depreciation"` // Value lost per 10,000 km
+	ResidualFloor       float64 `mapstructure:"residual_floor" yaml:"residual_floor"`             // Share of the base price a vehicle never drops below
+	VintageAppreciation float64 `mapstructure:"vintage_appreciation" yaml:"vintage_appreciation"` // Value gained per year past the vintage age, compounded
+}
+
+// Validate applies the defaults and keeps the rates between 0 and 1
+func (c *ValuationConfig) Validate() error {
+	if c.BasePrice == 0 {
+		c.BasePrice = 30000
+	}
+	if c.BasePrice < 0 {
+		return fmt.Errorf("valuation.base_price must be positive, got %v", c.BasePrice)
+	}
+	for _, rate := range []struct {
+		key          string
+		value        *float64
+		defaultValue float64
+	}{
+		{"annual_depreciation", &c.AnnualDepreciation, 0.15},
+		{"mileage_depreciation", &c.MileageDepreciation, 0.02},
+		{"residual_floor", &c.ResidualFloor, 0.1},
+		{"vintage_appreciation", &c.VintageAppreciation, 0.03},
+	} {
+		if *rate.value == 0 {
+			*rate.value = rate.defaultValue
+		}
+		if *rate.value < 0 || *rate.value >= 1 {
+			return fmt.Errorf("valuation.%s must be between 0 and 1, got %v", rate.key, *rate.value)
+		}
+	}
+	return nil
+}
+
+// JobsConfig sets how often background jobs run
+type JobsConfig struct {
+	VerificationExpiryIntervalMinutes int  `mapstructure:"verification_expiry_interval_minutes" yaml:"verification_expiry_interval_minutes"`
+	StatusFlagsIntervalMinutes        int  `mapstructure:"status_flags_interval_minutes" yaml:"status_flags_interval_minutes"`
+	OrphanBlobsIntervalMinutes        int  `mapstructure:"orphan_blobs_interval_minutes" yaml:"orphan_blobs_interval_minutes"`
+	OrphanBlobsGraceHours             int  `mapstructure:"orphan_blobs_grace_hours" yaml:"orphan_blobs_grace_hours"` // Younger files are never orphans
+	DeleteOrphanBlobs                 bool `mapstructure:"delete_orphan_blobs" yaml:"delete_orphan_blobs"`           // Scheduled runs only log orphans otherwise
+}
+
+// ReadinessConfig tunes GET /healthcheck/ready. A hard dependency being down
+// answers 503, any other only reports the instance as degraded.
+type ReadinessConfig struct {
+	HardDependencies []string `mapstructure:"hard_dependencies" yaml:"hard_dependencies"` // From ReadinessDependencies, empty means couchbase only
+	CheckTimeoutMs   int      `mapstructure:"check_timeout_ms" yaml:"check_timeout_ms"`
+}
+
+// ReadinessDependencies lists the dependencies the readiness probe checks
+var ReadinessDependencies = []string{"couchbase", "storage", "cosmos"}
+
+// Validate applies the defaults and rejects unknown dependencies
+func (c *ReadinessConfig) Validate() error {
+	if len(c.HardDependencies) == 0 {
+		c.HardDependencies = []string{"couchbase"}
+	}
+	for _, name := range c.HardDependencies {
+		if !slices.Contains(ReadinessDependencies, name) {
+			return fmt.Errorf("readiness.hard_dependencies must be among %v, got %q", ReadinessDependencies, name)
+		}
+	}
+	if c.CheckTimeoutMs == 0 {
+		c.CheckTimeoutMs = 2000
+	}
+	if c.CheckTimeoutMs < 0 {
+		return fmt.Errorf("readiness.check_timeout_ms must be positive, got %d", c.CheckTimeoutMs)
+	}
+	return nil
+}
+
+// CosmosConfig holds the Cosmos DB settings for GPS data. The keys stay at the
+// top level of the config file.
+type CosmosConfig struct {
+	Endpoint  string `mapstructure:"cosmosdb_endpoint" yaml:"cosmosdb_endpoint"`
+	Key       string `mapstructure:"cosmosdb_key" yaml:"cosmosdb_key"`
+	Database  string `mapstructure:"cosmosdb_database" yaml:"cosmosdb_database"`
+	Container string `mapstructure:"cosmosdb_container" yaml:"cosmosdb_container"`
+}
+
+// Configured reports whether any Cosmos DB setting is present
+func (c CosmosConfig) Configured() bool {
+	return c.Endpoint != "" || c.Key != "" || c.Database != "" || c.Container != ""
+}
+
+// Validate checks that a configured Cosmos DB has every setting and a usable endpoint
+func (c CosmosConfig) Validate() error {
+	missing := []string{}
+	for key, value := range map[string]string{
+		"cosmosdb_endpoint":  c.Endpoint,
+		"cosmosdb_key":       c.Key,
+		"cosmosdb_database":  c.Database,
+		"cosmosdb_container": c.Container,
+	} {
+		if value == "" {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		return fmt.Errorf("cosmos db is partially configured, missing %v", missing)
+	}
+
+	endpoint, err := url.Parse(c.Endpoint)
+	if err != nil || endpoint.Scheme != "https" && endpoint.Scheme != "http" || endpoint.Host == "" {
+		return fmt.Errorf("cosmosdb_endpoint must be an http(s) URL, got %q", c.Endpoint)
+	}
+
+	return nil
+}
+
+// DurabilityLevels lists the accepted couchbase_durability values
+var DurabilityLevels = []string{"none", "majority", "persistToMajority"}
+
+// ScanConsistencies lists the accepted couchbase_scan_consistency values.
+// request_plus queries wait for the index to include every earlier write;
+// not_bounded answers at once and may miss writes from the last moments.
+var ScanConsistencies = []string{"request_plus", "not_bounded"}
+
+// DuplicatePlatePolicies lists the accepted duplicate_plate_policy values: reject
+// fails the write, warn saves it with a warning and allow skips the check
+var DuplicatePlatePolicies = []string{"reject", "warn", "allow"}
+
+// MaxOwnerVehiclesPageSize caps owner_vehicles_page_size at the largest limit
+// GET /owners/:owner_id/vehicles accepts
+const MaxOwnerVehiclesPageSize = 100
+
+// Validate applies defaults and rejects values the app cannot run with
+func (c *AppConfig) Validate() error {
+	if c.CouchbaseDurability == "" {
+		c.CouchbaseDurability = "majority"
+	}
+	if !slices.Contains(DurabilityLevels, c.CouchbaseDurability) {
+		return fmt.Errorf("couchbase_durability must be one of %v, got %q", DurabilityLevels, c.CouchbaseDurability)
+	}
+	if c.CouchbaseScanConsistency == "" {
+		c.CouchbaseScanConsistency = "request_plus"
+	}
+	if !slices.Contains(ScanConsistencies, c.CouchbaseScanConsistency) {
+		return fmt.Errorf("couchbase_scan_consistency must be one of %v, got %q", ScanConsistencies, c.CouchbaseScanConsistency)
+	}
+
+	if c.DuplicatePlatePolicy == "" {
+		c.DuplicatePlatePolicy = "reject"
+	}
+	if !slices.Contains(DuplicatePlatePolicies, c.DuplicatePlatePolicy) {
+		return fmt.Errorf("duplicate_plate_policy must be one of %v, got %q", DuplicatePlatePolicies, c.DuplicatePlatePolicy)
+	}
+
+	if err := c.CouchbaseTimeouts.Validate(); err != nil {
+		return err
+	}
+
+	if c.Cosmos.Configured() {
+		if err := c.Cosmos.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.GPSMaxQueryLimit == 0 {
+		c.GPSMaxQueryLimit = 1000
+	}
+	if c.GPSMaxQueryLimit < 0 {
+		return fmt.Errorf("gps_max_query_limit must be positive, got %d", c.GPSMaxQueryLimit)
+	}
+
+	if c.GPSMaxSpeedKmh == 0 {
+		c.GPSMaxSpeedKmh = 300
+	}
+	if c.GPSMaxSpeedKmh < 0 {
+		return fmt.Errorf("gps_max_speed_kmh must be positive, got %v", c.GPSMaxSpeedKmh)
+	}
+
+	if c.MaxInFlightRequests < 0 {
+		return fmt.Errorf("max_in_flight_requests must not be negative, got %d", c.MaxInFlightRequests)
+	}
+	if c.MaxConcurrentUploads < 0 {
+		return fmt.Errorf("max_concurrent_uploads must not be negative, got %d", c.MaxConcurrentUploads)
+	}
+	if c.MaxQueuedUploads < 0 {
+		return fmt.Errorf("max_queued_uploads must not be negative, got %d", c.MaxQueuedUploads)
+	}
+	if c.VehicleStorageQuotaMB < 0 {
+		return fmt.Errorf("vehicle_storage_quota_mb must not be negative, got %d", c.VehicleStorageQuotaMB)
+	}
+	if c.MaxBodySizeMB == 0 {
+		c.MaxBodySizeMB = 4
+	}
+	if c.MaxBodySizeMB < 0 {
+		return fmt.Errorf("max_body_size_mb must be positive, got %d", c.MaxBodySizeMB)
+	}
+
+	if c.OwnerVehiclesPageSize == 0 {
+		c.OwnerVehiclesPageSize = 20
+	}
+	if c.OwnerVehiclesPageSize < 0 || c.OwnerVehiclesPageSize > MaxOwnerVehiclesPageSize {
+		return fmt.Errorf("owner_vehicles_page_size must be between 1 and %d, got %d", MaxOwnerVehiclesPageSize, c.OwnerVehiclesPageSize)
+	}
+
+	if c.MaxLookaheadDays == 0 {
+		c.MaxLookaheadDays = 365
+	}
+	if c.MaxLookaheadDays < 0 {
+		return fmt.Errorf("max_lookahead_days must be positive, got %d", c.MaxLookaheadDays)
+	}
+
+	if c.Jobs.VerificationExpiryIntervalMinutes == 0 {
+		c.Jobs.VerificationExpiryIntervalMinutes = 60
+	}
+	if c.Jobs.VerificationExpiryIntervalMinutes < 0 {
+		return fmt.Errorf("jobs.verification_expiry_interval_minutes must be positive, got %d", c.Jobs.VerificationExpiryIntervalMinutes)
+	}
+	if c.Jobs.StatusFlagsIntervalMinutes == 0 {
+		c.Jobs.StatusFlagsIntervalMinutes = 60
+	}
+	if c.Jobs.StatusFlagsIntervalMinutes < 0 {
+		return fmt.Errorf("jobs.status_flags_interval_minutes must be positive, got %d", c.Jobs.StatusFlagsIntervalMinutes)
+	}
+	if c.Jobs.OrphanBlobsIntervalMinutes == 0 {
+		c.Jobs.OrphanBlobsIntervalMinutes = 1440
+	}
+	if c.Jobs.OrphanBlobsIntervalMinutes < 0 {
+		return fmt.Errorf("jobs.orphan_blobs_interval_minutes must be positive, got %d", c.Jobs.OrphanBlobsIntervalMinutes)
+	}
+	if c.Jobs.OrphanBlobsGraceHours == 0 {
+		c.Jobs.OrphanBlobsGraceHours = 24
+	}
+	if c.Jobs.OrphanBlobsGraceHours < 0 {
+		return fmt.Errorf("jobs.orphan_blobs_grace_hours must be positive, got %d", c.Jobs.OrphanBlobsGraceHours)
+	}
+
+	if err := c.Readiness.Validate(); err != nil {
+		return err
+	}
+	if slices.Contains(c.Readiness.HardDependencies, "cosmos") && !c.Cosmos.Configured() {
+		return fmt.Errorf("readiness.hard_dependencies lists cosmos, but Cosmos DB is not configured")
+	}
+
+	if c.Audit.Mode == "" {
+		c.Audit.Mode = "fail_open"
+	}
+	if !slices.Contains(AuditModes, c.Audit.Mode) {
+		return fmt.Errorf("audit.mode must be one of %v, got %q", AuditModes, c.Audit.Mode)
+	}
+	if c.Audit.QueueSize == 0 {
+		c.Audit.QueueSize = 1000
+	}
+	if c.Audit.QueueSize < 0 {
+		return fmt.Errorf("audit.queue_size must be positive, got %d", c.Audit.QueueSize)
+	}
+
+	if err := c.Valuation.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.ServiceAuth.Validate(); err != nil {
+		return err
+	}
+
+	if c.RequestTimeoutSeconds == 0 {
+		c.RequestTimeoutSeconds = 30
+	}
+	if c.RequestTimeoutSeconds < 0 {
+		return fmt.Errorf("request_timeout_seconds must be positive, got %d", c.RequestTimeoutSeconds)
+	}
+
+	if c.ShutdownTimeoutSeconds == 0 {
+		c.ShutdownTimeoutSeconds = 5
+	}
+	if c.ShutdownTimeoutSeconds < 0 {
+		return fmt.Errorf("shutdown_timeout_seconds must be positive, got %d", c.ShutdownTimeoutSeconds)
+	}
+
+	return nil
 }
 
 func Read() *AppConfig {
@@ -36,5 +449,9 @@ func Read() *AppConfig {
 		panic(fmt.Errorf("fatal error unmarshalling config: %w", err))
 	}
 
+	if err := appConfig.Validate(); err != nil {
+		panic(fmt.Errorf("fatal error invalid config: %w", err))
+	}
+
 	return &appConfig
 }
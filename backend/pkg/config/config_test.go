@@ -0,0 +1,156 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCosmosConfig_Validate(t *testing.T) {
+	valid := CosmosConfig{
+		Endpoint:  "https://account.documents.azure.com:443/",
+		Key:       "key",
+		Database:  "trackly",
+		Container: "gps_data",
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(c *CosmosConfig)
+		wantErr bool
+	}{
+		{"complete", func(c *CosmosConfig) {}, false},
+		{"missing key", func(c *CosmosConfig) { c.Key = "" }, true},
+		{"missing container", func(c *CosmosConfig) { c.Container = "" }, true},
+		{"endpoint without scheme", func(c *CosmosConfig) { c.Endpoint = "account.documents.azure.com" }, true},
+		{"endpoint with other scheme", func(c *CosmosConfig) { c.Endpoint = "ftp://account" }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.modify(&cfg)
+
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAppConfig_Validate_CosmosOptional(t *testing.T) {
+	cfg := &AppConfig{}
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected config without Cosmos DB to be valid, got %v", err)
+	}
+
+	cfg.Cosmos.Endpoint = "https://account.documents.azure.com:443/"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected partially configured Cosmos DB to be rejected")
+	}
+}
+
+func TestAppConfig_Validate_OwnerVehiclesPageSize(t *testing.T) {
+	cfg := &AppConfig{}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cfg.OwnerVehiclesPageSize != 20 {
+		t.Errorf("Expected default page size 20, got %d", cfg.OwnerVehiclesPageSize)
+	}
+
+	cfg.OwnerVehiclesPageSize = MaxOwnerVehiclesPageSize + 1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected a page size above the maximum to be rejected")
+	}
+}
+
+func TestServiceAuthConfig_Validate(t *testing.T) {
+	digest := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+	tests := []struct {
+		name    string
+		cfg     ServiceAuthConfig
+		wantErr bool
+	}{
+		{"empty", ServiceAuthConfig{}, false},
+		{"rotated keys", ServiceAuthConfig{APIKeys: []APIKeyConfig{{"billing", digest}, {"billing", digest}}}, false},
+		{"missing service", ServiceAuthConfig{APIKeys: []APIKeyConfig{{"", digest}}}, true},
+		{"plain text key", ServiceAuthConfig{APIKeys: []APIKeyConfig{{"billing", "secret"}}}, true},
+		{"routes without keys", ServiceAuthConfig{Routes: []string{"/internal/"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAppConfig_Validate_CouchbaseTimeouts(t *testing.T) {
+	cfg := &AppConfig{}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cfg.CouchbaseTimeouts.KV != 5*time.Second || cfg.CouchbaseTimeouts.Query != 10*time.Second || cfg.CouchbaseTimeouts.Connect != 10*time.Second {
+		t.Errorf("Expected default timeouts, got %+v", cfg.CouchbaseTimeouts)
+	}
+
+	cfg.CouchbaseTimeouts.Query = -time.Second
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected a negative query timeout to be rejected")
+	}
+}
+
+func TestAppConfig_Validate_Valuation(t *testing.T) {
+	cfg := &AppConfig{}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cfg.Valuation.BasePrice != 30000 || cfg.Valuation.AnnualDepreciation != 0.15 || cfg.Valuation.ResidualFloor != 0.1 {
+		t.Errorf("Expected default valuation, got %+v", cfg.Valuation)
+	}
+
+	cfg.Valuation.AnnualDepreciation = 1.5
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an annual depreciation above 1 to be rejected")
+	}
+}
+
+func TestAppConfig_Validate_ScanConsistency(t *testing.T) {
+	cfg := &AppConfig{}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cfg.CouchbaseScanConsistency != "request_plus" {
+		t.Errorf("Expected request_plus by default, got %q", cfg.CouchbaseScanConsistency)
+	}
+
+	cfg.CouchbaseScanConsistency = "at_plus"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an unknown scan consistency to be rejected")
+	}
+}
+
+func TestAppConfig_Validate_Readiness(t *testing.T) {
+	cfg := &AppConfig{}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Readiness.HardDependencies, []string{"couchbase"}) || cfg.Readiness.CheckTimeoutMs != 2000 {
+		t.Errorf("Expected couchbase as the only hard dependency and a 2s timeout, got %+v", cfg.Readiness)
+	}
+
+	cfg.Readiness.HardDependencies = []string{"couchbase", "redis"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an unknown dependency to be rejected")
+	}
+
+	cfg.Readiness.HardDependencies = []string{"cosmos"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected cosmos to be rejected as hard while Cosmos DB is not configured")
+	}
+}
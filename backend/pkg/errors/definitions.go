@@ -33,6 +33,41 @@ var (
 		"Invalid ID format",
 		http.StatusBadRequest,
 	)
+
+	// ErrUnprocessableEntity is for well-formed input that breaks a business
+	// rule; ErrInvalidInput stays for input that is malformed
+	ErrUnprocessableEntity = New(
+		ErrorTypeValidation,
+		"UNPROCESSABLE_ENTITY",
+		"Input violates a business rule",
+		http.StatusUnprocessableEntity,
+	)
+)
+
+// Bad Request Errors
+var (
+	ErrUnsupportedMediaType = New(
+		ErrorTypeBadRequest,
+		"UNSUPPORTED_MEDIA_TYPE",
+		"Unsupported content type",
+		http.StatusUnsupportedMediaType,
+	)
+	// ErrStorageQuotaExceeded is returned when an upload would take a vehicle
+	// past its configured storage quota
+	ErrStorageQuotaExceeded = New(
+		ErrorTypeBadRequest,
+		"STORAGE_QUOTA_EXCEEDED",
+		"Storage quota exceeded",
+		http.StatusRequestEntityTooLarge,
+	)
+	// ErrPictureResolutionTooLow is returned when a picture is smaller than
+	// the minimum configured for its type
+	ErrPictureResolutionTooLow = New(
+		ErrorTypeValidation,
+		"PICTURE_RESOLUTION_TOO_LOW",
+		"Picture resolution is below the minimum for its type",
+		http.StatusBadRequest,
+	)
 )
 
 // Not Found Errors
@@ -112,6 +147,15 @@ var (
 		"Resource was modified by another request",
 		http.StatusConflict,
 	)
+
+	// ErrPreconditionFailed is for conditional requests, e.g. If-Unmodified-Since,
+	// whose condition no longer holds
+	ErrPreconditionFailed = New(
+		ErrorTypeConflict,
+		"PRECONDITION_FAILED",
+		"Resource was modified since the client last read it",
+		http.StatusPreconditionFailed,
+	)
 )
 
 // Internal Errors
@@ -137,6 +181,24 @@ var (
 		http.StatusInternalServerError,
 	)
 
+	// ErrDataCorruption is returned when a stored document no longer decodes,
+	// usually because it predates a schema change
+	ErrDataCorruption = New(
+		ErrorTypeInternal,
+		"DATA_CORRUPTION",
+		"Stored data could not be read",
+		http.StatusInternalServerError,
+	)
+
+	// ErrWriteAmbiguous is returned when the database cannot tell whether a
+	// write was applied, e.g. its durability requirement was not confirmed in time
+	ErrWriteAmbiguous = New(
+		ErrorTypeInternal,
+		"WRITE_AMBIGUOUS",
+		"Database write may not have been applied",
+		http.StatusInternalServerError,
+	)
+
 	ErrConfigurationError = New(
 		ErrorTypeInternal,
 		"CONFIGURATION_ERROR",
@@ -223,6 +285,14 @@ func NewValidationError(field, message string) *AppError {
 	})
 }
 
+// NewUnprocessableError creates a business rule violation for a field
+func NewUnprocessableError(field, message string) *AppError {
+	return ErrUnprocessableEntity.WithDetails(map[string]string{
+		"field":   field,
+		"message": message,
+	})
+}
+
 // NewNotFoundError creates a not found error for a specific resource
 func NewNotFoundError(resource, id string) *AppError {
 	return ErrResourceNotFound.WithDetails(map[string]string{
@@ -251,4 +321,12 @@ func NewDatabaseError(operation string, err error) *AppError {
 	return ErrDatabaseQuery.WithCause(err).WithDetails(map[string]string{
 		"operation": operation,
 	})
+}
+
+// NewDataCorruptionError reports a stored document that does not decode
+func NewDataCorruptionError(resource, id string, err error) *AppError {
+	return ErrDataCorruption.WithCause(err).WithDetails(map[string]string{
+		"resource": resource,
+		"id":       id,
+	})
 }
\ No newline at end of file
@@ -3,6 +3,7 @@ package errors
 import (
 	"errors"
 	"net/http"
+	"time"
 )
 
 // AppError represents a custom application error with additional context
@@ -13,6 +14,8 @@ type AppError struct {
 	HTTPStatus int       `json:"http_status"`
 	Details    any       `json:"details,omitempty"`
 	Cause      error     `json:"-"`
+	// RetryAfter is sent as the Retry-After header when set
+	RetryAfter time.Duration `json:"-"`
 }
 
 // ErrorType represents the category of error
@@ -65,6 +68,13 @@ func (e *AppError) WithCause(cause error) *AppError {
 	return &newErr
 }
 
+// WithRetryAfter tells clients how long to back off before retrying
+func (e *AppError) WithRetryAfter(d time.Duration) *AppError {
+	newErr := *e
+	newErr.RetryAfter = d
+	return &newErr
+}
+
 // New creates a new AppError
 func New(errorType ErrorType, code, message string, httpStatus int) *AppError {
 	return &AppError{
@@ -0,0 +1,64 @@
+package errors
+
+// DefaultLanguage is the language of the messages on the error definitions
+const DefaultLanguage = "en"
+
+// messageCatalog holds translated messages keyed by language and error code.
+// English lives on the definitions themselves, so only other locales are listed.
+var messageCatalog = map[string]map[string]string{
+	"tr": {
+		"INVALID_INPUT":                "Geçersiz veri gönderildi",
+		"MISSING_REQUIRED_FIELD":       "Zorunlu alan eksik",
+		"INVALID_FORMAT":               "Geçersiz format",
+		"INVALID_ID":                   "Geçersiz kimlik formatı",
+		"UNPROCESSABLE_ENTITY":         "Gönderilen veri bir iş kuralını ihlal ediyor",
+		"UNSUPPORTED_MEDIA_TYPE":       "Desteklenmeyen içerik türü",
+		"STORAGE_QUOTA_EXCEEDED":       "Depolama kotası aşıldı",
+		"PICTURE_RESOLUTION_TOO_LOW":   "Fotoğraf çözünürlüğü bu tür için gereken en düşük değerin altında",
+		"RESOURCE_NOT_FOUND":           "İstenen kaynak bulunamadı",
+		"PRODUCT_NOT_FOUND":            "Ürün bulunamadı",
+		"USER_NOT_FOUND":               "Kullanıcı bulunamadı",
+		"UNAUTHORIZED":                 "Kimlik doğrulaması gerekli",
+		"INVALID_TOKEN":                "Geçersiz veya süresi dolmuş token",
+		"FORBIDDEN":                    "Erişim reddedildi",
+		"INSUFFICIENT_PERMISSIONS":     "Bu işlem için yetkiniz yok",
+		"RESOURCE_EXISTS":              "Kaynak zaten mevcut",
+		"PRODUCT_EXISTS":               "Ürün zaten mevcut",
+		"CONCURRENT_MODIFICATION":      "Kaynak başka bir istek tarafından değiştirildi",
+		"PRECONDITION_FAILED":          "Kaynak son okunduğundan beri değiştirildi",
+		"INTERNAL_SERVER_ERROR":        "Sunucu hatası oluştu",
+		"DATABASE_CONNECTION_ERROR":    "Veritabanı bağlantısı başarısız",
+		"DATABASE_QUERY_ERROR":         "Veritabanı sorgusu başarısız",
+		"DATA_CORRUPTION":              "Kayıtlı veri okunamadı",
+		"WRITE_AMBIGUOUS":              "Veritabanı yazma işleminin uygulanıp uygulanmadığı bilinmiyor",
+		"CONFIGURATION_ERROR":          "Yapılandırma hatası",
+		"EXTERNAL_SERVICE_ERROR":       "Harici servis hatası",
+		"EXTERNAL_SERVICE_TIMEOUT":     "Harici servis zaman aşımına uğradı",
+		"EXTERNAL_SERVICE_UNAVAILABLE": "Harici servis kullanılamıyor",
+		"RATE_LIMIT_EXCEEDED":          "İstek limiti aşıldı",
+		"REQUEST_TIMEOUT":              "İstek zaman aşımına uğradı",
+		"OPERATION_TIMEOUT":            "İşlem zaman aşımına uğradı",
+		"SERVICE_UNAVAILABLE":          "Servis geçici olarak kullanılamıyor",
+		"MAINTENANCE_MODE":             "Servis bakım modunda",
+		"UNKNOWN_ERROR":                "Beklenmeyen bir hata oluştu",
+	},
+}
+
+// SupportedLanguages returns the languages error messages can be served in,
+// with the default first so it wins when the client has no preference
+func SupportedLanguages() []string {
+	languages := []string{DefaultLanguage}
+	for lang := range messageCatalog {
+		languages = append(languages, lang)
+	}
+	return languages
+}
+
+// LocalizedMessage returns the message for code in the given language,
+// falling back to the English message when no translation exists
+func LocalizedMessage(lang, code, fallback string) string {
+	if message, ok := messageCatalog[lang][code]; ok {
+		return message
+	}
+	return fallback
+}
@@ -1,50 +1,77 @@
 package errors
 
 import (
+	"context"
 	"errors"
+	"math"
+	"microservicetest/pkg/log"
+	"microservicetest/pkg/response"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 )
 
-// ErrorResponse represents the JSON structure for error responses
+// ErrorResponse represents the structure of error responses, sent as JSON or XML per the Accept header
 type ErrorResponse struct {
 	Error ErrorDetail `json:"error"`
 }
 
 // ErrorDetail contains the error information
 type ErrorDetail struct {
-	Type    ErrorType `json:"type"`
-	Code    string    `json:"code"`
-	Message string    `json:"message"`
-	Details any       `json:"details,omitempty"`
+	Type      ErrorType `json:"type"`
+	Code      string    `json:"code"`
+	Message   string    `json:"message"`
+	Details   any       `json:"details,omitempty"`
+	RequestID string    `json:"request_id,omitempty"` // Matches the X-Request-ID header and the server logs
 }
 
 // HandleError converts an error to an appropriate HTTP response
 func HandleError(c *fiber.Ctx, err error) error {
-	requestID := c.Locals("requestID")
-	if requestID == nil {
+	requestID, _ := c.Locals("requestID").(string)
+	if requestID == "" {
 		requestID = "unknown"
 	}
 
+	// Messages are localized, the code stays stable for clients to switch on
+	lang := c.AcceptsLanguages(SupportedLanguages()...)
+	if lang == "" {
+		lang = DefaultLanguage
+	}
+	c.Set(fiber.HeaderContentLanguage, lang)
+
+	// Downstream calls that ran past the request deadline surface as timeouts,
+	// whatever error they were wrapped in on the way up
+	if errors.Is(err, context.DeadlineExceeded) && GetErrorType(err) != ErrorTypeTimeout {
+		err = ErrRequestTimeout.WithCause(err)
+	}
+
 	var appErr *AppError
 	if errors.As(err, &appErr) {
 		// Log the error with context
-		logError(requestID.(string), c, appErr)
+		logError(c, appErr)
+
+		if appErr.RetryAfter > 0 {
+			// Retry-After is in whole seconds, round up so clients never retry early
+			seconds := int(math.Ceil(appErr.RetryAfter.Seconds()))
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+		}
 
 		// Return structured error response
-		return c.Status(appErr.HTTPStatus).JSON(ErrorResponse{
+		c.Status(appErr.HTTPStatus)
+		return response.Send(c, ErrorResponse{
 			Error: ErrorDetail{
-				Type:    appErr.Type,
-				Code:    appErr.Code,
-				Message: appErr.Message,
-				Details: appErr.Details,
+				Type:      appErr.Type,
+				Code:      appErr.Code,
+				Message:   LocalizedMessage(lang, appErr.Code, appErr.Message),
+				Details:   appErr.Details,
+				RequestID: requestID,
 			},
 		})
 	}
 
 	// Handle unknown errors
-	logError(requestID.(string), c, &AppError{
+	logError(c, &AppError{
 		Type:       ErrorTypeInternal,
 		Code:       "UNKNOWN_ERROR",
 		Message:    "An unexpected error occurred",
@@ -52,19 +79,20 @@ func HandleError(c *fiber.Ctx, err error) error {
 		Cause:      err,
 	})
 
-	return c.Status(500).JSON(ErrorResponse{
+	c.Status(500)
+	return response.Send(c, ErrorResponse{
 		Error: ErrorDetail{
-			Type:    ErrorTypeInternal,
-			Code:    "UNKNOWN_ERROR",
-			Message: "An unexpected error occurred",
+			Type:      ErrorTypeInternal,
+			Code:      "UNKNOWN_ERROR",
+			Message:   LocalizedMessage(lang, "UNKNOWN_ERROR", "An unexpected error occurred"),
+			RequestID: requestID,
 		},
 	})
 }
 
 // logError logs the error with appropriate level based on error type
-func logError(requestID string, c *fiber.Ctx, appErr *AppError) {
+func logError(c *fiber.Ctx, appErr *AppError) {
 	fields := []zap.Field{
-		zap.String("request_id", requestID),
 		zap.String("method", c.Method()),
 		zap.String("path", c.Path()),
 		zap.String("error_type", string(appErr.Type)),
@@ -80,23 +108,26 @@ func logError(requestID string, c *fiber.Ctx, appErr *AppError) {
 		fields = append(fields, zap.Error(appErr.Cause))
 	}
 
+	// The request-scoped logger already carries the request ID
+	logger := log.FromContext(c.UserContext())
+
 	// Log with appropriate level based on error type
 	switch appErr.Type {
-	case ErrorTypeValidation, ErrorTypeNotFound, ErrorTypeUnauthorized, ErrorTypeForbidden, ErrorTypeConflict:
+	case ErrorTypeValidation, ErrorTypeBadRequest, ErrorTypeNotFound, ErrorTypeUnauthorized, ErrorTypeForbidden, ErrorTypeConflict:
 		// Client errors - log as info/warn
-		zap.L().Warn("Client error", fields...)
+		logger.Warn("Client error", fields...)
 	case ErrorTypeInternal:
 		// Server errors - log as error
-		zap.L().Error("Server error", fields...)
+		logger.Error("Server error", fields...)
 	case ErrorTypeExternal, ErrorTypeTimeout, ErrorTypeUnavailable:
 		// External/infrastructure errors - log as warn
-		zap.L().Warn("External service error", fields...)
+		logger.Warn("External service error", fields...)
 	case ErrorTypeRateLimit:
 		// Rate limiting - log as info
-		zap.L().Info("Rate limit exceeded", fields...)
+		logger.Info("Rate limit exceeded", fields...)
 	default:
 		// Unknown error type - log as error
-		zap.L().Error("Unknown error type", fields...)
+		logger.Error("Unknown error type", fields...)
 	}
 }
 
@@ -132,4 +163,23 @@ func IsRetryable(err error) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+
+// NewItemError describes why one item of a bulk request failed. Validation
+// details that carry a message supply it in place of the generic one.
+func NewItemError(err error) *response.ItemError {
+	var appErr *AppError
+	if !errors.As(err, &appErr) {
+		appErr = ErrInternalServer
+	}
+
+	itemErr := &response.ItemError{
+		Code:    appErr.Code,
+		Message: appErr.Message,
+		Details: appErr.Details,
+	}
+	if details, ok := appErr.Details.(map[string]string); ok && details["message"] != "" {
+		itemErr.Message = details["message"]
+	}
+	return itemErr
+}
@@ -0,0 +1,59 @@
+package features
+
+import (
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Feature names, used as keys under `features:` in the config
+const (
+	PresignedUploads = "presigned_uploads"
+	// StatusDocumentEnforcement blocks status changes to statuses configured
+	// with enforce: true while required documents are missing
+	StatusDocumentEnforcement = "status_document_enforcement"
+	// UnknownOwnerNotFound answers 404 on owner queries for an owner no
+	// vehicle has ever belonged to, instead of an empty list
+	UnknownOwnerNotFound = "unknown_owner_not_found"
+)
+
+// Known lists every feature the code checks for
+var Known = []string{PresignedUploads, StatusDocumentEnforcement, UnknownOwnerNotFound}
+
+// Flags holds the features enabled for this environment. Features are off
+// unless the config turns them on, so new code can ship dark.
+type Flags struct {
+	enabled map[string]bool
+}
+
+// New builds the flag set from config. Unknown names are logged and kept,
+// so a typo shows up at startup instead of silently doing nothing.
+func New(config map[string]bool) *Flags {
+	enabled := make(map[string]bool, len(config))
+	for name, on := range config {
+		name = strings.ToLower(name)
+		if !slices.Contains(Known, name) {
+			zap.L().Warn("Unknown feature flag in config", zap.String("feature", name))
+		}
+		enabled[name] = on
+	}
+	return &Flags{enabled: enabled}
+}
+
+// IsEnabled reports whether the named feature is turned on
+func (f *Flags) IsEnabled(name string) bool {
+	return f.enabled[strings.ToLower(name)]
+}
+
+// Enabled returns the names of the enabled features, sorted
+func (f *Flags) Enabled() []string {
+	names := make([]string, 0, len(f.enabled))
+	for name, on := range f.enabled {
+		if on {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
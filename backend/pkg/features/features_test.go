@@ -0,0 +1,37 @@
+package features
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFlags(t *testing.T) {
+	flags := New(map[string]bool{
+		"Presigned_Uploads": true,
+		"websockets":        false,
+	})
+
+	if !flags.IsEnabled(PresignedUploads) {
+		t.Error("Expected presigned_uploads to be enabled")
+	}
+	if flags.IsEnabled("websockets") {
+		t.Error("Expected websockets to be disabled")
+	}
+	if flags.IsEnabled("hard_delete") {
+		t.Error("Expected a feature missing from config to be disabled")
+	}
+	if enabled := flags.Enabled(); !slices.Equal(enabled, []string{PresignedUploads}) {
+		t.Errorf("Expected [%s], got %v", PresignedUploads, enabled)
+	}
+}
+
+func TestFlags_Empty(t *testing.T) {
+	flags := New(nil)
+
+	if flags.IsEnabled(PresignedUploads) {
+		t.Error("Expected features to be off by default")
+	}
+	if enabled := flags.Enabled(); len(enabled) != 0 {
+		t.Errorf("Expected no enabled features, got %v", enabled)
+	}
+}
@@ -0,0 +1,30 @@
+package log
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestFromContext_FallsBackToGlobal(t *testing.T) {
+	if FromContext(context.Background()) != zap.L() {
+		t.Error("Expected the global logger without a request-scoped one")
+	}
+}
+
+func TestFromContext_ReturnsRequestLogger(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	ctx := WithLogger(context.Background(), zap.New(core).With(zap.String("request_id", "req-1")))
+
+	FromContext(ctx).Info("hello")
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 log entry, got %d", len(entries))
+	}
+	if entries[0].ContextMap()["request_id"] != "req-1" {
+		t.Errorf("Expected request_id req-1, got %v", entries[0].ContextMap())
+	}
+}
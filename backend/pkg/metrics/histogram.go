@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"encoding/json"
+	"expvar"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Histogram counts observations into buckets with fixed upper bounds. It is
+// published through expvar as {"count", "sum", "buckets"}, where each bucket
+// key is its upper bound and "+Inf" holds values above the last one.
+type Histogram struct {
+	mu     sync.Mutex
+	bounds []float64
+	counts []int64 // One per bound plus the +Inf bucket
+	count  int64
+	sum    float64
+}
+
+var _ expvar.Var = (*Histogram)(nil)
+
+// NewHistogram creates a histogram with the given bucket upper bounds and
+// publishes it under name
+func NewHistogram(name string, bounds ...float64) *Histogram {
+	h := newHistogram(bounds...)
+	expvar.Publish(name, h)
+	return h
+}
+
+func newHistogram(bounds ...float64) *Histogram {
+	bounds = append([]float64(nil), bounds...)
+	sort.Float64s(bounds)
+	return &Histogram{
+		bounds: bounds,
+		counts: make([]int64, len(bounds)+1),
+	}
+}
+
+// Observe records v in the first bucket whose upper bound is at least v
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.bounds, v)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.counts[i]++
+	h.count++
+	h.sum += v
+}
+
+func (h *Histogram) String() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	buckets := make(map[string]int64, len(h.counts))
+	for i, bound := range h.bounds {
+		buckets[strconv.FormatFloat(bound, 'g', -1, 64)] = h.counts[i]
+	}
+	buckets["+Inf"] = h.counts[len(h.bounds)]
+
+	data, _ := json.Marshal(struct {
+		Count   int64            `json:"count"`
+		Sum     float64          `json:"sum"`
+		Buckets map[string]int64 `json:"buckets"`
+	}{h.count, h.sum, buckets})
+	return string(data)
+}
@@ -0,0 +1,16 @@
+package metrics
+
+import "testing"
+
+func TestHistogram(t *testing.T) {
+	h := newHistogram(1, 0.1, 10)
+
+	for _, v := range []float64{0.05, 0.1, 0.5, 2, 20} {
+		h.Observe(v)
+	}
+
+	expected := `{"count":5,"sum":22.65,"buckets":{"+Inf":1,"0.1":2,"1":1,"10":1}}`
+	if got := h.String(); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
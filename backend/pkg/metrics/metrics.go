@@ -0,0 +1,32 @@
+package metrics
+
+import "expvar"
+
+// Counters are published through expvar and served as JSON on /debug/vars
+var (
+	// Lookups of idempotency keys that found a stored outcome and that did not
+	IdempotencyHits   = expvar.NewInt("idempotency_hits")
+	IdempotencyMisses = expvar.NewInt("idempotency_misses")
+
+	// Requests being handled and those shed over max_in_flight_requests
+	RequestsInFlight = expvar.NewInt("requests_in_flight")
+	RequestsShed     = expvar.NewInt("requests_shed")
+
+	// Uploads through app.LimitedStorage
+	UploadsInFlight  = expvar.NewInt("uploads_in_flight")
+	UploadQueueDepth = expvar.NewInt("upload_queue_depth")
+	UploadsRejected  = expvar.NewInt("uploads_rejected")
+
+	// Audit entries written through app.AsyncAuditLog
+	AuditQueueDepth     = expvar.NewInt("audit_queue_depth")
+	AuditEntriesDropped = expvar.NewInt("audit_entries_dropped") // Queue full or audit store failed
+
+	// Azure Blob Storage calls, counted as "<operation>.ok" or "<operation>.failed"
+	BlobOperations = expvar.NewMap("blob_operations")
+)
+
+// Histograms of Azure Blob Storage calls over all operations
+var (
+	BlobDurationSeconds = NewHistogram("blob_duration_seconds", 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30)
+	BlobTransferBytes   = NewHistogram("blob_transfer_bytes", 1<<10, 16<<10, 256<<10, 1<<20, 4<<20, 16<<20, 64<<20)
+)
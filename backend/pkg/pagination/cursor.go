@@ -0,0 +1,48 @@
+package pagination
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// ErrInvalidCursor is returned for cursors that were not produced by Encode
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor marks the last row of a keyset page by its sort value and ID. The
+// next page starts right after it, however many rows were added before it.
+type Cursor struct {
+	Value any    `json:"v"` // string or number, nil when the row has no sort value
+	ID    string `json:"id"`
+}
+
+// Encode returns the cursor as opaque URL-safe base64
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode parses a cursor from Encode. Anything else, including cursors with
+// extra fields, a missing ID or a sort value that is not a string or number,
+// is rejected with ErrInvalidCursor.
+func Decode(s string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var c Cursor
+	if err := dec.Decode(&c); err != nil || dec.More() || c.ID == "" {
+		return Cursor{}, ErrInvalidCursor
+	}
+	switch c.Value.(type) {
+	case string, float64, nil:
+	default:
+		return Cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
@@ -0,0 +1,47 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	for _, c := range []Cursor{
+		{Value: "2026-01-02T03:04:05.123456789Z", ID: "VEH_1"},
+		{Value: float64(1767225600000), ID: "DOC_1"},
+		{Value: nil, ID: "DOC_2"},
+	} {
+		got, err := Decode(c.Encode())
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if got != c {
+			t.Errorf("Expected %+v, got %+v", c, got)
+		}
+	}
+}
+
+func TestDecode_RejectsTampered(t *testing.T) {
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "!!!"},
+		{"not JSON", encode("VEH_1")},
+		{"missing ID", encode(`{"v":"2026-01-02T03:04:05Z"}`)},
+		{"unknown field", encode(`{"v":"x","id":"VEH_1","offset":10}`)},
+		{"object value", encode(`{"v":{"$gt":0},"id":"VEH_1"}`)},
+		{"trailing data", encode(`{"v":"x","id":"VEH_1"}{}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(tt.cursor); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("Expected ErrInvalidCursor, got %v", err)
+			}
+		})
+	}
+}
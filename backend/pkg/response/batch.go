@@ -0,0 +1,43 @@
+package response
+
+import "github.com/gofiber/fiber/v2"
+
+// Item statuses of a batch response
+const (
+	ItemSucceeded = "succeeded"
+	ItemFailed    = "failed"
+	ItemSkipped   = "skipped" // Nothing to do for the item, e.g. it exists already
+)
+
+// ItemResult reports one item of a bulk request. Bulk responses list one per
+// item, in request order.
+type ItemResult struct {
+	Index  int        `json:"index"`           // Position of the item in the request, from 0
+	ID     string     `json:"id,omitempty"`    // ID of the item, as sent or as created
+	Status string     `json:"status"`          // succeeded, failed or skipped
+	Error  *ItemError `json:"error,omitempty"` // Set when the item failed
+}
+
+// ItemError carries the same code, message and details an error response
+// would have for the item on its own
+type ItemError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details any    `json:"details,omitempty"`
+}
+
+// Batch is implemented by the responses of bulk endpoints
+type Batch interface {
+	ItemResults() []ItemResult
+}
+
+// BatchStatus is 200 OK when no item failed and 207 Multi-Status when any
+// did, in which case clients reconcile the batch from the item results
+func BatchStatus(results []ItemResult) int {
+	for _, result := range results {
+		if result.Status == ItemFailed {
+			return fiber.StatusMultiStatus
+		}
+	}
+	return fiber.StatusOK
+}
@@ -0,0 +1,38 @@
+package response
+
+import "encoding/json"
+
+// Envelope is the uniform body clients opt into. Data holds the handler's
+// response, Error the error detail of failed requests.
+type Envelope struct {
+	Data  json.RawMessage `json:"data"`
+	Meta  Meta            `json:"meta"`
+	Error json.RawMessage `json:"error,omitempty"`
+}
+
+type Meta struct {
+	RequestID string `json:"request_id"`
+}
+
+// Wrap puts a JSON response body into an Envelope. Error bodies are
+// {"error": ...}, so for failed requests that field becomes Envelope.Error
+// and data is null.
+func Wrap(body []byte, failed bool, requestID string) ([]byte, error) {
+	envelope := Envelope{Meta: Meta{RequestID: requestID}}
+
+	if !failed {
+		envelope.Data = body
+		return json.Marshal(envelope)
+	}
+
+	var errorBody struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(body, &errorBody); err != nil || errorBody.Error == nil {
+		// Not the usual error shape, keep it whole
+		envelope.Error = body
+	} else {
+		envelope.Error = errorBody.Error
+	}
+	return json.Marshal(envelope)
+}
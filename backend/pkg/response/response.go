@@ -0,0 +1,156 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// rootElement wraps every XML body, the JSON body is its content
+const rootElement = "response"
+
+// StatusCoder is implemented by responses sent with a status other than
+// 200 OK, such as 201 Created for a new resource
+type StatusCoder interface {
+	HTTPStatus() int
+}
+
+// Locator is implemented by responses for a newly created resource, whose
+// URL is sent in the Location header. An empty Location sends no header.
+type Locator interface {
+	Location() string
+}
+
+// Send writes v as XML when the client prefers application/xml and as JSON
+// otherwise. The XML is built from the JSON encoding, so both formats share
+// field names, omitempty rules and custom marshalers. A StatusCoder is sent
+// with its HTTPStatus and a Batch with its BatchStatus.
+func Send(c *fiber.Ctx, v any) error {
+	if locator, ok := v.(Locator); ok {
+		if location := locator.Location(); location != "" {
+			c.Location(location)
+		}
+	}
+	switch r := v.(type) {
+	case StatusCoder:
+		c.Status(r.HTTPStatus())
+	case Batch:
+		c.Status(BatchStatus(r.ItemResults()))
+	}
+
+	if c.Accepts(fiber.MIMEApplicationJSON, fiber.MIMEApplicationXML) != fiber.MIMEApplicationXML {
+		return c.JSON(v)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	body, err := FromJSON(data, rootElement)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationXMLCharsetUTF8)
+	return c.Send(body)
+}
+
+// FromJSON converts a JSON document to XML under a root element. Object keys
+// become element names, array items become <item> elements and null becomes
+// an empty element. Keys that are not valid XML names, such as arbitrary map
+// keys, become <entry key="..."> elements.
+func FromJSON(data []byte, root string) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+
+	if err := writeValue(dec, enc, root); err != nil {
+		return nil, err
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeValue reads the next JSON value from dec and writes it as one element
+func writeValue(dec *json.Decoder, enc *xml.Encoder, name string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	start := element(name)
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		for dec.More() {
+			child := "item"
+			if t == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				child = key.(string)
+			}
+			if err := writeValue(dec, enc, child); err != nil {
+				return err
+			}
+		}
+		// Closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+	case string:
+		err = enc.EncodeToken(xml.CharData(t))
+	case json.Number:
+		err = enc.EncodeToken(xml.CharData(t.String()))
+	case bool:
+		err = enc.EncodeToken(xml.CharData(strconv.FormatBool(t)))
+	case nil:
+	default:
+		err = fmt.Errorf("unexpected JSON token %v", tok)
+	}
+	if err != nil {
+		return err
+	}
+
+	return enc.EncodeToken(start.End())
+}
+
+func element(name string) xml.StartElement {
+	if isXMLName(name) {
+		return xml.StartElement{Name: xml.Name{Local: name}}
+	}
+	return xml.StartElement{
+		Name: xml.Name{Local: "entry"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: name}},
+	}
+}
+
+// isXMLName accepts the ASCII subset of XML names that JSON keys in this API use
+func isXMLName(name string) bool {
+	if name == "" || len(name) >= 3 && strings.EqualFold(name[:3], "xml") {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z':
+		case i > 0 && (r == '-' || r == '.' || '0' <= r && r <= '9'):
+		default:
+			return false
+		}
+	}
+	return true
+}
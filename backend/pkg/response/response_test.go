@@ -0,0 +1,205 @@
+package response
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestFromJSON(t *testing.T) {
+	data := `{"vehicle":{"id":"VEH_1","year":2020,"is_vintage":false,"deleted_at":null,"tags":["a","b"]},"types":{"registration":{"count":1},"1st":2}}`
+
+	got, err := FromJSON([]byte(data), "response")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := xml.Header + `<response>` +
+		`<vehicle><id>VEH_1</id><year>2020</year><is_vintage>false</is_vintage><deleted_at></deleted_at><tags><item>a</item><item>b</item></tags></vehicle>` +
+		`<types><registration><count>1</count></registration><entry key="1st">2</entry></types>` +
+		`</response>`
+	if string(got) != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, got)
+	}
+}
+
+func TestFromJSON_EscapesText(t *testing.T) {
+	got, err := FromJSON([]byte(`{"message":"<b> & \"c\""}`), "response")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var decoded struct {
+		Message string `xml:"message"`
+	}
+	if err := xml.Unmarshal(got, &decoded); err != nil {
+		t.Fatalf("Expected well-formed XML, got %v", err)
+	}
+	if decoded.Message != `<b> & "c"` {
+		t.Errorf("Expected the message to round trip, got %q", decoded.Message)
+	}
+}
+
+func TestSend_NegotiatesFormat(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		return Send(c, fiber.Map{"status": "OK"})
+	})
+
+	cases := []struct {
+		accept      string
+		contentType string
+		body        string
+	}{
+		{"", fiber.MIMEApplicationJSON, `{"status":"OK"}`},
+		{"*/*", fiber.MIMEApplicationJSON, `{"status":"OK"}`},
+		{"application/xml", fiber.MIMEApplicationXMLCharsetUTF8, xml.Header + `<response><status>OK</status></response>`},
+		{"application/json;q=0.5, application/xml", fiber.MIMEApplicationXMLCharsetUTF8, xml.Header + `<response><status>OK</status></response>`},
+	}
+
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", "/", nil)
+		if tc.accept != "" {
+			req.Header.Set(fiber.HeaderAccept, tc.accept)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if got := resp.Header.Get(fiber.HeaderContentType); got != tc.contentType {
+			t.Errorf("Accept %q: expected content type %s, got %s", tc.accept, tc.contentType, got)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if strings.TrimSpace(string(body)) != tc.body {
+			t.Errorf("Accept %q: expected body %s, got %s", tc.accept, tc.body, body)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		failed   bool
+		expected string
+	}{
+		{"success", `{"id":"VEH_1"}`, false, `{"data":{"id":"VEH_1"},"meta":{"request_id":"req-1"}}`},
+		{"list", `[1,2]`, false, `{"data":[1,2],"meta":{"request_id":"req-1"}}`},
+		{"error", `{"error":{"code":"RESOURCE_NOT_FOUND"}}`, true, `{"data":null,"meta":{"request_id":"req-1"},"error":{"code":"RESOURCE_NOT_FOUND"}}`},
+		{"parse error", `{"error":"unexpected EOF"}`, true, `{"data":null,"meta":{"request_id":"req-1"},"error":"unexpected EOF"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Wrap([]byte(tt.body), tt.failed, "req-1")
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSend_BatchStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []string
+		expected int
+	}{
+		{"all succeeded", []string{ItemSucceeded, ItemSucceeded}, fiber.StatusOK},
+		{"partial", []string{ItemSucceeded, ItemFailed}, fiber.StatusMultiStatus},
+		{"all failed", []string{ItemFailed}, fiber.StatusMultiStatus},
+		{"skipped", []string{ItemSucceeded, ItemSkipped}, fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batch := testBatch{}
+			for i, status := range tt.statuses {
+				batch.Results = append(batch.Results, ItemResult{Index: i, Status: status})
+			}
+
+			app := fiber.New()
+			app.Post("/", func(c *fiber.Ctx) error {
+				return Send(c, batch)
+			})
+			resp, err := app.Test(httptest.NewRequest("POST", "/", nil))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if resp.StatusCode != tt.expected {
+				t.Errorf("Expected status %d, got %d", tt.expected, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestSend_StatusCoder(t *testing.T) {
+	app := fiber.New()
+	app.Post("/created", func(c *fiber.Ctx) error {
+		return Send(c, &testCreated{ID: "VEH_1"})
+	})
+	app.Post("/plain", func(c *fiber.Ctx) error {
+		return Send(c, fiber.Map{"id": "VEH_1"})
+	})
+
+	for path, expected := range map[string]int{"/created": fiber.StatusCreated, "/plain": fiber.StatusOK} {
+		resp, err := app.Test(httptest.NewRequest("POST", path, nil))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if resp.StatusCode != expected {
+			t.Errorf("%s: expected status %d, got %d", path, expected, resp.StatusCode)
+		}
+	}
+}
+
+func TestSend_Locator(t *testing.T) {
+	app := fiber.New()
+	app.Post("/created", func(c *fiber.Ctx) error {
+		return Send(c, &testCreated{ID: "VEH_1"})
+	})
+	app.Post("/unchanged", func(c *fiber.Ctx) error {
+		return Send(c, &testCreated{})
+	})
+
+	for path, expected := range map[string]string{"/created": "/vehicles/VEH_1", "/unchanged": ""} {
+		resp, err := app.Test(httptest.NewRequest("POST", path, nil))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if got := resp.Header.Get(fiber.HeaderLocation); got != expected {
+			t.Errorf("%s: expected Location %q, got %q", path, expected, got)
+		}
+	}
+}
+
+type testCreated struct {
+	ID string `json:"id"`
+}
+
+func (r *testCreated) HTTPStatus() int {
+	return fiber.StatusCreated
+}
+
+func (r *testCreated) Location() string {
+	if r.ID == "" {
+		return ""
+	}
+	return "/vehicles/" + r.ID
+}
+
+type testBatch struct {
+	Results []ItemResult `json:"results"`
+}
+
+func (b testBatch) ItemResults() []ItemResult {
+	return b.Results
+}
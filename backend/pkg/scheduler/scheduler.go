@@ -0,0 +1,88 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Job is a unit of background work. Returned errors are logged, the job keeps its schedule.
+type Job func(ctx context.Context) error
+
+type entry struct {
+	name     string
+	interval time.Duration
+	job      Job
+}
+
+// Scheduler runs jobs on fixed intervals until stopped
+type Scheduler struct {
+	entries []entry
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// Every registers job to run once per interval. Register jobs before Start.
+func (s *Scheduler) Every(name string, interval time.Duration, job Job) {
+	s.entries = append(s.entries, entry{name: name, interval: interval, job: job})
+}
+
+// Start runs every registered job in its own goroutine, first after one interval
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	for _, e := range s.entries {
+		s.wg.Add(1)
+		go func(e entry) {
+			defer s.wg.Done()
+			s.loop(ctx, e)
+		}(e)
+	}
+}
+
+// Stop cancels running jobs and waits for them to return
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, e entry) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.run(ctx, e)
+		}
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, e entry) {
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			zap.L().Error("Scheduled job panicked", zap.String("job", e.name), zap.Any("panic", r))
+		}
+	}()
+
+	if err := e.job(ctx); err != nil {
+		zap.L().Error("Scheduled job failed", zap.String("job", e.name), zap.Error(err))
+		return
+	}
+
+	zap.L().Info("Scheduled job completed",
+		zap.String("job", e.name),
+		zap.Float64("duration_seconds", time.Since(start).Seconds()),
+	)
+}
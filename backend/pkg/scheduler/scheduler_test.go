@@ -0,0 +1,58 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScheduler_RunsJobsUntilStopped(t *testing.T) {
+	var runs, failures atomic.Int32
+
+	s := New()
+	s.Every("counter", 5*time.Millisecond, func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+	s.Every("failing", 5*time.Millisecond, func(ctx context.Context) error {
+		failures.Add(1)
+		return errors.New("boom")
+	})
+
+	s.Start(context.Background())
+	time.Sleep(50 * time.Millisecond)
+	s.Stop()
+
+	stopped := runs.Load()
+	if stopped < 2 {
+		t.Errorf("Expected the job to run repeatedly, got %d runs", stopped)
+	}
+	if failures.Load() < 2 {
+		t.Errorf("Expected a failing job to keep its schedule, got %d runs", failures.Load())
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if runs.Load() != stopped {
+		t.Error("Expected no runs after Stop")
+	}
+}
+
+func TestScheduler_RecoversFromPanics(t *testing.T) {
+	var runs atomic.Int32
+
+	s := New()
+	s.Every("panicking", 5*time.Millisecond, func(ctx context.Context) error {
+		runs.Add(1)
+		panic("boom")
+	})
+
+	s.Start(context.Background())
+	time.Sleep(30 * time.Millisecond)
+	s.Stop()
+
+	if runs.Load() < 2 {
+		t.Errorf("Expected the job to keep running after a panic, got %d runs", runs.Load())
+	}
+}
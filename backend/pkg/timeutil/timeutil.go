@@ -0,0 +1,23 @@
+package timeutil
+
+import (
+	apperrors "microservicetest/pkg/errors"
+	"time"
+)
+
+// ParseOptionalRFC3339 parses an optional RFC3339 input. An empty value is nil,
+// a malformed one is ErrInvalidFormat naming field.
+func ParseOptionalRFC3339(field, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, apperrors.ErrInvalidFormat.WithDetails(map[string]string{
+			"field":   field,
+			"message": "must be in RFC3339 format",
+		})
+	}
+	return &t, nil
+}
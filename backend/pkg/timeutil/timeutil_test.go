@@ -0,0 +1,48 @@
+package timeutil
+
+import (
+	"errors"
+	apperrors "microservicetest/pkg/errors"
+	"testing"
+	"time"
+)
+
+func TestParseOptionalRFC3339_Empty(t *testing.T) {
+	got, err := ParseOptionalRFC3339("expiry_date", "")
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got != nil {
+		t.Errorf("Expected nil time, got %v", got)
+	}
+}
+
+func TestParseOptionalRFC3339_Valid(t *testing.T) {
+	got, err := ParseOptionalRFC3339("expiry_date", "2025-03-01T10:30:00+02:00")
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := time.Date(2025, 3, 1, 8, 30, 0, 0, time.UTC)
+	if got == nil || !got.Equal(expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestParseOptionalRFC3339_Malformed(t *testing.T) {
+	for _, value := range []string{"2025-03-01", "01/03/2025", "tomorrow"} {
+		_, err := ParseOptionalRFC3339("issued_date", value)
+
+		if !errors.Is(err, apperrors.ErrInvalidFormat) {
+			t.Fatalf("Expected ErrInvalidFormat for %q, got %v", value, err)
+		}
+		var appErr *apperrors.AppError
+		if !errors.As(err, &appErr) {
+			t.Fatalf("Expected an AppError, got %T", err)
+		}
+		if details, _ := appErr.Details.(map[string]string); details["field"] != "issued_date" {
+			t.Errorf("Expected field issued_date, got %v", appErr.Details)
+		}
+	}
+}
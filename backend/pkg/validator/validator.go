@@ -2,6 +2,9 @@ package validator
 
 import (
 	"fmt"
+	"microservicetest/domain"
+	"reflect"
+	"slices"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
@@ -9,8 +12,49 @@ import (
 
 var validate *validator.Validate
 
+// enumTags maps custom validation tags to the canonical domain values they
+// accept, so request structs stay in sync with the constants
+var enumTags = map[string]func() []string{
+	"documenttype":    func() []string { return enumStrings(domain.AllDocumentTypes()) },
+	"picturetype":     func() []string { return enumStrings(domain.AllPictureTypes()) },
+	"fueltype":        func() []string { return enumStrings(domain.AllFuelTypes()) },
+	"vehiclestatus":   func() []string { return enumStrings(domain.AllVehicleStatuses()) },
+	"transmission":    func() []string { return enumStrings(domain.AllTransmissions()) },
+	"insurancestatus": func() []string { return enumStrings(domain.AllInsuranceStatuses()) },
+	"documentstatus":  func() []string { return enumStrings(domain.AllDocumentStatuses()) },
+}
+
 func init() {
 	validate = validator.New()
+
+	for tag, values := range enumTags {
+		registerEnum(tag, values)
+	}
+}
+
+// RegisterEnum adds a validation tag accepting the values returned by values,
+// for enums defined outside the domain package. Call it from an init function,
+// before any validation runs.
+func RegisterEnum(tag string, values func() []string) {
+	enumTags[tag] = values
+	registerEnum(tag, values)
+}
+
+func registerEnum(tag string, values func() []string) {
+	err := validate.RegisterValidation(tag, func(fl validator.FieldLevel) bool {
+		return slices.Contains(values(), fl.Field().String())
+	})
+	if err != nil {
+		panic(fmt.Sprintf("validator: register %s: %v", tag, err))
+	}
+}
+
+func enumStrings[T ~string](values []T) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = string(v)
+	}
+	return out
 }
 
 // Validate validates a struct and returns a formatted error if validation fails
@@ -33,10 +77,40 @@ func formatValidationErrors(errs validator.ValidationErrors) error {
 	return fmt.Errorf("%s", strings.Join(messages, "; "))
 }
 
+// FieldErrors validates a struct like Validate but returns one message per
+// invalid field, keyed by the field's name in the given struct tag (e.g. "form").
+// The map is empty when the struct is valid.
+func FieldErrors(s interface{}, tag string) (map[string]string, error) {
+	fields := map[string]string{}
+	err := validate.Struct(s)
+	if err == nil {
+		return fields, nil
+	}
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return nil, err
+	}
+
+	structType := reflect.Indirect(reflect.ValueOf(s)).Type()
+	for _, fieldErr := range validationErrors {
+		name := strings.ToLower(fieldErr.StructField())
+		if sf, ok := structType.FieldByName(fieldErr.StructField()); ok {
+			if tagged, _, _ := strings.Cut(sf.Tag.Get(tag), ","); tagged != "" {
+				name = tagged
+			}
+		}
+		fields[name] = describeFieldError(name, fieldErr)
+	}
+	return fields, nil
+}
+
 // formatFieldError formats a single field validation error
 func formatFieldError(err validator.FieldError) string {
-	field := strings.ToLower(err.Field())
-	
+	return describeFieldError(strings.ToLower(err.Field()), err)
+}
+
+// describeFieldError formats a field validation error under the given field name
+func describeFieldError(field string, err validator.FieldError) string {
 	switch err.Tag() {
 	case "required":
 		return fmt.Sprintf("%s is required", field)
@@ -62,7 +136,12 @@ func formatFieldError(err validator.FieldError) string {
 		return fmt.Sprintf("%s must be a valid URL", field)
 	case "uuid":
 		return fmt.Sprintf("%s must be a valid UUID", field)
+	case "datetime":
+		return fmt.Sprintf("%s must match the layout %s", field, err.Param())
 	default:
+		if values, ok := enumTags[err.Tag()]; ok {
+			return fmt.Sprintf("%s must be one of: %s", field, strings.Join(values(), " "))
+		}
 		return fmt.Sprintf("%s failed validation on '%s'", field, err.Tag())
 	}
 }
@@ -0,0 +1,134 @@
+package validator
+
+import (
+	"microservicetest/domain"
+	"strings"
+	"testing"
+)
+
+type enumRequest struct {
+	DocumentType string  `validate:"omitempty,documenttype"`
+	PictureType  string  `validate:"omitempty,picturetype"`
+	FuelType     string  `validate:"omitempty,fueltype"`
+	Status       *string `validate:"omitempty,vehiclestatus"`
+	Transmission string  `validate:"omitempty,transmission"`
+	Insurance    string  `validate:"omitempty,insurancestatus"`
+	Documents    string  `validate:"omitempty,documentstatus"`
+}
+
+func TestValidate_EnumTagsAcceptEveryConstant(t *testing.T) {
+	for _, docType := range domain.AllDocumentTypes() {
+		if err := Validate(&enumRequest{DocumentType: string(docType)}); err != nil {
+			t.Errorf("Expected document type %q to pass, got %v", docType, err)
+		}
+	}
+	for _, picType := range domain.AllPictureTypes() {
+		if err := Validate(&enumRequest{PictureType: string(picType)}); err != nil {
+			t.Errorf("Expected picture type %q to pass, got %v", picType, err)
+		}
+	}
+	for _, fuelType := range domain.AllFuelTypes() {
+		if err := Validate(&enumRequest{FuelType: string(fuelType)}); err != nil {
+			t.Errorf("Expected fuel type %q to pass, got %v", fuelType, err)
+		}
+	}
+	for _, status := range domain.AllVehicleStatuses() {
+		value := string(status)
+		if err := Validate(&enumRequest{Status: &value}); err != nil {
+			t.Errorf("Expected status %q to pass, got %v", status, err)
+		}
+	}
+	for _, transmission := range domain.AllTransmissions() {
+		if err := Validate(&enumRequest{Transmission: string(transmission)}); err != nil {
+			t.Errorf("Expected transmission %q to pass, got %v", transmission, err)
+		}
+	}
+	for _, status := range domain.AllInsuranceStatuses() {
+		if err := Validate(&enumRequest{Insurance: string(status)}); err != nil {
+			t.Errorf("Expected insurance status %q to pass, got %v", status, err)
+		}
+	}
+	for _, status := range domain.AllDocumentStatuses() {
+		if err := Validate(&enumRequest{Documents: string(status)}); err != nil {
+			t.Errorf("Expected document status %q to pass, got %v", status, err)
+		}
+	}
+}
+
+func TestValidate_EnumTagsRejectUnknownValues(t *testing.T) {
+	status := "borrowed"
+	cases := map[string]*enumRequest{
+		"documenttype":    {DocumentType: "passport"},
+		"picturetype":     {PictureType: "selfie"},
+		"fueltype":        {FuelType: "steam"},
+		"vehiclestatus":   {Status: &status},
+		"transmission":    {Transmission: "MANUAL"},
+		"insurancestatus": {Insurance: "lapsed"},
+		"documentstatus":  {Documents: "complete"},
+	}
+
+	for tag, req := range cases {
+		err := Validate(req)
+		if err == nil {
+			t.Errorf("Expected %s to reject the value", tag)
+			continue
+		}
+		if !strings.Contains(err.Error(), "must be one of: ") {
+			t.Errorf("Expected %s error to list the allowed values, got %v", tag, err)
+		}
+	}
+}
+
+func TestValidate_DocumentTypeFollowsRegisteredTypes(t *testing.T) {
+	domain.RegisterDocumentTypes("roadside_assistance")
+
+	if err := Validate(&enumRequest{DocumentType: "roadside_assistance"}); err != nil {
+		t.Errorf("Expected registered document type to pass, got %v", err)
+	}
+}
+
+func TestRegisterEnum(t *testing.T) {
+	RegisterEnum("testcolor", func() []string { return []string{"red", "blue"} })
+
+	type colorRequest struct {
+		Color string `validate:"omitempty,testcolor"`
+	}
+	if err := Validate(&colorRequest{Color: "red"}); err != nil {
+		t.Errorf("Expected red to pass, got %v", err)
+	}
+	err := Validate(&colorRequest{Color: "green"})
+	if err == nil || err.Error() != "color must be one of: red blue" {
+		t.Errorf("Expected green to be rejected with the allowed values, got %v", err)
+	}
+}
+
+func TestFieldErrors_KeysByTagName(t *testing.T) {
+	type formRequest struct {
+		FileSize int64  `form:"file_size" validate:"omitempty,gt=0"`
+		Name     string `form:"name" validate:"required"`
+		Untagged string `validate:"required"`
+	}
+
+	fields, err := FieldErrors(&formRequest{FileSize: -1}, "form")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := map[string]string{
+		"file_size": "file_size must be greater than 0",
+		"name":      "name is required",
+		"untagged":  "untagged is required",
+	}
+	if len(fields) != len(want) {
+		t.Errorf("Expected %d invalid fields, got %v", len(want), fields)
+	}
+	for field, message := range want {
+		if fields[field] != message {
+			t.Errorf("Expected %s to be %q, got %q", field, message, fields[field])
+		}
+	}
+
+	fields, err = FieldErrors(&formRequest{Name: "a", Untagged: "b"}, "form")
+	if err != nil || len(fields) != 0 {
+		t.Errorf("Expected a valid struct to have no field errors, got %v, %v", fields, err)
+	}
+}